@@ -0,0 +1,311 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/dbmedialab/go-graphql-client/internal/document"
+	"github.com/dbmedialab/go-graphql-client/jsonutil"
+)
+
+// Batch combines several queries, or several mutations, into a single
+// request, so that data that would take a round trip per query is fetched
+// in one. The root fields of each added query or mutation are aliased, and
+// its variables renamed, with a prefix unique to it, so the same one can be
+// added more than once.
+//
+// For example, two additions of
+//
+//	struct {
+//		CreateReview struct{ Stars Int } `graphql:"createReview(review: $review)"`
+//	}
+//
+// are sent as
+//
+//	mutation($b0_review:ReviewInput!$b1_review:ReviewInput!){b0_createReview:createReview(review:$b0_review){stars}b1_createReview:createReview(review:$b1_review){stars}}
+type Batch struct {
+	// StopOnInvalid controls what happens when an item's variables don't
+	// match the variables its mutation uses, or can't be declared. If true, nothing is sent and
+	// the item's error is returned. Otherwise, the item is left out, its
+	// Err is set, and the other items are sent.
+	StopOnInvalid bool
+
+	items []*BatchItem
+}
+
+// BatchItem is a query or mutation added to a Batch.
+type BatchItem struct {
+	// Err is set after the batch is executed if the item was left out
+	// because its variables were invalid, or if the server reported
+	// errors at paths under the item's root fields.
+	Err error
+
+	v         interface{}
+	variables map[string]interface{}
+	prefix    string
+}
+
+// Add adds query or mutation m, with its variables, to b. m should be a
+// pointer to struct that corresponds to the GraphQL schema; the item's part
+// of the response is populated into it.
+func (b *Batch) Add(m interface{}, variables map[string]interface{}) *BatchItem {
+	item := &BatchItem{v: m, variables: variables, prefix: fmt.Sprintf("b%d_", len(b.items))}
+	b.items = append(b.items, item)
+	return item
+}
+
+// MutateBatch executes the mutations in b as a single GraphQL request,
+// populating each item's part of the response into its mutation and setting
+// each item's Err. The returned error is non-nil only if the batch as a whole
+// failed: because of an invalid item when b.StopOnInvalid is set, a transport
+// problem, or server errors that can't be attributed to an item.
+//
+// The client's query options apply as they do to Mutate: items deeper than
+// MaxDepth allows are invalid, the batch fails if its cost is more than
+// MaxCost allows, and a Printer prints the document with b as its value.
+// Result hooks are called with the mutation of each item that was
+// populated, and an error a hook returns is set as the item's Err.
+func (c *Client) MutateBatch(ctx context.Context, b *Batch) error {
+	return c.batch(ctx, "mutation", b)
+}
+
+// QueryBatch executes the queries in b as a single GraphQL request, the
+// way MutateBatch executes mutations.
+func (c *Client) QueryBatch(ctx context.Context, b *Batch) error {
+	return c.batch(ctx, "query", b)
+}
+
+// batch executes the items of b as a single operation of type op.
+func (c *Client) batch(ctx context.Context, op string, b *Batch) error {
+	opts := newQueryOptions(c.withQueryOptions(nil))
+	var selections, args []string
+	variables := map[string]interface{}{}
+	var sent []*BatchItem
+	for _, item := range b.items {
+		item.Err = nil
+		sel, err := item.selection(opts)
+		if err == nil {
+			err = c.enums.check(item.variables)
+		}
+		var arg string
+		if err == nil {
+			arg, err = item.arguments(op, sel, opts)
+		}
+		if err != nil {
+			err = validationError(err)
+			if b.StopOnInvalid {
+				return err
+			}
+			item.Err = err
+			continue
+		}
+		selections = append(selections, sel)
+		args = append(args, arg)
+		for k, v := range item.variables {
+			variables[item.prefix+k] = v
+		}
+		sent = append(sent, item)
+	}
+	if len(sent) == 0 {
+		return validationError(fmt.Errorf("graphql: batch has no valid items"))
+	}
+
+	query := op + "{" + strings.Join(selections, "") + "}"
+	if len(variables) > 0 {
+		query = op + "(" + strings.Join(args, "") + "){" + strings.Join(selections, "") + "}"
+	}
+	values := make([]interface{}, len(sent))
+	for i, item := range sent {
+		values[i] = item.v
+	}
+	query, err := c.withFragments(query, values...)
+	if err != nil {
+		return validationError(err)
+	}
+	if err := checkCost(query, variables, opts); err != nil {
+		return validationError(err)
+	}
+	if opts.printer != nil {
+		if query, err = opts.printer.Print(query, b); err != nil {
+			return validationError(err)
+		}
+	}
+	in := Request{Query: query, Variables: c.inputVariables(variables)}
+	out, err := c.send(ctx, in)
+	if err != nil {
+		return err
+	}
+
+	var data map[string]json.RawMessage
+	if len(out.Data) > 0 {
+		if err := json.Unmarshal(out.Data, &data); err != nil {
+			return decodeError(err)
+		}
+	}
+	for _, item := range sent {
+		if data == nil {
+			// No data at all, for example because of a request-level error.
+			break
+		}
+		var buf bytes.Buffer
+		buf.WriteString("{")
+		first := true
+		for k, v := range data {
+			if !strings.HasPrefix(k, item.prefix) {
+				continue
+			}
+			if !first {
+				buf.WriteString(",")
+			}
+			first = false
+			key, _ := json.Marshal(strings.TrimPrefix(k, item.prefix))
+			buf.Write(key)
+			buf.WriteString(":")
+			buf.Write(v)
+		}
+		buf.WriteString("}")
+		if err := jsonutil.UnmarshalGraphQLOptions(buf.Bytes(), item.v, c.decodeOptions(nil)); err != nil {
+			item.Err = decodeError(err)
+		}
+	}
+
+	var unattributed errors
+	for _, e := range out.Errors {
+		item := itemForKey(sent, rootKey(e.Path))
+		if item == nil {
+			unattributed = append(unattributed, e)
+			continue
+		}
+		errs, _ := item.Err.(errors)
+		item.Err = append(errs, e)
+	}
+	for _, item := range sent {
+		if data == nil {
+			break
+		}
+		if !populated(item.Err) {
+			continue
+		}
+		for _, hook := range c.resultHooks {
+			if err := hook(ctx, in, item.v); err != nil {
+				item.Err = err
+				break
+			}
+		}
+	}
+	if len(unattributed) > 0 {
+		return unattributed
+	}
+	return nil
+}
+
+// itemForKey returns the item with the aliased root field key,
+// or nil if there isn't one.
+func itemForKey(items []*BatchItem, key string) *BatchItem {
+	if key == "" {
+		return nil
+	}
+	for _, item := range items {
+		if strings.HasPrefix(key, item.prefix) {
+			return item
+		}
+	}
+	return nil
+}
+
+// selection returns the item's aliased root fields, with variables renamed,
+// checking that the variables used and provided match.
+func (item *BatchItem) selection(opts *queryOptions) (string, error) {
+	t := reflect.TypeOf(item.v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return "", fmt.Errorf("graphql: batch item %T is not a struct", item.v)
+	}
+	var buf bytes.Buffer
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if isExcluded(f) {
+			continue
+		}
+		value, ok := fieldTag(f)
+		if (f.Anonymous && !ok) || strings.HasPrefix(strings.TrimSpace(value), "...") {
+			return "", fmt.Errorf("graphql: batch item %T: root field %s: embedded structs and fragments are not supported", item.v, f.Name)
+		}
+		name := opts.fieldName(f.Name)
+		key, field := name, name
+		if ok {
+			key, field = responseKey(f), unaliased(f)
+		}
+		io.WriteString(&buf, item.prefix+key+":"+field)
+		if err := writeQueryE(&buf, f.Type, reflect.Value{}, map[edge]int{}, nil, false, opts); err != nil {
+			return "", fmt.Errorf("graphql: batch item %T: %v", item.v, err)
+		}
+	}
+
+	if opts.maxDepth > 0 {
+		if err := checkDepth("{"+buf.String()+"}", opts.maxDepth); err != nil {
+			return "", fmt.Errorf("graphql: batch item %T: %s", item.v, strings.TrimPrefix(err.Error(), "graphql: "))
+		}
+	}
+	toks, err := document.Tokenize(buf.String())
+	if err != nil {
+		return "", fmt.Errorf("graphql: batch item %T: %v", item.v, err)
+	}
+	used := map[string]bool{}
+	for i := 1; i < len(toks); i++ {
+		if toks[i-1].Kind == document.Punctuator && toks[i-1].Value == "$" && toks[i].Kind == document.Name {
+			used[toks[i].Value] = true
+			toks[i].Value = item.prefix + toks[i].Value
+		}
+	}
+	for name := range used {
+		if _, ok := item.variables[name]; !ok {
+			return "", fmt.Errorf("graphql: batch item %T: variable $%s is used but not provided", item.v, name)
+		}
+	}
+	for name := range item.variables {
+		if !used[name] {
+			return "", fmt.Errorf("graphql: batch item %T: variable $%s is provided but not used", item.v, name)
+		}
+	}
+	return document.Compact(toks), nil
+}
+
+// arguments returns the declarations of the item's variables, renamed, for
+// its selection sel in an operation of type op.
+func (item *BatchItem) arguments(op, sel string, opts *queryOptions) (string, error) {
+	if len(item.variables) == 0 {
+		return "", nil
+	}
+	variables := make(map[string]interface{}, len(item.variables))
+	for k, v := range item.variables {
+		variables[item.prefix+k] = v
+	}
+	args, err := typedQueryArguments(op, "{"+sel+"}", variables, opts)
+	if err != nil {
+		return "", fmt.Errorf("graphql: batch item %T: %s", item.v, strings.TrimPrefix(err.Error(), "graphql: "))
+	}
+	return args, nil
+}
+
+// unaliased returns the field selection from the graphql tag of struct field
+// f, without any alias.
+// E.g., `graphql:"stars: rating(scale: 5)"` -> "rating(scale: 5)".
+func unaliased(f reflect.StructField) string {
+	value, _ := fieldTag(f)
+	head := value
+	if i := strings.IndexAny(head, "(@{"); i != -1 {
+		head = head[:i]
+	}
+	if i := strings.Index(head, ":"); i != -1 {
+		return strings.TrimSpace(value[i+1:])
+	}
+	return strings.TrimSpace(value)
+}
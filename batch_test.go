@@ -0,0 +1,372 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/dbmedialab/go-graphql-client"
+	"github.com/dbmedialab/go-graphql-client/introspection"
+)
+
+type ReviewInput struct {
+	Stars graphql.Int `json:"stars"`
+}
+
+type createReview struct {
+	CreateReview struct {
+		Stars graphql.Int
+	} `graphql:"createReview(review: $review)"`
+}
+
+type repository struct {
+	Repository struct {
+		StargazerCount graphql.Int
+	} `graphql:"repository(name: $name)"`
+}
+
+func TestClient_MutateBatch(t *testing.T) {
+	var gotQuery string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		body, _ := ioutil.ReadAll(req.Body)
+		var in struct{ Query string }
+		json.Unmarshal(body, &in)
+		gotQuery = in.Query
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{
+			"data": {
+				"b0_createReview": {"stars": 5},
+				"b2_createReview": null
+			},
+			"errors": [
+				{"message": "review rejected", "path": ["b2_createReview"]}
+			]
+		}`)
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}})
+
+	var m0, m1, m2 createReview
+	var b graphql.Batch
+	item0 := b.Add(&m0, map[string]interface{}{"review": ReviewInput{Stars: 5}})
+	item1 := b.Add(&m1, map[string]interface{}{"input": ReviewInput{Stars: 4}})
+	item2 := b.Add(&m2, map[string]interface{}{"review": ReviewInput{Stars: 1}})
+	if err := client.MutateBatch(context.Background(), &b); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := gotQuery, `mutation($b0_review:ReviewInput!$b2_review:ReviewInput!){b0_createReview:createReview(review:$b0_review){stars}b2_createReview:createReview(review:$b2_review){stars}}`; got != want {
+		t.Errorf("got query:\n%s\nwant:\n%s", got, want)
+	}
+	if item0.Err != nil {
+		t.Errorf("got item 0 error: %v, want: nil", item0.Err)
+	}
+	if got, want := m0.CreateReview.Stars, graphql.Int(5); got != want {
+		t.Errorf("got item 0 stars: %v, want: %v", got, want)
+	}
+	if got, want := item1.Err, "graphql: batch item *graphql_test.createReview: variable $review is used but not provided"; got == nil || got.Error() != want {
+		t.Errorf("got item 1 error: %v, want: %v", got, want)
+	}
+	if got, want := item2.Err, "review rejected"; got == nil || got.Error() != want {
+		t.Errorf("got item 2 error: %v, want: %v", got, want)
+	}
+}
+
+func TestClient_MutateBatch_fragments(t *testing.T) {
+	var gotQuery string
+	transport := graphql.TransportFunc(func(ctx context.Context, req graphql.Request) (*graphql.Response, error) {
+		gotQuery = req.Query
+		return &graphql.Response{Data: []byte(`{"b0_createUser": {"login": "gopher", "avatarUrl": "a.png"}}`)}, nil
+	})
+	client := graphql.NewPluggableClient(transport)
+	if err := client.RegisterFragment("userFields", "User", userFields{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.RegisterFragment("avatarFields", "Actor", avatarFields{}); err != nil {
+		t.Fatal(err)
+	}
+
+	var m struct {
+		CreateUser struct {
+			userFields `graphql:"...userFields"`
+		} `graphql:"createUser(login: \"gopher\")"`
+	}
+	var b graphql.Batch
+	b.Add(&m, nil)
+	if err := client.MutateBatch(context.Background(), &b); err != nil {
+		t.Fatal(err)
+	}
+	want := `mutation{b0_createUser:createUser(login:"gopher"){...userFields}}` + "\n" +
+		"fragment avatarFields on Actor{avatarUrl(size: 72)}\n" +
+		"fragment userFields on User{login,...avatarFields}"
+	if gotQuery != want {
+		t.Errorf("got query:\n%s\nwant:\n%s", gotQuery, want)
+	}
+	if got, want := m.CreateUser.AvatarURL, graphql.String("a.png"); got != want {
+		t.Errorf("got avatar URL: %v, want: %v", got, want)
+	}
+}
+
+func TestClient_MutateBatch_fieldNamer(t *testing.T) {
+	var gotQuery string
+	transport := graphql.TransportFunc(func(ctx context.Context, req graphql.Request) (*graphql.Response, error) {
+		gotQuery = req.Query
+		return &graphql.Response{Data: []byte(`{"b0_other_root": {"star_count": 3}}`)}, nil
+	})
+	client := graphql.NewPluggableClient(transport, graphql.WithFieldNamer(graphql.SnakeCase))
+
+	var m struct {
+		OtherRoot struct {
+			StarCount graphql.Int
+		}
+	}
+	var b graphql.Batch
+	b.Add(&m, nil)
+	if err := client.MutateBatch(context.Background(), &b); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := gotQuery, `mutation{b0_other_root:other_root{star_count}}`; got != want {
+		t.Errorf("got query:\n%s\nwant:\n%s", got, want)
+	}
+	if got, want := m.OtherRoot.StarCount, graphql.Int(3); got != want {
+		t.Errorf("got stars: %v, want: %v", got, want)
+	}
+}
+
+func TestClient_MutateBatch_stopOnInvalid(t *testing.T) {
+	sent := false
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		sent = true
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": {}}`)
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}})
+
+	var m0, m1 createReview
+	b := graphql.Batch{StopOnInvalid: true}
+	b.Add(&m0, map[string]interface{}{"review": ReviewInput{Stars: 5}})
+	b.Add(&m1, map[string]interface{}{"review": ReviewInput{Stars: 4}, "extra": graphql.Int(1)})
+	err := client.MutateBatch(context.Background(), &b)
+	if got, want := err, "graphql: batch item *graphql_test.createReview: variable $extra is provided but not used"; got == nil || got.Error() != want {
+		t.Errorf("got error: %v, want: %v", got, want)
+	}
+	if sent {
+		t.Error("batch was sent despite an invalid item")
+	}
+}
+
+func TestClient_MutateBatch_nilVariable(t *testing.T) {
+	var gotQuery string
+	transport := graphql.TransportFunc(func(ctx context.Context, req graphql.Request) (*graphql.Response, error) {
+		gotQuery = req.Query
+		return &graphql.Response{Data: []byte(`{"b1_createReview": {"stars": 4}}`)}, nil
+	})
+	client := graphql.NewPluggableClient(transport)
+
+	var m0, m1 createReview
+	var b graphql.Batch
+	item0 := b.Add(&m0, map[string]interface{}{"review": nil})
+	b.Add(&m1, map[string]interface{}{"review": ReviewInput{Stars: 4}})
+	if err := client.MutateBatch(context.Background(), &b); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := gotQuery, `mutation($b1_review:ReviewInput!){b1_createReview:createReview(review:$b1_review){stars}}`; got != want {
+		t.Errorf("got query:\n%s\nwant:\n%s", got, want)
+	}
+	if got, want := item0.Err, "graphql: batch item *graphql_test.createReview: panic declaring variables: runtime error: invalid memory address or nil pointer dereference"; got == nil || got.Error() != want {
+		t.Errorf("got item 0 error: %v, want: %v", got, want)
+	}
+	if got, want := m1.CreateReview.Stars, graphql.Int(4); got != want {
+		t.Errorf("got item 1 stars: %v, want: %v", got, want)
+	}
+}
+
+func TestClient_QueryBatch(t *testing.T) {
+	var gotQuery string
+	var gotVariables map[string]interface{}
+	transport := graphql.TransportFunc(func(ctx context.Context, req graphql.Request) (*graphql.Response, error) {
+		gotQuery, gotVariables = req.Query, req.Variables
+		return &graphql.Response{Data: []byte(`{
+			"b0_viewer": {"login": "gopher"},
+			"b1_repository": {"stargazerCount": 10},
+			"b2_repository": {"stargazerCount": 20}
+		}`)}, nil
+	})
+	var viewer struct {
+		Viewer struct {
+			Login graphql.String
+		}
+	}
+	var go1, tools repository
+	var b graphql.Batch
+	b.Add(&viewer, nil)
+	b.Add(&go1, map[string]interface{}{"name": graphql.String("go")})
+	b.Add(&tools, map[string]interface{}{"name": graphql.String("tools")})
+	if err := graphql.NewPluggableClient(transport).QueryBatch(context.Background(), &b); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := gotQuery, `query($b1_name:String!$b2_name:String!){b0_viewer:viewer{login}b1_repository:repository(name:$b1_name){stargazerCount}b2_repository:repository(name:$b2_name){stargazerCount}}`; got != want {
+		t.Errorf("got query:\n%s\nwant:\n%s", got, want)
+	}
+	if got, want := len(gotVariables), 2; got != want {
+		t.Errorf("got %d variables, want %d", got, want)
+	}
+	if got, want := viewer.Viewer.Login, graphql.String("gopher"); got != want {
+		t.Errorf("got login: %v, want: %v", got, want)
+	}
+	if got, want := go1.Repository.StargazerCount, graphql.Int(10); got != want {
+		t.Errorf("got go stars: %v, want: %v", got, want)
+	}
+	if got, want := tools.Repository.StargazerCount, graphql.Int(20); got != want {
+		t.Errorf("got tools stars: %v, want: %v", got, want)
+	}
+}
+
+func TestClient_QueryBatch_fragmentsAndFieldNamer(t *testing.T) {
+	var gotQuery string
+	transport := graphql.TransportFunc(func(ctx context.Context, req graphql.Request) (*graphql.Response, error) {
+		gotQuery = req.Query
+		return &graphql.Response{Data: []byte(`{
+			"b0_viewer": {"login": "gopher", "avatar_url": "a.png"},
+			"b1_rate_limit": {"remaining": 99}
+		}`)}, nil
+	})
+	client := graphql.NewPluggableClient(transport, graphql.WithFieldNamer(graphql.SnakeCase))
+	type avatar struct {
+		AvatarURL graphql.String
+	}
+	type user struct {
+		Login  graphql.String
+		avatar `graphql:"...avatar"`
+	}
+	if err := client.RegisterFragment("user", "User", user{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.RegisterFragment("avatar", "Actor", avatar{}); err != nil {
+		t.Fatal(err)
+	}
+
+	var viewer struct {
+		Viewer struct {
+			user `graphql:"...user"`
+		}
+	}
+	var limit struct {
+		RateLimit struct {
+			Remaining graphql.Int
+		}
+	}
+	var b graphql.Batch
+	b.Add(&viewer, nil)
+	b.Add(&limit, nil)
+	if err := client.QueryBatch(context.Background(), &b); err != nil {
+		t.Fatal(err)
+	}
+	want := `query{b0_viewer:viewer{...user}b1_rate_limit:rate_limit{remaining}}` + "\n" +
+		"fragment avatar on Actor{avatar_url}\n" +
+		"fragment user on User{login,...avatar}"
+	if gotQuery != want {
+		t.Errorf("got query:\n%s\nwant:\n%s", gotQuery, want)
+	}
+	if got, want := viewer.Viewer.AvatarURL, graphql.String("a.png"); got != want {
+		t.Errorf("got avatar URL: %v, want: %v", got, want)
+	}
+	if got, want := limit.RateLimit.Remaining, graphql.Int(99); got != want {
+		t.Errorf("got remaining: %v, want: %v", got, want)
+	}
+}
+
+func TestClient_QueryBatch_queryOptions(t *testing.T) {
+	schema, err := introspection.Parse([]byte(`{"__schema": {
+		"queryType": {"name": "Query"},
+		"types": [
+			{"kind": "OBJECT", "name": "Query", "fields": [
+				{"name": "repository", "args": [
+					{"name": "name", "type": {"kind": "NON_NULL", "ofType": {"kind": "SCALAR", "name": "String"}}}
+				], "type": {"kind": "OBJECT", "name": "Repository"}}
+			]}
+		]
+	}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var gotQuery string
+	transport := graphql.TransportFunc(func(ctx context.Context, req graphql.Request) (*graphql.Response, error) {
+		gotQuery = req.Query
+		return &graphql.Response{Data: []byte(`{
+			"b0_repository": {"stargazerCount": 10},
+			"b2_repository": {"stargazerCount": 0}
+		}`)}, nil
+	})
+	printer := graphql.PrinterFunc(func(doc string, v interface{}) (string, error) {
+		return fmt.Sprintf("# %T\n%s", v, doc), nil
+	})
+	noStars := graphql.ResultHook(func(ctx context.Context, req graphql.Request, v interface{}) error {
+		if r, ok := v.(*repository); ok && r.Repository.StargazerCount == 0 {
+			return fmt.Errorf("no stars")
+		}
+		return nil
+	})
+	client := graphql.NewPluggableClient(transport,
+		graphql.WithSchema(schema),
+		graphql.WithQueryOptions(graphql.MaxDepth(2), graphql.WithPrinter(printer)),
+		graphql.WithResultHooks(noStars),
+	)
+
+	var deep struct {
+		Viewer struct {
+			Repositories struct {
+				Nodes []struct {
+					Name graphql.String
+				}
+			}
+		}
+	}
+	var go1, empty repository
+	var b graphql.Batch
+	item0 := b.Add(&go1, map[string]interface{}{"name": "go"})
+	item1 := b.Add(&deep, nil)
+	item2 := b.Add(&empty, map[string]interface{}{"name": "empty"})
+	if err := client.QueryBatch(context.Background(), &b); err != nil {
+		t.Fatal(err)
+	}
+	want := "# *graphql.Batch\n" +
+		`query($b0_name:String!$b2_name:String!){b0_repository:repository(name:$b0_name){stargazerCount}b2_repository:repository(name:$b2_name){stargazerCount}}`
+	if gotQuery != want {
+		t.Errorf("got query:\n%s\nwant:\n%s", gotQuery, want)
+	}
+	if item0.Err != nil {
+		t.Errorf("got item 0 error: %v, want: nil", item0.Err)
+	}
+	if got, want := item1.Err, "graphql: batch item *struct { Viewer struct { Repositories struct { Nodes []struct { Name graphql.String } } } }: selection of repositories is deeper than the maximum depth of 2"; got == nil || got.Error() != want {
+		t.Errorf("got item 1 error: %v, want: %v", got, want)
+	}
+	if got, want := item2.Err, "no stars"; got == nil || got.Error() != want {
+		t.Errorf("got item 2 error: %v, want: %v", got, want)
+	}
+}
+
+func TestClient_QueryBatch_maxCost(t *testing.T) {
+	sent := false
+	transport := graphql.TransportFunc(func(ctx context.Context, req graphql.Request) (*graphql.Response, error) {
+		sent = true
+		return &graphql.Response{Data: []byte(`{}`)}, nil
+	})
+	client := graphql.NewPluggableClient(transport, graphql.WithQueryOptions(graphql.MaxCost(&graphql.CostModel{}, 3)))
+
+	var b graphql.Batch
+	for i := 0; i < 2; i++ {
+		var r repository
+		b.Add(&r, map[string]interface{}{"name": graphql.String("go")})
+	}
+	err := client.QueryBatch(context.Background(), &b)
+	if got, want := err, "graphql: operation's estimated cost of 4 is more than the maximum of 3"; got == nil || got.Error() != want {
+		t.Errorf("got error: %v, want: %v", got, want)
+	}
+	if sent {
+		t.Error("batch was sent despite its cost")
+	}
+}
@@ -0,0 +1,156 @@
+package graphql
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/dbmedialab/go-graphql-client/internal/document"
+)
+
+// budgetBuckets is how many buckets the window of an ErrorBudget is split
+// into. Outcomes expire from the window a bucket at a time.
+const budgetBuckets = 10
+
+// ErrorBudget tracks the rolling success rate of operations by name, so
+// that callers can shed optional operations when the server is degrading.
+// Install it with WithMiddleware(b.Middleware), or record outcomes of
+// operations with Record. The zero value is usable, with a target success
+// rate of 99% over a minute.
+type ErrorBudget struct {
+	// Target is the success rate that operations are expected to meet.
+	// If zero, 0.99 is used.
+	Target float64
+
+	// Window is how far back outcomes are counted.
+	// If zero, a minute is used.
+	Window time.Duration
+
+	// MinRequests is how many outcomes an operation must have in the
+	// window before its budget can be exhausted, so that a single early
+	// failure doesn't shed everything.
+	MinRequests int
+
+	// Clock tells the time. If nil, the system clock is used.
+	Clock Clock
+
+	mu  sync.Mutex
+	ops map[string]*budgetWindow
+}
+
+type budgetWindow struct {
+	buckets [budgetBuckets]struct {
+		start      int64 // Start of the bucket's period, in units of its duration.
+		ok, failed int
+	}
+}
+
+func (b *ErrorBudget) window() time.Duration {
+	if b.Window == 0 {
+		return time.Minute
+	}
+	return b.Window
+}
+
+func (b *ErrorBudget) target() float64 {
+	if b.Target == 0 {
+		return 0.99
+	}
+	return b.Target
+}
+
+// Record records the outcome of an operation named operation; err is nil if
+// it succeeded. Anonymous operations are all recorded under "".
+func (b *ErrorBudget) Record(operation string, err error) {
+	period := b.period()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.ops == nil {
+		b.ops = map[string]*budgetWindow{}
+	}
+	w := b.ops[operation]
+	if w == nil {
+		w = &budgetWindow{}
+		b.ops[operation] = w
+	}
+	bucket := &w.buckets[period%budgetBuckets]
+	if bucket.start != period {
+		bucket.start, bucket.ok, bucket.failed = period, 0, 0
+	}
+	if err == nil {
+		bucket.ok++
+	} else {
+		bucket.failed++
+	}
+}
+
+// SuccessRate returns the fraction of the operation's outcomes in the window
+// that were successes, and how many outcomes there were. With no outcomes,
+// the rate is 1.
+func (b *ErrorBudget) SuccessRate(operation string) (rate float64, n int) {
+	period := b.period()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	w := b.ops[operation]
+	if w == nil {
+		return 1, 0
+	}
+	var ok int
+	for _, bucket := range w.buckets {
+		if period-bucket.start < budgetBuckets {
+			ok += bucket.ok
+			n += bucket.ok + bucket.failed
+		}
+	}
+	if n == 0 {
+		return 1, 0
+	}
+	return float64(ok) / float64(n), n
+}
+
+// IsBudgetExhausted reports whether the operation's success rate in the
+// window is below the target, given at least MinRequests outcomes.
+func (b *ErrorBudget) IsBudgetExhausted(operation string) bool {
+	rate, n := b.SuccessRate(operation)
+	return n > 0 && n >= b.MinRequests && rate < b.target()
+}
+
+// Middleware records the outcome of every operation sent through next,
+// under the name given in its query. Operations that fail in transport,
+// or whose responses have errors, count as failures.
+func (b *ErrorBudget) Middleware(next Transport) Transport {
+	return TransportFunc(func(ctx context.Context, req Request) (*Response, error) {
+		resp, err := next.Do(ctx, req)
+		outcome := err
+		if err == nil && len(resp.Errors) > 0 {
+			outcome = resp.Errors
+		}
+		b.Record(queryOperationName(req.Query), outcome)
+		return resp, err
+	})
+}
+
+// period returns the current bucket period.
+func (b *ErrorBudget) period() int64 {
+	d := int64(b.window()) / budgetBuckets
+	if d <= 0 {
+		d = 1
+	}
+	return clockOrSystem(b.Clock).Now().UnixNano() / d
+}
+
+// queryOperationName returns the name of the operation in query,
+// or "" if it's anonymous or can't be determined.
+func queryOperationName(query string) string {
+	toks, err := document.Tokenize(query)
+	if err != nil || len(toks) < 2 {
+		return ""
+	}
+	switch toks[0].Value {
+	case "query", "mutation", "subscription":
+		if toks[0].Kind == document.Name && toks[1].Kind == document.Name {
+			return toks[1].Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,63 @@
+package graphql_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/dbmedialab/go-graphql-client"
+)
+
+// settableClock is a Clock whose time is set by the test.
+type settableClock struct{ t time.Time }
+
+func (c *settableClock) Now() time.Time { return c.t }
+
+func TestErrorBudget(t *testing.T) {
+	clock := &settableClock{t: time.Unix(1000, 0)}
+	b := &graphql.ErrorBudget{Target: 0.9, Window: 10 * time.Second, MinRequests: 5, Clock: clock}
+
+	for i := 0; i < 4; i++ {
+		b.Record("Search", fmt.Errorf("unavailable"))
+	}
+	if b.IsBudgetExhausted("Search") {
+		t.Error("budget exhausted before MinRequests outcomes")
+	}
+	b.Record("Search", nil)
+	if rate, n := b.SuccessRate("Search"); rate != 0.2 || n != 5 {
+		t.Errorf("got success rate %v over %d, want 0.2 over 5", rate, n)
+	}
+	if !b.IsBudgetExhausted("Search") {
+		t.Error("got budget not exhausted, want exhausted")
+	}
+	if b.IsBudgetExhausted("Viewer") {
+		t.Error("got budget of another operation exhausted")
+	}
+
+	// Once the failures leave the window, the budget recovers.
+	clock.t = clock.t.Add(11 * time.Second)
+	if rate, n := b.SuccessRate("Search"); rate != 1 || n != 0 {
+		t.Errorf("got success rate %v over %d after window, want 1 over 0", rate, n)
+	}
+}
+
+func TestErrorBudget_Middleware(t *testing.T) {
+	var b graphql.ErrorBudget
+	transport := graphql.TransportFunc(func(ctx context.Context, req graphql.Request) (*graphql.Response, error) {
+		return &graphql.Response{Data: []byte(`{"viewer": null}`)}, nil
+	})
+	client := graphql.NewPluggableClient(transport, graphql.WithMiddleware(b.Middleware))
+
+	var q struct {
+		Viewer *struct {
+			Login graphql.String
+		}
+	}
+	if err := client.QueryCustom(context.Background(), &q, "query Viewer { viewer { login } }", nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, n := b.SuccessRate("Viewer"); n != 1 {
+		t.Errorf("got %d outcomes for Viewer, want 1", n)
+	}
+}
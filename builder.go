@@ -0,0 +1,212 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// Selection is a field, or inline fragment, of a selection set built at run
+// time, for when the fields to select aren't known until then, such as
+// columns that a user picks, and so can't be a struct. Make one with Field
+// or On, and the selection set with BuildQueryFields:
+//
+//	graphql.BuildQueryFields(
+//		graphql.Field("repository").
+//			Args(graphql.Arg("owner", graphql.Var("owner")), graphql.Arg("name", graphql.Var("name"))).
+//			Select(graphql.Field("name"), graphql.Field("stargazers").Select(graphql.Field("totalCount"))),
+//	)
+//
+// gives "{repository(owner: $owner, name: $name){name,stargazers{totalCount}}}",
+// the same document GenerateQueryFields gives for the struct with those
+// fields and tags. The methods add to the selection and return it, so that
+// calls can be chained.
+type Selection struct {
+	alias  string
+	name   string // Or "... on Type", for inline fragments.
+	args   []Argument
+	fields []*Selection
+}
+
+// Field returns a selection of the field called name.
+func Field(name string) *Selection {
+	return &Selection{name: name}
+}
+
+// On returns an inline fragment on the GraphQL type named typ, whose fields
+// are only selected for objects of that type.
+func On(typ string) *Selection {
+	return &Selection{name: "... on " + typ}
+}
+
+// As gives the field a response key, alias, other than its name.
+func (s *Selection) As(alias string) *Selection {
+	s.alias = alias
+	return s
+}
+
+// Args adds arguments to the field.
+func (s *Selection) Args(args ...Argument) *Selection {
+	s.args = append(s.args, args...)
+	return s
+}
+
+// Select adds fields to the selection set of the field, or fragment.
+func (s *Selection) Select(fields ...*Selection) *Selection {
+	s.fields = append(s.fields, fields...)
+	return s
+}
+
+// String returns the selection as it's written in a query.
+func (s *Selection) String() string {
+	var buf bytes.Buffer
+	s.write(&buf)
+	return buf.String()
+}
+
+func (s *Selection) write(w io.Writer) {
+	if s.alias != "" {
+		io.WriteString(w, s.alias+":")
+	}
+	io.WriteString(w, s.name)
+	if len(s.args) > 0 {
+		io.WriteString(w, "(")
+		for i, a := range s.args {
+			if i > 0 {
+				io.WriteString(w, ", ")
+			}
+			io.WriteString(w, a.name+": "+a.value)
+		}
+		io.WriteString(w, ")")
+	}
+	if len(s.fields) > 0 {
+		writeSelections(w, s.fields)
+	}
+}
+
+// BuildQueryFields returns the selection set of fields, as GenerateQueryFields
+// does for structs. Like the result of GenerateQueryFields, it can be passed
+// to QueryCustom and MutateCustom as the query.
+func BuildQueryFields(fields ...*Selection) string {
+	var buf bytes.Buffer
+	writeSelections(&buf, fields)
+	return buf.String()
+}
+
+func writeSelections(w io.Writer, fields []*Selection) {
+	io.WriteString(w, "{")
+	for i, f := range fields {
+		if i > 0 {
+			io.WriteString(w, ",")
+		}
+		f.write(w)
+	}
+	io.WriteString(w, "}")
+}
+
+// Argument is an argument of a field, made with Arg.
+type Argument struct {
+	name  string
+	value string // As written in a query.
+}
+
+// Var is a reference to the variable it names, for use as an argument value.
+type Var string
+
+// EnumValue is a value of an enum, for use as an argument value: it's written
+// as is, rather than quoted as a string.
+type EnumValue string
+
+// Arg returns the argument called name with value. The value is a Var, an
+// EnumValue, nil, or a string, boolean or number, or slice or array, or map
+// with string keys, of them, such as the scalar types of this package.
+//
+// Arg panics if value is of another type.
+func Arg(name string, value interface{}) Argument {
+	var buf bytes.Buffer
+	if err := writeArgumentValue(&buf, reflect.ValueOf(value)); err != nil {
+		panic(fmt.Errorf("graphql: argument %s: %v", name, err))
+	}
+	return Argument{name: name, value: buf.String()}
+}
+
+// writeArgumentValue writes v as a GraphQL value literal.
+func writeArgumentValue(w *bytes.Buffer, v reflect.Value) error {
+	if !v.IsValid() {
+		w.WriteString("null")
+		return nil
+	}
+	switch x := v.Interface().(type) {
+	case Var:
+		w.WriteString("$" + string(x))
+		return nil
+	case EnumValue:
+		w.WriteString(string(x))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			w.WriteString("null")
+			return nil
+		}
+		return writeArgumentValue(w, v.Elem())
+	case reflect.String:
+		// JSON's strings are GraphQL's.
+		b, err := json.Marshal(v.String())
+		if err != nil {
+			return err
+		}
+		w.Write(b)
+	case reflect.Bool:
+		w.WriteString(strconv.FormatBool(v.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		w.WriteString(strconv.FormatInt(v.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		w.WriteString(strconv.FormatUint(v.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		w.WriteString(strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()))
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			w.WriteString("null")
+			return nil
+		}
+		w.WriteString("[")
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				w.WriteString(", ")
+			}
+			if err := writeArgumentValue(w, v.Index(i)); err != nil {
+				return err
+			}
+		}
+		w.WriteString("]")
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("unsupported map key type %v", v.Type().Key())
+		}
+		keys := make([]string, 0, v.Len())
+		for _, k := range v.MapKeys() {
+			keys = append(keys, k.String())
+		}
+		sort.Strings(keys)
+		w.WriteString("{")
+		for i, k := range keys {
+			if i > 0 {
+				w.WriteString(", ")
+			}
+			w.WriteString(k + ": ")
+			if err := writeArgumentValue(w, v.MapIndex(reflect.ValueOf(k).Convert(v.Type().Key()))); err != nil {
+				return err
+			}
+		}
+		w.WriteString("}")
+	default:
+		return fmt.Errorf("unsupported value type %v", v.Type())
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package graphql_test
+
+import (
+	"testing"
+
+	"github.com/dbmedialab/go-graphql-client"
+)
+
+func TestBuildQueryFields(t *testing.T) {
+	var q struct {
+		Repository struct {
+			Name       graphql.String
+			Stargazers struct {
+				TotalCount graphql.Int
+			}
+			Issues struct {
+				Nodes []struct {
+					Title graphql.String
+				}
+			} `graphql:"issues(first: 10, states: [OPEN])"`
+		} `graphql:"repository(owner: $owner, name: $name)"`
+		Node struct {
+			User struct {
+				Login graphql.String
+			} `graphql:"... on User"`
+		} `graphql:"viewer:node(id: \"MDQ6VXNlcjE=\")"`
+	}
+	got := graphql.BuildQueryFields(
+		graphql.Field("repository").
+			Args(graphql.Arg("owner", graphql.Var("owner")), graphql.Arg("name", graphql.Var("name"))).
+			Select(
+				graphql.Field("name"),
+				graphql.Field("stargazers").Select(graphql.Field("totalCount")),
+				graphql.Field("issues").
+					Args(graphql.Arg("first", 10), graphql.Arg("states", []graphql.EnumValue{"OPEN"})).
+					Select(graphql.Field("nodes").Select(graphql.Field("title"))),
+			),
+		graphql.Field("node").As("viewer").
+			Args(graphql.Arg("id", graphql.ID("MDQ6VXNlcjE="))).
+			Select(graphql.On("User").Select(graphql.Field("login"))),
+	)
+	if want := graphql.GenerateQueryFields(q); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestArg(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  string
+	}{
+		{nil, `x: null`},
+		{"a \"b\"\n", `x: "a \"b\"\n"`},
+		{graphql.Boolean(true), `x: true`},
+		{graphql.Float(1.5), `x: 1.5`},
+		{graphql.NewInt(3), `x: 3`},
+		{(*graphql.Int)(nil), `x: null`},
+		{[]interface{}{1, "a", graphql.Var("v")}, `x: [1, "a", $v]`},
+		{map[string]interface{}{"b": graphql.EnumValue("DESC"), "a": []string(nil)}, `x: {a: null, b: DESC}`},
+	}
+	for _, tc := range tests {
+		if got := graphql.Field("f").Args(graphql.Arg("x", tc.value)).String(); got != "f("+tc.want+")" {
+			t.Errorf("%#v: got %s, want f(%s)", tc.value, got, tc.want)
+		}
+	}
+
+	defer func() {
+		if got, want := recover(), "graphql: argument x: unsupported value type struct {}"; got == nil || got.(error).Error() != want {
+			t.Errorf("got panic: %v, want: %v", got, want)
+		}
+	}()
+	graphql.Arg("x", struct{}{})
+}
@@ -0,0 +1,35 @@
+package graphql
+
+import (
+	"encoding/base64"
+	"reflect"
+)
+
+// WithBytesEncoding makes the client encode []byte variables, including the
+// fields of input objects, with enc, and decode response fields of type
+// []byte with it, for servers whose binary scalars use an encoding other
+// than standard, padded base64, which is the default. For example,
+//
+//	graphql.WithBytesEncoding(base64.RawURLEncoding)
+//
+// Byte slice types with MarshalJSON, MarshalText or MarshalGQL methods, or
+// their unmarshaling counterparts, encode and decode themselves instead.
+func WithBytesEncoding(enc *base64.Encoding) ClientOption {
+	return func(c *Client) {
+		c.bytesEncoding = enc
+	}
+}
+
+// isBytes reports whether t is a byte slice type that's encoded as base64,
+// rather than by methods of its own.
+func isBytes(t reflect.Type) bool {
+	if t.Kind() != reflect.Slice || t.Elem().Kind() != reflect.Uint8 {
+		return false
+	}
+	for _, m := range []reflect.Type{jsonMarshaler, textMarshaler, gqlMarshaler} {
+		if t.Implements(m) || reflect.PtrTo(t).Implements(m) {
+			return false
+		}
+	}
+	return true
+}
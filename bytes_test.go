@@ -0,0 +1,70 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/dbmedialab/go-graphql-client"
+)
+
+func TestBytes(t *testing.T) {
+	var gotQuery, gotVariables string
+	response := `{"upload": {"digest": "+/8=", "chunks": ["aGk="]}}`
+	transport := graphql.TransportFunc(func(ctx context.Context, req graphql.Request) (*graphql.Response, error) {
+		gotQuery = req.Query
+		b, err := json.Marshal(req.Variables)
+		gotVariables = string(b)
+		return &graphql.Response{Data: []byte(response)}, err
+	})
+	type FileInput struct {
+		Content []byte `graphql:"content"`
+	}
+	var q struct {
+		Upload struct {
+			Digest *[]byte
+			Chunks [][]byte
+		} `graphql:"upload(data: $data, file: $file)"`
+	}
+	variables := map[string]interface{}{
+		"data": []byte{0xfb, 0xff},
+		"file": FileInput{Content: []byte("hi")},
+	}
+
+	client := graphql.NewPluggableClient(transport)
+	if err := client.Query(context.Background(), &q, variables); err != nil {
+		t.Fatal(err)
+	}
+	if want := `query($data:Base64!$file:FileInput!){upload(data: $data, file: $file){digest,chunks}}`; gotQuery != want {
+		t.Errorf("got query: %s, want: %s", gotQuery, want)
+	}
+	if want := `{"data":"+/8=","file":{"content":"aGk="}}`; gotVariables != want {
+		t.Errorf("got variables: %s, want: %s", gotVariables, want)
+	}
+	if got, want := *q.Upload.Digest, []byte{0xfb, 0xff}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got digest: %v, want: %v", got, want)
+	}
+
+	response = `{"upload": {"digest": "-_8", "chunks": ["aGk"]}}`
+	client = graphql.NewPluggableClient(transport,
+		graphql.WithBytesEncoding(base64.RawURLEncoding),
+		graphql.WithScalarTypes(graphql.ScalarTypes{reflect.TypeOf([]byte(nil)): "Bytes"}))
+	q.Upload.Digest, q.Upload.Chunks = nil, nil
+	if err := client.Query(context.Background(), &q, variables); err != nil {
+		t.Fatal(err)
+	}
+	if want := `query($data:Bytes!$file:FileInput!){upload(data: $data, file: $file){digest,chunks}}`; gotQuery != want {
+		t.Errorf("got query: %s, want: %s", gotQuery, want)
+	}
+	if want := `{"data":"-_8","file":{"content":"aGk"}}`; gotVariables != want {
+		t.Errorf("got variables: %s, want: %s", gotVariables, want)
+	}
+	if got, want := *q.Upload.Digest, []byte{0xfb, 0xff}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got digest: %v, want: %v", got, want)
+	}
+	if got, want := q.Upload.Chunks, [][]byte{[]byte("hi")}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got chunks: %q, want: %q", got, want)
+	}
+}
@@ -0,0 +1,47 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/dbmedialab/go-graphql-client/internal/document"
+)
+
+// Canonical returns the canonical serialization of r, for middleware that
+// signs requests to gateways that check the signature over the same bytes.
+//
+// The canonical serialization is JSON without insignificant whitespace,
+// with the keys of every object in sorted order, "<", ">" and "&" not
+// escaped, and the query minified: comments and commas are removed, and
+// tokens separated by a single space only where they'd otherwise run
+// together. Variables are omitted if there are none. It's stable across
+// versions of this package: a request produces the same canonical bytes
+// from any version.
+func (r Request) Canonical() ([]byte, error) {
+	toks, err := document.Tokenize(r.Query)
+	if err != nil {
+		return nil, err
+	}
+	r.Query = document.Compact(toks)
+
+	// Round-trip through generic values so that the keys of structs as
+	// well as of maps come out sorted.
+	b, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
@@ -0,0 +1,46 @@
+package graphql_test
+
+import (
+	"testing"
+
+	"github.com/dbmedialab/go-graphql-client"
+)
+
+func TestRequest_Canonical(t *testing.T) {
+	type ReviewInput struct {
+		Stars      graphql.Int    `json:"stars"`
+		Commentary graphql.String `json:"commentary"`
+	}
+	tests := []struct {
+		in   graphql.Request
+		want string
+	}{
+		{
+			in:   graphql.Request{Query: "{\n  viewer {\n    login, # The name.\n    bio\n  }\n}\n"},
+			want: `{"query":"{viewer{login bio}}"}`,
+		},
+		{
+			in: graphql.Request{
+				Query: `mutation($ep: Episode!, $review: ReviewInput!) { createReview(episode: $ep, review: $review) { stars } }`,
+				Variables: map[string]interface{}{
+					"review": ReviewInput{Stars: 5, Commentary: "<3 & more"},
+					"ep":     "JEDI",
+				},
+			},
+			want: `{"query":"mutation($ep:Episode!$review:ReviewInput!){createReview(episode:$ep review:$review){stars}}","variables":{"ep":"JEDI","review":{"commentary":"<3 & more","stars":5}}}`,
+		},
+		{
+			in:   graphql.Request{Query: `query { a(x: "a  b", y: 1.50) ... on T { b } }`},
+			want: `{"query":"query{a(x:\"a  b\"y:1.50)... on T{b}}"}`,
+		},
+	}
+	for _, tc := range tests {
+		got, err := tc.in.Canonical()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != tc.want {
+			t.Errorf("got:\n%s\nwant:\n%s", got, tc.want)
+		}
+	}
+}
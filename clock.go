@@ -0,0 +1,56 @@
+package graphql
+
+import (
+	"context"
+	"time"
+)
+
+// Clock tells the time. Components whose behavior depends on the time take
+// one, so that tests can make them deterministic by providing a fake.
+type Clock interface {
+	Now() time.Time
+}
+
+// SleepClock is a Clock that can also be waited on. Components that wait,
+// such as for the backoff between retries, wait with the After of their
+// Clock if it's a SleepClock, so that a fake can keep tests from waiting in
+// real time.
+type SleepClock interface {
+	Clock
+	// After returns a channel on which the time is sent once d has passed.
+	After(d time.Duration) <-chan time.Time
+}
+
+// systemClock is the Clock used when none is given.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// clockOrSystem returns c, or the system clock if c is nil.
+func clockOrSystem(c Clock) Clock {
+	if c == nil {
+		return systemClock{}
+	}
+	return c
+}
+
+// sleep waits for d to pass on c, or for ctx to be done, reporting whether
+// d passed.
+func sleep(ctx context.Context, c Clock, d time.Duration) bool {
+	if sc, ok := c.(SleepClock); ok {
+		select {
+		case <-sc.After(d):
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
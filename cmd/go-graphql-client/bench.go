@@ -0,0 +1,217 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dbmedialab/go-graphql-client"
+)
+
+// loadCassette returns the requests recorded in the cassette at path.
+func loadCassette(path string) ([]graphql.Request, error) {
+	c, err := graphql.LoadCassette(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(c.Interactions) == 0 {
+		return nil, fmt.Errorf("%s: no interactions recorded", path)
+	}
+	reqs := make([]graphql.Request, len(c.Interactions))
+	for i, in := range c.Interactions {
+		reqs[i] = in.Request
+	}
+	return reqs, nil
+}
+
+// operationNameKey is the context key of the name of the operation that a
+// request is for, in a document of several.
+type operationNameKey struct{}
+
+// withOperationName sends requests with the operation name of their
+// context, if any, since QueryCustom names only the document's first.
+func withOperationName(next graphql.Transport) graphql.Transport {
+	return graphql.TransportFunc(func(ctx context.Context, req graphql.Request) (*graphql.Response, error) {
+		if name, ok := ctx.Value(operationNameKey{}).(string); ok && name != "" {
+			req.OperationName = name
+		}
+		return next.Do(ctx, req)
+	})
+}
+
+// runBench implements the bench command.
+func runBench(args []string) int {
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	client := addClientFlags(fs)
+	operation := addOperationFlags(fs)
+	cassettePath := fs.String("cassette", "", "replay the requests recorded in cassette `file`, in turn, rather than an operation")
+	rps := fs.Float64("rps", 0, "send at most `n` operations per second; 0 means as fast as possible")
+	concurrency := fs.Int("c", 1, "send up to `n` operations at once")
+	total := fs.Int("n", 100, "send `n` operations in all; 0 means until -duration is up")
+	duration := fs.Duration("duration", 0, "stop sending operations after `duration`; 0 means no limit")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: go-graphql-client bench -endpoint URL [flags] [operation]")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Sends an operation, or the requests recorded in a cassette, repeatedly, and")
+		fmt.Fprintln(os.Stderr, "reports the rate of errors, by kind, and percentiles of the latency.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if *client.endpoint == "" || fs.NArg() > 1 || *concurrency < 1 || *total < 0 ||
+		*total == 0 && *duration <= 0 || *cassettePath != "" && (fs.NArg() == 1 || *operation.file != "") {
+		fs.Usage()
+		return 2
+	}
+
+	var reqs []graphql.Request
+	if *cassettePath != "" {
+		var err error
+		if reqs, err = loadCassette(*cassettePath); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+	} else {
+		query, variables, err := operation.load(fs.Arg(0))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+		reqs = []graphql.Request{{Query: query, Variables: variables}}
+	}
+
+	b := &bench{
+		client:      graphql.NewClient(*client.endpoint, nil, append(client.options(), graphql.WithMiddleware(withOperationName))...),
+		reqs:        reqs,
+		rps:         *rps,
+		concurrency: *concurrency,
+		total:       *total,
+		duration:    *duration,
+	}
+	b.run().print()
+	return 0
+}
+
+// bench sends requests as a bench command's flags say.
+type bench struct {
+	client      *graphql.Client
+	reqs        []graphql.Request
+	rps         float64
+	concurrency int
+	total       int
+	duration    time.Duration
+}
+
+// benchResult is what a bench observed.
+type benchResult struct {
+	elapsed   time.Duration
+	latencies []time.Duration // Of all operations, sorted.
+	errors    map[string]int  // By kind.
+}
+
+// run sends the requests and returns the result.
+func (b *bench) run() *benchResult {
+	jobs := make(chan graphql.Request)
+	start := time.Now()
+	go func() {
+		defer close(jobs)
+		var tick <-chan time.Time
+		if b.rps > 0 {
+			t := time.NewTicker(time.Duration(float64(time.Second) / b.rps))
+			defer t.Stop()
+			tick = t.C
+		}
+		for i := 0; b.total == 0 || i < b.total; i++ {
+			if b.duration > 0 && time.Since(start) >= b.duration {
+				return
+			}
+			if tick != nil && i > 0 {
+				<-tick
+			}
+			jobs <- b.reqs[i%len(b.reqs)]
+		}
+	}()
+
+	var (
+		mu  sync.Mutex
+		res = &benchResult{errors: map[string]int{}}
+		wg  sync.WaitGroup
+	)
+	for i := 0; i < b.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for req := range jobs {
+				var data json.RawMessage
+				ctx := context.WithValue(context.Background(), operationNameKey{}, req.OperationName)
+				sent := time.Now()
+				err := b.client.QueryCustom(ctx, &data, req.Query, req.Variables)
+				latency := time.Since(sent)
+				mu.Lock()
+				res.latencies = append(res.latencies, latency)
+				if err != nil {
+					res.errors[graphql.ErrorKind(err)]++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	res.elapsed = time.Since(start)
+	sort.Slice(res.latencies, func(i, j int) bool { return res.latencies[i] < res.latencies[j] })
+	return res
+}
+
+// percentile returns the latency that p percent of operations took at most.
+func (r *benchResult) percentile(p float64) time.Duration {
+	if len(r.latencies) == 0 {
+		return 0
+	}
+	i := int(float64(len(r.latencies))*p/100+0.5) - 1
+	if i < 0 {
+		i = 0
+	}
+	return r.latencies[i]
+}
+
+// print writes a report of r to standard output.
+func (r *benchResult) print() {
+	n := len(r.latencies)
+	fmt.Printf("operations  %d in %v (%.1f/s)\n", n, r.elapsed-r.elapsed%time.Millisecond, float64(n)/r.elapsed.Seconds())
+	failed := 0
+	var kinds []string
+	for kind, count := range r.errors {
+		failed += count
+		kinds = append(kinds, fmt.Sprintf("%s %d", kind, count))
+	}
+	sort.Strings(kinds)
+	errorRate := 0.0
+	if n > 0 {
+		errorRate = 100 * float64(failed) / float64(n)
+	}
+	fmt.Printf("errors      %d (%.2f%%)", failed, errorRate)
+	if len(kinds) > 0 {
+		fmt.Printf(": %s", strings.Join(kinds, ", "))
+	}
+	fmt.Println()
+	if n == 0 {
+		return
+	}
+	var sum time.Duration
+	for _, l := range r.latencies {
+		sum += l
+	}
+	fmt.Printf("latency     mean %v  p50 %v  p90 %v  p95 %v  p99 %v  max %v\n",
+		round(sum/time.Duration(n)), round(r.percentile(50)), round(r.percentile(90)),
+		round(r.percentile(95)), round(r.percentile(99)), round(r.latencies[n-1]))
+}
+
+// round truncates latency d to 10µs for reporting.
+func round(d time.Duration) time.Duration {
+	return d - d%(10*time.Microsecond)
+}
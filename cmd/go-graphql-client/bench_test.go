@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dbmedialab/go-graphql-client"
+)
+
+func TestBenchResult_percentile(t *testing.T) {
+	ms := func(ns ...int) []time.Duration {
+		var ds []time.Duration
+		for _, n := range ns {
+			ds = append(ds, time.Duration(n)*time.Millisecond)
+		}
+		return ds
+	}
+	tests := []struct {
+		latencies []time.Duration
+		p         float64
+		want      time.Duration
+	}{
+		{nil, 50, 0},
+		{ms(7), 50, 7 * time.Millisecond},
+		{ms(7), 99, 7 * time.Millisecond},
+		{ms(1, 2, 3, 4), 50, 2 * time.Millisecond},
+		{ms(1, 2, 3, 4), 90, 4 * time.Millisecond},
+		{ms(1, 2, 3, 4, 5, 6, 7, 8, 9, 10), 90, 9 * time.Millisecond},
+		{ms(1, 2, 3, 4, 5, 6, 7, 8, 9, 10), 99, 10 * time.Millisecond},
+		{ms(1, 2, 3, 4, 5, 6, 7, 8, 9, 10), 1, time.Millisecond},
+	}
+	for _, tc := range tests {
+		r := &benchResult{latencies: tc.latencies}
+		if got := r.percentile(tc.p); got != tc.want {
+			t.Errorf("p%v of %v: got %v, want %v", tc.p, tc.latencies, got, tc.want)
+		}
+	}
+}
+
+func TestBench_cassette(t *testing.T) {
+	var (
+		mu  sync.Mutex
+		got []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var in struct{ OperationName string }
+		json.NewDecoder(req.Body).Decode(&in)
+		mu.Lock()
+		got = append(got, in.OperationName)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": {"b": 1}}`))
+	}))
+	defer server.Close()
+
+	cassette := &graphql.Cassette{Interactions: []graphql.Interaction{
+		{Request: graphql.Request{Query: "query A{a} query B{b}", OperationName: "B"}},
+	}}
+	path := filepath.Join(os.TempDir(), "graphql-bench-test.json")
+	defer os.Remove(path)
+	if err := cassette.WriteFile(path); err != nil {
+		t.Fatal(err)
+	}
+	reqs, err := loadCassette(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := &bench{
+		client:      graphql.NewClient(server.URL, nil, graphql.WithMiddleware(withOperationName)),
+		reqs:        reqs,
+		concurrency: 2,
+		total:       3,
+	}
+	res := b.run()
+	if len(res.errors) != 0 {
+		t.Errorf("got errors %v, want none", res.errors)
+	}
+	if got, want := len(res.latencies), 3; got != want {
+		t.Errorf("got %d latencies, want %d", got, want)
+	}
+	if want := []string{"B", "B", "B"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got operation names %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/dbmedialab/go-graphql-client/internal/document"
+	"github.com/dbmedialab/go-graphql-client/introspection"
+)
+
+// complete returns the completions in schema of the name that text ends
+// with: the fields of the selection set it's in, or the arguments of the
+// field whose arguments it's in. It returns the name being completed too,
+// which may be empty. Text that can't be tokenized has no completions.
+func complete(schema *introspection.Schema, text string) (prefix string, completions []string) {
+	toks, err := document.Tokenize(text)
+	if err != nil {
+		return "", nil
+	}
+	toks = toks[:len(toks)-1] // Drop EOF.
+	if n := len(toks); n > 0 && toks[n-1].Kind == document.Name && toks[n-1].Pos+len(toks[n-1].Value) == len(text) {
+		// The name isn't finished.
+		prefix = toks[n-1].Value
+		toks = toks[:n-1]
+	}
+
+	var (
+		stack   []*introspection.Type // Types of the selection sets text is in.
+		root    = schema.QueryType
+		field   string               // The last field seen in the innermost selection set.
+		on      string               // The type condition of the next selection set, if any.
+		args    *introspection.Field // The field whose arguments text is in, if any.
+		parens  int
+		afterOn bool
+	)
+	top := func() *introspection.Type {
+		if len(stack) == 0 {
+			return nil
+		}
+		return stack[len(stack)-1]
+	}
+	for i, tok := range toks {
+		if parens > 0 {
+			switch tok.Value {
+			case "(":
+				parens++
+			case ")":
+				if parens--; parens == 0 {
+					args = nil
+				}
+			}
+			continue
+		}
+		switch {
+		case tok.Kind == document.Name && len(stack) == 0 && tok.Value == "mutation":
+			root = schema.MutationType
+		case tok.Kind == document.Name && len(stack) == 0 && tok.Value == "subscription":
+			root = schema.SubscriptionType
+		case tok.Kind == document.Name && afterOn:
+			on, afterOn = tok.Value, false
+		case tok.Kind == document.Name && tok.Value == "on" && (len(stack) == 0 || i > 0 && toks[i-1].Value == "..."):
+			afterOn = true
+		case tok.Kind == document.Name && len(stack) > 0 && (i == 0 || toks[i-1].Value != "@"):
+			field = tok.Value
+		case tok.Value == "(":
+			parens = 1
+			if t := top(); t != nil && toks[i-1].Kind == document.Name && (i < 2 || toks[i-2].Value != "@") {
+				args = t.Field(field)
+			}
+		case tok.Value == "{":
+			var t *introspection.Type
+			switch {
+			case on != "":
+				t = schema.Type(on)
+			case len(stack) == 0 && root != nil:
+				t = schema.Type(root.Name)
+			case len(stack) > 0 && top() != nil:
+				if f := top().Field(field); f != nil {
+					t = schema.Type(namedType(f.Type))
+				}
+			}
+			stack = append(stack, t)
+			field, on = "", ""
+		case tok.Value == "}":
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+			field = ""
+		}
+	}
+
+	var names []string
+	switch {
+	case parens > 0 && args != nil:
+		for _, a := range args.Args {
+			names = append(names, a.Name)
+		}
+	case parens == 0 && top() != nil:
+		for _, f := range top().Fields {
+			names = append(names, f.Name)
+		}
+		names = append(names, "__typename")
+	}
+	for _, name := range names {
+		if strings.HasPrefix(name, prefix) {
+			completions = append(completions, name)
+		}
+	}
+	sort.Strings(completions)
+	return prefix, completions
+}
+
+// namedType returns the name of the type t wraps, or is.
+func namedType(t introspection.TypeRef) string {
+	for t.OfType != nil {
+		t = *t.OfType
+	}
+	if t.Name == nil {
+		return ""
+	}
+	return *t.Name
+}
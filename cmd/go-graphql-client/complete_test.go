@@ -0,0 +1,68 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dbmedialab/go-graphql-client/introspection"
+)
+
+func TestComplete(t *testing.T) {
+	schema, err := introspection.Parse([]byte(`{"__schema": {
+		"queryType": {"name": "Query"},
+		"mutationType": {"name": "Mutation"},
+		"types": [
+			{"kind": "OBJECT", "name": "Query", "fields": [
+				{"name": "viewer", "args": [], "type": {"kind": "NON_NULL", "ofType": {"kind": "OBJECT", "name": "User"}}},
+				{"name": "search", "args": [
+					{"name": "query", "type": {"kind": "SCALAR", "name": "String"}},
+					{"name": "first", "type": {"kind": "SCALAR", "name": "Int"}}
+				], "type": {"kind": "LIST", "ofType": {"kind": "OBJECT", "name": "Repository"}}}
+			]},
+			{"kind": "OBJECT", "name": "Mutation", "fields": [
+				{"name": "star", "args": [], "type": {"kind": "OBJECT", "name": "Repository"}}
+			]},
+			{"kind": "OBJECT", "name": "User", "fields": [
+				{"name": "login", "args": [], "type": {"kind": "SCALAR", "name": "String"}},
+				{"name": "location", "args": [], "type": {"kind": "SCALAR", "name": "String"}}
+			]},
+			{"kind": "OBJECT", "name": "Repository", "fields": [
+				{"name": "name", "args": [], "type": {"kind": "SCALAR", "name": "String"}},
+				{"name": "owner", "args": [], "type": {"kind": "OBJECT", "name": "User"}}
+			]}
+		]
+	}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		text        string
+		prefix      string
+		completions []string
+	}{
+		{"{", "", []string{"__typename", "search", "viewer"}},
+		{"{vi", "vi", []string{"viewer"}},
+		{"query Q {s", "s", []string{"search"}},
+		{"{viewer{lo", "lo", []string{"location", "login"}},
+		{"{viewer{\n  login\n  lo", "lo", []string{"location", "login"}},
+		{"{viewer{login} s", "s", []string{"search"}},
+		{"{search(", "", []string{"first", "query"}},
+		{"{search(q", "q", []string{"query"}},
+		{`{search(query: "{viewer{") {o`, "o", []string{"owner"}},
+		{`{search(query: "(") {owner{l`, "l", []string{"location", "login"}},
+		{"{search @include(if: true) {n", "n", []string{"name"}},
+		{"{search {... on User {lo", "lo", []string{"location", "login"}},
+		{"fragment F on Repository {o", "o", []string{"owner"}},
+		{"mutation {s", "s", []string{"star"}},
+		{"mutation {star {n", "n", []string{"name"}},
+		{"{viewer{name{", "", nil},
+		{"lo", "lo", nil},
+		{`{search(query: "`, "", nil},
+	}
+	for _, tc := range tests {
+		prefix, completions := complete(schema, tc.text)
+		if prefix != tc.prefix || !reflect.DeepEqual(completions, tc.completions) {
+			t.Errorf("%q: got %q, %q, want %q, %q", tc.text, prefix, completions, tc.prefix, tc.completions)
+		}
+	}
+}
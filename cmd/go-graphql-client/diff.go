@@ -0,0 +1,63 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/dbmedialab/go-graphql-client/introspection"
+)
+
+// runDiff implements the diff command. It exits with status 1 if any
+// breaking change is found, so it can be used to gate deploys.
+func runDiff(args []string) int {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	breakingOnly := fs.Bool("breaking", false, "only report breaking changes")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: go-graphql-client diff [-breaking] old.json new.json")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Both files hold introspection query results. The exit status is 1 if there are breaking changes.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return 2
+	}
+
+	old, err := loadSchema(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	new, err := loadSchema(fs.Arg(1))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	changes := introspection.Diff(old, new)
+	for _, c := range changes {
+		if *breakingOnly && !c.Breaking {
+			continue
+		}
+		fmt.Println(c)
+	}
+	if introspection.HasBreaking(changes) {
+		return 1
+	}
+	return 0
+}
+
+func loadSchema(path string) (*introspection.Schema, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s, err := introspection.Parse(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return s, nil
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/dbmedialab/go-graphql-client/internal/codegen"
+)
+
+// scalarFlag collects repeated -scalar Name=Type flags.
+type scalarFlag map[string]string
+
+func (f scalarFlag) String() string { return "" }
+
+func (f scalarFlag) Set(s string) error {
+	i := strings.Index(s, "=")
+	if i <= 0 || i == len(s)-1 {
+		return fmt.Errorf("want Name=Type, got %q", s)
+	}
+	f[s[:i]] = s[i+1:]
+	return nil
+}
+
+// runGenerate implements the generate command.
+func runGenerate(args []string) int {
+	fs := flag.NewFlagSet("generate", flag.ExitOnError)
+	schemaPath := fs.String("schema", "", "introspection result `file` describing the schema (required)")
+	pkg := fs.String("package", "main", "package `name` of the generated code")
+	out := fs.String("o", "", "output `file` (default standard output)")
+	scalars := scalarFlag{}
+	fs.Var(scalars, "scalar", "map a custom scalar to a Go type, as `Name=[import/path.]Type` (repeatable)")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: go-graphql-client generate -schema schema.json [flags] file.graphql...")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Generates Go response structs, variables structs and document constants")
+		fmt.Fprintln(os.Stderr, "for the named operations in the given files, for use with QueryCustom.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if *schemaPath == "" || fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	schema, err := loadSchema(*schemaPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	var sources []codegen.Source
+	for _, path := range fs.Args() {
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+		sources = append(sources, codegen.Source{Name: path, Text: string(b)})
+	}
+	code, err := codegen.Generate(codegen.Config{Package: *pkg, Schema: schema, Scalars: scalars}, sources...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if *out == "" {
+		os.Stdout.Write(code)
+		return 0
+	}
+	if err := ioutil.WriteFile(*out, code, 0644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
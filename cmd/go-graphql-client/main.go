@@ -0,0 +1,66 @@
+// go-graphql-client is a command-line tool for working with GraphQL endpoints
+// and schemas, built on top of the graphql package.
+//
+// Usage:
+//
+//	go-graphql-client <command> [flags] [arguments]
+//
+// The commands are:
+//
+//	bench     load-test an endpoint with an operation or a cassette
+//	diff      report changes between two introspection results
+//	generate  generate Go types for operations in .graphql files
+//	minify    print documents minified, or their hashes
+//	repl      execute operations interactively
+//	run       execute an operation and print its result
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+// command is a go-graphql-client subcommand.
+type command struct {
+	name    string
+	summary string
+	// run executes the command with the arguments that follow its name.
+	// It returns the process exit code.
+	run func(args []string) int
+}
+
+var commands = []command{
+	{name: "bench", summary: "load-test an endpoint with an operation or a cassette", run: runBench},
+	{name: "diff", summary: "report changes between two introspection results", run: runDiff},
+	{name: "generate", summary: "generate Go types for operations in .graphql files", run: runGenerate},
+	{name: "minify", summary: "print documents minified, or their hashes", run: runMinify},
+	{name: "repl", summary: "execute operations interactively", run: runREPL},
+	{name: "run", summary: "execute an operation and print its result", run: runRun},
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() < 1 {
+		usage()
+		os.Exit(2)
+	}
+	for _, c := range commands {
+		if c.name == flag.Arg(0) {
+			os.Exit(c.run(flag.Args()[1:]))
+		}
+	}
+	fmt.Fprintf(os.Stderr, "go-graphql-client: unknown command %q\n", flag.Arg(0))
+	usage()
+	os.Exit(2)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: go-graphql-client <command> [flags] [arguments]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "The commands are:")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "\t%-10s%s\n", c.name, c.summary)
+	}
+}
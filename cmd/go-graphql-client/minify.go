@@ -0,0 +1,55 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/dbmedialab/go-graphql-client"
+)
+
+// runMinify implements the minify command.
+func runMinify(args []string) int {
+	fs := flag.NewFlagSet("minify", flag.ExitOnError)
+	hash := fs.Bool("hash", false, "print the SHA-256 hash of each minified document, as HashQuery gives it, before its name")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: go-graphql-client minify [-hash] [file.graphql...]")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Prints each document, or standard input if no files are given, minified")
+		fmt.Fprintln(os.Stderr, "as WithMinifiedQueries sends it, one document per line.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	paths := fs.Args()
+	if len(paths) == 0 {
+		paths = []string{"-"}
+	}
+	status := 0
+	for _, path := range paths {
+		var b []byte
+		var err error
+		if path == "-" {
+			b, err = ioutil.ReadAll(os.Stdin)
+		} else {
+			b, err = ioutil.ReadFile(path)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+		query, err := graphql.NormalizeQuery(string(b))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+			status = 1
+			continue
+		}
+		if *hash {
+			fmt.Printf("%s  %s\n", graphql.HashQuery(query), path)
+			continue
+		}
+		fmt.Println(query)
+	}
+	return status
+}
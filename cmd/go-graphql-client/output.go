@@ -0,0 +1,258 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+// outputFlags are the flags of the commands that print results.
+type outputFlags struct {
+	format  *string
+	compact *bool
+}
+
+// addOutputFlags defines the flags of commands that print results in fs.
+func addOutputFlags(fs *flag.FlagSet) *outputFlags {
+	return &outputFlags{
+		format:  fs.String("output", "json", "print results as `format`: json, ndjson (a row per line) or table"),
+		compact: fs.Bool("compact", false, "print json results on one line rather than indented"),
+	}
+}
+
+// check reports an error if the format isn't known.
+func (f *outputFlags) check() error {
+	switch *f.format {
+	case "json", "ndjson", "table":
+		return nil
+	}
+	return fmt.Errorf("-output: unknown format %q; want json, ndjson or table", *f.format)
+}
+
+// print writes data to standard output in the format the flags give.
+func (f *outputFlags) print(data json.RawMessage) error {
+	if *f.format == "json" {
+		return printJSON(data, *f.compact)
+	}
+	b, err := formatRows(*f.format, data)
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(b)
+	return err
+}
+
+// formatRows returns the rows of data in format, ndjson or table.
+func formatRows(format string, data json.RawMessage) ([]byte, error) {
+	v, err := decodeOrdered(data)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if format == "ndjson" {
+		for _, row := range rows(v) {
+			b, err := json.Marshal(row)
+			if err != nil {
+				return nil, err
+			}
+			buf.Write(append(b, '\n'))
+		}
+	} else if err := writeTable(&buf, rows(v)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// object is a JSON object that keeps the order of its keys, which is the
+// order the fields were selected in.
+type object struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+// MarshalJSON encodes o with its keys in order.
+func (o *object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, k := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(o.values[k])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// decodeOrdered decodes data as encoding/json does into an interface{},
+// except that objects are *object and numbers are json.Number.
+func decodeOrdered(data json.RawMessage) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return decodeValue(dec)
+}
+
+func decodeValue(dec *json.Decoder) (interface{}, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch tok {
+	case json.Delim('{'):
+		o := &object{values: map[string]interface{}{}}
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			k := key.(string)
+			if _, ok := o.values[k]; !ok {
+				o.keys = append(o.keys, k)
+			}
+			o.values[k] = value
+		}
+		_, err := dec.Token() // '}'
+		return o, err
+	case json.Delim('['):
+		a := []interface{}{}
+		for dec.More() {
+			value, err := decodeValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			a = append(a, value)
+		}
+		_, err := dec.Token() // ']'
+		return a, err
+	}
+	return tok, nil
+}
+
+// rows returns the rows of data: the elements of its first list, looking
+// through the fields of objects in order, with the edges of connections
+// replaced by their nodes. Data without lists is a row of its own.
+func rows(data interface{}) []interface{} {
+	if list, ok := firstList(data); ok {
+		for i, row := range list {
+			// An edge of a connection.
+			if o, ok := row.(*object); ok {
+				if node, ok := o.values["node"]; ok {
+					list[i] = node
+				}
+			}
+		}
+		return list
+	}
+	if data == nil {
+		return nil
+	}
+	return []interface{}{data}
+}
+
+func firstList(v interface{}) ([]interface{}, bool) {
+	switch v := v.(type) {
+	case []interface{}:
+		return v, true
+	case *object:
+		for _, k := range []string{"edges", "nodes"} {
+			if list, ok := v.values[k].([]interface{}); ok {
+				return list, true
+			}
+		}
+		for _, k := range v.keys {
+			if list, ok := firstList(v.values[k]); ok {
+				return list, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// writeTable writes rows as a table, with a column per leaf field of the
+// rows, named by its path, such as "author.login". Lists are written as
+// JSON.
+func writeTable(w io.Writer, rows []interface{}) error {
+	var (
+		columns []string
+		seen    = map[string]bool{}
+		cells   []map[string]string
+	)
+	for _, row := range rows {
+		cell := map[string]string{}
+		if err := flatten(row, "", cell, func(column string) {
+			if !seen[column] {
+				seen[column] = true
+				columns = append(columns, column)
+			}
+		}); err != nil {
+			return err
+		}
+		cells = append(cells, cell)
+	}
+	if len(columns) == 0 {
+		return nil
+	}
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(columns, "\t"))
+	for _, cell := range cells {
+		values := make([]string, len(columns))
+		for i, column := range columns {
+			values[i] = cell[column]
+		}
+		fmt.Fprintln(tw, strings.Join(values, "\t"))
+	}
+	return tw.Flush()
+}
+
+// flatten adds the leaf fields of v to cell, by their paths under prefix,
+// calling column with each path.
+func flatten(v interface{}, prefix string, cell map[string]string, column func(string)) error {
+	if o, ok := v.(*object); ok {
+		for _, k := range o.keys {
+			path := k
+			if prefix != "" {
+				path = prefix + "." + k
+			}
+			if err := flatten(o.values[k], path, cell, column); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if prefix == "" {
+		prefix = "value"
+	}
+	column(prefix)
+	switch v := v.(type) {
+	case string:
+		cell[prefix] = strings.NewReplacer("\t", " ", "\n", " ").Replace(v)
+	case nil:
+		cell[prefix] = ""
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		cell[prefix] = string(b)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package main
+
+import "testing"
+
+func TestFormatRows(t *testing.T) {
+	const repositories = `{"viewer": {"login": "gopher", "repositories": {"totalCount": 2, "edges": [
+		{"node": {"name": "go", "owner": {"login": "golang"}, "topics": ["lang"]}},
+		{"node": {"name": "tools", "owner": {"login": "golang"}, "description": "a\ttab"}}
+	]}}}`
+	tests := []struct {
+		name   string
+		format string
+		data   string
+		want   string
+	}{
+		{
+			name:   "ndjson connection",
+			format: "ndjson",
+			data:   repositories,
+			want: `{"name":"go","owner":{"login":"golang"},"topics":["lang"]}` + "\n" +
+				`{"name":"tools","owner":{"login":"golang"},"description":"a\ttab"}` + "\n",
+		},
+		{
+			name:   "ndjson list",
+			format: "ndjson",
+			data:   `{"search": [{"b": 2, "a": 1}, {"b": 3}]}`,
+			want:   `{"b":2,"a":1}` + "\n" + `{"b":3}` + "\n",
+		},
+		{
+			name:   "ndjson no list",
+			format: "ndjson",
+			data:   `{"viewer": {"login": "gopher"}}`,
+			want:   `{"viewer":{"login":"gopher"}}` + "\n",
+		},
+		{
+			name:   "ndjson null",
+			format: "ndjson",
+			data:   `null`,
+			want:   "",
+		},
+		{
+			name:   "table connection",
+			format: "table",
+			data:   repositories,
+			want: "name   owner.login  topics    description\n" +
+				"go     golang       [\"lang\"]  \n" +
+				"tools  golang                 a tab\n",
+		},
+		{
+			name:   "table scalars",
+			format: "table",
+			data:   `{"numbers": [1, 2.5, null]}`,
+			want:   "value\n1\n2.5\n\n",
+		},
+		{
+			name:   "table no list",
+			format: "table",
+			data:   `{"viewer": {"login": "gopher", "id": 7}}`,
+			want:   "viewer.login  viewer.id\ngopher        7\n",
+		},
+		{
+			name:   "table empty",
+			format: "table",
+			data:   `{"search": []}`,
+			want:   "",
+		},
+	}
+	for _, tc := range tests {
+		got, err := formatRows(tc.format, []byte(tc.data))
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if string(got) != tc.want {
+			t.Errorf("%s: got:\n%s\nwant:\n%s", tc.name, got, tc.want)
+		}
+	}
+}
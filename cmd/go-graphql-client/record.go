@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/dbmedialab/go-graphql-client"
+)
+
+// recordFlags are the flags of the commands that can record the
+// operations they send in a cassette.
+type recordFlags struct {
+	record *string
+	redact *string
+}
+
+// addRecordFlags defines the flags of commands that record operations in fs.
+func addRecordFlags(fs *flag.FlagSet) *recordFlags {
+	return &recordFlags{
+		record: fs.String("record", "", "record the operations sent, and their responses, in cassette `file`, adding to it if it exists"),
+		redact: fs.String("redact", "", "redact the variables and response fields that JSON `file` names, as {\"variables\": [...], \"fields\": [...]}"),
+	}
+}
+
+// recording is a cassette being recorded.
+type recording struct {
+	path      string
+	cassette  *graphql.Cassette
+	redaction *graphql.Redaction
+}
+
+// open returns the recording the flags ask for, or nil if they don't.
+func (f *recordFlags) open() (*recording, error) {
+	if *f.record == "" {
+		if *f.redact != "" {
+			return nil, fmt.Errorf("-redact needs -record")
+		}
+		return nil, nil
+	}
+	r := &recording{path: *f.record}
+	var err error
+	if r.cassette, err = graphql.LoadCassette(r.path); os.IsNotExist(err) {
+		r.cassette = &graphql.Cassette{}
+	} else if err != nil {
+		return nil, err
+	}
+	if *f.redact != "" {
+		b, err := ioutil.ReadFile(*f.redact)
+		if err != nil {
+			return nil, err
+		}
+		r.redaction = &graphql.Redaction{}
+		if err := json.Unmarshal(b, r.redaction); err != nil {
+			return nil, fmt.Errorf("-redact: %v", err)
+		}
+	}
+	return r, nil
+}
+
+// option returns the client option that records operations.
+func (r *recording) option() graphql.ClientOption {
+	return graphql.WithMiddleware(func(next graphql.Transport) graphql.Transport {
+		return graphql.TransportRecorder{Transport: next, Cassette: r.cassette, Redaction: r.redaction}
+	})
+}
+
+// save writes the cassette, with what's been recorded so far.
+func (r *recording) save() error {
+	return r.cassette.WriteFile(r.path)
+}
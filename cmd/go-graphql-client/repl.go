@@ -0,0 +1,361 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/dbmedialab/go-graphql-client/internal/document"
+	"github.com/dbmedialab/go-graphql-client/introspection"
+)
+
+// maxHistory is the number of operations kept in the history file.
+const maxHistory = 1000
+
+const replHelp = `Type an operation to execute it once its braces are balanced, along with
+any fragments typed before it. End a line with a tab to complete the field or
+argument name before it. Commands:
+
+	:set name value  set a variable; JSON values are decoded
+	:unset name      remove a variable
+	:vars            print the variables
+	:history         list the operations executed
+	:again n         execute operation n of the history again
+	:complete text   list the completions of the name that text ends with
+	:schema          introspect the endpoint again
+	:cancel          discard the operation being typed
+	:help            print this help
+	:quit            exit (as does end of input)`
+
+// runREPL implements the repl command.
+func runREPL(args []string) int {
+	fs := flag.NewFlagSet("repl", flag.ExitOnError)
+	client := addClientFlags(fs)
+	record := addRecordFlags(fs)
+	schemaPath := fs.String("schema", "", "introspection result `file` to complete names from (default introspect the endpoint)")
+	historyPath := fs.String("history", defaultHistoryPath(), "`file` to keep the history of operations in; empty means none")
+	output := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: go-graphql-client repl -endpoint URL [flags]")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Reads operations from standard input and executes them one by one, with the")
+		fmt.Fprintln(os.Stderr, "variables set so far. Type :help for the commands.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if *client.endpoint == "" || fs.NArg() != 0 {
+		fs.Usage()
+		return 2
+	}
+
+	if err := output.check(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	rec, err := record.open()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	s := &session{
+		runner:      newRunner(client, output, rec),
+		variables:   map[string]interface{}{},
+		historyPath: *historyPath,
+	}
+	if *schemaPath != "" {
+		schema, err := loadSchema(*schemaPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+		s.schema = schema
+	} else {
+		s.introspect()
+	}
+	if err := s.loadHistory(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	s.loop(os.Stdin)
+	return 0
+}
+
+// defaultHistoryPath returns the history file in the user's home
+// directory, or "" if there's no home directory.
+func defaultHistoryPath() string {
+	home := os.Getenv("HOME")
+	if home == "" {
+		return ""
+	}
+	return filepath.Join(home, ".go-graphql-client_history")
+}
+
+// session is the state of a repl command.
+type session struct {
+	runner      *runner
+	schema      *introspection.Schema // To complete names from, if not nil.
+	variables   map[string]interface{}
+	history     []string
+	historyPath string
+
+	pending string // The operation being typed.
+}
+
+// loop reads and handles lines from in until its end or :quit.
+func (s *session) loop(in io.Reader) {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(nil, 1<<20)
+	for {
+		if s.pending == "" {
+			fmt.Fprint(os.Stderr, "> ")
+		} else {
+			fmt.Fprint(os.Stderr, "... ")
+		}
+		if !scanner.Scan() {
+			break
+		}
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(strings.TrimSpace(line), ":"):
+			if !s.command(strings.TrimSpace(line)[1:]) {
+				return
+			}
+		case strings.HasSuffix(line, "\t"):
+			s.completeLine(strings.TrimRight(line, "\t"))
+		default:
+			s.pending += line + "\n"
+			if complete, ok := balanced(s.pending); ok {
+				s.execute(complete)
+				s.pending = ""
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	fmt.Fprintln(os.Stderr)
+}
+
+// balanced returns operation, trimmed, and whether it's ready to be
+// executed: whether it has the selection set of an operation, rather than
+// just fragment definitions, and all of its braces are closed.
+func balanced(operation string) (string, bool) {
+	toks, err := document.Tokenize(operation)
+	if err != nil {
+		// Wait for the rest of a string, say; the server reports any
+		// other syntax error once the operation is sent.
+		return "", false
+	}
+	var (
+		depth    int  // Of the selection sets being read.
+		parens   int  // Arguments and variable definitions are skipped.
+		fragment bool // Whether the definition being read is a fragment's.
+		sets     int  // Selection sets of operations.
+	)
+	for _, tok := range toks {
+		switch {
+		case tok.Kind == document.Name && depth == 0 && parens == 0 && tok.Value == "fragment":
+			fragment = true
+		case tok.Kind != document.Punctuator:
+		case tok.Value == "(":
+			parens++
+		case tok.Value == ")":
+			parens--
+		case parens > 0:
+		case tok.Value == "{":
+			if depth == 0 && !fragment {
+				sets++
+			}
+			depth++
+		case tok.Value == "}":
+			if depth--; depth == 0 {
+				fragment = false
+			}
+		}
+	}
+	return strings.TrimSpace(operation), sets > 0 && depth <= 0
+}
+
+// execute executes operation with the variables set, and adds it to the
+// history.
+func (s *session) execute(operation string) {
+	s.runner.run(operation, s.variables)
+	s.history = append(s.history, operation)
+	if err := s.appendHistory(operation); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+}
+
+// command handles a command, given without its colon. It reports whether
+// to read more lines.
+func (s *session) command(cmd string) bool {
+	name, arg := cmd, ""
+	if i := strings.IndexAny(cmd, " \t"); i != -1 {
+		name, arg = cmd[:i], strings.TrimSpace(cmd[i+1:])
+	}
+	switch name {
+	case "set":
+		i := strings.IndexAny(arg, " \t")
+		if i <= 0 {
+			fmt.Fprintln(os.Stderr, "usage: :set name value")
+			break
+		}
+		s.variables[arg[:i]] = variableValue(strings.TrimSpace(arg[i+1:]))
+	case "unset":
+		delete(s.variables, arg)
+	case "vars":
+		b, err := json.Marshal(s.variables)
+		if err == nil {
+			err = printJSON(b, *s.runner.output.compact)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	case "history":
+		for i, operation := range s.history {
+			fmt.Printf("%4d  %s\n", i+1, oneLine(operation))
+		}
+	case "again":
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 1 || n > len(s.history) {
+			fmt.Fprintf(os.Stderr, "no operation %q in the history\n", arg)
+			break
+		}
+		s.execute(s.history[n-1])
+	case "complete":
+		s.printCompletions(arg)
+	case "schema":
+		s.introspect()
+	case "cancel":
+		s.pending = ""
+	case "help":
+		fmt.Fprintln(os.Stderr, replHelp)
+	case "quit", "exit":
+		return false
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command :%s; type :help for the commands\n", name)
+	}
+	return true
+}
+
+// completeLine completes the name that line, to be added to the operation
+// being typed, ends with. A single completion is added to the operation,
+// along with the rest of line; otherwise the completions are listed.
+func (s *session) completeLine(line string) {
+	completions := s.printCompletions(s.pending + line)
+	if len(completions) != 1 {
+		return
+	}
+	prefix, _ := complete(s.schema, s.pending+line)
+	line = line[:len(line)-len(prefix)] + completions[0]
+	s.pending += line + "\n"
+	fmt.Fprintln(os.Stderr, line)
+	if complete, ok := balanced(s.pending); ok {
+		s.execute(complete)
+		s.pending = ""
+	}
+}
+
+// printCompletions prints the completions of the name text ends with,
+// and returns them.
+func (s *session) printCompletions(text string) []string {
+	if s.schema == nil {
+		fmt.Fprintln(os.Stderr, "no schema to complete names from")
+		return nil
+	}
+	_, completions := complete(s.schema, text)
+	if len(completions) == 0 {
+		fmt.Fprintln(os.Stderr, "no completions")
+	} else {
+		fmt.Fprintln(os.Stderr, strings.Join(completions, "  "))
+	}
+	return completions
+}
+
+// introspect loads the schema from the endpoint, keeping the one loaded
+// before if it fails.
+func (s *session) introspect() {
+	var data json.RawMessage
+	err := s.runner.client.QueryCustom(context.Background(), &data, introspection.Query, nil)
+	s.runner.save()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "introspecting the schema: %v\n", err)
+		return
+	}
+	schema, err := introspection.Parse(data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "introspecting the schema: %v\n", err)
+		return
+	}
+	s.schema = schema
+}
+
+// loadHistory reads the history file, which holds an operation encoded as
+// a JSON string per line.
+func (s *session) loadHistory() error {
+	if s.historyPath == "" {
+		return nil
+	}
+	b, err := ioutil.ReadFile(s.historyPath)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	for _, line := range strings.Split(string(b), "\n") {
+		var operation string
+		if json.Unmarshal([]byte(line), &operation) == nil && operation != "" {
+			s.history = append(s.history, operation)
+		}
+	}
+	if len(s.history) <= maxHistory {
+		return nil
+	}
+	// Forget the oldest operations.
+	s.history = s.history[len(s.history)-maxHistory:]
+	var buf bytes.Buffer
+	for _, operation := range s.history {
+		b, err := json.Marshal(operation)
+		if err != nil {
+			return err
+		}
+		buf.Write(append(b, '\n'))
+	}
+	return ioutil.WriteFile(s.historyPath, buf.Bytes(), 0600)
+}
+
+// appendHistory adds operation to the history file.
+func (s *session) appendHistory(operation string) error {
+	if s.historyPath == "" {
+		return nil
+	}
+	f, err := os.OpenFile(s.historyPath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(operation)
+	if err == nil {
+		_, err = f.Write(append(b, '\n'))
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// oneLine returns operation compacted onto one line, if it can be.
+func oneLine(operation string) string {
+	toks, err := document.Tokenize(operation)
+	if err != nil {
+		return strings.Join(strings.Fields(operation), " ")
+	}
+	return document.Compact(toks)
+}
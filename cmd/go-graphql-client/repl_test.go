@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBalanced(t *testing.T) {
+	tests := []struct {
+		in    string
+		ready bool
+	}{
+		{"", false},
+		{"query Q", false},
+		{"{viewer{login}}", true},
+		{"{viewer{\n", false},
+		{"{viewer{\nlogin}}\n", true},
+		{`{search(q: "}") {`, false},
+		{`{search(q: "{") {name}}`, true},
+		{`{search(q: """a { b""") {name}}`, true},
+		{"{viewer # }\n", false},
+		{`{search(q: "`, false},
+		{"query($in: In = {a: 1})", false},
+		{"query($in: In = {a: 1}) {search(in: $in) {name}}", true},
+		{"fragment F on User {login}", false},
+		{"fragment F on User @dir(a: {b: 1}) {login}", false},
+		{"fragment F on User {login}\n{viewer{...F}}", true},
+		{"{viewer{...F}}\nfragment F on User {login}", true},
+	}
+	for _, tc := range tests {
+		got, ready := balanced(tc.in)
+		if ready != tc.ready {
+			t.Errorf("%q: got ready %v, want %v", tc.in, ready, tc.ready)
+		}
+		if ready && got != strings.TrimSpace(tc.in) {
+			t.Errorf("%q: got operation %q, want it trimmed", tc.in, got)
+		}
+	}
+}
+
+func TestSession(t *testing.T) {
+	type request struct {
+		Query     string
+		Variables map[string]interface{}
+	}
+	var got []request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var in request
+		json.NewDecoder(req.Body).Decode(&in)
+		got = append(got, in)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": {"user": {"name": "Gopher"}}}`))
+	}))
+	defer server.Close()
+
+	token, format, compact, timeout := "", "json", true, time.Second
+	s := &session{
+		runner: newRunner(
+			&clientFlags{endpoint: &server.URL, token: &token, timeout: &timeout},
+			&outputFlags{format: &format, compact: &compact},
+			nil,
+		),
+		variables: map[string]interface{}{},
+	}
+	s.loop(strings.NewReader(strings.Join([]string{
+		`:set login "gopher"`,
+		":set first 10",
+		":set",
+		"fragment F on User {",
+		"  name",
+		"}",
+		`{user(login: $login, q: "}") {`,
+		"  ...F",
+		"}}",
+		":unset first",
+		":again 1",
+		":again 3",
+		":quit",
+		"{ignored}",
+	}, "\n")))
+
+	const query = "fragment F on User {\n  name\n}\n" + `{user(login: $login, q: "}") {` + "\n  ...F\n}}"
+	want := []request{
+		{Query: query, Variables: map[string]interface{}{"login": "gopher", "first": float64(10)}},
+		{Query: query, Variables: map[string]interface{}{"login": "gopher"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got requests:\n%#v\nwant:\n%#v", got, want)
+	}
+	if want := []string{query, query}; !reflect.DeepEqual(s.history, want) {
+		t.Errorf("got history %q, want %q", s.history, want)
+	}
+	if want := map[string]interface{}{"login": "gopher"}; !reflect.DeepEqual(s.variables, want) {
+		t.Errorf("got variables %v, want %v", s.variables, want)
+	}
+}
+
+func TestSession_command(t *testing.T) {
+	s := &session{variables: map[string]interface{}{}}
+	tests := []struct {
+		cmd  string
+		more bool
+		want map[string]interface{}
+	}{
+		{"set login gopher", true, map[string]interface{}{"login": "gopher"}},
+		{"set ids [1, 2]", true, map[string]interface{}{"login": "gopher", "ids": []interface{}{float64(1), float64(2)}}},
+		{"set  login \t \"a b\" ", true, map[string]interface{}{"login": "a b", "ids": []interface{}{float64(1), float64(2)}}},
+		{"set login", true, map[string]interface{}{"login": "a b", "ids": []interface{}{float64(1), float64(2)}}},
+		{"unset ids", true, map[string]interface{}{"login": "a b"}},
+		{"unset missing", true, map[string]interface{}{"login": "a b"}},
+		{"again 1", true, map[string]interface{}{"login": "a b"}},
+		{"cancel", true, map[string]interface{}{"login": "a b"}},
+		{"nonsense", true, map[string]interface{}{"login": "a b"}},
+		{"quit", false, map[string]interface{}{"login": "a b"}},
+		{"exit", false, map[string]interface{}{"login": "a b"}},
+	}
+	for _, tc := range tests {
+		s.pending = "{viewer"
+		if more := s.command(tc.cmd); more != tc.more {
+			t.Errorf(":%s: got %v, want %v", tc.cmd, more, tc.more)
+		}
+		if !reflect.DeepEqual(s.variables, tc.want) {
+			t.Errorf(":%s: got variables %v, want %v", tc.cmd, s.variables, tc.want)
+		}
+		if tc.cmd == "cancel" && s.pending != "" {
+			t.Errorf(":cancel: got pending %q, want none", s.pending)
+		}
+	}
+	if len(s.history) != 0 {
+		t.Errorf("got history %q, want none from :again with an empty history", s.history)
+	}
+}
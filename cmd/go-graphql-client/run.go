@@ -0,0 +1,305 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dbmedialab/go-graphql-client"
+)
+
+// varFlag collects repeated -var name=value flags.
+type varFlag map[string]interface{}
+
+func (f varFlag) String() string { return "" }
+
+func (f varFlag) Set(s string) error {
+	i := strings.Index(s, "=")
+	if i <= 0 {
+		return fmt.Errorf("want name=value, got %q", s)
+	}
+	f[s[:i]] = variableValue(s[i+1:])
+	return nil
+}
+
+// variableValue returns the value of a variable given as text: values that
+// are valid JSON, such as numbers, booleans, lists and objects, are sent as
+// decoded, and any other text as a string.
+func variableValue(text string) interface{} {
+	var v interface{}
+	if err := json.Unmarshal([]byte(text), &v); err != nil {
+		return text
+	}
+	return v
+}
+
+// headerFlag collects repeated -H "Key: Value" flags.
+type headerFlag [][2]string
+
+func (f *headerFlag) String() string { return "" }
+
+func (f *headerFlag) Set(s string) error {
+	i := strings.Index(s, ":")
+	if i <= 0 {
+		return fmt.Errorf("want Key: Value, got %q", s)
+	}
+	*f = append(*f, [2]string{strings.TrimSpace(s[:i]), strings.TrimSpace(s[i+1:])})
+	return nil
+}
+
+// clientFlags are the flags of the commands that send operations to an
+// endpoint.
+type clientFlags struct {
+	endpoint *string
+	headers  headerFlag
+	token    *string
+	timeout  *time.Duration
+}
+
+// addClientFlags defines the flags of commands that send operations in fs.
+func addClientFlags(fs *flag.FlagSet) *clientFlags {
+	f := &clientFlags{
+		endpoint: fs.String("endpoint", os.Getenv("GRAPHQL_ENDPOINT"), "GraphQL server `URL` (default $GRAPHQL_ENDPOINT)"),
+		token:    fs.String("token", os.Getenv("GRAPHQL_TOKEN"), "send `token` as a bearer token (default $GRAPHQL_TOKEN)"),
+		timeout:  fs.Duration("timeout", 30*time.Second, "limit each operation to `duration`; 0 means no limit"),
+	}
+	fs.Var(&f.headers, "H", "send an HTTP header, as `\"Key: Value\"` (repeatable)")
+	return f
+}
+
+// runner sends operations to the endpoint the flags name, and prints
+// their results.
+type runner struct {
+	client    *graphql.Client
+	output    *outputFlags
+	recording *recording // The cassette operations are recorded in, if any.
+
+	// resp is the response to the last operation sent, kept to report
+	// all of its errors.
+	resp *graphql.Response
+}
+
+// options returns the client options the flags give.
+func (f *clientFlags) options() []graphql.ClientOption {
+	opts := []graphql.ClientOption{graphql.WithTimeout(*f.timeout)}
+	for _, h := range f.headers {
+		opts = append(opts, graphql.WithHeader(h[0], h[1]))
+	}
+	if *f.token != "" {
+		opts = append(opts, graphql.WithHeader("Authorization", "Bearer "+*f.token))
+	}
+	return opts
+}
+
+func newRunner(f *clientFlags, output *outputFlags, rec *recording) *runner {
+	r := &runner{output: output, recording: rec}
+	opts := f.options()
+	if rec != nil {
+		opts = append(opts, rec.option())
+	}
+	opts = append(opts, graphql.WithMiddleware(func(next graphql.Transport) graphql.Transport {
+		return graphql.TransportFunc(func(ctx context.Context, req graphql.Request) (*graphql.Response, error) {
+			out, err := next.Do(ctx, req)
+			r.resp = out
+			return out, err
+		})
+	}))
+	r.client = graphql.NewClient(*f.endpoint, nil, opts...)
+	return r
+}
+
+// run executes query with variables, printing the data of the result to
+// standard output and any errors to standard error. It reports whether
+// there were no errors.
+func (r *runner) run(query string, variables map[string]interface{}) bool {
+	r.resp = nil
+	var data json.RawMessage
+	err := r.client.QueryCustom(context.Background(), &data, query, variables)
+	r.save()
+	if len(data) > 0 && string(data) != "null" {
+		if err := r.output.print(data); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return false
+		}
+	}
+	if err == nil {
+		return true
+	}
+	if r.resp != nil && len(r.resp.Errors) > 0 {
+		for _, e := range r.resp.Errors {
+			locations := make([][2]int, len(e.Locations))
+			for i, l := range e.Locations {
+				locations[i] = [2]int{l.Line, l.Column}
+			}
+			fmt.Fprintln(os.Stderr, formatError(e.Message, e.Path, locations))
+		}
+	} else {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	return false
+}
+
+// save writes the cassette being recorded, if any, reporting any error.
+func (r *runner) save() {
+	if r.recording == nil {
+		return
+	}
+	if err := r.recording.save(); err != nil {
+		fmt.Fprintf(os.Stderr, "recording %s: %v\n", r.recording.path, err)
+	}
+}
+
+// runRun implements the run command. It exits with status 1 if the
+// operation fails, after printing whatever data the server returned.
+func runRun(args []string) int {
+	fs := flag.NewFlagSet("run", flag.ExitOnError)
+	client := addClientFlags(fs)
+	operation := addOperationFlags(fs)
+	record := addRecordFlags(fs)
+	output := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: go-graphql-client run -endpoint URL [flags] [operation]")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Executes a query or mutation, given as the argument, by -file, or on standard")
+		fmt.Fprintln(os.Stderr, "input, and prints the data of the result. Errors are printed to standard error,")
+		fmt.Fprintln(os.Stderr, "and the exit status is 1 if there are any.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if *client.endpoint == "" || fs.NArg() > 1 || fs.NArg() == 1 && *operation.file != "" {
+		fs.Usage()
+		return 2
+	}
+
+	if err := output.check(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	query, variables, err := operation.load(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	rec, err := record.open()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if !newRunner(client, output, rec).run(query, variables) {
+		return 1
+	}
+	return 0
+}
+
+// operationFlags are the flags of the commands that take an operation to
+// send with variables.
+type operationFlags struct {
+	file          *string
+	variablesJSON *string
+	vars          varFlag
+}
+
+// addOperationFlags defines the flags of commands that take an operation
+// in fs.
+func addOperationFlags(fs *flag.FlagSet) *operationFlags {
+	f := &operationFlags{
+		file:          fs.String("file", "", "read the operation from `file` rather than the argument or standard input"),
+		variablesJSON: fs.String("variables", "", "variables as a JSON `object`; -var flags override them"),
+		vars:          varFlag{},
+	}
+	fs.Var(f.vars, "var", "set a variable, as `name=value`; JSON values are decoded (repeatable)")
+	return f
+}
+
+// load returns the operation, given as arg or as the flags say, and its
+// variables.
+func (f *operationFlags) load(arg string) (string, map[string]interface{}, error) {
+	query, err := readOperation(arg, *f.file)
+	if err != nil {
+		return "", nil, err
+	}
+	variables := map[string]interface{}{}
+	if *f.variablesJSON != "" {
+		if err := json.Unmarshal([]byte(*f.variablesJSON), &variables); err != nil {
+			return "", nil, fmt.Errorf("-variables: %v", err)
+		}
+	}
+	for name, v := range f.vars {
+		variables[name] = v
+	}
+	return query, variables, nil
+}
+
+// readOperation returns the operation given as arg, else read from file,
+// else read from standard input.
+func readOperation(arg, file string) (string, error) {
+	if arg != "" {
+		return arg, nil
+	}
+	var b []byte
+	var err error
+	if file != "" {
+		b, err = ioutil.ReadFile(file)
+	} else {
+		b, err = ioutil.ReadAll(os.Stdin)
+	}
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(string(b)) == "" {
+		return "", fmt.Errorf("no operation given")
+	}
+	return string(b), nil
+}
+
+// printJSON writes data to standard output, indented unless compact.
+func printJSON(data json.RawMessage, compact bool) error {
+	var buf bytes.Buffer
+	var err error
+	if compact {
+		err = json.Compact(&buf, data)
+	} else {
+		err = json.Indent(&buf, data, "", "  ")
+	}
+	if err != nil {
+		return err
+	}
+	buf.WriteByte('\n')
+	_, err = buf.WriteTo(os.Stdout)
+	return err
+}
+
+// formatError formats a GraphQL error as "path: message (line:column)",
+// leaving out the parts the server didn't give. Locations are pairs of
+// line and column.
+func formatError(message string, path []interface{}, locations [][2]int) string {
+	var b bytes.Buffer
+	for i, p := range path {
+		if i > 0 {
+			b.WriteByte('.')
+		}
+		fmt.Fprint(&b, p)
+	}
+	if b.Len() > 0 {
+		b.WriteString(": ")
+	}
+	b.WriteString(message)
+	for i, l := range locations {
+		if i == 0 {
+			b.WriteString(" (")
+		} else {
+			b.WriteString(", ")
+		}
+		fmt.Fprintf(&b, "%d:%d", l[0], l[1])
+		if i == len(locations)-1 {
+			b.WriteByte(')')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestVarFlag_Set(t *testing.T) {
+	tests := []struct {
+		in   string
+		want interface{}
+		err  string
+	}{
+		{in: "login=gopher", want: "gopher"},
+		{in: "login=", want: ""},
+		{in: "first=10", want: float64(10)},
+		{in: "draft=true", want: true},
+		{in: "after=null", want: nil},
+		{in: `login="10"`, want: "10"},
+		{in: `ids=[1,"a"]`, want: []interface{}{float64(1), "a"}},
+		{in: `input={"stars":5}`, want: map[string]interface{}{"stars": float64(5)}},
+		{in: "q=a=b", want: "a=b"},
+		{in: "q={not json", want: "{not json"},
+		{in: "login", err: `want name=value, got "login"`},
+		{in: "=gopher", err: `want name=value, got "=gopher"`},
+	}
+	for _, tc := range tests {
+		f := varFlag{}
+		err := f.Set(tc.in)
+		if tc.err != "" {
+			if err == nil || err.Error() != tc.err {
+				t.Errorf("%s: got error: %v, want: %v", tc.in, err, tc.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tc.in, err)
+			continue
+		}
+		if len(f) != 1 {
+			t.Errorf("%s: got variables %v, want one", tc.in, f)
+			continue
+		}
+		for _, got := range f {
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("%s: got %#v, want %#v", tc.in, got, tc.want)
+			}
+		}
+	}
+}
+
+func TestHeaderFlag_Set(t *testing.T) {
+	var f headerFlag
+	for _, s := range []string{"X-Tenant: acme", "Accept:application/json", "X-Empty:", "X-Time: 12:30"} {
+		if err := f.Set(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := headerFlag{{"X-Tenant", "acme"}, {"Accept", "application/json"}, {"X-Empty", ""}, {"X-Time", "12:30"}}
+	if !reflect.DeepEqual(f, want) {
+		t.Errorf("got headers %q, want %q", f, want)
+	}
+	for _, s := range []string{"X-Tenant", ": acme"} {
+		if err := f.Set(s); err == nil {
+			t.Errorf("%q: got error: nil, want non-nil", s)
+		}
+	}
+}
+
+func TestReadOperation(t *testing.T) {
+	path := filepath.Join(os.TempDir(), "graphql-run-test.graphql")
+	defer os.Remove(path)
+	if err := ioutil.WriteFile(path, []byte("{viewer{login}}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	blank := filepath.Join(os.TempDir(), "graphql-run-test-blank.graphql")
+	defer os.Remove(blank)
+	if err := ioutil.WriteFile(blank, []byte(" \n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		arg, file string
+		want      string
+		err       bool
+	}{
+		{arg: "{a}", want: "{a}"},
+		{arg: "{a}", file: path, want: "{a}"},
+		{file: path, want: "{viewer{login}}\n"},
+		{file: blank, err: true},
+		{file: filepath.Join(os.TempDir(), "graphql-run-test-missing.graphql"), err: true},
+	}
+	for _, tc := range tests {
+		got, err := readOperation(tc.arg, tc.file)
+		if tc.err {
+			if err == nil {
+				t.Errorf("%q, %q: got error: nil, want non-nil", tc.arg, tc.file)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q, %q: %v", tc.arg, tc.file, err)
+		} else if got != tc.want {
+			t.Errorf("%q, %q: got %q, want %q", tc.arg, tc.file, got, tc.want)
+		}
+	}
+}
+
+func TestOperationFlags_load(t *testing.T) {
+	tests := []struct {
+		variablesJSON string
+		vars          varFlag
+		want          map[string]interface{}
+		err           string
+	}{
+		{
+			want: map[string]interface{}{},
+		},
+		{
+			variablesJSON: `{"login": "gopher", "first": 10}`,
+			want:          map[string]interface{}{"login": "gopher", "first": float64(10)},
+		},
+		{
+			variablesJSON: `{"login": "gopher", "first": 10}`,
+			vars:          varFlag{"first": float64(5), "after": "x"},
+			want:          map[string]interface{}{"login": "gopher", "first": float64(5), "after": "x"},
+		},
+		{
+			variablesJSON: `[1]`,
+			err:           "-variables: json: cannot unmarshal array into Go value of type map[string]interface {}",
+		},
+	}
+	for _, tc := range tests {
+		file := ""
+		f := &operationFlags{file: &file, variablesJSON: &tc.variablesJSON, vars: tc.vars}
+		query, variables, err := f.load("{a}")
+		if tc.err != "" {
+			if err == nil || err.Error() != tc.err {
+				t.Errorf("%s: got error: %v, want: %v", tc.variablesJSON, err, tc.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tc.variablesJSON, err)
+			continue
+		}
+		if query != "{a}" {
+			t.Errorf("%s: got query %q, want %q", tc.variablesJSON, query, "{a}")
+		}
+		if !reflect.DeepEqual(variables, tc.want) {
+			t.Errorf("%s: got variables %v, want %v", tc.variablesJSON, variables, tc.want)
+		}
+	}
+}
+
+func TestFormatError(t *testing.T) {
+	tests := []struct {
+		path      []interface{}
+		locations [][2]int
+		want      string
+	}{
+		{want: "not found"},
+		{path: []interface{}{"viewer"}, want: "viewer: not found"},
+		{path: []interface{}{"repository", "issues", float64(2), "title"}, want: "repository.issues.2.title: not found"},
+		{locations: [][2]int{{1, 3}}, want: "not found (1:3)"},
+		{locations: [][2]int{{1, 3}, {4, 5}}, want: "not found (1:3, 4:5)"},
+		{path: []interface{}{"viewer", "login"}, locations: [][2]int{{2, 7}}, want: "viewer.login: not found (2:7)"},
+	}
+	for _, tc := range tests {
+		if got := formatError("not found", tc.path, tc.locations); got != tc.want {
+			t.Errorf("got %q, want %q", got, tc.want)
+		}
+	}
+}
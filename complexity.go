@@ -0,0 +1,289 @@
+package graphql
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/dbmedialab/go-graphql-client/internal/document"
+	"github.com/dbmedialab/go-graphql-client/introspection"
+)
+
+// Complexity is an estimate of the work a server does to execute an
+// operation, as estimated by CostModel.Estimate.
+type Complexity struct {
+	// Fields is the number of fields selected, including those of the
+	// fragments spread.
+	Fields int
+	// Depth is the deepest nesting of fields, as MaxDepth counts it.
+	Depth int
+	// Cost is the sum of the weights of the fields selected, each
+	// multiplied by the number of items of the lists it's selected in.
+	Cost int
+}
+
+// CostModel estimates the complexity of operations the way gateways that
+// enforce cost budgets do, so that operations that would exceed a budget
+// can be rejected before they're sent. Each field costs its weight, for
+// each item of the lists it's selected in. The size of a list is given by
+// the first of the field's ListSizeArgs it has, such as first: 100 on a
+// connection, which sizes the connection's edges and nodes. The zero value
+// gives every field a weight of 1, and sizes lists by first and last.
+//
+// Its Cost method can serve as the Cost of a CostBudget.
+type CostModel struct {
+	// Schema, if not nil, tells which fields are lists, and the types that
+	// Weights can name fields of.
+	Schema *introspection.Schema
+
+	// Weights are the costs of fields, by "Type.field" if Schema knows the
+	// type they're selected on, or by name. If a field isn't given, it
+	// costs DefaultWeight.
+	Weights map[string]int
+
+	// DefaultWeight is the cost of fields not in Weights. If zero, 1 is used.
+	DefaultWeight int
+
+	// ListSizeArgs are the arguments that give the number of items of a
+	// list. If nil, "first" and "last" are used.
+	ListSizeArgs []string
+
+	// DefaultListSize is the number of items assumed for fields that
+	// Schema says are lists, but that aren't sized by an argument, either
+	// their own or that of the connection they're the edges or nodes of.
+	// If zero, 1 is used.
+	DefaultListSize int
+}
+
+// Estimate returns the complexity of the operation that req executes, with
+// its variables as the values of arguments that are variables. Fragments
+// spread must be defined in req.Query.
+func (m *CostModel) Estimate(req Request) (Complexity, error) {
+	doc, err := document.Parse(req.Query)
+	if err != nil {
+		return Complexity{}, err
+	}
+	op := doc.Operation(req.OperationName)
+	if op == nil {
+		if req.OperationName == "" {
+			return Complexity{}, fmt.Errorf("graphql: document has several operations, but no operation name")
+		}
+		return Complexity{}, fmt.Errorf("graphql: document has no operation %s", req.OperationName)
+	}
+	e := estimator{model: m, doc: doc, variables: req.Variables, spreading: map[string]bool{}}
+	var root *introspection.Type
+	if s := m.Schema; s != nil {
+		var name *introspection.TypeName
+		switch op.Type {
+		case "query":
+			name = s.QueryType
+		case "mutation":
+			name = s.MutationType
+		case "subscription":
+			name = s.SubscriptionType
+		}
+		if name != nil {
+			root = s.Type(name.Name)
+		}
+	}
+	e.selectionSet(root, op.SelectionSet, 1, 1, false)
+	return e.c, e.err
+}
+
+// Cost returns the estimated cost of req, or 1 if it can't be estimated,
+// so that the server reports what's wrong with it.
+func (m *CostModel) Cost(req Request) int {
+	c, err := m.Estimate(req)
+	if err != nil {
+		return 1
+	}
+	return c.Cost
+}
+
+// MaxCost makes Query and Mutate fail, without sending anything, if the
+// cost of the operation, as m estimates it with the variables given, is
+// more than max. WithQueryOptions sets a maximum for every operation of a
+// client.
+func MaxCost(m *CostModel, max int) QueryOption {
+	return func(o *queryOptions) {
+		o.costModel, o.maxCost = m, max
+	}
+}
+
+// checkCost returns an error if the cost of the operation in query, with
+// the definitions of the fragments it spreads, is more than the maximum the
+// query options give.
+func checkCost(query string, variables map[string]interface{}, o *queryOptions) error {
+	if o.costModel == nil {
+		return nil
+	}
+	c, err := o.costModel.Estimate(Request{Query: query, Variables: variables})
+	if err != nil {
+		return err
+	}
+	if c.Cost > o.maxCost {
+		return fmt.Errorf("graphql: operation's estimated cost of %d is more than the maximum of %d", c.Cost, o.maxCost)
+	}
+	return nil
+}
+
+// estimator adds up the complexity of an operation.
+type estimator struct {
+	model     *CostModel
+	doc       *document.Document
+	variables map[string]interface{}
+	spreading map[string]bool // Fragments being spread, to stop cycles.
+
+	c   Complexity
+	err error
+}
+
+// selectionSet adds the complexity of selections on parent, which is nil if
+// it's not known, at depth, selected mult times. If sized, the selections
+// are those of a field sized by an argument.
+func (e *estimator) selectionSet(parent *introspection.Type, selections []document.Selection, depth, mult int, sized bool) {
+	for _, sel := range selections {
+		switch sel := sel.(type) {
+		case *document.Field:
+			e.field(parent, sel, depth, mult, sized)
+		case *document.InlineFragment:
+			on := parent
+			if sel.TypeCondition != "" && e.model.Schema != nil {
+				on = e.model.Schema.Type(sel.TypeCondition)
+			}
+			e.selectionSet(on, sel.SelectionSet, depth, mult, sized)
+		case *document.FragmentSpread:
+			f := e.doc.Fragment(sel.Name)
+			if f == nil {
+				if e.err == nil {
+					e.err = fmt.Errorf("graphql: fragment %s isn't defined", sel.Name)
+				}
+				continue
+			}
+			if e.spreading[f.Name] {
+				continue
+			}
+			var on *introspection.Type
+			if e.model.Schema != nil {
+				on = e.model.Schema.Type(f.TypeCondition)
+			}
+			e.spreading[f.Name] = true
+			e.selectionSet(on, f.SelectionSet, depth, mult, sized)
+			e.spreading[f.Name] = false
+		}
+	}
+}
+
+func (e *estimator) field(parent *introspection.Type, f *document.Field, depth, mult int, sized bool) {
+	e.c.Fields++
+	if depth > e.c.Depth {
+		e.c.Depth = depth
+	}
+	var def *introspection.Field
+	if parent != nil {
+		def = parent.Field(f.Name)
+	}
+	e.c.Cost += mult * e.weight(parent, f.Name)
+	if len(f.SelectionSet) == 0 {
+		return
+	}
+
+	var child *introspection.Type
+	if def != nil {
+		child = e.model.Schema.Type(namedTypeName(def.Type))
+	}
+	if size, ok := e.listSize(f); ok {
+		e.selectionSet(child, f.SelectionSet, depth+1, mult*size, true)
+		return
+	}
+	if def != nil && isListType(def.Type) && !sized {
+		size := e.model.DefaultListSize
+		if size == 0 {
+			size = 1
+		}
+		mult *= size
+	}
+	e.selectionSet(child, f.SelectionSet, depth+1, mult, false)
+}
+
+// weight returns the cost of the field called name on parent.
+func (e *estimator) weight(parent *introspection.Type, name string) int {
+	if parent != nil {
+		if w, ok := e.model.Weights[parent.Name+"."+name]; ok {
+			return w
+		}
+	}
+	if w, ok := e.model.Weights[name]; ok {
+		return w
+	}
+	if e.model.DefaultWeight != 0 {
+		return e.model.DefaultWeight
+	}
+	return 1
+}
+
+// listSize returns the size of the list f is, as given by its arguments,
+// if they do.
+func (e *estimator) listSize(f *document.Field) (int, bool) {
+	names := e.model.ListSizeArgs
+	if names == nil {
+		names = []string{"first", "last"}
+	}
+	for _, name := range names {
+		for _, a := range f.Arguments {
+			if a.Name != name {
+				continue
+			}
+			switch a.Value.Kind {
+			case document.IntValue:
+				if n, err := strconv.Atoi(a.Value.Raw); err == nil {
+					return n, true
+				}
+			case document.VariableValue:
+				if n, ok := intValue(e.variables[a.Value.Name]); ok {
+					return n, true
+				}
+			}
+		}
+	}
+	return 0, false
+}
+
+// intValue returns the value of v, a variable, if it's an integer.
+func intValue(v interface{}) (int, bool) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return int(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		// Variables decoded from JSON.
+		if f := rv.Float(); f == float64(int(f)) {
+			return int(f), true
+		}
+	}
+	return 0, false
+}
+
+// isListType reports whether t is a list type, or a non-null one.
+func isListType(t introspection.TypeRef) bool {
+	if t.Kind == "NON_NULL" && t.OfType != nil {
+		t = *t.OfType
+	}
+	return t.Kind == "LIST"
+}
+
+// namedTypeName returns the name of the type t wraps, or is.
+func namedTypeName(t introspection.TypeRef) string {
+	for t.OfType != nil {
+		t = *t.OfType
+	}
+	if t.Name == nil {
+		return ""
+	}
+	return *t.Name
+}
@@ -0,0 +1,151 @@
+package graphql_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dbmedialab/go-graphql-client"
+	"github.com/dbmedialab/go-graphql-client/introspection"
+)
+
+func TestCostModel_Estimate(t *testing.T) {
+	schema, err := introspection.Parse([]byte(`{"__schema": {
+		"queryType": {"name": "Query"},
+		"types": [
+			{"kind": "OBJECT", "name": "Query", "fields": [
+				{"name": "repository", "args": [], "type": {"kind": "OBJECT", "name": "Repository"}}
+			]},
+			{"kind": "OBJECT", "name": "Repository", "fields": [
+				{"name": "name", "args": [], "type": {"kind": "SCALAR", "name": "String"}},
+				{"name": "issues", "args": [], "type": {"kind": "OBJECT", "name": "IssueConnection"}},
+				{"name": "languages", "args": [], "type": {"kind": "LIST", "ofType": {"kind": "OBJECT", "name": "Language"}}}
+			]},
+			{"kind": "OBJECT", "name": "IssueConnection", "fields": [
+				{"name": "nodes", "args": [], "type": {"kind": "LIST", "ofType": {"kind": "OBJECT", "name": "Issue"}}}
+			]},
+			{"kind": "OBJECT", "name": "Issue", "fields": [
+				{"name": "title", "args": [], "type": {"kind": "SCALAR", "name": "String"}},
+				{"name": "labels", "args": [], "type": {"kind": "OBJECT", "name": "LabelConnection"}}
+			]},
+			{"kind": "OBJECT", "name": "LabelConnection", "fields": [
+				{"name": "nodes", "args": [], "type": {"kind": "LIST", "ofType": {"kind": "OBJECT", "name": "Label"}}}
+			]},
+			{"kind": "OBJECT", "name": "Label", "fields": [
+				{"name": "name", "args": [], "type": {"kind": "SCALAR", "name": "String"}}
+			]},
+			{"kind": "OBJECT", "name": "Language", "fields": [
+				{"name": "name", "args": [], "type": {"kind": "SCALAR", "name": "String"}}
+			]}
+		]
+	}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := `query($n: Int!) {
+		repository {
+			name
+			issues(first: $n) { nodes { ...issue } }
+			languages { name }
+		}
+	}
+	fragment issue on Issue { title labels(first: 5) { nodes { name } } }`
+
+	tests := []struct {
+		name  string
+		model graphql.CostModel
+		want  graphql.Complexity
+	}{
+		{
+			name:  "zero",
+			model: graphql.CostModel{},
+			// repository 1, name 1, issues 1, nodes 10, title 10,
+			// labels 10, nodes 50, name 50, languages 1, name 1.
+			want: graphql.Complexity{Fields: 10, Depth: 6, Cost: 135},
+		},
+		{
+			name:  "schema and weights",
+			model: graphql.CostModel{Schema: schema, Weights: map[string]int{"Issue.title": 0, "name": 0}, DefaultListSize: 20},
+			// As above, but titles and names are free, and
+			// languages are a list of 20.
+			want: graphql.Complexity{Fields: 10, Depth: 6, Cost: 73},
+		},
+	}
+	for _, tc := range tests {
+		got, err := tc.model.Estimate(graphql.Request{Query: query, Variables: map[string]interface{}{"n": graphql.Int(10)}})
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if got != tc.want {
+			t.Errorf("%s: got %+v, want %+v", tc.name, got, tc.want)
+		}
+	}
+
+	_, err = (&graphql.CostModel{}).Estimate(graphql.Request{Query: "{a{...b}}"})
+	if got, want := err, "graphql: fragment b isn't defined"; got == nil || got.Error() != want {
+		t.Errorf("got error: %v, want: %v", got, want)
+	}
+}
+
+func TestMaxCost(t *testing.T) {
+	sent := 0
+	transport := graphql.TransportFunc(func(ctx context.Context, req graphql.Request) (*graphql.Response, error) {
+		sent++
+		return &graphql.Response{Data: []byte(`{"viewer": {"repositories": {"nodes": []}}}`)}, nil
+	})
+	var q struct {
+		Viewer struct {
+			Repositories struct {
+				Nodes []struct {
+					Name string
+				}
+			} `graphql:"repositories(first: $first)"`
+		}
+	}
+	client := graphql.NewPluggableClient(transport, graphql.WithQueryOptions(graphql.MaxCost(&graphql.CostModel{}, 50)))
+
+	// viewer 1, repositories 1, nodes 10, name 10.
+	if err := client.Query(context.Background(), &q, map[string]interface{}{"first": graphql.Int(10)}); err != nil {
+		t.Fatal(err)
+	}
+	err := client.Query(context.Background(), &q, map[string]interface{}{"first": graphql.Int(100)})
+	if got, want := err, "graphql: operation's estimated cost of 202 is more than the maximum of 50"; got == nil || got.Error() != want {
+		t.Errorf("got error: %v, want: %v", got, want)
+	}
+	if sent != 1 {
+		t.Errorf("got %d operations sent, want 1", sent)
+	}
+}
+
+func TestMaxCost_fragments(t *testing.T) {
+	transport := graphql.TransportFunc(func(ctx context.Context, req graphql.Request) (*graphql.Response, error) {
+		return &graphql.Response{Data: []byte(`{"viewer": {"login": "gopher", "avatarUrl": ""}}`)}, nil
+	})
+	var q struct {
+		Viewer struct {
+			userFields `graphql:"...userFields"`
+		}
+	}
+	for _, tc := range []struct {
+		max  int
+		want string
+	}{
+		{3, ""},
+		{2, "graphql: operation's estimated cost of 3 is more than the maximum of 2"},
+	} {
+		client := graphql.NewPluggableClient(transport, graphql.WithQueryOptions(graphql.MaxCost(&graphql.CostModel{}, tc.max)))
+		if err := client.RegisterFragment("userFields", "User", userFields{}); err != nil {
+			t.Fatal(err)
+		}
+		if err := client.RegisterFragment("avatarFields", "Actor", avatarFields{}); err != nil {
+			t.Fatal(err)
+		}
+		// The fields of fragments count.
+		err := client.Query(context.Background(), &q, nil)
+		if tc.want == "" && err != nil {
+			t.Errorf("max %d: got error: %v", tc.max, err)
+		}
+		if tc.want != "" && (err == nil || err.Error() != tc.want) {
+			t.Errorf("max %d: got error: %v, want: %v", tc.max, err, tc.want)
+		}
+	}
+}
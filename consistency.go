@@ -0,0 +1,94 @@
+package graphql
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+// ConsistencyTransport is an http.RoundTripper that gives read-your-writes
+// consistency with eventually consistent servers that support it: it
+// captures the consistency token that responses, typically to mutations,
+// carry in a header, and attaches the latest one to each later request.
+// Use it as the transport of the http.Client given to NewClient.
+//
+// The token is shared by every request sent with the transport, unless a
+// request's context was made by WithConsistencyScope, in which case it's
+// shared by the requests made with that context and contexts derived from it.
+type ConsistencyTransport struct {
+	// Base is the transport to send requests with.
+	// If nil, http.DefaultTransport is used.
+	Base http.RoundTripper
+
+	// Header is the name of the response header holding the token, and of
+	// the request header it's attached in. If empty, "X-Consistency-Token"
+	// is used.
+	Header string
+
+	token consistencyToken
+}
+
+// consistencyToken holds the latest consistency token in a scope.
+type consistencyToken struct {
+	mu    sync.Mutex
+	value string
+}
+
+func (t *consistencyToken) get() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.value
+}
+
+func (t *consistencyToken) set(value string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.value = value
+}
+
+type consistencyKey struct{}
+
+// WithConsistencyScope returns a copy of ctx with which a ConsistencyTransport
+// keeps the consistency token separately from other requests, so that a
+// chain of operations, such as those handling one user request, reads its
+// own writes without waiting on everyone else's.
+func WithConsistencyScope(ctx context.Context) context.Context {
+	return context.WithValue(ctx, consistencyKey{}, &consistencyToken{})
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *ConsistencyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	header := t.Header
+	if header == "" {
+		header = "X-Consistency-Token"
+	}
+	token, ok := req.Context().Value(consistencyKey{}).(*consistencyToken)
+	if !ok {
+		token = &t.token
+	}
+
+	if value := token.get(); value != "" {
+		// Don't modify the caller's request.
+		r := new(http.Request)
+		*r = *req
+		r.Header = make(http.Header, len(req.Header)+1)
+		for k, v := range req.Header {
+			r.Header[k] = v
+		}
+		r.Header.Set(header, value)
+		req = r
+	}
+
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if value := resp.Header.Get(header); value != "" {
+		token.set(value)
+	}
+	return resp, nil
+}
@@ -0,0 +1,65 @@
+package graphql_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/dbmedialab/go-graphql-client"
+)
+
+func TestConsistencyTransport(t *testing.T) {
+	var gotToken string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		gotToken = req.Header.Get("X-Consistency-Token")
+		if req.URL.Query().Get("write") != "" {
+			w.Header().Set("X-Consistency-Token", req.URL.Query().Get("write"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+	})
+	transport := &graphql.ConsistencyTransport{Base: localRoundTripper{handler: mux}}
+	httpClient := &http.Client{Transport: transport}
+	reads := graphql.NewClient("/graphql", httpClient)
+
+	var q struct {
+		Viewer struct {
+			Login graphql.String
+		}
+	}
+	write := func(ctx context.Context, token string) {
+		// Pretend to write; the server answers with a new token.
+		writes := graphql.NewClient("/graphql?write="+token, httpClient)
+		if err := writes.Mutate(ctx, &q, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	read := func(ctx context.Context) string {
+		if err := reads.Query(ctx, &q, nil); err != nil {
+			t.Fatal(err)
+		}
+		return gotToken
+	}
+
+	if got := read(context.Background()); got != "" {
+		t.Errorf("got token %q before any write, want none", got)
+	}
+	write(context.Background(), "t1")
+	if got, want := read(context.Background()), "t1"; got != want {
+		t.Errorf("got token %q, want %q", got, want)
+	}
+
+	// Scoped tokens are kept apart from the shared one.
+	scoped := graphql.WithConsistencyScope(context.Background())
+	if got := read(scoped); got != "" {
+		t.Errorf("got token %q in new scope, want none", got)
+	}
+	write(scoped, "t2")
+	if got, want := read(scoped), "t2"; got != want {
+		t.Errorf("got scoped token %q, want %q", got, want)
+	}
+	if got, want := read(context.Background()), "t1"; got != want {
+		t.Errorf("got shared token %q, want %q", got, want)
+	}
+}
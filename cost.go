@@ -0,0 +1,144 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrOverBudget is returned for operations that a CostBudget refused to
+// execute because their cost would exceed the budget.
+var ErrOverBudget = withKinds(fmt.Errorf("graphql: operation exceeds cost budget"), ErrRateLimited)
+
+type tenantKey struct{}
+
+// WithTenant returns a copy of ctx with which operations are charged to the
+// budget of tenant, for a CostBudget.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// CostBudget limits the total estimated cost of the operations executed in
+// each window of time, per tenant. Install it with WithMiddleware(b.Middleware).
+// Operations are charged to the tenant set with WithTenant, or to "" if none.
+//
+// When one of a tenant's operations fails, with the server saying when to
+// retry with a Retry-After header or a retryAfter extension, as RetryAfter
+// reads them, the tenant's operations are refused until then.
+type CostBudget struct {
+	// Limit is the total cost each tenant may spend per window.
+	Limit int
+
+	// Window is the period after which spending is reset.
+	// If zero, a minute is used.
+	Window time.Duration
+
+	// Cost estimates the cost of a request.
+	// If nil, every operation costs 1.
+	Cost func(Request) int
+
+	// Clock tells the time. If nil, the system clock is used.
+	Clock Clock
+
+	mu      sync.Mutex
+	spent   map[string]*costWindow
+	refused int64
+}
+
+type costWindow struct {
+	period int64 // The window spent is for, in units of the window duration.
+	spent  int
+	until  time.Time // Before which the server asked for operations to be put off.
+}
+
+// Middleware refuses operations sent through next, with ErrOverBudget,
+// if their cost would take their tenant over the limit.
+func (b *CostBudget) Middleware(next Transport) Transport {
+	return TransportFunc(func(ctx context.Context, req Request) (*Response, error) {
+		tenant, _ := ctx.Value(tenantKey{}).(string)
+		cost := 1
+		if b.Cost != nil {
+			cost = b.Cost(req)
+		}
+		if !b.charge(tenant, cost) {
+			return nil, ErrOverBudget
+		}
+		resp, err := next.Do(ctx, req)
+		if after, ok := retryAfter(resp, err); ok && (err != nil || len(resp.Errors) > 0) {
+			// A success may still tell when the next operation can be
+			// made, but it's only a refusal that's waited for.
+			b.holdOff(tenant, after)
+		}
+		return resp, err
+	})
+}
+
+// holdOff refuses tenant's operations for d.
+func (b *CostBudget) holdOff(tenant string, d time.Duration) {
+	until := clockOrSystem(b.Clock).Now().Add(d)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	w := b.window(tenant)
+	if until.After(w.until) {
+		w.until = until
+	}
+}
+
+// Remaining returns how much of tenant's budget is left in the current window.
+func (b *CostBudget) Remaining(tenant string) int {
+	period := b.period()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	w := b.spent[tenant]
+	if w == nil || w.period != period {
+		return b.Limit
+	}
+	return b.Limit - w.spent
+}
+
+// Refusals returns how many operations have been refused so far.
+func (b *CostBudget) Refusals() int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.refused
+}
+
+// charge spends cost from tenant's budget, reporting whether there was enough left.
+func (b *CostBudget) charge(tenant string, cost int) bool {
+	now := clockOrSystem(b.Clock).Now()
+	period := b.period()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	w := b.window(tenant)
+	if w.period != period {
+		w.period, w.spent = period, 0
+	}
+	if w.spent+cost > b.Limit || now.Before(w.until) {
+		b.refused++
+		return false
+	}
+	w.spent += cost
+	return true
+}
+
+// window returns tenant's window, adding it if needed. b.mu must be held.
+func (b *CostBudget) window(tenant string) *costWindow {
+	if b.spent == nil {
+		b.spent = map[string]*costWindow{}
+	}
+	w := b.spent[tenant]
+	if w == nil {
+		w = &costWindow{}
+		b.spent[tenant] = w
+	}
+	return w
+}
+
+func (b *CostBudget) period() int64 {
+	d := b.Window
+	if d == 0 {
+		d = time.Minute
+	}
+	return clockOrSystem(b.Clock).Now().UnixNano() / int64(d)
+}
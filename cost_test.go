@@ -0,0 +1,87 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dbmedialab/go-graphql-client"
+)
+
+func TestCostBudget(t *testing.T) {
+	clock := &settableClock{t: time.Unix(600, 0)}
+	b := &graphql.CostBudget{
+		Limit:  5,
+		Window: time.Minute,
+		// Charge one per field, as a stand-in for a real estimate.
+		Cost:  func(req graphql.Request) int { return strings.Count(req.Query, " ") + 1 },
+		Clock: clock,
+	}
+	transport := graphql.TransportFunc(func(ctx context.Context, req graphql.Request) (*graphql.Response, error) {
+		return &graphql.Response{Data: []byte(`{}`)}, nil
+	})
+	client := graphql.NewPluggableClient(transport, graphql.WithMiddleware(b.Middleware))
+	query := func(ctx context.Context, query string) error {
+		var q struct{}
+		return client.QueryCustom(ctx, &q, query, nil)
+	}
+
+	if err := query(context.Background(), "{a b c}"); err != nil {
+		t.Fatal(err)
+	}
+	if err := query(context.Background(), "{a b c}"); err != graphql.ErrOverBudget {
+		t.Errorf("got error: %v, want: ErrOverBudget", err)
+	}
+	if got, want := b.Remaining(""), 2; got != want {
+		t.Errorf("got remaining: %d, want: %d", got, want)
+	}
+
+	// Other tenants have their own budgets.
+	if err := query(graphql.WithTenant(context.Background(), "acme"), "{a b c}"); err != nil {
+		t.Errorf("tenant acme: %v", err)
+	}
+
+	// Budgets are reset each window.
+	clock.t = clock.t.Add(time.Minute)
+	if err := query(context.Background(), "{a b c}"); err != nil {
+		t.Errorf("after window: %v", err)
+	}
+	if got, want := b.Refusals(), int64(1); got != want {
+		t.Errorf("got refusals: %d, want: %d", got, want)
+	}
+}
+
+func TestCostBudget_retryAfter(t *testing.T) {
+	clock := &settableClock{t: time.Unix(600, 0)}
+	b := &graphql.CostBudget{Limit: 100, Clock: clock}
+	limited := true
+	transport := graphql.TransportFunc(func(ctx context.Context, req graphql.Request) (*graphql.Response, error) {
+		if limited {
+			limited = false
+			resp := &graphql.Response{Data: []byte(`null`)}
+			err := json.Unmarshal([]byte(`[{"message": "throttled", "extensions": {"retryAfter": 30}}]`), &resp.Errors)
+			return resp, err
+		}
+		return &graphql.Response{Data: []byte(`{}`)}, nil
+	})
+	client := graphql.NewPluggableClient(transport, graphql.WithMiddleware(b.Middleware))
+	var q struct{}
+
+	if err := client.QueryCustom(context.Background(), &q, "{a}", nil); err == nil {
+		t.Fatal("got error: nil, want: non-nil")
+	}
+	// The tenant is held off for as long as the server asked.
+	clock.t = clock.t.Add(29 * time.Second)
+	if err := client.QueryCustom(context.Background(), &q, "{a}", nil); err != graphql.ErrOverBudget {
+		t.Errorf("got error: %v, want: ErrOverBudget", err)
+	}
+	if err := client.QueryCustom(graphql.WithTenant(context.Background(), "acme"), &q, "{a}", nil); err != nil {
+		t.Errorf("tenant acme: %v", err)
+	}
+	clock.t = clock.t.Add(time.Second)
+	if err := client.QueryCustom(context.Background(), &q, "{a}", nil); err != nil {
+		t.Errorf("after waiting: %v", err)
+	}
+}
@@ -0,0 +1,45 @@
+package graphql
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+)
+
+// Marshaler is implemented by custom scalars, such as UUIDs, amounts of
+// money or GeoJSON geometries, that encode themselves as variables.
+// MarshalGQL writes the value encoded as JSON to w. The methods are those
+// of gqlgen's server-side scalars, so the same types can serve both ends.
+//
+// A struct type implementing Marshaler or Unmarshaler is a leaf of queries:
+// its fields aren't selected.
+type Marshaler interface {
+	MarshalGQL(w io.Writer)
+}
+
+// Unmarshaler is implemented by custom scalars that decode themselves from
+// responses. UnmarshalGQL is called with the scalar's value, as encoding/json
+// decodes it into an interface{}, except that numbers are json.Number.
+// Null values aren't passed to it: they leave scalars as they are, and set
+// pointers to them to nil.
+type Unmarshaler interface {
+	UnmarshalGQL(v interface{}) error
+}
+
+var (
+	gqlMarshaler   = reflect.TypeOf((*Marshaler)(nil)).Elem()
+	gqlUnmarshaler = reflect.TypeOf((*Unmarshaler)(nil)).Elem()
+)
+
+// marshaledValue is a variable value whose JSON encoding is written by its
+// MarshalGQL method.
+type marshaledValue struct {
+	m Marshaler
+}
+
+// MarshalJSON encodes v as MarshalGQL writes it.
+func (v marshaledValue) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	v.m.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,114 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"testing"
+
+	"github.com/dbmedialab/go-graphql-client"
+)
+
+// money is a custom scalar encoded as an object.
+type money struct {
+	cents    int64
+	currency string
+}
+
+func (m money) MarshalGQL(w io.Writer) {
+	fmt.Fprintf(w, `{"cents":%d,"currency":%q}`, m.cents, m.currency)
+}
+
+func (m *money) UnmarshalGQL(v interface{}) error {
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("money must be an object, not %T", v)
+	}
+	cents, err := obj["cents"].(json.Number).Int64()
+	if err != nil {
+		return err
+	}
+	m.cents, m.currency = cents, obj["currency"].(string)
+	return nil
+}
+
+// point is a custom scalar encoded as a list of coordinates.
+type point []float64
+
+func (p *point) MarshalGQL(w io.Writer) {
+	b, _ := json.Marshal([]float64(*p))
+	w.Write(b)
+}
+
+func (p *point) UnmarshalGQL(v interface{}) error {
+	coords, ok := v.([]interface{})
+	if !ok {
+		return fmt.Errorf("point must be a list, not %T", v)
+	}
+	*p = nil
+	for _, c := range coords {
+		f, err := strconv.ParseFloat(string(c.(json.Number)), 64)
+		if err != nil {
+			return err
+		}
+		*p = append(*p, f)
+	}
+	return nil
+}
+
+func TestClient_Query_customScalars(t *testing.T) {
+	var gotQuery, gotVariables string
+	transport := graphql.TransportFunc(func(ctx context.Context, req graphql.Request) (*graphql.Response, error) {
+		gotQuery = req.Query
+		b, err := json.Marshal(req.Variables)
+		if err != nil {
+			return nil, err
+		}
+		gotVariables = string(b)
+		return &graphql.Response{Data: []byte(`{"product": {
+			"price": {"cents": 150, "currency": "EUR"},
+			"discount": null,
+			"location": [59.9, 10.7],
+			"stores": [[1, 2], null]
+		}}`)}, nil
+	})
+	client := graphql.NewPluggableClient(transport)
+
+	var q struct {
+		Product struct {
+			Price    money
+			Discount *money
+			Location point
+			Stores   []*point
+		} `graphql:"product(maxPrice: $maxPrice, near: $near)"`
+	}
+	discount := money{cents: 1}
+	q.Product.Discount = &discount
+	variables := map[string]interface{}{
+		"maxPrice": money{cents: 200, currency: "EUR"},
+		"near":     point{1, 2},
+	}
+	if err := client.Query(context.Background(), &q, variables); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := gotQuery, "query($maxPrice:money!$near:point!){product(maxPrice: $maxPrice, near: $near){price,discount,location,stores}}"; got != want {
+		t.Errorf("got query: %q, want: %q", got, want)
+	}
+	if got, want := gotVariables, `{"maxPrice":{"cents":200,"currency":"EUR"},"near":[1,2]}`; got != want {
+		t.Errorf("got variables: %s, want: %s", got, want)
+	}
+	if got, want := q.Product.Price, (money{cents: 150, currency: "EUR"}); got != want {
+		t.Errorf("got price: %+v, want: %+v", got, want)
+	}
+	if q.Product.Discount != nil {
+		t.Errorf("got discount: %+v, want: nil", *q.Product.Discount)
+	}
+	if got, want := fmt.Sprint(q.Product.Location), "[59.9 10.7]"; got != want {
+		t.Errorf("got location: %s, want: %s", got, want)
+	}
+	if len(q.Product.Stores) != 2 || q.Product.Stores[1] != nil || fmt.Sprint(*q.Product.Stores[0]) != "[1 2]" {
+		t.Errorf("got stores: %v, want: [[1 2] nil]", q.Product.Stores)
+	}
+}
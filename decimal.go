@@ -0,0 +1,33 @@
+package graphql
+
+import (
+	"fmt"
+	"reflect"
+)
+
+var stringer = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+
+// WithDecimalType declares variables of t, an arbitrary-precision decimal
+// type such as shopspring/decimal's decimal.Decimal, as the GraphQL scalar
+// named name, such as "Decimal" or "BigDecimal", and sends them as strings
+// of their exact values, so that amounts of money don't lose precision to
+// float64 on the way. The adapter is small: t must have a String method
+// giving its value, and *t an UnmarshalText method parsing one, which
+// decodes response fields of type t from numbers as well as strings.
+//
+// WithDecimalType panics if t doesn't have those methods.
+func WithDecimalType(t reflect.Type, name string) ClientOption {
+	if !t.Implements(stringer) || !reflect.PtrTo(t).Implements(textUnmarshaler) {
+		panic(fmt.Sprintf("graphql: WithDecimalType: %v needs String and UnmarshalText methods", t))
+	}
+	setType := WithScalarTypes(ScalarTypes{t: name})
+	return func(c *Client) {
+		decimals := make(map[reflect.Type]bool, len(c.decimals)+1)
+		for t := range c.decimals {
+			decimals[t] = true
+		}
+		decimals[t] = true
+		c.decimals = decimals
+		setType(c)
+	}
+}
@@ -0,0 +1,74 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dbmedialab/go-graphql-client"
+)
+
+// amount is a decimal adapter, as one would be written for a decimal
+// package, that keeps the digits it's given.
+type amount struct {
+	digits string
+}
+
+func (a amount) String() string { return a.digits }
+
+func (a *amount) UnmarshalText(text []byte) error {
+	if strings.Trim(string(text), "-.0123456789") != "" {
+		return fmt.Errorf("invalid amount %q", text)
+	}
+	a.digits = string(text)
+	return nil
+}
+
+func TestWithDecimalType(t *testing.T) {
+	var gotQuery, gotVariables string
+	transport := graphql.TransportFunc(func(ctx context.Context, req graphql.Request) (*graphql.Response, error) {
+		gotQuery = req.Query
+		b, err := json.Marshal(req.Variables)
+		gotVariables = string(b)
+		return &graphql.Response{Data: []byte(`{"transfer": {"amount": 12345678901234567.89, "fee": "0.10"}}`)}, err
+	})
+	type TransferInput struct {
+		Amount amount `graphql:"amount"`
+	}
+	var q struct {
+		Transfer struct {
+			Amount amount
+			Fee    *amount
+		} `graphql:"transfer(input: $input, limit: $limit)"`
+	}
+	variables := map[string]interface{}{
+		"input": TransferInput{Amount: amount{"12345678901234567.89"}},
+		"limit": amount{"1e3"},
+	}
+	client := graphql.NewPluggableClient(transport, graphql.WithDecimalType(reflect.TypeOf(amount{}), "Decimal"))
+	if err := client.Mutate(context.Background(), &q, variables); err != nil {
+		t.Fatal(err)
+	}
+	if want := `mutation($input:TransferInput!$limit:Decimal!){transfer(input: $input, limit: $limit){amount,fee}}`; gotQuery != want {
+		t.Errorf("got query: %s, want: %s", gotQuery, want)
+	}
+	if want := `{"input":{"amount":"12345678901234567.89"},"limit":"1e3"}`; gotVariables != want {
+		t.Errorf("got variables: %s, want: %s", gotVariables, want)
+	}
+	if got, want := q.Transfer.Amount.String(), "12345678901234567.89"; got != want {
+		t.Errorf("got amount: %s, want: %s", got, want)
+	}
+	if got, want := q.Transfer.Fee.String(), "0.10"; got != want {
+		t.Errorf("got fee: %s, want: %s", got, want)
+	}
+
+	defer func() {
+		if got, want := recover(), "graphql: WithDecimalType: int needs String and UnmarshalText methods"; got != want {
+			t.Errorf("got panic: %v, want: %v", got, want)
+		}
+	}()
+	graphql.WithDecimalType(reflect.TypeOf(0), "Decimal")
+}
@@ -0,0 +1,113 @@
+package graphql
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/dbmedialab/go-graphql-client/jsonutil"
+)
+
+// DecodeHook transforms a value decoded from a response, for example to
+// trim strings, normalize currency codes, or map legacy enum values to
+// current ones. v is a pointer to the value, which the hook may change.
+// An error stops decoding, and is returned from the operation.
+type DecodeHook func(v interface{}) error
+
+// WithDecodeHook calls hook with every value of type t decoded into the
+// results of the client's operations, once the value has been decoded
+// (including, for a struct, its fields). Values of type *t are passed to
+// hook unless they're nil.
+func WithDecodeHook(t reflect.Type, hook DecodeHook) ClientOption {
+	return func(c *Client) {
+		c.decodeHooks = c.decodeHooks.with(func(h *decodeHooks) {
+			h.byType[t] = append(h.byType[t][:len(h.byType[t]):len(h.byType[t])], hook)
+		})
+	}
+}
+
+// WithTaggedDecodeHook calls hook with the values decoded into struct fields
+// that name it in their graphql-decode tag, which is a comma-separated
+// list of hook names. E.g.,
+//
+//	Title graphql.String `graphql-decode:"trim"`
+//
+// Hooks for a field's type are called before those for its tag.
+func WithTaggedDecodeHook(name string, hook DecodeHook) ClientOption {
+	return func(c *Client) {
+		c.decodeHooks = c.decodeHooks.with(func(h *decodeHooks) {
+			h.byTag[name] = append(h.byTag[name][:len(h.byTag[name]):len(h.byTag[name])], hook)
+		})
+	}
+}
+
+// decodeHooks are the decode hooks of a client. They're copied on write,
+// since they may be shared with clients the client was cloned from.
+type decodeHooks struct {
+	byType map[reflect.Type][]DecodeHook
+	byTag  map[string][]DecodeHook
+}
+
+// with returns a copy of h changed by change.
+func (h decodeHooks) with(change func(*decodeHooks)) decodeHooks {
+	c := decodeHooks{
+		byType: make(map[reflect.Type][]DecodeHook, len(h.byType)+1),
+		byTag:  make(map[string][]DecodeHook, len(h.byTag)+1),
+	}
+	for t, hooks := range h.byType {
+		c.byType[t] = hooks
+	}
+	for name, hooks := range h.byTag {
+		c.byTag[name] = hooks
+	}
+	change(&c)
+	return c
+}
+
+// call calls the hooks for struct field f, or a slice element if f
+// is nil, with its value v.
+func (h decodeHooks) call(f *reflect.StructField, v reflect.Value) error {
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if !v.CanAddr() || v.Kind() == reflect.Ptr {
+		return nil
+	}
+	for _, hook := range h.byType[v.Type()] {
+		if err := hook(v.Addr().Interface()); err != nil {
+			return err
+		}
+	}
+	if f == nil {
+		return nil
+	}
+	tag, ok := f.Tag.Lookup("graphql-decode")
+	if !ok {
+		return nil
+	}
+	for _, name := range strings.Split(tag, ",") {
+		for _, hook := range h.byTag[strings.TrimSpace(name)] {
+			if err := hook(v.Addr().Interface()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// decodeOptions returns the options for decoding the results of the
+// client's operations, reporting progress to progress if it's not nil.
+func (c *Client) decodeOptions(progress *progressReporter) jsonutil.Options {
+	var opts jsonutil.Options
+	if progress != nil {
+		opts.ObjectDecoded = progress.objectDecoded
+	}
+	if len(c.decodeHooks.byType) > 0 || len(c.decodeHooks.byTag) > 0 {
+		opts.Hook = c.decodeHooks.call
+	}
+	if c.fieldNamer != nil {
+		opts.FieldName = c.fieldNamer.name
+	}
+	opts.MaxDepth = c.maxDecodeDepth
+	opts.BytesEncoding = c.bytesEncoding
+	return opts
+}
@@ -0,0 +1,77 @@
+package graphql_test
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dbmedialab/go-graphql-client"
+)
+
+type currency graphql.String
+
+func TestWithDecodeHook(t *testing.T) {
+	transport := graphql.TransportFunc(func(ctx context.Context, req graphql.Request) (*graphql.Response, error) {
+		return &graphql.Response{Data: []byte(`{"product": {"title": "  Shoe ", "price": {"currency": "nok"}, "status": "SOLD_OUT_LEGACY"}}`)}, nil
+	})
+	upper := func(v interface{}) error {
+		c := v.(*currency)
+		*c = currency(strings.ToUpper(string(*c)))
+		return nil
+	}
+	trim := func(v interface{}) error {
+		s := v.(*graphql.String)
+		*s = graphql.String(strings.TrimSpace(string(*s)))
+		return nil
+	}
+	legacy := func(v interface{}) error {
+		s := v.(*graphql.String)
+		switch *s {
+		case "SOLD_OUT_LEGACY":
+			*s = "SOLD_OUT"
+		case "":
+		default:
+			return fmt.Errorf("unknown status %q", *s)
+		}
+		return nil
+	}
+	client := graphql.NewPluggableClient(transport,
+		graphql.WithDecodeHook(reflect.TypeOf(currency("")), upper),
+		graphql.WithTaggedDecodeHook("trim", trim),
+		graphql.WithTaggedDecodeHook("status", legacy),
+	)
+
+	type product struct {
+		Title graphql.String `graphql-decode:"trim"`
+		Price *struct {
+			Currency currency
+		}
+		Status graphql.String `graphql-decode:"trim, status"`
+	}
+	var q struct {
+		Product product
+	}
+	if err := client.Query(context.Background(), &q, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := q.Product.Title, graphql.String("Shoe"); got != want {
+		t.Errorf("got title: %q, want: %q", got, want)
+	}
+	if got, want := q.Product.Price.Currency, currency("NOK"); got != want {
+		t.Errorf("got currency: %q, want: %q", got, want)
+	}
+	if got, want := q.Product.Status, graphql.String("SOLD_OUT"); got != want {
+		t.Errorf("got status: %q, want: %q", got, want)
+	}
+
+	transport = graphql.TransportFunc(func(ctx context.Context, req graphql.Request) (*graphql.Response, error) {
+		return &graphql.Response{Data: []byte(`{"product": {"title": "Shoe", "price": null, "status": "GONE"}}`)}, nil
+	})
+	client = graphql.NewPluggableClient(transport, graphql.WithTaggedDecodeHook("status", legacy))
+	err := client.Query(context.Background(), &q, nil)
+	if got, want := err, `unknown status "GONE"`; got == nil || got.Error() != want {
+		t.Errorf("got error: %v, want: %v", got, want)
+	}
+}
@@ -0,0 +1,90 @@
+package graphql
+
+import (
+	"fmt"
+
+	"github.com/dbmedialab/go-graphql-client/internal/document"
+	"github.com/dbmedialab/go-graphql-client/jsonutil"
+)
+
+// DefaultMaxDecodeDepth is the deepest nesting of objects and lists in
+// responses that clients decode, unless WithMaxDecodeDepth says otherwise.
+const DefaultMaxDecodeDepth = jsonutil.DefaultMaxDepth
+
+// WithMaxDecodeDepth makes the client fail operations whose response data is
+// nested more than n objects and lists deep, with a *DepthError, protecting
+// it from pathologically nested responses. The data object itself is the
+// first level. The limit can't be raised beyond what encoding/json allows.
+func WithMaxDecodeDepth(n int) ClientOption {
+	return func(c *Client) {
+		c.maxDecodeDepth = n
+	}
+}
+
+// DepthError is the error for a response nested deeper than MaxDepth levels.
+// It's of kind ErrDecode.
+type DepthError struct {
+	MaxDepth int
+}
+
+func (e *DepthError) Error() string {
+	return fmt.Sprintf("graphql: response nested deeper than %d levels", e.MaxDepth)
+}
+
+// Is reports whether target is ErrDecode.
+func (e *DepthError) Is(target error) bool {
+	return target == ErrDecode
+}
+
+// MaxDepth makes Query and Mutate fail, without sending anything, if the
+// fields derived from the struct nest more than n levels deep, so that
+// operations that a server limiting depth would reject fail fast. Root
+// fields are at the first level, their subfields at the second, and so on;
+// inline fragments don't add levels. Fields of named fragments spread into
+// the operation aren't counted. WithQueryOptions sets a maximum for every
+// operation of a client.
+func MaxDepth(n int) QueryOption {
+	return func(o *queryOptions) {
+		o.maxDepth = n
+	}
+}
+
+// checkDepth returns an error if the fields in selection set query nest
+// more than max levels deep.
+func checkDepth(query string, max int) error {
+	doc, err := document.Parse(query)
+	if err != nil {
+		return err
+	}
+	for _, op := range doc.Operations {
+		if err := checkSelectionDepth(op.SelectionSet, 1, max); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkSelectionDepth returns an error if selections, at level depth, nest
+// more than max levels deep. The selections of inline fragments are at the
+// level of the fields they're among.
+func checkSelectionDepth(selections []document.Selection, depth, max int) error {
+	for _, sel := range selections {
+		switch sel := sel.(type) {
+		case *document.Field:
+			if len(sel.SelectionSet) == 0 {
+				continue
+			}
+			if depth+1 > max {
+				return fmt.Errorf("graphql: selection of %s is deeper than the maximum depth of %d", sel.Name, max)
+			}
+			if err := checkSelectionDepth(sel.SelectionSet, depth+1, max); err != nil {
+				return err
+			}
+		case *document.InlineFragment:
+			if err := checkSelectionDepth(sel.SelectionSet, depth, max); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
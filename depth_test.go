@@ -0,0 +1,36 @@
+package graphql_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dbmedialab/go-graphql-client"
+)
+
+func TestWithMaxDecodeDepth(t *testing.T) {
+	transport := graphql.TransportFunc(func(ctx context.Context, req graphql.Request) (*graphql.Response, error) {
+		return &graphql.Response{Data: []byte(`{"viewer": {"friends": [{"login": "a"}]}}`)}, nil
+	})
+	var q struct {
+		Viewer struct {
+			Friends []struct {
+				Login graphql.String
+			}
+		}
+	}
+
+	client := graphql.NewPluggableClient(transport, graphql.WithMaxDecodeDepth(4))
+	if err := client.Query(context.Background(), &q, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	client = graphql.NewPluggableClient(transport, graphql.WithMaxDecodeDepth(3))
+	err := client.Query(context.Background(), &q, nil)
+	depthErr, ok := err.(*graphql.DepthError)
+	if !ok || depthErr.MaxDepth != 3 {
+		t.Fatalf("got error: %#v, want a *DepthError for a depth of 3", err)
+	}
+	if got, want := err.Error(), "graphql: response nested deeper than 3 levels"; got != want {
+		t.Errorf("got error: %q, want: %q", got, want)
+	}
+}
@@ -0,0 +1,181 @@
+//go:build go1.13
+// +build go1.13
+
+package graphql
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+)
+
+// DialOptions controls how a client connects to the server over dual-stack
+// (IPv4 and IPv6) networks.
+type DialOptions struct {
+	// PreferIPv6 makes connections try the server's IPv6 addresses first,
+	// whatever order the resolver returns them in.
+	PreferIPv6 bool
+
+	// FallbackDelay is how long to wait for a connection using the
+	// preferred address family before also trying the other one
+	// ("Happy Eyeballs"). Zero means 300ms; a negative value disables
+	// the race, so the other family is only tried after the preferred
+	// one fails.
+	FallbackDelay time.Duration
+
+	// Timeout limits the time each connection attempt may take.
+	// Zero means no limit.
+	Timeout time.Duration
+}
+
+// WithDialOptions configures how a client created by NewClient connects
+// to the server. It has no effect on clients with other transports, or whose
+// HTTP client has a transport other than an *http.Transport.
+func WithDialOptions(o DialOptions) ClientOption {
+	return func(c *Client) {
+		withHTTPTransport(c, func(ht *http.Transport) http.RoundTripper {
+			ht.DialContext = o.dial
+			return ht
+		})
+	}
+}
+
+// withHTTPTransport replaces the transport of the HTTP client of c, a client
+// created by NewClient, with the round tripper f returns, given a copy of
+// the transport to modify. Clients with other transports, or whose HTTP
+// clients have transports other than an *http.Transport, are left as they
+// are.
+func withHTTPTransport(c *Client, f func(*http.Transport) http.RoundTripper) {
+	t, ok := c.transport.(TransportHTTP)
+	if !ok {
+		return
+	}
+	var hc http.Client
+	if t.HTTPClient != nil {
+		hc = *t.HTTPClient
+	}
+	var ht *http.Transport
+	switch rt := hc.Transport.(type) {
+	case nil:
+		ht = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		ht = rt.Clone()
+	default:
+		return
+	}
+	hc.Transport = f(ht)
+	t.HTTPClient = &hc
+	c.transport = t
+}
+
+func (o DialOptions) dial(ctx context.Context, network, address string) (net.Conn, error) {
+	d := net.Dialer{Timeout: o.Timeout, FallbackDelay: o.FallbackDelay}
+	if !o.PreferIPv6 || network != "tcp" {
+		// The standard dialer races the address families, preferring
+		// the family of the first address resolved.
+		return d.DialContext(ctx, network, address)
+	}
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	var primary, fallback []string
+	for _, a := range addrs {
+		if a.IP.To4() == nil {
+			primary = append(primary, net.JoinHostPort(a.String(), port))
+		} else {
+			fallback = append(fallback, net.JoinHostPort(a.String(), port))
+		}
+	}
+	if len(primary) == 0 || len(fallback) == 0 {
+		return dialSerial(ctx, &d, network, append(primary, fallback...))
+	}
+	return o.dialParallel(ctx, &d, network, primary, fallback)
+}
+
+// dialParallel races connections to the primary and fallback addresses,
+// giving the primary ones a head start of o.FallbackDelay.
+func (o DialOptions) dialParallel(ctx context.Context, d *net.Dialer, network string, primary, fallback []string) (net.Conn, error) {
+	delay := o.FallbackDelay
+	if delay == 0 {
+		delay = 300 * time.Millisecond
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		conn    net.Conn
+		err     error
+		primary bool
+	}
+	results := make(chan result, 2)
+	race := func(primary bool, addrs []string) {
+		conn, err := dialSerial(ctx, d, network, addrs)
+		results <- result{conn, err, primary}
+	}
+	go race(true, primary)
+
+	var fallbackTimer <-chan time.Time
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		fallbackTimer = timer.C
+	}
+	fallbackStarted := false
+	var firstErr error
+	for pending := 1; pending > 0; {
+		select {
+		case <-fallbackTimer:
+			if !fallbackStarted {
+				fallbackStarted = true
+				pending++
+				go race(false, fallback)
+			}
+		case r := <-results:
+			pending--
+			if r.err == nil {
+				if pending > 0 {
+					// Close the losing connection, if it succeeds.
+					go func() {
+						if r := <-results; r.conn != nil {
+							r.conn.Close()
+						}
+					}()
+				}
+				return r.conn, nil
+			}
+			if firstErr == nil {
+				firstErr = r.err
+			}
+			if r.primary && !fallbackStarted {
+				fallbackStarted = true
+				pending++
+				go race(false, fallback)
+			}
+		}
+	}
+	return nil, firstErr
+}
+
+// dialSerial tries addrs in order, returning the first connection made.
+func dialSerial(ctx context.Context, d *net.Dialer, network string, addrs []string) (net.Conn, error) {
+	var firstErr error
+	for _, addr := range addrs {
+		conn, err := d.DialContext(ctx, network, addr)
+		if err == nil {
+			return conn, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	if firstErr == nil {
+		firstErr = &net.AddrError{Err: "no addresses", Addr: ""}
+	}
+	return nil, firstErr
+}
@@ -0,0 +1,49 @@
+//go:build go1.13
+// +build go1.13
+
+package graphql
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+)
+
+func TestDialOptions_preferIPv6(t *testing.T) {
+	// Only listen on IPv4, so that any IPv6 attempt fails and the dialer
+	// has to fall back.
+	l, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Skip("no IPv4 loopback:", err)
+	}
+	defer l.Close()
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+	_, port, _ := net.SplitHostPort(l.Addr().String())
+
+	o := DialOptions{PreferIPv6: true}
+	conn, err := o.dial(context.Background(), "tcp", net.JoinHostPort("localhost", port))
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+}
+
+func TestWithDialOptions(t *testing.T) {
+	c := NewClient("http://example.com/graphql", nil, WithDialOptions(DialOptions{PreferIPv6: true}))
+	ht, ok := c.transport.(TransportHTTP).HTTPClient.Transport.(*http.Transport)
+	if !ok || ht.DialContext == nil {
+		t.Fatal("got client without custom dialer")
+	}
+	if ht == http.DefaultTransport {
+		t.Error("http.DefaultTransport was modified")
+	}
+}
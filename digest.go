@@ -0,0 +1,73 @@
+package graphql
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// digestAlgorithms are the digest algorithms that responses can be
+// verified with, by their names in the Content-Digest and Digest headers.
+var digestAlgorithms = map[string]func() hash.Hash{
+	"sha-256": sha256.New,
+	"sha-512": sha512.New,
+}
+
+// responseDigest returns a hash to compute over the body of a response with
+// header h, and the sum the server says it should have. The Content-Digest
+// header (RFC 9530) is preferred over the older Digest header (RFC 3230).
+func responseDigest(h http.Header) (hash.Hash, []byte, error) {
+	for _, header := range []string{"Content-Digest", "Digest"} {
+		for _, value := range h[header] {
+			for _, d := range strings.Split(value, ",") {
+				i := strings.Index(d, "=")
+				if i == -1 {
+					continue
+				}
+				alg := strings.ToLower(strings.TrimSpace(d[:i]))
+				newHash, ok := digestAlgorithms[alg]
+				if !ok {
+					continue
+				}
+				// Content-Digest wraps the sum in colons, as a byte sequence.
+				sum, err := base64.StdEncoding.DecodeString(strings.Trim(strings.TrimSpace(d[i+1:]), ":"))
+				if err != nil {
+					return nil, nil, fmt.Errorf("graphql: malformed %s header: %v", header, err)
+				}
+				return newHash(), sum, nil
+			}
+		}
+	}
+	return nil, nil, fmt.Errorf("graphql: response has no supported digest")
+}
+
+// checkDigest reports an error if the sum of h isn't want.
+func checkDigest(h hash.Hash, want []byte) error {
+	if !bytes.Equal(h.Sum(nil), want) {
+		return fmt.Errorf("graphql: response digest mismatch")
+	}
+	return nil
+}
+
+// readVerified reads all of body, checking it against the digest in header h.
+func readVerified(h http.Header, body io.Reader) ([]byte, error) {
+	digest, want, err := responseDigest(h)
+	if err != nil {
+		return nil, err
+	}
+	b, err := ioutil.ReadAll(io.TeeReader(body, digest))
+	if err != nil {
+		return nil, err
+	}
+	if err := checkDigest(digest, want); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
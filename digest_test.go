@@ -0,0 +1,65 @@
+package graphql_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"testing"
+
+	"github.com/dbmedialab/go-graphql-client"
+)
+
+func TestTransportHTTP_VerifyDigest(t *testing.T) {
+	const body = `{"data": {"viewer": {"login": "gopher"}}}`
+	sum := sha256.Sum256([]byte(body))
+	good := base64.StdEncoding.EncodeToString(sum[:])
+	bad := base64.StdEncoding.EncodeToString(make([]byte, sha256.Size))
+
+	tests := []struct {
+		header, value string
+		wantErr       string
+	}{
+		{header: "Content-Digest", value: "sha-256=:" + good + ":"},
+		{header: "Digest", value: "md5=abc, SHA-256=" + good},
+		{header: "Content-Digest", value: "sha-256=:" + bad + ":", wantErr: "graphql: response digest mismatch"},
+		{header: "Digest", value: "md5=abc", wantErr: "graphql: response has no supported digest"},
+		{wantErr: "graphql: response has no supported digest"},
+	}
+	for _, tc := range tests {
+		for _, spool := range []int64{0, 1} {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				if tc.header != "" {
+					w.Header().Set(tc.header, tc.value)
+				}
+				mustWrite(w, body)
+			})
+			client := graphql.NewPluggableClient(graphql.TransportHTTP{
+				URL:            "/graphql",
+				HTTPClient:     &http.Client{Transport: localRoundTripper{handler: mux}},
+				VerifyDigest:   true,
+				SpoolThreshold: spool,
+			})
+
+			var q struct {
+				Viewer struct {
+					Login graphql.String
+				}
+			}
+			err := client.Query(context.Background(), &q, nil)
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Errorf("%s: %s (spool %d): %v", tc.header, tc.value, spool, err)
+				} else if q.Viewer.Login != "gopher" {
+					t.Errorf("%s: %s (spool %d): got login %q", tc.header, tc.value, spool, q.Viewer.Login)
+				}
+				continue
+			}
+			if err == nil || err.Error() != tc.wantErr {
+				t.Errorf("%s: %s (spool %d): got error: %v, want: %v", tc.header, tc.value, spool, err, tc.wantErr)
+			}
+		}
+	}
+}
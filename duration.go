@@ -0,0 +1,42 @@
+package graphql
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// formatDuration formats d as an ISO 8601 duration, in hours, minutes and
+// seconds, such as "PT1H30M" or "PT0.25S", as time.Duration variables are
+// sent. Days aren't used, since they aren't always 24 hours long.
+func formatDuration(d time.Duration) string {
+	if d == 0 {
+		return "PT0S"
+	}
+	var b bytes.Buffer
+	if d < 0 {
+		b.WriteByte('-')
+		d = -d
+	}
+	b.WriteString("PT")
+	if h := d / time.Hour; h > 0 {
+		fmt.Fprintf(&b, "%dH", h)
+		d -= h * time.Hour
+	}
+	if m := d / time.Minute; m > 0 {
+		fmt.Fprintf(&b, "%dM", m)
+		d -= m * time.Minute
+	}
+	if d > 0 {
+		fmt.Fprintf(&b, "%d", d/time.Second)
+		if ns := d % time.Second; ns > 0 {
+			b.WriteString(strings.TrimRight(fmt.Sprintf(".%09d", ns), "0"))
+		}
+		b.WriteByte('S')
+	}
+	return b.String()
+}
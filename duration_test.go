@@ -0,0 +1,54 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/dbmedialab/go-graphql-client"
+)
+
+func TestDuration(t *testing.T) {
+	var gotQuery, gotVariables string
+	transport := graphql.TransportFunc(func(ctx context.Context, req graphql.Request) (*graphql.Response, error) {
+		gotQuery = req.Query
+		b, err := json.Marshal(req.Variables)
+		gotVariables = string(b)
+		return &graphql.Response{Data: []byte(`{"job": {"timeout": "PT1H30M", "elapsed": "1m30.5s", "backoff": 250000000, "grace": null}}`)}, err
+	})
+	var q struct {
+		Job struct {
+			Timeout time.Duration
+			Elapsed *time.Duration
+			Backoff time.Duration
+			Grace   *time.Duration
+		} `graphql:"job(timeout: $timeout, ttl: $ttl)"`
+	}
+	ttl := -(2*time.Second + 250*time.Millisecond)
+	variables := map[string]interface{}{
+		"timeout": 90 * time.Minute,
+		"ttl":     &ttl,
+	}
+	if err := graphql.NewPluggableClient(transport).Query(context.Background(), &q, variables); err != nil {
+		t.Fatal(err)
+	}
+	if want := `query($timeout:Duration!$ttl:Duration){job(timeout: $timeout, ttl: $ttl){timeout,elapsed,backoff,grace}}`; gotQuery != want {
+		t.Errorf("got query: %s, want: %s", gotQuery, want)
+	}
+	if want := `{"timeout":"PT1H30M","ttl":"-PT2.25S"}`; gotVariables != want {
+		t.Errorf("got variables: %s, want: %s", gotVariables, want)
+	}
+	if got, want := q.Job.Timeout, 90*time.Minute; got != want {
+		t.Errorf("got timeout: %v, want: %v", got, want)
+	}
+	if got, want := *q.Job.Elapsed, 90500*time.Millisecond; got != want {
+		t.Errorf("got elapsed: %v, want: %v", got, want)
+	}
+	if got, want := q.Job.Backoff, 250*time.Millisecond; got != want {
+		t.Errorf("got backoff: %v, want: %v", got, want)
+	}
+	if q.Job.Grace != nil {
+		t.Errorf("got grace: %v, want: nil", *q.Job.Grace)
+	}
+}
@@ -0,0 +1,111 @@
+package graphql
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// WithEnum registers string type t as GraphQL enum type name, whose values
+// are values. Variables of type t are declared as being of type name, and
+// operations with variables holding other values of t, including in lists
+// and the fields of input objects, fail before they're sent. E.g.,
+//
+//	graphql.WithEnum(reflect.TypeOf(starwars.Episode("")), "Episode", "NEWHOPE", "EMPIRE", "JEDI")
+//
+// WithEnum panics if t isn't a string type.
+func WithEnum(t reflect.Type, name string, values ...string) ClientOption {
+	if t.Kind() != reflect.String {
+		panic(fmt.Sprintf("graphql: enum type %v isn't a string type", t))
+	}
+	e := &enum{name: name, values: make(map[string]bool, len(values))}
+	for _, v := range values {
+		e.values[v] = true
+	}
+	declare := WithScalarTypes(ScalarTypes{t: name})
+	return func(c *Client) {
+		// Copy the enums, since they may be shared with the client this
+		// one was cloned from.
+		enums := make(enums, len(c.enums)+1)
+		for t, e := range c.enums {
+			enums[t] = e
+		}
+		enums[t] = e
+		c.enums = enums
+		declare(c)
+	}
+}
+
+// enum is an enum type registered with WithEnum.
+type enum struct {
+	name   string
+	values map[string]bool
+}
+
+// enums are the enum types of a client, by Go type.
+type enums map[reflect.Type]*enum
+
+var typedValueType = reflect.TypeOf(typedValue{})
+
+// check returns an error if any of variables holds a value of an enum type
+// that isn't one of its values.
+func (e enums) check(variables map[string]interface{}) error {
+	if len(e) == 0 {
+		return nil
+	}
+	// Check in order, so that the same variable is reported each time.
+	names := make([]string, 0, len(variables))
+	for name := range variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := e.checkValue(reflect.ValueOf(variables[name]), name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkValue checks v, which is in variable name.
+func (e enums) checkValue(v reflect.Value, name string) error {
+	if !v.IsValid() {
+		return nil
+	}
+	t := v.Type()
+	if enum, ok := e[t]; ok {
+		if !enum.values[v.String()] {
+			return fmt.Errorf("graphql: variable $%s holds %q, which isn't a value of enum %s", name, v.String(), enum.name)
+		}
+		return nil
+	}
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return e.checkValue(v.Elem(), name)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := e.checkValue(v.Index(i), name); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		for _, k := range v.MapKeys() {
+			if err := e.checkValue(v.MapIndex(k), name); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		if t == typedValueType {
+			return e.checkValue(v.FieldByName("value"), name)
+		}
+		for i := 0; i < t.NumField(); i++ {
+			if f := t.Field(i); f.PkgPath != "" && !f.Anonymous {
+				continue
+			}
+			if err := e.checkValue(v.Field(i), name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
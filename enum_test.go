@@ -0,0 +1,65 @@
+package graphql_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/dbmedialab/go-graphql-client"
+)
+
+type episode string
+
+func TestWithEnum(t *testing.T) {
+	var gotQuery string
+	sent := 0
+	transport := graphql.TransportFunc(func(ctx context.Context, req graphql.Request) (*graphql.Response, error) {
+		sent++
+		gotQuery = req.Query
+		return &graphql.Response{Data: []byte(`{"hero": {"name": "R2-D2"}}`)}, nil
+	})
+	client := graphql.NewPluggableClient(transport, graphql.WithEnum(reflect.TypeOf(episode("")), "Episode", "NEWHOPE", "EMPIRE", "JEDI"))
+
+	var q struct {
+		Hero struct {
+			Name graphql.String
+		} `graphql:"hero(episode: $episode)"`
+	}
+	if err := client.Query(context.Background(), &q, map[string]interface{}{"episode": episode("JEDI")}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := gotQuery, "query($episode:Episode!){hero(episode: $episode){name}}"; got != want {
+		t.Errorf("got query: %q, want: %q", got, want)
+	}
+
+	type filter struct {
+		Episodes []*episode
+	}
+	invalid := episode("PHANTOM")
+	tests := []struct {
+		variables map[string]interface{}
+		want      string
+	}{
+		{
+			variables: map[string]interface{}{"episode": episode("jedi")},
+			want:      `graphql: variable $episode holds "jedi", which isn't a value of enum Episode`,
+		},
+		{
+			variables: map[string]interface{}{"episode": filter{Episodes: []*episode{nil, &invalid}}},
+			want:      `graphql: variable $episode holds "PHANTOM", which isn't a value of enum Episode`,
+		},
+		{
+			variables: map[string]interface{}{"episode": graphql.WithType("Episode", episode(""))},
+			want:      `graphql: variable $episode holds "", which isn't a value of enum Episode`,
+		},
+	}
+	for _, tc := range tests {
+		err := client.Query(context.Background(), &q, tc.variables)
+		if got := err; got == nil || got.Error() != tc.want {
+			t.Errorf("got error: %v, want: %v", got, tc.want)
+		}
+	}
+	if sent != 1 {
+		t.Errorf("got %d requests sent, want 1", sent)
+	}
+}
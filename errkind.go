@@ -0,0 +1,174 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/dbmedialab/go-graphql-client/jsonutil"
+)
+
+// Kinds of errors that operations fail with. Every error returned by Query,
+// Mutate, their Custom variants, Subscribe, MutateBatch and QueryBatch is of
+// at least one kind, which errors.Is reports, so callers needn't match error
+// messages, except for errors of result hooks, which are returned as the
+// hooks return them:
+//
+//	if errors.Is(err, graphql.ErrRateLimited) {
+//		// Back off.
+//	}
+//
+// The errors keep their own messages.
+var (
+	// ErrTransport is the kind of error in sending a request or reading
+	// its response, such as a refused connection or an HTTP status other
+	// than 200 OK.
+	ErrTransport = fmt.Errorf("graphql: transport error")
+
+	// ErrGraphQL is the kind of the errors reported by the GraphQL server
+	// in its response.
+	ErrGraphQL = fmt.Errorf("graphql: GraphQL error")
+
+	// ErrDecode is the kind of error in decoding a response.
+	ErrDecode = fmt.Errorf("graphql: decode error")
+
+	// ErrTimeout is the kind of error for operations that ran out of time.
+	// Such errors are also of kind ErrTransport.
+	ErrTimeout = fmt.Errorf("graphql: timeout")
+
+	// ErrRateLimited is the kind of error for operations refused because
+	// of too much load: by the server, with HTTP status 429 Too Many
+	// Requests, in which case the error is also of kind ErrTransport, or
+	// by a Shedder or CostBudget, with ErrShed or ErrOverBudget.
+	ErrRateLimited = fmt.Errorf("graphql: rate limited")
+
+	// ErrValidation is the kind of error for operations that are invalid,
+	// and so aren't sent, such as ones whose variables don't match those
+	// they use, or that are deeper or costlier than MaxDepth or MaxCost
+	// allow, and for results that the client's Validator rejects.
+	ErrValidation = fmt.Errorf("graphql: validation error")
+)
+
+// errorKinds name the kinds of errors, most specific first.
+var errorKinds = []struct {
+	err  error
+	name string
+}{
+	{ErrTimeout, "timeout"},
+	{ErrRateLimited, "rate_limited"},
+	{ErrValidation, "validation"},
+	{ErrGraphQL, "graphql"},
+	{ErrDecode, "decode"},
+	{ErrTransport, "transport"},
+}
+
+// ErrorKind returns the name of the most specific kind of err, for logs and
+// metrics: "timeout", "rate_limited", "validation", "graphql", "decode" or
+// "transport". It returns "other" for errors of no kind, such as those of
+// result hooks, and "" for nil.
+func ErrorKind(err error) string {
+	if err == nil {
+		return ""
+	}
+	for _, kind := range errorKinds {
+		for e := err; e != nil; {
+			if is, ok := e.(interface{ Is(error) bool }); ok && is.Is(kind.err) {
+				return kind.name
+			}
+			u, ok := e.(interface{ Unwrap() error })
+			if !ok {
+				break
+			}
+			e = u.Unwrap()
+		}
+	}
+	return "other"
+}
+
+// kindError is an error of the given kinds.
+type kindError struct {
+	err   error
+	kinds []error
+}
+
+// withKinds returns err as an error of kinds.
+func withKinds(err error, kinds ...error) error {
+	return &kindError{err: err, kinds: kinds}
+}
+
+func (e *kindError) Error() string { return e.err.Error() }
+
+// Unwrap returns the error of the kinds.
+func (e *kindError) Unwrap() error { return e.err }
+
+// Is reports whether e is of kind target.
+func (e *kindError) Is(target error) bool {
+	for _, kind := range e.kinds {
+		if target == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Is reports whether target is ErrGraphQL.
+func (e errors) Is(target error) bool {
+	return target == ErrGraphQL
+}
+
+// transportError returns err, from sending a request, as an error of its
+// kinds. Errors that already have kinds, and context.Canceled, which is
+// the caller's doing, are returned as is.
+func transportError(ctx context.Context, err error) error {
+	switch e := err.(type) {
+	case nil, *kindError, errors:
+		return err
+	case *StaleResultError:
+		e.Err = transportError(ctx, e.Err)
+		return e
+	}
+	if err == context.Canceled {
+		return err
+	}
+	if t, ok := err.(interface{ Timeout() bool }); (ok && t.Timeout()) || err == context.DeadlineExceeded || ctx.Err() == context.DeadlineExceeded {
+		return withKinds(err, ErrTimeout, ErrTransport)
+	}
+	return withKinds(err, ErrTransport)
+}
+
+// decodeError returns err, from decoding a response, as an error of kind
+// ErrDecode. Errors that already have kinds are returned as is.
+func decodeError(err error) error {
+	switch e := err.(type) {
+	case nil, *kindError, errors, *DepthError:
+		return err
+	case *jsonutil.DepthError:
+		return &DepthError{MaxDepth: e.MaxDepth}
+	}
+	return withKinds(err, ErrDecode)
+}
+
+// validationError returns err, from constructing or checking an operation,
+// as an error of kind ErrValidation. Errors that already have kinds are
+// returned as is.
+func validationError(err error) error {
+	switch err.(type) {
+	case nil, *kindError:
+		return err
+	}
+	return withKinds(err, ErrValidation)
+}
+
+// statusError returns the error for a response with a status other than
+// 200 OK.
+func statusError(resp *http.Response) error {
+	err := &httpStatusError{status: resp.Status}
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+		err.retryAfter, err.hasRetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return withKinds(err, ErrRateLimited, ErrTransport)
+	}
+	return withKinds(err, ErrTransport)
+}
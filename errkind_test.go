@@ -0,0 +1,172 @@
+//go:build go1.13
+// +build go1.13
+
+package graphql_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/dbmedialab/go-graphql-client"
+)
+
+func TestClient_Query_errorKinds(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		want    []error
+		notWant []error
+	}{
+		{
+			name: "graphql",
+			handler: func(w http.ResponseWriter, req *http.Request) {
+				mustWrite(w, `{"data": null, "errors": [{"message": "boom"}]}`)
+			},
+			want:    []error{graphql.ErrGraphQL},
+			notWant: []error{graphql.ErrTransport, graphql.ErrDecode},
+		},
+		{
+			name: "status",
+			handler: func(w http.ResponseWriter, req *http.Request) {
+				http.Error(w, "down", http.StatusBadGateway)
+			},
+			want:    []error{graphql.ErrTransport},
+			notWant: []error{graphql.ErrRateLimited, graphql.ErrGraphQL},
+		},
+		{
+			name: "rate limited",
+			handler: func(w http.ResponseWriter, req *http.Request) {
+				http.Error(w, "slow down", http.StatusTooManyRequests)
+			},
+			want: []error{graphql.ErrTransport, graphql.ErrRateLimited},
+		},
+		{
+			name: "decode",
+			handler: func(w http.ResponseWriter, req *http.Request) {
+				mustWrite(w, `{"data": {"viewer": {"login": 42}}}`)
+			},
+			want:    []error{graphql.ErrDecode},
+			notWant: []error{graphql.ErrTransport},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: tc.handler}})
+			var q struct {
+				Viewer struct {
+					Login graphql.String
+				}
+			}
+			err := client.Query(context.Background(), &q, nil)
+			if err == nil {
+				t.Fatal("got error: nil, want: non-nil")
+			}
+			for _, kind := range tc.want {
+				if !errors.Is(err, kind) {
+					t.Errorf("got error %q not of kind %q", err, kind)
+				}
+			}
+			for _, kind := range tc.notWant {
+				if errors.Is(err, kind) {
+					t.Errorf("got error %q of kind %q", err, kind)
+				}
+			}
+		})
+	}
+}
+
+func TestClient_Query_timeoutKind(t *testing.T) {
+	transport := graphql.TransportFunc(func(ctx context.Context, req graphql.Request) (*graphql.Response, error) {
+		return nil, fmt.Errorf("waiting: %v", context.DeadlineExceeded)
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 0)
+	defer cancel()
+	client := graphql.NewPluggableClient(transport)
+	var q struct {
+		Viewer struct{ Login graphql.String }
+	}
+	err := client.Query(ctx, &q, nil)
+	if !errors.Is(err, graphql.ErrTimeout) || !errors.Is(err, graphql.ErrTransport) {
+		t.Errorf("got error %q, want kinds ErrTimeout and ErrTransport", err)
+	}
+	if got, want := err.Error(), "waiting: context deadline exceeded"; got != want {
+		t.Errorf("got error: %q, want: %q", got, want)
+	}
+}
+
+func TestErrShed_kind(t *testing.T) {
+	if !errors.Is(graphql.ErrShed, graphql.ErrRateLimited) {
+		t.Error("ErrShed isn't of kind ErrRateLimited")
+	}
+	if !errors.Is(graphql.ErrOverBudget, graphql.ErrRateLimited) {
+		t.Error("ErrOverBudget isn't of kind ErrRateLimited")
+	}
+}
+
+func TestClient_errorKinds_validation(t *testing.T) {
+	transport := graphql.TransportFunc(func(ctx context.Context, req graphql.Request) (*graphql.Response, error) {
+		return &graphql.Response{Data: []byte(`{"viewer": {"login": ""}}`)}, nil
+	})
+	validator := graphql.WithValidator(graphql.ValidatorFunc(func(v interface{}) error {
+		return fmt.Errorf("login is empty")
+	}))
+	var q struct {
+		Viewer struct {
+			Login graphql.String
+		}
+	}
+	tests := []struct {
+		name  string
+		query func() error
+	}{
+		{"max depth", func() error {
+			return graphql.NewPluggableClient(transport).Query(context.Background(), &q, nil, graphql.MaxDepth(1))
+		}},
+		{"missing variable", func() error {
+			return graphql.NewPluggableClient(transport, graphql.WithVariableChecks()).QueryCustom(context.Background(), &q, "query($id:ID!){viewer(id:$id){login}}", nil)
+		}},
+		{"batch", func() error {
+			return graphql.NewPluggableClient(transport).QueryBatch(context.Background(), &graphql.Batch{})
+		}},
+		{"result", func() error {
+			return graphql.NewPluggableClient(transport, validator).Query(context.Background(), &q, nil)
+		}},
+	}
+	for _, tc := range tests {
+		err := tc.query()
+		if !errors.Is(err, graphql.ErrValidation) {
+			t.Errorf("%s: got error %v, want one of kind ErrValidation", tc.name, err)
+		}
+		if errors.Is(err, graphql.ErrTransport) {
+			t.Errorf("%s: got error %v of kind ErrTransport", tc.name, err)
+		}
+	}
+}
+
+func TestErrorKind(t *testing.T) {
+	transport := graphql.TransportFunc(func(ctx context.Context, req graphql.Request) (*graphql.Response, error) {
+		return nil, fmt.Errorf("connection refused")
+	})
+	var q struct{ A graphql.Int }
+	queryErr := graphql.NewPluggableClient(transport).Query(context.Background(), &q, nil)
+
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{nil, ""},
+		{queryErr, "transport"},
+		{graphql.ErrShed, "rate_limited"},
+		{&graphql.StaleResultError{Err: queryErr}, "transport"},
+		{&graphql.ResultValidationError{Err: fmt.Errorf("bad")}, "validation"},
+		{fmt.Errorf("hook failed"), "other"},
+	}
+	for _, tc := range tests {
+		if got := graphql.ErrorKind(tc.err); got != tc.want {
+			t.Errorf("ErrorKind(%v) = %q, want %q", tc.err, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,270 @@
+package graphql
+
+import (
+	"context"
+	"encoding"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// PageInfo is the pagination information of a connection, as Relay's
+// cursor connections specification defines it. Select it with a field
+// of this type tagged `graphql:"pageInfo"`.
+type PageInfo struct {
+	HasNextPage bool
+	EndCursor   string
+}
+
+// RowWriter is where an Exporter writes rows: CSVRowWriter writes them as
+// CSV, and other formats, such as Parquet, can implement it to stream rows
+// to their own writers.
+type RowWriter interface {
+	// WriteHeader is called once, before any rows, with the names of
+	// the columns.
+	WriteHeader(columns []string) error
+	// WriteRow writes a row, holding a value per column: the value of
+	// the node's field, with pointers dereferenced, or nil if it's nil.
+	WriteRow(values []interface{}) error
+	// Flush is called after each page of rows.
+	Flush() error
+}
+
+// Exporter streams the nodes of a connection to a RowWriter a page at a
+// time, fetching the next page once the last one is written, so that
+// exporting large connections doesn't mean holding them in memory.
+//
+// Each node is a row, with a column per field of the node struct. The
+// fields of struct fields are columns of their own, named by their paths,
+// such as "author.login". Columns are named by fields' response keys, as
+// selected, unless a `csv:"name"` tag names them; fields tagged `csv:"-"`
+// are left out.
+type Exporter struct {
+	Client *Client
+
+	// Query is a pointer to the query struct, which selects a page of the
+	// connection after the cursor in the variable named by After.
+	Query interface{}
+
+	// Variables are the variables of the query, other than After.
+	Variables map[string]interface{}
+
+	// Page returns the nodes in Query, a slice of structs or pointers to
+	// them, and the connection's page info, once a page has been fetched.
+	Page func() (nodes interface{}, pageInfo PageInfo)
+
+	// After names the variable the cursor of the last page is passed
+	// in, as a String, which is null for the first page. If empty,
+	// "after" is used.
+	After string
+}
+
+// Export writes the nodes of every page to w, returning how many there
+// were. It stops at the first error, from fetching a page or writing it,
+// or if a page with a next page has an empty end cursor or that of the
+// page before, which would otherwise fetch the same page forever.
+func (e *Exporter) Export(ctx context.Context, w RowWriter) (rows int, err error) {
+	q := reflect.ValueOf(e.Query)
+	if q.Kind() != reflect.Ptr || q.IsNil() {
+		return 0, fmt.Errorf("graphql: Exporter.Query must be a non-nil pointer, not %T", e.Query)
+	}
+	after := e.After
+	if after == "" {
+		after = "after"
+	}
+	variables := make(map[string]interface{}, len(e.Variables)+1)
+	for k, v := range e.Variables {
+		variables[k] = v
+	}
+	variables[after] = (*String)(nil)
+
+	var (
+		columns []exportColumn
+		cursor  string // Of the last page.
+	)
+	for {
+		// Pages are decoded into a fresh query, so that nothing is
+		// left over from the last.
+		q.Elem().Set(reflect.Zero(q.Elem().Type()))
+		if err := e.Client.Query(ctx, e.Query, variables); err != nil {
+			return rows, err
+		}
+		nodes, pageInfo := e.Page()
+		v := reflect.ValueOf(nodes)
+		if v.Kind() != reflect.Slice {
+			return rows, fmt.Errorf("graphql: Exporter.Page returned %T, not a slice of nodes", nodes)
+		}
+		if columns == nil {
+			t := v.Type().Elem()
+			for t.Kind() == reflect.Ptr {
+				t = t.Elem()
+			}
+			if t.Kind() != reflect.Struct {
+				return rows, fmt.Errorf("graphql: Exporter.Page returned %T, not a slice of node structs", nodes)
+			}
+			columns = exportColumns(t, "", nil)
+			names := make([]string, len(columns))
+			for i, c := range columns {
+				names[i] = c.name
+			}
+			if err := w.WriteHeader(names); err != nil {
+				return rows, err
+			}
+		}
+		values := make([]interface{}, len(columns))
+		for i := 0; i < v.Len(); i++ {
+			node := v.Index(i)
+			for j, c := range columns {
+				values[j] = c.value(node)
+			}
+			if err := w.WriteRow(values); err != nil {
+				return rows, err
+			}
+			rows++
+		}
+		if err := w.Flush(); err != nil {
+			return rows, err
+		}
+		if !pageInfo.HasNextPage {
+			return rows, nil
+		}
+		if pageInfo.EndCursor == "" || pageInfo.EndCursor == cursor {
+			return rows, fmt.Errorf("graphql: connection has a next page, but its end cursor %q doesn't advance", pageInfo.EndCursor)
+		}
+		cursor = pageInfo.EndCursor
+		variables[after] = NewString(String(cursor))
+	}
+}
+
+// exportColumn is a column of an export: a leaf field of the node struct.
+type exportColumn struct {
+	name  string
+	index []int // Of the field, through the structs it's nested in.
+}
+
+// value returns the column's value in node, a node struct or a pointer to
+// one.
+func (c exportColumn) value(node reflect.Value) interface{} {
+	v := node
+	for _, i := range c.index {
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return nil
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	return v.Interface()
+}
+
+// exportColumns returns the columns of the fields of struct type t,
+// named under prefix, at index.
+func exportColumns(t reflect.Type, prefix string, index []int) []exportColumn {
+	var columns []exportColumn
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" && !f.Anonymous || isExcluded(f) || f.Tag.Get("csv") == "-" {
+			continue
+		}
+		fieldIndex := append(index[:len(index):len(index)], i)
+		tag, tagged := fieldTag(f)
+		ft := f.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		name := f.Tag.Get("csv")
+		if name == "" {
+			if f.Anonymous && !tagged || strings.HasPrefix(tag, "...") {
+				// Embedded structs, and fragments, are inlined.
+				if ft.Kind() == reflect.Struct && !isScalar(ft) {
+					columns = append(columns, exportColumns(ft, prefix, fieldIndex)...)
+				}
+				continue
+			}
+			name = responseKey(f)
+		}
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+		if ft.Kind() == reflect.Struct && !isScalar(ft) {
+			columns = append(columns, exportColumns(ft, name, fieldIndex)...)
+			continue
+		}
+		columns = append(columns, exportColumn{name: name, index: fieldIndex})
+	}
+	return columns
+}
+
+// CSVRowWriter is a RowWriter that writes rows as CSV records. Strings are
+// written as they are, nil as an empty field, values with MarshalText or
+// MarshalGQL methods as they marshal, lists and objects as JSON, and other
+// values as fmt.Sprint formats them.
+type CSVRowWriter struct {
+	w *csv.Writer
+}
+
+// NewCSVRowWriter returns a CSVRowWriter that writes to w.
+func NewCSVRowWriter(w *csv.Writer) *CSVRowWriter {
+	return &CSVRowWriter{w: w}
+}
+
+// WriteHeader writes columns as the header record.
+func (w *CSVRowWriter) WriteHeader(columns []string) error {
+	return w.w.Write(columns)
+}
+
+// WriteRow writes values as a record.
+func (w *CSVRowWriter) WriteRow(values []interface{}) error {
+	record := make([]string, len(values))
+	for i, v := range values {
+		s, err := csvField(v)
+		if err != nil {
+			return err
+		}
+		record[i] = s
+	}
+	return w.w.Write(record)
+}
+
+// Flush writes the records buffered to the underlying writer.
+func (w *CSVRowWriter) Flush() error {
+	w.w.Flush()
+	return w.w.Error()
+}
+
+func csvField(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case encoding.TextMarshaler:
+		b, err := v.MarshalText()
+		return string(b), err
+	case Marshaler:
+		b, err := marshaledValue{v}.MarshalJSON()
+		if err != nil {
+			return "", err
+		}
+		var s string
+		if json.Unmarshal(b, &s) == nil {
+			return s, nil
+		}
+		return string(b), nil
+	}
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.String:
+		return rv.String(), nil
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.Struct:
+		b, err := json.Marshal(v)
+		return string(b), err
+	}
+	return fmt.Sprint(v), nil
+}
@@ -0,0 +1,120 @@
+package graphql_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"testing"
+
+	"github.com/dbmedialab/go-graphql-client"
+)
+
+func TestExporter(t *testing.T) {
+	pages := map[string]string{
+		"": `{"repository": {"issues": {
+			"nodes": [
+				{"number": 1, "title": "Crash, on start", "author": {"login": "gopher"}, "labels": ["bug"], "secret": "x"},
+				{"number": 2, "title": "Docs", "author": null, "labels": []}
+			],
+			"pageInfo": {"hasNextPage": true, "endCursor": "c2"}}}}`,
+		"c2": `{"repository": {"issues": {
+			"nodes": [{"number": 3, "title": "Feature", "author": {"login": "ghost"}, "labels": null}],
+			"pageInfo": {"hasNextPage": false, "endCursor": "c3"}}}}`,
+	}
+	var afters []interface{}
+	transport := graphql.TransportFunc(func(ctx context.Context, req graphql.Request) (*graphql.Response, error) {
+		after, _ := req.Variables["after"].(*graphql.String)
+		var cursor string
+		if after != nil {
+			cursor = string(*after)
+		}
+		afters = append(afters, cursor)
+		return &graphql.Response{Data: []byte(pages[cursor])}, nil
+	})
+	var q struct {
+		Repository struct {
+			Issues struct {
+				Nodes []struct {
+					Number int
+					Title  string `csv:"summary"`
+					Author *struct {
+						Login string
+					}
+					Labels []string
+					Secret string `csv:"-"`
+				}
+				PageInfo graphql.PageInfo
+			} `graphql:"issues(first: 2, after: $after)"`
+		} `graphql:"repository(owner: $owner, name: $name)"`
+	}
+	e := &graphql.Exporter{
+		Client:    graphql.NewPluggableClient(transport),
+		Query:     &q,
+		Variables: map[string]interface{}{"owner": graphql.String("golang"), "name": graphql.String("go")},
+		Page: func() (interface{}, graphql.PageInfo) {
+			return q.Repository.Issues.Nodes, q.Repository.Issues.PageInfo
+		},
+	}
+	var buf bytes.Buffer
+	rows, err := e.Export(context.Background(), graphql.NewCSVRowWriter(csv.NewWriter(&buf)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rows != 3 {
+		t.Errorf("got %d rows, want 3", rows)
+	}
+	want := `number,summary,author.login,labels
+1,"Crash, on start",gopher,"[""bug""]"
+2,Docs,,[]
+3,Feature,ghost,null
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+	if got, want := len(afters), 2; got != want || afters[1] != "c2" {
+		t.Errorf("got cursors %q, want two, the second c2", afters)
+	}
+}
+
+func TestExporter_stuckCursor(t *testing.T) {
+	for _, endCursor := range []string{"", "c1"} {
+		requests := 0
+		transport := graphql.TransportFunc(func(ctx context.Context, req graphql.Request) (*graphql.Response, error) {
+			requests++
+			if requests > 3 {
+				t.Fatalf("end cursor %q: sent %d requests", endCursor, requests)
+			}
+			cursor := "c1"
+			if requests > 1 {
+				cursor = endCursor
+			}
+			return &graphql.Response{Data: []byte(`{"issues": {
+				"nodes": [{"number": 1}],
+				"pageInfo": {"hasNextPage": true, "endCursor": "` + cursor + `"}}}`)}, nil
+		})
+		var q struct {
+			Issues struct {
+				Nodes []struct {
+					Number int
+				}
+				PageInfo graphql.PageInfo
+			} `graphql:"issues(after: $after)"`
+		}
+		e := &graphql.Exporter{
+			Client: graphql.NewPluggableClient(transport),
+			Query:  &q,
+			Page: func() (interface{}, graphql.PageInfo) {
+				return q.Issues.Nodes, q.Issues.PageInfo
+			},
+		}
+		var buf bytes.Buffer
+		rows, err := e.Export(context.Background(), graphql.NewCSVRowWriter(csv.NewWriter(&buf)))
+		want := `graphql: connection has a next page, but its end cursor "` + endCursor + `" doesn't advance`
+		if err == nil || err.Error() != want {
+			t.Errorf("end cursor %q: got error: %v, want: %v", endCursor, err, want)
+		}
+		if rows != 2 || requests != 2 {
+			t.Errorf("end cursor %q: got %d rows from %d requests, want 2 from 2", endCursor, rows, requests)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package graphql
+
+import "context"
+
+type requestExtensionsKey struct{}
+
+// WithRequestExtension returns a copy of ctx with which operations are sent
+// with the entry key set to value in their request's extensions, for
+// gateway contracts such as cost hints or feature flags. Entries already in
+// the request, such as those set by middleware, are kept.
+func WithRequestExtension(ctx context.Context, key string, value interface{}) context.Context {
+	old, _ := ctx.Value(requestExtensionsKey{}).(map[string]interface{})
+	ext := make(map[string]interface{}, len(old)+1)
+	for k, v := range old {
+		ext[k] = v
+	}
+	ext[key] = value
+	return context.WithValue(ctx, requestExtensionsKey{}, ext)
+}
+
+type responseExtensionsKey struct{}
+
+// WithResponseExtensions returns a copy of ctx with which receive is called
+// with the extensions of the response to each operation, if it has any,
+// as middleware left them, once the response has been received.
+func WithResponseExtensions(ctx context.Context, receive func(ext map[string]interface{})) context.Context {
+	return context.WithValue(ctx, responseExtensionsKey{}, receive)
+}
+
+// withRequestExtensions returns req with the extensions set with ctx added.
+// req's own extensions map isn't modified.
+func withRequestExtensions(ctx context.Context, req Request) Request {
+	add, _ := ctx.Value(requestExtensionsKey{}).(map[string]interface{})
+	if len(add) == 0 {
+		return req
+	}
+	ext := make(map[string]interface{}, len(req.Extensions)+len(add))
+	for k, v := range add {
+		ext[k] = v
+	}
+	for k, v := range req.Extensions {
+		ext[k] = v
+	}
+	req.Extensions = ext
+	return req
+}
+
+// receiveExtensions passes ext to the receivers set with ctx, if any.
+func receiveExtensions(ctx context.Context, ext map[string]interface{}) {
+	receiveTracing(ctx, ext)
+	if receive, ok := ctx.Value(responseExtensionsKey{}).(func(map[string]interface{})); ok && len(ext) > 0 {
+		receive(ext)
+	}
+}
@@ -0,0 +1,76 @@
+package graphql_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/dbmedialab/go-graphql-client"
+)
+
+func TestExtensions(t *testing.T) {
+	var gotBody string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		body, _ := ioutil.ReadAll(req.Body)
+		gotBody = string(body)
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}, "extensions": {"cost": {"actual": 2}}}`)
+	})
+	middleware := func(next graphql.Transport) graphql.Transport {
+		return graphql.TransportFunc(func(ctx context.Context, req graphql.Request) (*graphql.Response, error) {
+			if req.Extensions == nil {
+				req.Extensions = map[string]interface{}{}
+			}
+			req.Extensions["flags"] = []string{"beta"}
+			resp, err := next.Do(ctx, req)
+			if resp != nil && resp.Extensions != nil {
+				resp.Extensions["seenBy"] = "middleware"
+			}
+			return resp, err
+		})
+	}
+
+	for _, spool := range []int64{0, 1} {
+		transport := graphql.TransportHTTP{
+			URL:            "/graphql",
+			HTTPClient:     &http.Client{Transport: localRoundTripper{handler: mux}},
+			SpoolThreshold: spool,
+		}
+		var opts []graphql.ClientOption
+		if spool == 0 {
+			// Responses are only spooled without middleware.
+			opts = append(opts, graphql.WithMiddleware(middleware))
+		}
+		client := graphql.NewPluggableClient(transport, opts...)
+
+		var got map[string]interface{}
+		ctx := graphql.WithRequestExtension(context.Background(), "costHint", 3)
+		ctx = graphql.WithResponseExtensions(ctx, func(ext map[string]interface{}) {
+			got = ext
+		})
+		var q struct {
+			Viewer struct {
+				Login graphql.String
+			}
+		}
+		if err := client.Query(ctx, &q, nil); err != nil {
+			t.Fatal(err)
+		}
+
+		want := map[string]interface{}{"cost": map[string]interface{}{"actual": 2.0}}
+		wantBody := `{"query":"{viewer{login}}","extensions":{"costHint":3}}` + "\n"
+		if spool == 0 {
+			want["seenBy"] = "middleware"
+			wantBody = `{"query":"{viewer{login}}","extensions":{"costHint":3,"flags":["beta"]}}` + "\n"
+		}
+		if gotBody != wantBody {
+			t.Errorf("spool %d: got body: %q, want: %q", spool, gotBody, wantBody)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("spool %d: got extensions: %v, want: %v", spool, got, want)
+		}
+	}
+}
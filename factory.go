@@ -0,0 +1,55 @@
+package graphql
+
+import "sync"
+
+// ClientFactory makes clients for the tenants of a multi-tenant service,
+// each configured for its tenant (with its own credentials, headers, or
+// endpoint), while sharing the base client's transport and middleware, and
+// so its connection pools, caches, and metrics.
+//
+// Operations of a tenant's client are executed as if with WithTenant, so
+// middleware such as CostBudget accounts for them per tenant, and caches
+// such as StaleCache keep each tenant's results apart. Middleware that
+// caches results must key them by tenant too.
+type ClientFactory struct {
+	base      *Client
+	configure func(tenant string) []ClientOption
+
+	mu      sync.Mutex
+	clients map[string]*Client
+}
+
+// NewClientFactory returns a factory of clients made from base, configured
+// for each tenant by the options that configure returns for it.
+func NewClientFactory(base *Client, configure func(tenant string) []ClientOption) *ClientFactory {
+	return &ClientFactory{base: base, configure: configure}
+}
+
+// Client returns the client for tenant. Clients are made once per tenant,
+// and reused until Forget is called for the tenant.
+func (f *ClientFactory) Client(tenant string) *Client {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if c, ok := f.clients[tenant]; ok {
+		return c
+	}
+	var opts []ClientOption
+	if f.configure != nil {
+		opts = f.configure(tenant)
+	}
+	c := f.base.With(opts...)
+	c.tenant = tenant
+	if f.clients == nil {
+		f.clients = map[string]*Client{}
+	}
+	f.clients[tenant] = c
+	return c
+}
+
+// Forget drops the client for tenant, so that the next call to Client makes
+// a new one, for example after the tenant's configuration has changed.
+func (f *ClientFactory) Forget(tenant string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.clients, tenant)
+}
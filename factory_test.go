@@ -0,0 +1,96 @@
+package graphql_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/dbmedialab/go-graphql-client"
+)
+
+func TestClientFactory(t *testing.T) {
+	type request struct{ path, auth string }
+	var got []request
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) {
+		got = append(got, request{req.URL.Path, req.Header.Get("Authorization")})
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+	})
+	base := graphql.NewPluggableClient(graphql.TransportHTTP{
+		URL:         "/graphql",
+		HTTPClient:  &http.Client{Transport: localRoundTripper{handler: mux}},
+		AllowedURLs: []string{"/eu/graphql"},
+	})
+	budget := &graphql.CostBudget{Limit: 1}
+	base = base.With(graphql.WithMiddleware(budget.Middleware))
+	factory := graphql.NewClientFactory(base, func(tenant string) []graphql.ClientOption {
+		opts := []graphql.ClientOption{graphql.WithHeader("Authorization", "Bearer "+tenant)}
+		if tenant == "acme-eu" {
+			opts = append(opts, graphql.WithEndpointURL("/eu/graphql"))
+		}
+		return opts
+	})
+
+	if factory.Client("acme") != factory.Client("acme") {
+		t.Error("got different clients for the same tenant")
+	}
+
+	var q struct {
+		Viewer struct {
+			Login graphql.String
+		}
+	}
+	for _, tenant := range []string{"acme", "acme-eu"} {
+		if err := factory.Client(tenant).Query(context.Background(), &q, nil); err != nil {
+			t.Fatalf("%s: %v", tenant, err)
+		}
+	}
+	want := []request{{"/graphql", "Bearer acme"}, {"/eu/graphql", "Bearer acme-eu"}}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("got requests %v, want %v", got, want)
+	}
+
+	// Shared middleware accounts for each tenant separately.
+	if got := budget.Remaining("acme"); got != 0 {
+		t.Errorf("got remaining budget for acme: %d, want: 0", got)
+	}
+	if err := factory.Client("acme").Query(context.Background(), &q, nil); err != graphql.ErrOverBudget {
+		t.Errorf("got error: %v, want: ErrOverBudget", err)
+	}
+}
+
+func TestClientFactory_staleCache(t *testing.T) {
+	var down bool
+	transport := graphql.TransportFunc(func(ctx context.Context, req graphql.Request) (*graphql.Response, error) {
+		if down {
+			return nil, fmt.Errorf("down")
+		}
+		return &graphql.Response{Data: []byte(`{"viewer": {"login": "alice"}}`)}, nil
+	})
+	cache := &graphql.StaleCache{}
+	base := graphql.NewPluggableClient(transport, graphql.WithMiddleware(cache.Middleware))
+	factory := graphql.NewClientFactory(base, nil)
+
+	var q struct {
+		Viewer struct {
+			Login graphql.String
+		}
+	}
+	if err := factory.Client("alice").Query(context.Background(), &q, nil); err != nil {
+		t.Fatal(err)
+	}
+	down = true
+	q.Viewer.Login = ""
+	// The tenants share the cache, but not its entries.
+	if err := factory.Client("bob").Query(context.Background(), &q, nil); err == nil || err.Error() != "down" {
+		t.Errorf("got error: %v, want: down", err)
+	}
+	if q.Viewer.Login != "" {
+		t.Errorf("got login %q for bob, want none", q.Viewer.Login)
+	}
+	if _, ok := factory.Client("alice").Query(context.Background(), &q, nil).(*graphql.StaleResultError); !ok {
+		t.Error("got no stale result for alice, want one")
+	}
+}
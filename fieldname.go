@@ -0,0 +1,60 @@
+package graphql
+
+import "github.com/dbmedialab/go-graphql-client/ident"
+
+// A FieldNamer names the GraphQL field selected for a struct field without
+// a graphql tag, given the struct field's Go name. Responses are decoded
+// into such fields by the same names.
+type FieldNamer func(goName string) string
+
+// LowerCamelCase names fields in lowerCamelCase, treating initialisms as
+// words: "ClientMutationID" is selected as "clientMutationId". It's the
+// default.
+func LowerCamelCase(goName string) string {
+	return ident.ParseMixedCaps(goName).ToLowerCamelCase()
+}
+
+// SnakeCase names fields in snake_case: "ClientMutationID" is selected as
+// "client_mutation_id".
+func SnakeCase(goName string) string {
+	return ident.ParseMixedCaps(goName).ToSnakeCase()
+}
+
+// GoName names fields exactly as their Go names: "ClientMutationID" is
+// selected as "ClientMutationID".
+func GoName(goName string) string {
+	return goName
+}
+
+// fieldNamer is a FieldNamer set by WithFieldNamer. Its address tells apart
+// the fields generated with it from those generated with other namers.
+type fieldNamer struct {
+	name FieldNamer
+}
+
+// WithFieldNamer makes Query and Mutate select untagged struct fields by the
+// names name gives them, and decode responses into such fields by the same
+// names, for servers whose schemas don't name fields in lowerCamelCase.
+// Naming rules for initialisms and such can be written with the ident
+// package.
+func WithFieldNamer(name FieldNamer) ClientOption {
+	n := &fieldNamer{name: name}
+	return func(c *Client) {
+		c.fieldNamer = n
+		WithQueryOptions(n.option)(c)
+	}
+}
+
+// option sets n as the query options' field namer.
+func (n *fieldNamer) option(o *queryOptions) {
+	o.fieldNamer = n
+}
+
+// fieldName returns the name of the GraphQL field selected for untagged
+// struct field goName.
+func (o *queryOptions) fieldName(goName string) string {
+	if o.fieldNamer == nil {
+		return LowerCamelCase(goName)
+	}
+	return o.fieldNamer.name(goName)
+}
@@ -0,0 +1,53 @@
+package graphql_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/dbmedialab/go-graphql-client"
+)
+
+func TestWithFieldNamer(t *testing.T) {
+	type query struct {
+		Viewer struct {
+			AvatarURL graphql.String
+			Login     graphql.String `graphql:"handle"`
+		}
+	}
+	tests := []struct {
+		name      string
+		namer     graphql.FieldNamer
+		wantQuery string
+		data      string
+	}{
+		{"default", nil, "{viewer{avatarUrl,handle}}", `{"viewer": {"avatarUrl": "a.png", "handle": "gopher"}}`},
+		{"snake case", graphql.SnakeCase, "{viewer{avatar_url,handle}}", `{"viewer": {"avatar_url": "a.png", "handle": "gopher"}}`},
+		{"Go name", graphql.GoName, "{Viewer{AvatarURL,handle}}", `{"Viewer": {"AvatarURL": "a.png", "handle": "gopher"}}`},
+		{"custom", strings.ToUpper, "{VIEWER{AVATARURL,handle}}", `{"VIEWER": {"AVATARURL": "a.png", "handle": "gopher"}}`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var gotQuery string
+			transport := graphql.TransportFunc(func(ctx context.Context, req graphql.Request) (*graphql.Response, error) {
+				gotQuery = req.Query
+				return &graphql.Response{Data: []byte(tc.data)}, nil
+			})
+			var opts []graphql.ClientOption
+			if tc.namer != nil {
+				opts = append(opts, graphql.WithFieldNamer(tc.namer))
+			}
+			client := graphql.NewPluggableClient(transport, opts...)
+			var q query
+			if err := client.Query(context.Background(), &q, nil); err != nil {
+				t.Fatal(err)
+			}
+			if gotQuery != tc.wantQuery {
+				t.Errorf("got query: %q, want: %q", gotQuery, tc.wantQuery)
+			}
+			if q.Viewer.AvatarURL != "a.png" || q.Viewer.Login != "gopher" {
+				t.Errorf("got viewer: %+v", q.Viewer)
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package graphql
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/dbmedialab/go-graphql-client/internal/document"
+)
+
+// fragmentRegistry holds the named fragments registered with a client.
+// It's shared by clones of the client.
+type fragmentRegistry struct {
+	mu        sync.RWMutex
+	fragments map[string]string // Definition by fragment name.
+}
+
+// RegisterFragment registers a named fragment, on the GraphQL type named
+// on, whose fields are derived from v the same way a query's are. Queries
+// and mutations of c, and of clients made from c with With, can then spread
+// it with a `graphql:"...name"` tag on a field of the same type as v, and
+// its definition is added to their documents automatically.
+//
+// For example, after
+//
+//	type UserFields struct {
+//		Login graphql.String
+//		Name  graphql.String
+//	}
+//	client.RegisterFragment("userFields", "User", UserFields{})
+//
+// the query
+//
+//	var q struct {
+//		Viewer struct {
+//			UserFields `graphql:"...userFields"`
+//		}
+//	}
+//
+// is sent as
+//
+//	{viewer{...userFields}}
+//	fragment userFields on User{login,name}
+//
+// v can also be a string holding the fragment's selection set, with or
+// without its braces, such as "login name avatarUrl(size: 72)", for
+// fragments whose fields the response structs that spread them leave out.
+func (c *Client) RegisterFragment(name, on string, v interface{}) error {
+	if name == "" || name == "on" || strings.ContainsAny(name, " \t\n,(){}:@$") {
+		return fmt.Errorf("graphql: invalid fragment name %q", name)
+	}
+	var fields string
+	if body, ok := v.(string); ok {
+		body = strings.TrimSpace(body)
+		if !strings.HasPrefix(body, "{") {
+			body = "{" + body + "}"
+		}
+		toks, err := document.Tokenize(body)
+		if err != nil {
+			return fmt.Errorf("graphql: fragment %s: %v", name, err)
+		}
+		fields = document.Compact(toks)
+	} else {
+		var opts []QueryOption
+		if c.fieldNamer != nil {
+			opts = append(opts, c.fieldNamer.option)
+		}
+		var err error
+		fields, err = generateQueryFields(v, opts)
+		if err != nil {
+			return fmt.Errorf("graphql: fragment %s: %v", name, err)
+		}
+	}
+	definition := "fragment " + name + " on " + on + fields
+	c.fragments.mu.Lock()
+	defer c.fragments.mu.Unlock()
+	if _, ok := c.fragments.fragments[name]; ok {
+		return fmt.Errorf("graphql: fragment %s is already registered", name)
+	}
+	if c.fragments.fragments == nil {
+		c.fragments.fragments = map[string]string{}
+	}
+	c.fragments.fragments[name] = definition
+	return nil
+}
+
+// withFragments returns query, derived from vs, followed by the definitions
+// of the registered fragments it spreads, directly or through other fragments.
+func (c *Client) withFragments(query string, vs ...interface{}) (string, error) {
+	used := map[string]bool{}
+	seen := map[reflect.Type]bool{}
+	for _, v := range vs {
+		namedSpreads(reflect.TypeOf(v), used, seen)
+	}
+	if len(used) == 0 {
+		return query, nil
+	}
+
+	c.fragments.mu.RLock()
+	defer c.fragments.mu.RUnlock()
+	definitions := map[string]string{}
+	for len(used) > 0 {
+		var name string
+		for name = range used {
+			break
+		}
+		delete(used, name)
+		if _, ok := definitions[name]; ok {
+			continue
+		}
+		definition, ok := c.fragments.fragments[name]
+		if !ok {
+			return "", fmt.Errorf("graphql: unknown fragment %s", name)
+		}
+		definitions[name] = definition
+		for _, spread := range spreadNames(definition) {
+			if _, ok := definitions[spread]; !ok {
+				used[spread] = true
+			}
+		}
+	}
+
+	names := make([]string, 0, len(definitions))
+	for name := range definitions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var buf bytes.Buffer
+	buf.WriteString(query)
+	for _, name := range names {
+		buf.WriteString("\n")
+		buf.WriteString(definitions[name])
+	}
+	return buf.String(), nil
+}
+
+// namedSpread returns the name of the fragment that struct field f spreads,
+// or "" if it doesn't spread a named fragment (inline fragments don't).
+func namedSpread(f reflect.StructField) string {
+	value, _ := fieldTag(f)
+	value = strings.TrimSpace(value)
+	if !strings.HasPrefix(value, "...") {
+		return ""
+	}
+	name := strings.TrimSpace(strings.TrimPrefix(value, "..."))
+	if i := strings.IndexAny(name, " @"); i != -1 {
+		name = name[:i]
+	}
+	if name == "" || name == "on" {
+		return ""
+	}
+	return name
+}
+
+// namedSpreads adds the names of the fragments spread in t to used.
+func namedSpreads(t reflect.Type, used map[string]bool, seen map[reflect.Type]bool) {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || seen[t] {
+		return
+	}
+	seen[t] = true
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if isExcluded(f) {
+			continue
+		}
+		if name := namedSpread(f); name != "" {
+			used[name] = true
+			continue
+		}
+		namedSpreads(f.Type, used, seen)
+	}
+}
+
+// spreadNames returns the names of the fragments spread in definition.
+func spreadNames(definition string) []string {
+	toks, err := document.Tokenize(definition)
+	if err != nil {
+		// Definitions are checked when they're registered.
+		return nil
+	}
+	var names []string
+	for i := 1; i < len(toks); i++ {
+		if toks[i-1].Kind == document.Punctuator && toks[i-1].Value == "..." &&
+			toks[i].Kind == document.Name && toks[i].Value != "on" {
+			names = append(names, toks[i].Value)
+		}
+	}
+	return names
+}
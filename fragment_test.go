@@ -0,0 +1,130 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/dbmedialab/go-graphql-client"
+)
+
+type avatarFields struct {
+	AvatarURL graphql.String `graphql:"avatarUrl(size: 72)"`
+}
+
+type userFields struct {
+	Login        graphql.String
+	avatarFields `graphql:"...avatarFields"`
+}
+
+func TestClient_RegisterFragment(t *testing.T) {
+	var gotQuery string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		var in struct{ Query string }
+		json.NewDecoder(req.Body).Decode(&in)
+		gotQuery = in.Query
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher", "avatarUrl": "https://example.com/gopher.png"}}}`)
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}})
+	if err := client.RegisterFragment("userFields", "User", userFields{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.RegisterFragment("avatarFields", "Actor", avatarFields{}); err != nil {
+		t.Fatal(err)
+	}
+
+	var q struct {
+		Viewer struct {
+			userFields `graphql:"...userFields"`
+		}
+	}
+	if err := client.Query(context.Background(), &q, nil); err != nil {
+		t.Fatal(err)
+	}
+	want := "{viewer{...userFields}}\n" +
+		"fragment avatarFields on Actor{avatarUrl(size: 72)}\n" +
+		"fragment userFields on User{login,...avatarFields}"
+	if gotQuery != want {
+		t.Errorf("got query:\n%s\nwant:\n%s", gotQuery, want)
+	}
+	if q.Viewer.Login != "gopher" || q.Viewer.AvatarURL != "https://example.com/gopher.png" {
+		t.Errorf("got viewer: %+v", q.Viewer)
+	}
+
+	if err := client.RegisterFragment("userFields", "User", userFields{}); err == nil {
+		t.Error("got error: nil, want: non-nil for a duplicate fragment")
+	}
+
+	var unknown struct {
+		Viewer struct {
+			userFields `graphql:"...missingFields"`
+		}
+	}
+	err := client.Query(context.Background(), &unknown, nil)
+	if got, want := err, "graphql: unknown fragment missingFields"; got == nil || got.Error() != want {
+		t.Errorf("got error: %v, want: %v", got, want)
+	}
+}
+
+func TestClient_RegisterFragment_string(t *testing.T) {
+	var gotQuery string
+	transport := graphql.TransportFunc(func(ctx context.Context, req graphql.Request) (*graphql.Response, error) {
+		gotQuery = req.Query
+		return &graphql.Response{Data: []byte(`{"article": {"title": "Gophers", "teaser": "All about gophers"}}`)}, nil
+	})
+	client := graphql.NewPluggableClient(transport)
+	if err := client.RegisterFragment("ArticleTeaser", "Article", `
+		title
+		teaser: summary(length: 80)
+	`); err != nil {
+		t.Fatal(err)
+	}
+	var q struct {
+		Article struct {
+			Teaser struct {
+				Title  graphql.String
+				Teaser graphql.String
+			} `graphql:"...ArticleTeaser"`
+		} `graphql:"article(id: 1)"`
+	}
+	if err := client.Query(context.Background(), &q, nil); err != nil {
+		t.Fatal(err)
+	}
+	want := "{article(id: 1){...ArticleTeaser}}\n" +
+		"fragment ArticleTeaser on Article{title teaser:summary(length:80)}"
+	if gotQuery != want {
+		t.Errorf("got query:\n%s\nwant:\n%s", gotQuery, want)
+	}
+	if q.Article.Teaser.Teaser != "All about gophers" {
+		t.Errorf("got article: %+v", q.Article)
+	}
+
+	if err := client.RegisterFragment("ArticleSearch", "Article", `related(q: "a...b") { ...ArticleTeaser }`); err != nil {
+		t.Fatal(err)
+	}
+	var search struct {
+		Article struct {
+			Search struct {
+				Title  graphql.String
+				Teaser graphql.String
+			} `graphql:"...ArticleSearch"`
+		} `graphql:"article(id: 1)"`
+	}
+	if err := client.Query(context.Background(), &search, nil); err != nil {
+		t.Fatal(err)
+	}
+	want = "{article(id: 1){...ArticleSearch}}\n" +
+		"fragment ArticleSearch on Article{related(q:\"a...b\"){...ArticleTeaser}}\n" +
+		"fragment ArticleTeaser on Article{title teaser:summary(length:80)}"
+	if gotQuery != want {
+		t.Errorf("got query:\n%s\nwant:\n%s", gotQuery, want)
+	}
+
+	err := client.RegisterFragment("broken", "Article", `title "`)
+	if err == nil {
+		t.Error("got error: nil, want: non-nil for an invalid selection set")
+	}
+}
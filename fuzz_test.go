@@ -0,0 +1,39 @@
+//go:build go1.18
+// +build go1.18
+
+package graphql
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/dbmedialab/go-graphql-client/jsonutil"
+)
+
+func FuzzDecodeResponse(f *testing.F) {
+	for _, seed := range []string{
+		`{"data": {"viewer": {"login": "gopher"}}}`,
+		`{"data": null, "errors": [{"message": "m", "path": ["viewer", 0], "locations": [{"line": 1, "column": 2}]}]}`,
+		`{"extensions": {"cost": 1}, "data": {"viewer": {"repositories": [{"name": "a"}, null]}}}`,
+		`{"data": {"viewer": {"login": 1e999}}}`,
+		`{"errors": {"message": "m"}}`,
+		`[]`,
+	} {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var q struct {
+			Viewer *struct {
+				Login        String
+				Repositories []*struct {
+					Name String
+				}
+			}
+		}
+		err := decodeResponse(bytes.NewReader(data), &q, jsonutil.Options{}, func(map[string]interface{}) {})
+		if err != nil && strings.HasPrefix(err.Error(), "panic decoding ") {
+			t.Errorf("decoding %q: %v", data, err)
+		}
+	})
+}
@@ -2,42 +2,120 @@ package graphql
 
 import (
 	"context"
+	"encoding/base64"
 	"net/http"
+	"reflect"
+	"time"
 
-	"github.com/dbmedialab/go-graphql-client/internal/jsonutil"
+	"github.com/dbmedialab/go-graphql-client/jsonutil"
 )
 
 // Client is a GraphQL client.
 type Client struct {
-	transport Transport
+	transport  Transport
+	subscriber SubscriptionTransport // Of subscriptions, if not nil.
+	header     http.Header
+	timeout    time.Duration
+	endpoint   string
+	tenant     string
+	progress   func(Progress)
+	fragments  *fragmentRegistry
+
+	httpOverrides httpOverrides // Of TransportHTTP's requests.
+
+	resultHooks []ResultHook
+
+	unusedVariables func(req Request, unused []string) error
+	minifyQueries   bool // Of QueryCustom and MutateCustom.
+	checkVariables  bool // Of QueryCustom and MutateCustom.
+
+	decodeHooks decodeHooks
+	fieldNamer  *fieldNamer // Names untagged fields, if not nil.
+	enums       enums
+
+	bytesEncoding *base64.Encoding      // Of byte slices, if not the standard one.
+	decimals      map[reflect.Type]bool // Types sent as their String methods give.
+
+	maxDecodeDepth int // Of response data, or the default if not positive.
+
+	queryOptions []QueryOption // Defaults for every query and mutation.
 }
 
 // NewClient creates a GraphQL client targeting the specified GraphQL server URL.
 // If httpClient is nil, then http.DefaultClient is used.
-func NewClient(url string, httpClient *http.Client) *Client {
-	return &Client{
-		transport: TransportHTTP{
-			URL:        url,
-			HTTPClient: httpClient,
-		},
-	}
+func NewClient(url string, httpClient *http.Client, opts ...ClientOption) *Client {
+	return NewPluggableClient(TransportHTTP{
+		URL:        url,
+		HTTPClient: httpClient,
+	}, opts...)
 }
 
 // NewPluggableClient creates a GraphQL client using the transport implementation given.
 // This is like NewClient, but can support any implementation, rather than just http.
 // (This may also be useful for testing -- you can provide a transport which uses
 // fixture data on the filesystem, for example!)
-func NewPluggableClient(transport Transport) *Client {
-	return &Client{
+func NewPluggableClient(transport Transport, opts ...ClientOption) *Client {
+	c := &Client{
 		transport: transport,
+		fragments: &fragmentRegistry{},
 	}
+	// Middleware wraps transport, so whether it can subscribe is known
+	// only before the options are applied.
+	if st, ok := transport.(SubscriptionTransport); ok {
+		c.subscriber = st
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 // Query executes a single GraphQL query request,
 // with a query derived from q, populating the response into it.
 // q should be a pointer to struct that corresponds to the GraphQL schema.
-func (c *Client) Query(ctx context.Context, q interface{}, variables map[string]interface{}) error {
-	return c.do(ctx, q, constructQuery(q, variables), variables)
+func (c *Client) Query(ctx context.Context, q interface{}, variables map[string]interface{}, opts ...QueryOption) error {
+	query, variables, err := c.construct(constructQuery, q, variables, opts)
+	if err != nil {
+		return err
+	}
+	return c.do(ctx, q, query, variables)
+}
+
+// construct returns the document for an operation derived from v by
+// constructOp, with the client's query options followed by opts, and the
+// variables to send with it.
+func (c *Client) construct(constructOp func(interface{}, map[string]interface{}, ...QueryOption) (string, error), v interface{}, variables map[string]interface{}, opts []QueryOption) (string, map[string]interface{}, error) {
+	opts = c.withQueryOptions(opts)
+	build := func(variables map[string]interface{}) (string, error) {
+		query, err := constructOp(v, variables, opts...)
+		if err != nil {
+			return "", err
+		}
+		return c.withFragments(query, v)
+	}
+	query, err := build(variables)
+	if err != nil {
+		return "", nil, validationError(err)
+	}
+	used, err := c.usedVariables(query, variables)
+	if err != nil {
+		return "", nil, validationError(err)
+	}
+	if len(used) != len(variables) {
+		// Declare only the variables used.
+		variables = used
+		if query, err = build(variables); err != nil {
+			return "", nil, validationError(err)
+		}
+	}
+	o := newQueryOptions(opts)
+	if err := checkCost(query, variables, o); err != nil {
+		return "", nil, validationError(err)
+	}
+	if o.printer != nil {
+		query, err = o.printer.Print(query, v)
+	}
+	return query, variables, validationError(err)
 }
 
 // QueryCustom executes a single GraphQL query request,
@@ -45,14 +123,22 @@ func (c *Client) Query(ctx context.Context, q interface{}, variables map[string]
 // slot should be a pointer to struct that corresponds to the GraphQL schema,
 // and the variables in the query must be provided by the variables map.
 func (c *Client) QueryCustom(ctx context.Context, q interface{}, query string, variables map[string]interface{}) error {
+	query, variables, err := c.customQuery(query, variables)
+	if err != nil {
+		return err
+	}
 	return c.do(ctx, q, query, variables)
 }
 
 // Mutate executes a single GraphQL mutation request,
 // with a mutation derived from m, populating the response into it.
 // m should be a pointer to struct that corresponds to the GraphQL schema.
-func (c *Client) Mutate(ctx context.Context, m interface{}, variables map[string]interface{}) error {
-	return c.do(ctx, m, constructMutation(m, variables), variables)
+func (c *Client) Mutate(ctx context.Context, m interface{}, variables map[string]interface{}, opts ...QueryOption) error {
+	query, variables, err := c.construct(constructMutation, m, variables, opts)
+	if err != nil {
+		return err
+	}
+	return c.do(ctx, m, query, variables)
 }
 
 // MutateCustom executes a single GraphQL mutation request,
@@ -60,24 +146,88 @@ func (c *Client) Mutate(ctx context.Context, m interface{}, variables map[string
 // m should be a pointer to struct that corresponds to the GraphQL schema,
 // and the variables in the query must be provided by the variables map.
 func (c *Client) MutateCustom(ctx context.Context, m interface{}, query string, variables map[string]interface{}) error {
+	query, variables, err := c.customQuery(query, variables)
+	if err != nil {
+		return err
+	}
 	return c.do(ctx, m, query, variables)
 }
 
+// customQuery returns query, minified if the client minifies queries, and
+// the variables it uses, having checked them if the client checks variables.
+func (c *Client) customQuery(query string, variables map[string]interface{}) (string, map[string]interface{}, error) {
+	variables, err := c.usedVariables(query, variables)
+	if err != nil {
+		return "", nil, validationError(err)
+	}
+	if c.checkVariables {
+		if err := checkVariables(query, variables); err != nil {
+			return "", nil, validationError(err)
+		}
+	}
+	if c.minifyQueries {
+		if query, err = NormalizeQuery(query); err != nil {
+			return "", nil, validationError(err)
+		}
+	}
+	return query, variables, nil
+}
+
 // do executes a single GraphQL operation.
 func (c *Client) do(ctx context.Context, v interface{}, query string, variables map[string]interface{}) error {
+	if err := c.enums.check(variables); err != nil {
+		return validationError(err)
+	}
 	in := Request{
-		Query:     query,
-		Variables: variables,
+		Query:         query,
+		Variables:     c.inputVariables(variables),
+		OperationName: queryOperationName(query),
+	}
+	err := c.execute(ctx, v, in)
+	if len(c.resultHooks) == 0 || !populated(err) {
+		return err
 	}
+	for _, hook := range c.resultHooks {
+		if err := hook(ctx, in, v); err != nil {
+			return err
+		}
+	}
+	return err
+}
 
-	out, err := c.transport.Do(ctx, in)
-	if err != nil {
+// execute sends in and decodes the response into v.
+func (c *Client) execute(ctx context.Context, v interface{}, in Request) error {
+	var progress *progressReporter
+	if c.progress != nil {
+		progress = &progressReporter{fn: c.progress}
+		ctx = context.WithValue(ctx, progressKey{}, progress)
+	}
+	if t, ok := c.transport.(TransportHTTP); ok && t.SpoolThreshold > 0 {
+		err := c.doSpooled(ctx, t, in, v, progress)
+		if progress != nil {
+			progress.done()
+		}
 		return err
 	}
-	err = jsonutil.UnmarshalGraphQL(out.Data, v)
+	out, err := c.send(ctx, in)
+	var stale *StaleResultError
+	if s, ok := err.(*StaleResultError); ok {
+		// Populate the stale result, but still report the failure.
+		stale, out, err = s, s.response, nil
+	}
 	if err != nil {
 		return err
 	}
+	err = jsonutil.UnmarshalGraphQLOptions(out.Data, v, c.decodeOptions(progress))
+	if progress != nil {
+		progress.done()
+	}
+	if err != nil {
+		return decodeError(err)
+	}
+	if stale != nil {
+		return stale
+	}
 	if len(out.Errors) > 0 {
 		return out.Errors
 	}
@@ -89,14 +239,54 @@ func (c *Client) do(ctx context.Context, v interface{}, query string, variables
 //
 // Specification: https://facebook.github.io/graphql/#sec-Errors.
 type errors []struct {
-	Message   string
+	Message   string `json:"message"`
 	Locations []struct {
-		Line   int
-		Column int
-	}
+		Line   int `json:"line"`
+		Column int `json:"column"`
+	} `json:"locations,omitempty"`
+	// Path is the path to the response field that the error is for,
+	// made up of field names and list indices, if it's known.
+	Path []interface{} `json:"path,omitempty"`
+	// Extensions holds additional information about the error, such as
+	// a "code", as the server provides.
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
 }
 
 // Error implements error interface.
 func (e errors) Error() string {
 	return e[0].Message
 }
+
+// ErrorsByAlias splits the GraphQL errors in err up by the root field they're
+// for, keyed by the field's response key (its alias, if it has one). This is
+// useful when several independent operations are combined into one request
+// using aliases: each can be given its own errors. Errors whose path isn't
+// known are keyed by "".
+//
+// If err doesn't hold errors reported by the GraphQL server, ErrorsByAlias
+// returns nil.
+func ErrorsByAlias(err error) map[string]error {
+	errs, ok := err.(errors)
+	if !ok {
+		return nil
+	}
+	byAlias := map[string]errors{}
+	for _, e := range errs {
+		key := rootKey(e.Path)
+		byAlias[key] = append(byAlias[key], e)
+	}
+	m := make(map[string]error, len(byAlias))
+	for key, errs := range byAlias {
+		m[key] = errs
+	}
+	return m
+}
+
+// rootKey returns the response key of the root field in path, or "".
+func rootKey(path []interface{}) string {
+	if len(path) == 0 {
+		return ""
+	}
+	key, _ := path[0].(string)
+	return key
+}
@@ -3,6 +3,7 @@ package graphql_test
 import (
 	"context"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -63,6 +64,113 @@ func TestClient_Query_partialResultWithErrorResponse(t *testing.T) {
 	}
 }
 
+func TestErrorsByAlias(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{
+			"data": {"a": null, "b": {"name": null}},
+			"errors": [
+				{"message": "a failed", "path": ["a"]},
+				{"message": "b.name failed", "path": ["b", "name"]},
+				{"message": "rate limited"}
+			]
+		}`)
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}})
+
+	var q struct {
+		A *struct{ Name *graphql.String } `graphql:"a: user(login: \"a\")"`
+		B *struct{ Name *graphql.String } `graphql:"b: user(login: \"b\")"`
+	}
+	err := client.Query(context.Background(), &q, nil)
+	got := graphql.ErrorsByAlias(err)
+	want := map[string]string{"a": "a failed", "b": "b.name failed", "": "rate limited"}
+	if len(got) != len(want) {
+		t.Fatalf("got %d aliases with errors, want %d", len(got), len(want))
+	}
+	for alias, msg := range want {
+		if got[alias] == nil || got[alias].Error() != msg {
+			t.Errorf("got error for alias %q: %v, want: %v", alias, got[alias], msg)
+		}
+	}
+
+	if got := graphql.ErrorsByAlias(io.EOF); got != nil {
+		t.Errorf("got %v for a non-GraphQL error, want nil", got)
+	}
+}
+
+func TestClient_Query_injectTypename(t *testing.T) {
+	var gotQuery string
+	transport := graphql.TransportFunc(func(ctx context.Context, req graphql.Request) (*graphql.Response, error) {
+		gotQuery = req.Query
+		return &graphql.Response{Data: []byte(`{"__typename": "Query", "viewer": {"__typename": "User", "login": "gopher"}}`)}, nil
+	})
+	client := graphql.NewPluggableClient(transport, graphql.WithQueryOptions(graphql.InjectTypename()))
+
+	var q struct {
+		Viewer struct {
+			Login graphql.String
+		}
+	}
+	if err := client.Query(context.Background(), &q, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := gotQuery, "{__typename,viewer{__typename,login}}"; got != want {
+		t.Errorf("got query: %q, want: %q", got, want)
+	}
+	if got, want := q.Viewer.Login, graphql.String("gopher"); got != want {
+		t.Errorf("got login: %q, want: %q", got, want)
+	}
+}
+
+func TestClient_Query_operationName(t *testing.T) {
+	var gotBody string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		body, _ := ioutil.ReadAll(req.Body)
+		gotBody = string(body)
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}})
+
+	var q struct {
+		Viewer struct {
+			Login graphql.String
+		}
+	}
+	if err := client.Query(context.Background(), &q, nil, graphql.OperationName("GetViewer")); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := gotBody, `{"query":"query GetViewer{viewer{login}}","operationName":"GetViewer"}`+"\n"; got != want {
+		t.Errorf("got body: %q, want: %q", got, want)
+	}
+}
+
+func TestClient_Query_constructionError(t *testing.T) {
+	sent := false
+	transport := graphql.TransportFunc(func(ctx context.Context, req graphql.Request) (*graphql.Response, error) {
+		sent = true
+		return &graphql.Response{Data: []byte(`{}`)}, nil
+	})
+	client := graphql.NewPluggableClient(transport)
+
+	type node struct {
+		Children []node
+	}
+	var q struct {
+		Root node
+	}
+	err := client.Query(context.Background(), &q, nil)
+	if got, want := err, "cycle found: struct { Root graphql_test.node }.Root->graphql_test.node.Children->node"; got == nil || got.Error() != want {
+		t.Errorf("got error: %v, want: %v", got, want)
+	}
+	if sent {
+		t.Error("query was sent despite the error")
+	}
+}
+
 // localRoundTripper is an http.RoundTripper that executes HTTP transactions
 // by using handler directly, instead of going over an HTTP connection.
 type localRoundTripper struct {
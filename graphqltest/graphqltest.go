@@ -0,0 +1,96 @@
+// Package graphqltest provides helpers for testing code that uses the
+// graphql package, such as checking the queries that structs derive in
+// table tests:
+//
+//	func TestQueries(t *testing.T) {
+//		graphqltest.AssertQuery(t, viewerQuery{}, `{ viewer { login avatarUrl(size: 72) } }`)
+//	}
+package graphqltest
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/dbmedialab/go-graphql-client"
+	"github.com/dbmedialab/go-graphql-client/internal/document"
+)
+
+// TB is the part of testing.TB that the helpers use, so that they can be
+// given a *testing.T, *testing.B, or a fake in tests of their own.
+type TB interface {
+	Errorf(format string, args ...interface{})
+}
+
+// AssertQuery reports an error to t if the selection set that v derives,
+// as graphql.GenerateQueryFields gives it, isn't want. Both are normalized
+// as graphql.NormalizeQuery does, so want can be written with whitespace,
+// commas and comments as is readable; fields are compared in order, which
+// for v is the order of its struct fields. The error shows both selection
+// sets indented, and a diff of their lines.
+func AssertQuery(t TB, v interface{}, want string) {
+	if h, ok := t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+	got, err := graphql.GenerateQueryFieldsE(v)
+	if err != nil {
+		t.Errorf("graphqltest: %T: %v", v, err)
+		return
+	}
+	wantToks, err := document.Tokenize(want)
+	if err != nil {
+		t.Errorf("graphqltest: want: %v", err)
+		return
+	}
+	gotToks, err := document.Tokenize(got)
+	if err != nil {
+		t.Errorf("graphqltest: %T: %v", v, err)
+		return
+	}
+	if document.Compact(gotToks) == document.Compact(wantToks) {
+		return
+	}
+	gotText, wantText := document.Indent(gotToks, "  "), document.Indent(wantToks, "  ")
+	t.Errorf("query of %T:\ngot:\n%s\nwant:\n%s\ndiff (-got +want):\n%s", v, gotText, wantText, diff(gotText, wantText))
+}
+
+// diff returns the differences between the lines of a and b, with a line
+// per line of either, prefixed by "-" if it's only in a, "+" if it's only
+// in b, and " " if it's in both.
+func diff(a, b string) string {
+	x, y := strings.Split(a, "\n"), strings.Split(b, "\n")
+	// lcs[i][j] is the length of the longest common subsequence of
+	// x[i:] and y[j:].
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			switch {
+			case x[i] == y[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	var buf bytes.Buffer
+	i, j := 0, 0
+	for i < len(x) || j < len(y) {
+		switch {
+		case i < len(x) && j < len(y) && x[i] == y[j]:
+			fmt.Fprintf(&buf, " %s\n", x[i])
+			i, j = i+1, j+1
+		case j == len(y) || i < len(x) && lcs[i+1][j] >= lcs[i][j+1]:
+			fmt.Fprintf(&buf, "-%s\n", x[i])
+			i++
+		default:
+			fmt.Fprintf(&buf, "+%s\n", y[j])
+			j++
+		}
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
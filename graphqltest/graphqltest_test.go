@@ -0,0 +1,76 @@
+package graphqltest_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/dbmedialab/go-graphql-client"
+	"github.com/dbmedialab/go-graphql-client/graphqltest"
+)
+
+// recorder is a graphqltest.TB that records the errors reported to it.
+type recorder struct {
+	errors []string
+}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+type viewerQuery struct {
+	Viewer struct {
+		Login     graphql.String
+		AvatarURL graphql.String `graphql:"avatarUrl(size: 72)"`
+	}
+}
+
+func TestAssertQuery(t *testing.T) {
+	graphqltest.AssertQuery(t, viewerQuery{}, `
+		# The signed-in user.
+		{
+			viewer {
+				login,
+				avatarUrl(size: 72)
+			}
+		}
+	`)
+
+	var r recorder
+	graphqltest.AssertQuery(&r, viewerQuery{}, `{viewer{login bio avatarUrl(size: 72)}}`)
+	if len(r.errors) != 1 {
+		t.Fatalf("got %d errors, want 1", len(r.errors))
+	}
+	want := `query of graphqltest_test.viewerQuery:
+got:
+{
+  viewer {
+    login
+    avatarUrl(size: 72)
+  }
+}
+want:
+{
+  viewer {
+    login
+    bio
+    avatarUrl(size: 72)
+  }
+}
+diff (-got +want):
+ {
+   viewer {
+     login
++    bio
+     avatarUrl(size: 72)
+   }
+ }`
+	if r.errors[0] != want {
+		t.Errorf("got error:\n%s\nwant:\n%s", r.errors[0], want)
+	}
+
+	r.errors = nil
+	graphqltest.AssertQuery(&r, viewerQuery{}, `{viewer{login(}`)
+	if len(r.errors) != 1 {
+		t.Errorf("got %d errors, want 1 for an invalid want", len(r.errors))
+	}
+}
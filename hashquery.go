@@ -0,0 +1,35 @@
+package graphql
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/dbmedialab/go-graphql-client/internal/document"
+)
+
+// NormalizeQuery returns query with insignificant whitespace, commas and
+// comments removed, as Canonical minifies queries: tokens are separated
+// by a single space only where they'd otherwise run together. Strings are
+// kept as written. Queries that differ only in formatting normalize to the
+// same text, which is stable across versions of this package.
+func NormalizeQuery(query string) (string, error) {
+	toks, err := document.Tokenize(query)
+	if err != nil {
+		return "", err
+	}
+	return document.Compact(toks), nil
+}
+
+// HashQuery returns the SHA-256 hash of query, normalized by NormalizeQuery,
+// in lowercase hexadecimal, as Apollo's persisted queries identify queries
+// by, so that the hashes of a client's queries match those of an allowlist
+// built from the same operations, however they're formatted. Servers that
+// hash the query they're sent expect its normalized text to be sent with
+// the hash. A query that can't be tokenized is hashed as is.
+func HashQuery(query string) string {
+	if normalized, err := NormalizeQuery(query); err == nil {
+		query = normalized
+	}
+	sum := sha256.Sum256([]byte(query))
+	return hex.EncodeToString(sum[:])
+}
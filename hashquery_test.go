@@ -0,0 +1,38 @@
+package graphql_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/dbmedialab/go-graphql-client"
+)
+
+func TestHashQuery(t *testing.T) {
+	queries := []string{
+		"{viewer{login bio}}",
+		"{ viewer { login, bio } }",
+		"# The viewer.\n{\n  viewer {\n    login\n    bio # Optional.\n  }\n}\n",
+	}
+	sum := sha256.Sum256([]byte("{viewer{login bio}}"))
+	want := hex.EncodeToString(sum[:])
+	for _, q := range queries {
+		if got := graphql.HashQuery(q); got != want {
+			t.Errorf("HashQuery(%q) = %s, want %s", q, got, want)
+		}
+	}
+	if got := graphql.HashQuery(`{a(s: "x  y")}`); got == graphql.HashQuery(`{a(s: "x y")}`) {
+		t.Errorf("HashQuery ignored spaces in a string: %s", got)
+	}
+
+	got, err := graphql.NormalizeQuery(`query Q($id: ID!) { node(id: $id) { ... on User { login } } }`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `query Q($id:ID!){node(id:$id){... on User{login}}}`; got != want {
+		t.Errorf("got: %s, want: %s", got, want)
+	}
+	if _, err := graphql.NormalizeQuery(`{a(s: "unterminated)}`); err == nil {
+		t.Error("got no error normalizing an unterminated string")
+	}
+}
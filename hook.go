@@ -0,0 +1,31 @@
+package graphql
+
+import "context"
+
+// ResultHook is called with the result of an operation after it has been
+// decoded into v, the value passed to Query, Mutate, or their Custom
+// variants. It may inspect or modify v, for example to validate, enrich,
+// or scrub it. A non-nil error is returned from the operation instead of
+// any GraphQL errors.
+//
+// Hooks are called whenever a result was decoded, including partial results
+// accompanied by GraphQL errors, and stale results served by a StaleCache.
+type ResultHook func(ctx context.Context, req Request, v interface{}) error
+
+// WithResultHooks adds hooks to be called, in order, with the result of
+// every operation. A hook returning an error stops the rest.
+func WithResultHooks(hooks ...ResultHook) ClientOption {
+	return func(c *Client) {
+		c.resultHooks = append(c.resultHooks[:len(c.resultHooks):len(c.resultHooks)], hooks...)
+	}
+}
+
+// populated reports whether an operation that returned err had its result
+// decoded, so that err is about the result rather than a failure to get one.
+func populated(err error) bool {
+	switch err.(type) {
+	case nil, errors, *StaleResultError:
+		return true
+	}
+	return false
+}
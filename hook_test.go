@@ -0,0 +1,78 @@
+package graphql_test
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/dbmedialab/go-graphql-client"
+)
+
+func TestWithResultHooks(t *testing.T) {
+	fail := false
+	transport := graphql.TransportFunc(func(ctx context.Context, req graphql.Request) (*graphql.Response, error) {
+		if fail {
+			return nil, fmt.Errorf("connection refused")
+		}
+		return &graphql.Response{Data: []byte(`{"viewer": {"login": "gopher", "email": "gopher@example.com"}}`)}, nil
+	})
+
+	type viewer struct {
+		Login graphql.String
+		Email graphql.String
+	}
+	var calls []string
+	scrub := func(ctx context.Context, req graphql.Request, v interface{}) error {
+		calls = append(calls, "scrub")
+		// Clear every field named Email, whatever the query.
+		var walk func(reflect.Value)
+		walk = func(v reflect.Value) {
+			switch v.Kind() {
+			case reflect.Ptr:
+				if !v.IsNil() {
+					walk(v.Elem())
+				}
+			case reflect.Struct:
+				for i := 0; i < v.NumField(); i++ {
+					if v.Type().Field(i).Name == "Email" {
+						v.Field(i).Set(reflect.Zero(v.Field(i).Type()))
+					} else {
+						walk(v.Field(i))
+					}
+				}
+			}
+		}
+		walk(reflect.ValueOf(v))
+		return nil
+	}
+	validate := func(ctx context.Context, req graphql.Request, v interface{}) error {
+		calls = append(calls, "validate")
+		return fmt.Errorf("invalid result")
+	}
+	client := graphql.NewPluggableClient(transport, graphql.WithResultHooks(scrub, validate))
+
+	var q struct {
+		Viewer viewer
+	}
+	err := client.Query(context.Background(), &q, nil)
+	if got, want := err, "invalid result"; got == nil || got.Error() != want {
+		t.Errorf("got error: %v, want: %v", got, want)
+	}
+	if got, want := q.Viewer, (viewer{Login: "gopher"}); got != want {
+		t.Errorf("got viewer: %+v, want: %+v", got, want)
+	}
+	if got, want := fmt.Sprint(calls), "[scrub validate]"; got != want {
+		t.Errorf("got calls: %v, want: %v", got, want)
+	}
+
+	// Hooks aren't called without a result.
+	calls = nil
+	fail = true
+	if err := client.Query(context.Background(), &q, nil); err == nil || err.Error() != "connection refused" {
+		t.Errorf("got error: %v, want: connection refused", err)
+	}
+	if len(calls) != 0 {
+		t.Errorf("got calls: %v, want none", calls)
+	}
+}
@@ -0,0 +1,151 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strings"
+)
+
+// httpOverrides change how TransportHTTP sends a request: with another
+// method, to a longer path, or with query parameters.
+type httpOverrides struct {
+	method string
+	path   string
+	query  url.Values
+}
+
+type httpOverridesKey struct{}
+
+// WithURLPath returns a copy of ctx with which TransportHTTP sends requests
+// to path under its URL, such as "v2" for https://example.com/graphql/v2,
+// for gateways that route by path. It replaces the path given by the client's
+// WithURLPathSuffix.
+func WithURLPath(ctx context.Context, path string) context.Context {
+	o := httpOverridesFrom(ctx)
+	o.path = path
+	return context.WithValue(ctx, httpOverridesKey{}, o)
+}
+
+// WithURLQuery returns a copy of ctx with which TransportHTTP adds params to
+// the query of its URL, such as an api-version or locale a gateway requires.
+// They replace the client's WithURLQueryParams of the same names.
+func WithURLQuery(ctx context.Context, params url.Values) context.Context {
+	o := httpOverridesFrom(ctx)
+	o.query = mergeQuery(o.query, params)
+	return context.WithValue(ctx, httpOverridesKey{}, o)
+}
+
+// WithMethod returns a copy of ctx with which TransportHTTP sends requests
+// with method rather than POST. With GET, the request is encoded in the
+// query of the URL, as GraphQL over HTTP describes, which suits caches of
+// queries; servers refuse mutations sent that way.
+func WithMethod(ctx context.Context, method string) context.Context {
+	o := httpOverridesFrom(ctx)
+	o.method = method
+	return context.WithValue(ctx, httpOverridesKey{}, o)
+}
+
+// WithURLPathSuffix sends the client's operations to path under the
+// transport's URL, as WithURLPath does for a single context.
+func WithURLPathSuffix(path string) ClientOption {
+	return func(c *Client) {
+		c.httpOverrides.path = path
+	}
+}
+
+// WithURLQueryParams adds params to the query of the transport's URL for the
+// client's operations, as WithURLQuery does for a single context.
+func WithURLQueryParams(params url.Values) ClientOption {
+	return func(c *Client) {
+		c.httpOverrides.query = mergeQuery(c.httpOverrides.query, params)
+	}
+}
+
+// WithHTTPMethod sends the client's operations with method, as WithMethod
+// does for a single context.
+func WithHTTPMethod(method string) ClientOption {
+	return func(c *Client) {
+		c.httpOverrides.method = method
+	}
+}
+
+func httpOverridesFrom(ctx context.Context) httpOverrides {
+	o, _ := ctx.Value(httpOverridesKey{}).(httpOverrides)
+	return o
+}
+
+// withHTTPOverrides returns a copy of ctx with the overrides o, under those
+// ctx already has.
+func withHTTPOverrides(ctx context.Context, o httpOverrides) context.Context {
+	if o.method == "" && o.path == "" && len(o.query) == 0 {
+		return ctx
+	}
+	call := httpOverridesFrom(ctx)
+	if call.method != "" {
+		o.method = call.method
+	}
+	if call.path != "" {
+		o.path = call.path
+	}
+	o.query = mergeQuery(o.query, call.query)
+	return context.WithValue(ctx, httpOverridesKey{}, o)
+}
+
+// mergeQuery returns a copy of q with the values of params, replacing those
+// of the same names. q isn't modified, since it may be shared.
+func mergeQuery(q, params url.Values) url.Values {
+	if len(params) == 0 {
+		return q
+	}
+	merged := make(url.Values, len(q)+len(params))
+	for k, v := range q {
+		merged[k] = v
+	}
+	for k, v := range params {
+		merged[k] = v
+	}
+	return merged
+}
+
+// url returns rawurl with the overrides' path and query. If get, the
+// request is encoded in the query too.
+func (o httpOverrides) url(rawurl string, req Request, get bool) (string, error) {
+	if o.path == "" && len(o.query) == 0 && !get {
+		return rawurl, nil
+	}
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return "", err
+	}
+	if o.path != "" {
+		u.Path = strings.TrimSuffix(u.Path, "/") + "/" + strings.TrimPrefix(o.path, "/")
+		u.RawPath = ""
+	}
+	q := u.Query()
+	for k, v := range o.query {
+		q[k] = v
+	}
+	if get {
+		q.Set("query", req.Query)
+		if req.OperationName != "" {
+			q.Set("operationName", req.OperationName)
+		}
+		if len(req.Variables) > 0 {
+			b, err := json.Marshal(req.Variables)
+			if err != nil {
+				return "", err
+			}
+			q.Set("variables", string(b))
+		}
+		if len(req.Extensions) > 0 {
+			b, err := json.Marshal(req.Extensions)
+			if err != nil {
+				return "", err
+			}
+			q.Set("extensions", string(b))
+		}
+	}
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
@@ -0,0 +1,45 @@
+package graphql_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/dbmedialab/go-graphql-client"
+)
+
+func TestHTTPOverrides(t *testing.T) {
+	var gotMethod, gotURL string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) {
+		gotMethod, gotURL = req.Method, req.URL.String()
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+	})
+	client := graphql.NewClient("/graphql?client=go", &http.Client{Transport: localRoundTripper{handler: mux}},
+		graphql.WithURLPathSuffix("v1"),
+		graphql.WithURLQueryParams(url.Values{"api-version": {"2024-01"}, "locale": {"nb"}}))
+	var q struct {
+		Viewer struct{ Login string }
+	}
+
+	if err := client.Query(context.Background(), &q, nil); err != nil {
+		t.Fatal(err)
+	}
+	if want := "/graphql/v1?api-version=2024-01&client=go&locale=nb"; gotMethod != "POST" || gotURL != want {
+		t.Errorf("got %s %s, want POST %s", gotMethod, gotURL, want)
+	}
+
+	// A call's overrides replace the client's.
+	ctx := graphql.WithURLPath(context.Background(), "/v2")
+	ctx = graphql.WithURLQuery(ctx, url.Values{"locale": {"en"}})
+	ctx = graphql.WithMethod(ctx, "GET")
+	if err := client.Query(ctx, &q, map[string]interface{}{"n": graphql.Int(1)}); err != nil {
+		t.Fatal(err)
+	}
+	want := "/graphql/v2?api-version=2024-01&client=go&locale=en&query=query%28%24n%3AInt%21%29%7Bviewer%7Blogin%7D%7D&variables=%7B%22n%22%3A1%7D"
+	if gotMethod != "GET" || gotURL != want {
+		t.Errorf("got %s %s, want GET %s", gotMethod, gotURL, want)
+	}
+}
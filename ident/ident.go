@@ -156,6 +156,16 @@ func (n Name) ToLowerCamelCase() string {
 	return strings.Join(n, "")
 }
 
+// ToSnakeCase expresses identifer name in snake_case naming convention.
+//
+// E.g., "client_mutation_id".
+func (n Name) ToSnakeCase() string {
+	for i, word := range n {
+		n[i] = strings.ToLower(word)
+	}
+	return strings.Join(n, "_")
+}
+
 // isInitialism reports whether word is an initialism.
 func isInitialism(word string) (string, bool) {
 	initialism := strings.ToUpper(word)
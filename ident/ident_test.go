@@ -114,6 +114,23 @@ func TestName_ToLowerCamelCase(t *testing.T) {
 	}
 }
 
+func TestName_ToSnakeCase(t *testing.T) {
+	tests := []struct {
+		in   ident.Name
+		want string
+	}{
+		{in: ident.Name{"client", "Mutation", "Id"}, want: "client_mutation_id"},
+		{in: ident.Name{"CLIENT", "MUTATION", "ID"}, want: "client_mutation_id"},
+		{in: ident.Name{"Viewer"}, want: "viewer"},
+	}
+	for _, tc := range tests {
+		got := tc.in.ToSnakeCase()
+		if got != tc.want {
+			t.Errorf("got: %q, want: %q", got, tc.want)
+		}
+	}
+}
+
 func TestMixedCapsToLowerCamelCase(t *testing.T) {
 	tests := []struct {
 		in   string
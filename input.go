@@ -0,0 +1,255 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// inputVariables returns variables with the structs in their values made
+// into input objects, their fields named as the client names those of
+// queries, custom scalars encoded by their MarshalGQL methods, byte slices
+// encoded as WithBytesEncoding says, durations in ISO 8601 format, and the
+// decimals of WithDecimalType as strings.
+func (c *Client) inputVariables(variables map[string]interface{}) map[string]interface{} {
+	e := inputEncoding{name: LowerCamelCase, bytes: c.bytesEncoding, decimals: c.decimals}
+	if c.fieldNamer != nil {
+		e.name = c.fieldNamer.name
+	}
+	var converted map[string]interface{}
+	for k, v := range variables {
+		if v == nil || !e.hasInputObjects(reflect.TypeOf(v)) {
+			continue
+		}
+		if converted == nil {
+			// Copy the variables, since they're the caller's.
+			converted = make(map[string]interface{}, len(variables))
+			for k, v := range variables {
+				converted[k] = v
+			}
+		}
+		converted[k] = e.inputValue(reflect.ValueOf(v))
+	}
+	if converted == nil {
+		return variables
+	}
+	return converted
+}
+
+// inputEncoding says how variables are encoded.
+type inputEncoding struct {
+	name     FieldNamer            // Names the fields of input objects.
+	bytes    *base64.Encoding      // Encodes byte slices, if not nil.
+	decimals map[reflect.Type]bool // Types encoded as their String methods give.
+}
+
+// hasInputObjects reports whether values of type t may hold structs to be
+// sent as input objects: structs that don't encode themselves as JSON, and
+// lists, maps and pointers of them; or custom scalars, durations, decimals,
+// or byte slices to be encoded with e.bytes. Values given with WithType may
+// hold any of them.
+func (e inputEncoding) hasInputObjects(t reflect.Type) bool {
+	if e.bytes != nil && isBytes(t) || t == durationType || e.decimals[t] || t == typedValueType {
+		return true
+	}
+	if t.Implements(gqlMarshaler) || reflect.PtrTo(t).Implements(gqlMarshaler) {
+		return true
+	}
+	if t.Implements(jsonMarshaler) || t.Implements(textMarshaler) ||
+		reflect.PtrTo(t).Implements(jsonMarshaler) || reflect.PtrTo(t).Implements(textMarshaler) {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return e.hasInputObjects(t.Elem())
+	case reflect.Struct, reflect.Interface:
+		return true
+	}
+	return false
+}
+
+// inputValue returns v, with the structs in it made into input objects and
+// its custom scalars, byte slices, durations and decimals marshaled, as a
+// value to be encoded as JSON.
+func (e inputEncoding) inputValue(v reflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	t := v.Type()
+	switch {
+	case t == typedValueType:
+		tv := v.Interface().(typedValue)
+		return typedValue{typ: tv.typ, value: e.inputValue(reflect.ValueOf(tv.value))}
+	case t.Implements(gqlMarshaler):
+		if t.Kind() == reflect.Ptr && v.IsNil() {
+			return nil
+		}
+		return marshaledValue{m: v.Interface().(Marshaler)}
+	case reflect.PtrTo(t).Implements(gqlMarshaler):
+		if !v.CanAddr() {
+			p := reflect.New(t)
+			p.Elem().Set(v)
+			v = p.Elem()
+		}
+		return marshaledValue{m: v.Addr().Interface().(Marshaler)}
+	case e.bytes != nil && isBytes(t):
+		if v.IsNil() {
+			return nil
+		}
+		return e.bytes.EncodeToString(v.Bytes())
+	case t == durationType:
+		return formatDuration(time.Duration(v.Int()))
+	case e.decimals[t]:
+		return v.Interface().(fmt.Stringer).String()
+	}
+	if !e.hasInputObjects(t) {
+		return v.Interface()
+	}
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return e.inputValue(v.Elem())
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		l := make([]interface{}, v.Len())
+		for i := range l {
+			l[i] = e.inputValue(v.Index(i))
+		}
+		return l
+	case reflect.Map:
+		if v.IsNil() || t.Key().Kind() != reflect.String {
+			return v.Interface()
+		}
+		m := make(map[string]interface{}, v.Len())
+		for _, k := range v.MapKeys() {
+			m[k.String()] = e.inputValue(v.MapIndex(k))
+		}
+		return m
+	case reflect.Struct:
+		var o inputObject
+		o.addFields(v, e)
+		return o
+	}
+	return v.Interface()
+}
+
+// inputObject is an input object value, whose fields are encoded in order.
+type inputObject []inputField
+
+type inputField struct {
+	name  string
+	value interface{}
+}
+
+// addFields adds the fields of struct v to o. They're named by their graphql
+// tags, or else by name; fields without a graphql tag but with a json tag
+// keep the name and options it gives them, as they had before structs were
+// sent as input objects.
+func (o *inputObject) addFields(v reflect.Value, e inputEncoding) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, tagged := f.Tag.Lookup("graphql")
+		if !tagged {
+			tag, tagged = f.Tag.Lookup("json")
+		}
+		if ft := f.Type; f.Anonymous && !tagged && (ft.Kind() == reflect.Struct || ft.Kind() == reflect.Ptr && ft.Elem().Kind() == reflect.Struct) {
+			if fv := reflect.Indirect(v.Field(i)); fv.IsValid() {
+				o.addFields(fv, e)
+			}
+			continue
+		}
+		if f.PkgPath != "" || tag == "-" {
+			continue
+		}
+		fieldName, opts := tag, ""
+		if i := strings.Index(tag, ","); i != -1 {
+			fieldName, opts = tag[:i], tag[i+1:]
+		}
+		if fieldName == "" {
+			fieldName = e.name(f.Name)
+		}
+		fv := v.Field(i)
+		if hasOption(opts, "omitempty") && isEmpty(fv) {
+			continue
+		}
+		*o = append(*o, inputField{name: fieldName, value: e.inputValue(fv)})
+	}
+}
+
+// MarshalJSON encodes o as a JSON object.
+func (o inputObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := json.Marshal(f.name)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		value, err := json.Marshal(f.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// hasOption reports whether the comma-separated tag options opts include opt.
+func hasOption(opts, opt string) bool {
+	for _, o := range strings.Split(opts, ",") {
+		if o == opt {
+			return true
+		}
+	}
+	return false
+}
+
+// isEmpty reports whether v is false, 0, a nil pointer or interface, or an
+// empty string, list or map, which the omitempty option leaves out, as it
+// does in encoding/json.
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
+
+// inputTypeName returns the name of the input type that variables of struct
+// type t are declared with: that given by the graphql-type tag of a blank
+// field, if it has one, or else its Go name.
+func inputTypeName(t reflect.Type) string {
+	for i := 0; i < t.NumField(); i++ {
+		if f := t.Field(i); f.Name == "_" {
+			if name, ok := f.Tag.Lookup("graphql-type"); ok {
+				return name
+			}
+		}
+	}
+	return t.Name()
+}
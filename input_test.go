@@ -0,0 +1,138 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/dbmedialab/go-graphql-client"
+)
+
+func TestClient_Mutate_inputObject(t *testing.T) {
+	type TagInput struct {
+		Name graphql.String `graphql:"label"`
+	}
+	type ReviewInput struct {
+		_          struct{}       `graphql-type:"CreateReviewInput"`
+		Stars      graphql.Int    `graphql:"stars"`
+		Commentary graphql.String // Named by the field namer.
+		Tags       []TagInput
+		Author     *TagInput `graphql:"author,omitempty"`
+		Legacy     string    `json:"legacy_name"`
+		Skipped    string    `graphql:"-"`
+	}
+	var gotQuery, gotVariables string
+	transport := graphql.TransportFunc(func(ctx context.Context, req graphql.Request) (*graphql.Response, error) {
+		gotQuery = req.Query
+		b, err := json.Marshal(req.Variables)
+		if err != nil {
+			return nil, err
+		}
+		gotVariables = string(b)
+		return &graphql.Response{Data: []byte(`{"createReview": {"stars": 5}}`)}, nil
+	})
+	client := graphql.NewPluggableClient(transport)
+
+	var m struct {
+		CreateReview struct {
+			Stars graphql.Int
+		} `graphql:"createReview(review: $review)"`
+	}
+	review := ReviewInput{
+		Stars:      5,
+		Commentary: "Great!",
+		Tags:       []TagInput{{Name: "classic"}},
+		Legacy:     "x",
+		Skipped:    "y",
+	}
+	if err := client.Mutate(context.Background(), &m, map[string]interface{}{"review": review}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := gotQuery, "mutation($review:CreateReviewInput!){createReview(review: $review){stars}}"; got != want {
+		t.Errorf("got query: %q, want: %q", got, want)
+	}
+	if got, want := gotVariables, `{"review":{"stars":5,"commentary":"Great!","tags":[{"label":"classic"}],"legacy_name":"x"}}`; got != want {
+		t.Errorf("got variables: %s, want: %s", got, want)
+	}
+}
+
+func TestClient_Mutate_inputObjectTypeMapping(t *testing.T) {
+	type ReviewInput struct {
+		ClientMutationID *graphql.String
+	}
+	var gotQuery, gotVariables string
+	transport := graphql.TransportFunc(func(ctx context.Context, req graphql.Request) (*graphql.Response, error) {
+		gotQuery = req.Query
+		b, err := json.Marshal(req.Variables)
+		if err != nil {
+			return nil, err
+		}
+		gotVariables = string(b)
+		return &graphql.Response{Data: []byte(`{"createReview": {"stars": 5}}`)}, nil
+	})
+	client := graphql.NewPluggableClient(transport,
+		graphql.WithScalarTypes(graphql.ScalarTypes{reflect.TypeOf(ReviewInput{}): "NewReview"}),
+		graphql.WithFieldNamer(graphql.SnakeCase),
+	)
+
+	var m struct {
+		CreateReview struct {
+			Stars graphql.Int
+		} `graphql:"createReview(reviews: $reviews)"`
+	}
+	variables := map[string]interface{}{
+		"reviews": []*ReviewInput{{ClientMutationID: graphql.NewString("1")}, nil},
+	}
+	if err := client.Mutate(context.Background(), &m, variables); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := gotQuery, "mutation($reviews:[NewReview]!){createReview(reviews: $reviews){stars}}"; got != want {
+		t.Errorf("got query: %q, want: %q", got, want)
+	}
+	if got, want := gotVariables, `{"reviews":[{"client_mutation_id":"1"},null]}`; got != want {
+		t.Errorf("got variables: %s, want: %s", got, want)
+	}
+	if got, ok := variables["reviews"].([]*ReviewInput); !ok || len(got) != 2 {
+		t.Errorf("the caller's variables were modified: %v", variables)
+	}
+}
+
+func TestClient_Mutate_inputObjectWithType(t *testing.T) {
+	type ReviewInput struct {
+		Name graphql.String `graphql:"the_name"`
+	}
+	var gotQuery, gotVariables string
+	transport := graphql.TransportFunc(func(ctx context.Context, req graphql.Request) (*graphql.Response, error) {
+		gotQuery = req.Query
+		b, err := json.Marshal(req.Variables)
+		if err != nil {
+			return nil, err
+		}
+		gotVariables = string(b)
+		return &graphql.Response{Data: []byte(`{"createReview": {"stars": 5}}`)}, nil
+	})
+	client := graphql.NewPluggableClient(transport)
+
+	var m struct {
+		CreateReview struct {
+			Stars graphql.Int
+		} `graphql:"createReview(review: $review, price: $price, ttl: $ttl, delay: $delay)"`
+	}
+	variables := graphql.VariablesFrom(struct {
+		Delay time.Duration `graphql-type:"Duration"`
+	}{Delay: time.Minute})
+	variables["review"] = graphql.WithType("In!", ReviewInput{Name: "n"})
+	variables["price"] = graphql.WithType("Money!", money{cents: 3, currency: "EUR"})
+	variables["ttl"] = graphql.WithType("Duration!", time.Hour)
+	if err := client.Mutate(context.Background(), &m, variables); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := gotQuery, "mutation($delay:Duration$price:Money!$review:In!$ttl:Duration!){createReview(review: $review, price: $price, ttl: $ttl, delay: $delay){stars}}"; got != want {
+		t.Errorf("got query: %q, want: %q", got, want)
+	}
+	if got, want := gotVariables, `{"delay":"PT1M","price":{"cents":3,"currency":"EUR"},"review":{"the_name":"n"},"ttl":"PT1H"}`; got != want {
+		t.Errorf("got variables: %s, want: %s", got, want)
+	}
+}
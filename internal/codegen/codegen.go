@@ -0,0 +1,533 @@
+// Package codegen generates Go types for operations written in GraphQL
+// documents, for use with the QueryCustom and MutateCustom methods of
+// graphql.Client.
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dbmedialab/go-graphql-client/ident"
+	"github.com/dbmedialab/go-graphql-client/internal/document"
+	"github.com/dbmedialab/go-graphql-client/introspection"
+)
+
+// Config configures code generation.
+type Config struct {
+	// Package is the name of the generated package.
+	Package string
+	// Schema is used to resolve the types of selected fields and variables.
+	Schema *introspection.Schema
+	// Scalars maps custom GraphQL scalar names to Go types, written as
+	// "[import/path.]Type". E.g., "DateTime": "time.Time".
+	// Custom scalars not in Scalars are generated as string types.
+	Scalars map[string]string
+}
+
+// Source is a GraphQL document to generate code for.
+type Source struct {
+	// Name identifies the document in error messages, typically its filename.
+	Name string
+	Text string
+}
+
+// builtinScalars maps the built-in GraphQL scalars to graphql package types.
+var builtinScalars = map[string]string{
+	"Boolean": "graphql.Boolean",
+	"Float":   "graphql.Float",
+	"ID":      "graphql.ID",
+	"Int":     "graphql.Int",
+	"String":  "graphql.String",
+}
+
+// Generate returns formatted Go source declaring, for each named operation
+// in sources, a response struct, a variables struct and a document constant,
+// plus types for the fragments, enums, input objects and custom scalars they use.
+func Generate(cfg Config, sources ...Source) ([]byte, error) {
+	g := &generator{
+		cfg:     cfg,
+		imports: map[string]bool{},
+		named:   map[string]bool{},
+	}
+	for _, src := range sources {
+		doc, err := document.Parse(src.Text)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", src.Name, err)
+		}
+		g.docs = append(g.docs, doc)
+		g.fragments = append(g.fragments, doc.Fragments...)
+	}
+	for _, f := range g.fragments {
+		if err := g.fragment(f); err != nil {
+			return nil, err
+		}
+	}
+	for i, doc := range g.docs {
+		for _, op := range doc.Operations {
+			if err := g.operation(doc, op); err != nil {
+				return nil, fmt.Errorf("%s: %v", sources[i].Name, err)
+			}
+		}
+	}
+	return g.output()
+}
+
+type generator struct {
+	cfg       Config
+	docs      []*document.Document
+	fragments []*document.Fragment
+	decls     bytes.Buffer
+	imports   map[string]bool
+	// named tracks schema types (enums, input objects, scalars) already declared.
+	named map[string]bool
+	// pending holds schema types that are referenced but not yet declared.
+	pending []string
+}
+
+func (g *generator) printf(format string, a ...interface{}) {
+	fmt.Fprintf(&g.decls, format, a...)
+}
+
+func (g *generator) fragment(f *document.Fragment) error {
+	t := g.cfg.Schema.Type(f.TypeCondition)
+	if t == nil {
+		return fmt.Errorf("fragment %s: unknown type %s", f.Name, f.TypeCondition)
+	}
+	body, err := g.selectionSet(t, f.SelectionSet)
+	if err != nil {
+		return fmt.Errorf("fragment %s: %v", f.Name, err)
+	}
+	name := exportedName(f.Name)
+	g.printf("// %s is the %s fragment on %s.\ntype %s %s\n\n", name, f.Name, f.TypeCondition, name, body)
+	return nil
+}
+
+func (g *generator) operation(doc *document.Document, op *document.Operation) error {
+	if op.Name == "" {
+		return fmt.Errorf("anonymous %s: operations must be named", op.Type)
+	}
+	var root *introspection.TypeName
+	switch op.Type {
+	case "query":
+		root = g.cfg.Schema.QueryType
+	case "mutation":
+		root = g.cfg.Schema.MutationType
+	case "subscription":
+		root = g.cfg.Schema.SubscriptionType
+	}
+	if root == nil || g.cfg.Schema.Type(root.Name) == nil {
+		return fmt.Errorf("%s %s: schema has no %s type", op.Type, op.Name, op.Type)
+	}
+	body, err := g.selectionSet(g.cfg.Schema.Type(root.Name), op.SelectionSet)
+	if err != nil {
+		return fmt.Errorf("%s %s: %v", op.Type, op.Name, err)
+	}
+	name := exportedName(op.Name)
+	opType := exportedName(op.Type)
+	g.printf("// %s%s is the response of the %s %s.\ntype %s%s %s\n\n", name, opType, op.Name, op.Type, name, opType, body)
+
+	if err := g.variables(name, op); err != nil {
+		return fmt.Errorf("%s %s: %v", op.Type, op.Name, err)
+	}
+
+	text, err := document.Bundle(op, g.docs...)
+	if err != nil {
+		return fmt.Errorf("%s %s: %v", op.Type, op.Name, err)
+	}
+	g.printf("// %sDocument is the %s %s document.\nconst %sDocument = %s\n\n", name, op.Name, op.Type, name, quote(text))
+	return nil
+}
+
+func (g *generator) lookupFragment(name string) (*document.Fragment, bool) {
+	for _, f := range g.fragments {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return nil, false
+}
+
+// variables declares the variables struct for op, a constructor that takes
+// the required variables, and a ToMap method.
+// Required variables are values, optional ones are pointers.
+func (g *generator) variables(name string, op *document.Operation) error {
+	type variable struct {
+		def    *document.VariableDefinition
+		goName string
+		goType string
+	}
+	var vars []variable
+	for _, v := range op.VariableDefinitions {
+		typ, err := g.inputType(v.Type)
+		if err != nil {
+			return fmt.Errorf("variable $%s: %v", v.Name, err)
+		}
+		if v.Type.NonNull && v.DefaultValue != nil {
+			// A variable with a default value may be omitted, even if its type is non-null.
+			typ = "*" + typ
+		}
+		vars = append(vars, variable{v, exportedName(v.Name), typ})
+	}
+	required := func(v variable) bool { return v.def.Type.NonNull && v.def.DefaultValue == nil }
+
+	typeName := name + "Variables"
+	g.printf("// %s are the variables of the %s %s.\ntype %s struct {\n", typeName, op.Name, op.Type, typeName)
+	for _, v := range vars {
+		tag := v.def.Name
+		if !required(v) {
+			tag += ",omitempty"
+		}
+		g.printf("%s %s `json:%s`\n", v.goName, v.goType, strconv.Quote(tag))
+	}
+	g.printf("}\n\n")
+
+	var params, inits []string
+	for _, v := range vars {
+		if required(v) {
+			param := paramName(v.def.Name)
+			params = append(params, param+" "+v.goType)
+			inits = append(inits, v.goName+": "+param)
+		}
+	}
+	g.printf("// New%s returns %s variables with all required variables set.\n", typeName, op.Name)
+	g.printf("func New%s(%s) %s {\nreturn %s{%s}\n}\n\n", typeName, strings.Join(params, ", "), typeName, typeName, strings.Join(inits, ", "))
+
+	g.printf("// ToMap returns v as a variables map, omitting optional variables that are nil.\n")
+	g.printf("func (v %s) ToMap() map[string]interface{} {\n", typeName)
+	g.printf("m := map[string]interface{}{\n")
+	for _, v := range vars {
+		if required(v) {
+			g.printf("%q: v.%s,\n", v.def.Name, v.goName)
+		}
+	}
+	g.printf("}\n")
+	for _, v := range vars {
+		if !required(v) {
+			g.printf("if v.%s != nil {\nm[%q] = v.%s\n}\n", v.goName, v.def.Name, v.goName)
+		}
+	}
+	g.printf("return m\n}\n\n")
+	return nil
+}
+
+// selectionSet returns a struct type literal for selections on parent.
+func (g *generator) selectionSet(parent *introspection.Type, selections []document.Selection) (string, error) {
+	var buf bytes.Buffer
+	buf.WriteString("struct {\n")
+	seen := map[string]bool{}
+	if err := g.fields(&buf, parent, selections, seen); err != nil {
+		return "", err
+	}
+	buf.WriteString("}")
+	return buf.String(), nil
+}
+
+func (g *generator) fields(buf *bytes.Buffer, parent *introspection.Type, selections []document.Selection, seen map[string]bool) error {
+	for _, s := range selections {
+		switch s := s.(type) {
+		case *document.Field:
+			goName := exportedName(s.ResponseKey())
+			if s.Name == "__typename" {
+				goName = "Typename"
+			}
+			if seen[goName] {
+				// Repeated selections of a field are merged by the server.
+				continue
+			}
+			seen[goName] = true
+			typ, err := g.fieldType(parent, s)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(buf, "%s %s", goName, typ)
+			if text := selectionText(s.Text); s.Name == "__typename" || text != ident.ParseMixedCaps(goName).ToLowerCamelCase() {
+				fmt.Fprintf(buf, " `graphql:%s`", strconv.Quote(text))
+			}
+			buf.WriteString("\n")
+		case *document.InlineFragment:
+			if s.TypeCondition == "" && len(s.Directives) == 0 {
+				if err := g.fields(buf, parent, s.SelectionSet, seen); err != nil {
+					return err
+				}
+				continue
+			}
+			on := parent
+			if s.TypeCondition != "" {
+				if on = g.cfg.Schema.Type(s.TypeCondition); on == nil {
+					return fmt.Errorf("unknown type %s", s.TypeCondition)
+				}
+			}
+			body, err := g.selectionSet(on, s.SelectionSet)
+			if err != nil {
+				return err
+			}
+			goName := exportedName(on.Name)
+			if seen[goName] {
+				return fmt.Errorf("%s: selected more than once", goName)
+			}
+			seen[goName] = true
+			fmt.Fprintf(buf, "%s %s `graphql:%s`\n", goName, body, strconv.Quote(selectionText(s.Text)))
+		case *document.FragmentSpread:
+			f, ok := g.lookupFragment(s.Name)
+			if !ok {
+				return fmt.Errorf("unknown fragment %s", s.Name)
+			}
+			// An embedded field without a graphql tag is inlined, so the
+			// fragment's fields are populated from the parent object.
+			fmt.Fprintf(buf, "%s\n", exportedName(f.Name))
+		}
+	}
+	return nil
+}
+
+// fieldType returns the Go type for field f of parent.
+func (g *generator) fieldType(parent *introspection.Type, f *document.Field) (string, error) {
+	if f.Name == "__typename" {
+		return g.graphqlType("String"), nil
+	}
+	def := parent.Field(f.Name)
+	if def == nil {
+		return "", fmt.Errorf("type %s has no field %s", parent.Name, f.Name)
+	}
+	return g.outputType(def.Type, f, false)
+}
+
+// outputType returns the Go type for ref, the type of field f.
+// The selection set of f is used for composite types.
+func (g *generator) outputType(ref introspection.TypeRef, f *document.Field, nonNull bool) (string, error) {
+	switch ref.Kind {
+	case "NON_NULL":
+		return g.outputType(*ref.OfType, f, true)
+	case "LIST":
+		elem, err := g.outputType(*ref.OfType, f, false)
+		return "[]" + elem, err
+	}
+	t := g.cfg.Schema.Type(ref.String())
+	if t == nil {
+		return "", fmt.Errorf("unknown type %s", ref)
+	}
+	var typ string
+	switch t.Kind {
+	case "OBJECT", "INTERFACE", "UNION":
+		if len(f.SelectionSet) == 0 {
+			return "", fmt.Errorf("field %s of type %s must have a selection", f.Name, t.Name)
+		}
+		body, err := g.selectionSet(t, f.SelectionSet)
+		if err != nil {
+			return "", err
+		}
+		typ = body
+	default:
+		if len(f.SelectionSet) != 0 {
+			return "", fmt.Errorf("field %s of type %s cannot have a selection", f.Name, t.Name)
+		}
+		var err error
+		if typ, err = g.namedType(t); err != nil {
+			return "", err
+		}
+	}
+	if !nonNull {
+		typ = "*" + typ
+	}
+	return typ, nil
+}
+
+// inputType returns the Go type for variable type t.
+// Required types are values, optional ones are pointers.
+func (g *generator) inputType(t *document.Type) (string, error) {
+	var typ string
+	if t.Elem != nil {
+		elem, err := g.inputType(t.Elem)
+		if err != nil {
+			return "", err
+		}
+		typ = "[]" + elem
+	} else {
+		st := g.cfg.Schema.Type(t.Name)
+		if st == nil {
+			return "", fmt.Errorf("unknown type %s", t.Name)
+		}
+		var err error
+		if typ, err = g.namedType(st); err != nil {
+			return "", err
+		}
+	}
+	if !t.NonNull {
+		typ = "*" + typ
+	}
+	return typ, nil
+}
+
+// inputTypeRef is like inputType, for types of input object fields.
+func (g *generator) inputTypeRef(ref introspection.TypeRef) (string, error) {
+	return g.inputType(toDocumentType(ref))
+}
+
+func toDocumentType(ref introspection.TypeRef) *document.Type {
+	switch ref.Kind {
+	case "NON_NULL":
+		t := toDocumentType(*ref.OfType)
+		t.NonNull = true
+		return t
+	case "LIST":
+		return &document.Type{Elem: toDocumentType(*ref.OfType)}
+	}
+	return &document.Type{Name: ref.String()}
+}
+
+// namedType returns the Go type for a scalar, enum or input object type,
+// arranging for it to be declared if needed.
+func (g *generator) namedType(t *introspection.Type) (string, error) {
+	switch t.Kind {
+	case "SCALAR":
+		if _, ok := builtinScalars[t.Name]; ok {
+			return g.graphqlType(t.Name), nil
+		}
+		if typ, ok := g.cfg.Scalars[t.Name]; ok {
+			return g.qualify(typ), nil
+		}
+	case "ENUM", "INPUT_OBJECT":
+	default:
+		return "", fmt.Errorf("unexpected %s type %s", t.Kind, t.Name)
+	}
+	name := exportedName(t.Name)
+	if !g.named[t.Name] {
+		g.named[t.Name] = true
+		g.pending = append(g.pending, t.Name)
+	}
+	return name, nil
+}
+
+// qualify returns a Go type expression for typ, written as
+// "[import/path.]Type", and records its import.
+func (g *generator) qualify(typ string) string {
+	i := strings.LastIndex(typ, ".")
+	if i == -1 {
+		return typ
+	}
+	path := typ[:i]
+	g.imports[path] = true
+	return path[strings.LastIndex(path, "/")+1:] + typ[i:]
+}
+
+// graphqlType returns the graphql package type for a built-in scalar,
+// and records the import of the graphql package.
+func (g *generator) graphqlType(name string) string {
+	g.imports["github.com/dbmedialab/go-graphql-client"] = true
+	return builtinScalars[name]
+}
+
+// declareNamed declares the pending enum, input object and scalar types.
+// Declaring an input object may add more pending types.
+func (g *generator) declareNamed() error {
+	for len(g.pending) > 0 {
+		tn := g.pending[0]
+		g.pending = g.pending[1:]
+		t := g.cfg.Schema.Type(tn)
+		name := exportedName(t.Name)
+		switch t.Kind {
+		case "SCALAR":
+			g.printf("// %s is the %s scalar.\ntype %s string\n\n", name, t.Name, name)
+		case "ENUM":
+			g.printf("// %s is the %s enum.\ntype %s string\n\n", name, t.Name, name)
+			g.printf("// Values of %s.\nconst (\n", name)
+			for _, v := range t.EnumValues {
+				g.printf("%s%s %s = %q\n", name, ident.ParseScreamingSnakeCase(v.Name).ToMixedCaps(), name, v.Name)
+			}
+			g.printf(")\n\n")
+		case "INPUT_OBJECT":
+			var buf bytes.Buffer
+			for _, f := range t.InputFields {
+				typ, err := g.inputTypeRef(f.Type)
+				if err != nil {
+					return fmt.Errorf("input %s: field %s: %v", t.Name, f.Name, err)
+				}
+				tag := f.Name
+				if f.Type.Kind != "NON_NULL" {
+					tag += ",omitempty"
+				}
+				fmt.Fprintf(&buf, "%s %s `json:%s`\n", exportedName(f.Name), typ, strconv.Quote(tag))
+			}
+			g.printf("// %s is the %s input type.\ntype %s struct {\n%s}\n\n", name, t.Name, name, buf.String())
+		}
+	}
+	return nil
+}
+
+func (g *generator) output() ([]byte, error) {
+	if err := g.declareNamed(); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by go-graphql-client generate. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n\n", g.cfg.Package)
+	if len(g.imports) > 0 {
+		// Standard library imports go first, in their own group.
+		var std, other []string
+		for p := range g.imports {
+			if strings.Contains(strings.SplitN(p, "/", 2)[0], ".") {
+				other = append(other, p)
+			} else {
+				std = append(std, p)
+			}
+		}
+		sort.Strings(std)
+		sort.Strings(other)
+		buf.WriteString("import (\n")
+		for _, p := range std {
+			fmt.Fprintf(&buf, "%q\n", p)
+		}
+		if len(std) > 0 && len(other) > 0 {
+			buf.WriteString("\n")
+		}
+		for _, p := range other {
+			fmt.Fprintf(&buf, "%q\n", p)
+		}
+		buf.WriteString(")\n\n")
+	}
+	buf.Write(g.decls.Bytes())
+	out, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %v", err)
+	}
+	return out, nil
+}
+
+// selectionText returns the minified source text of a field or inline
+// fragment, as used in a graphql struct field tag.
+func selectionText(src string) string {
+	toks, err := document.Tokenize(src)
+	if err != nil {
+		// src was already successfully tokenized as part of its document.
+		panic(err)
+	}
+	return document.Compact(toks)
+}
+
+// exportedName returns an exported Go identifier for GraphQL name.
+// E.g., "avatarUrl" -> "AvatarURL".
+func exportedName(name string) string {
+	return ident.ParseLowerCamelCase(strings.TrimLeft(name, "_")).ToMixedCaps()
+}
+
+// paramName returns an unexported Go identifier for GraphQL variable name.
+func paramName(name string) string {
+	p := ident.ParseLowerCamelCase(strings.TrimLeft(name, "_")).ToLowerCamelCase()
+	if token.Lookup(p).IsKeyword() {
+		p += "_"
+	}
+	return p
+}
+
+// quote returns s as a Go string literal, preferring a raw string.
+func quote(s string) string {
+	if strings.Contains(s, "`") {
+		return strconv.Quote(s)
+	}
+	return "`" + s + "`"
+}
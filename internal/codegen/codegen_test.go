@@ -0,0 +1,87 @@
+package codegen_test
+
+import (
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/dbmedialab/go-graphql-client/internal/codegen"
+	"github.com/dbmedialab/go-graphql-client/introspection"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func TestGenerate(t *testing.T) {
+	b, err := ioutil.ReadFile(filepath.Join("testdata", "schema.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema, err := introspection.Parse(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sources []codegen.Source
+	for _, name := range []string{"hero.graphql", "fragments.graphql"} {
+		b, err := ioutil.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		sources = append(sources, codegen.Source{Name: name, Text: string(b)})
+	}
+	got, err := codegen.Generate(codegen.Config{
+		Package: "starwars",
+		Schema:  schema,
+		Scalars: map[string]string{"Time": "time.Time"},
+	}, sources...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	golden := filepath.Join("testdata", "starwars.golden")
+	if *update {
+		if err := ioutil.WriteFile(golden, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := ioutil.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("generated code differs from %s:\n%s", golden, got)
+	}
+}
+
+func TestGenerate_errors(t *testing.T) {
+	b, err := ioutil.ReadFile(filepath.Join("testdata", "schema.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema, err := introspection.Parse(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"{ hero { name } }", "q.graphql: anonymous query: operations must be named"},
+		{"query Q { hero { mass } }", "q.graphql: query Q: type Character has no field mass"},
+		{"query Q { hero }", "q.graphql: query Q: field hero of type Character must have a selection"},
+		{"query Q { hero { name { first } } }", "q.graphql: query Q: field name of type String cannot have a selection"},
+		{"subscription S { hero { name } }", "q.graphql: subscription S: schema has no subscription type"},
+		{"query Q { hero { ...missing } }", "q.graphql: query Q: unknown fragment missing"},
+		{"query Q { hero(", "q.graphql: graphql: syntax error at 1:16: unexpected end of document, expected \")\""},
+	}
+	for _, tc := range tests {
+		_, err := codegen.Generate(codegen.Config{Package: "p", Schema: schema}, codegen.Source{Name: "q.graphql", Text: tc.in})
+		if err == nil {
+			t.Errorf("%q: got error: nil, want: %q", tc.in, tc.want)
+			continue
+		}
+		if err.Error() != tc.want {
+			t.Errorf("%q:\ngot error:  %q\nwant error: %q", tc.in, err, tc.want)
+		}
+	}
+}
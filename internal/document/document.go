@@ -0,0 +1,271 @@
+// Package document provides a lexer and parser for GraphQL executable
+// documents (operations and fragments).
+//
+// Specification: https://facebook.github.io/graphql/October2016/#sec-Language.
+package document
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Document is a parsed GraphQL executable document.
+type Document struct {
+	Operations []*Operation
+	Fragments  []*Fragment
+	// Source is the text the document was parsed from.
+	Source string
+}
+
+// Operation is an operation definition.
+type Operation struct {
+	// Type is "query", "mutation" or "subscription".
+	Type string
+	// Name is empty for anonymous operations.
+	Name                string
+	VariableDefinitions []*VariableDefinition
+	Directives          []*Directive
+	SelectionSet        []Selection
+	// Pos and End are the byte offsets of the definition in the source.
+	Pos, End int
+}
+
+// Fragment is a fragment definition.
+type Fragment struct {
+	Name          string
+	TypeCondition string
+	Directives    []*Directive
+	SelectionSet  []Selection
+	// Pos and End are the byte offsets of the definition in the source.
+	Pos, End int
+}
+
+// VariableDefinition declares a variable of an operation.
+type VariableDefinition struct {
+	Name         string
+	Type         *Type
+	DefaultValue *Value
+}
+
+// Type is a reference to a type, as written in a variable definition.
+type Type struct {
+	// Name is the named type, or empty if this is a list type.
+	Name string
+	// Elem is the element type of a list type.
+	Elem    *Type
+	NonNull bool
+}
+
+// String returns t in GraphQL notation. E.g., "[Int!]!".
+func (t *Type) String() string {
+	s := t.Name
+	if t.Elem != nil {
+		s = "[" + t.Elem.String() + "]"
+	}
+	if t.NonNull {
+		s += "!"
+	}
+	return s
+}
+
+// Selection is one of *Field, *FragmentSpread or *InlineFragment.
+type Selection interface {
+	isSelection()
+}
+
+// Field is a field selection.
+type Field struct {
+	Alias        string
+	Name         string
+	Arguments    []*Argument
+	Directives   []*Directive
+	SelectionSet []Selection
+	// Text is the source text of the field, not including its selection set.
+	// E.g., "avatar: avatarUrl(size: 72)".
+	Text string
+}
+
+// ResponseKey returns the key of f in the response: its alias, or its name.
+func (f *Field) ResponseKey() string {
+	if f.Alias != "" {
+		return f.Alias
+	}
+	return f.Name
+}
+
+// FragmentSpread is a named fragment spread. E.g., "...userFields".
+type FragmentSpread struct {
+	Name       string
+	Directives []*Directive
+}
+
+// InlineFragment is an inline fragment. E.g., "... on User{name}".
+type InlineFragment struct {
+	// TypeCondition is empty if the fragment has no type condition.
+	TypeCondition string
+	Directives    []*Directive
+	SelectionSet  []Selection
+	// Text is the source text of the fragment, not including its selection set.
+	// E.g., "... on User".
+	Text string
+}
+
+func (*Field) isSelection()          {}
+func (*FragmentSpread) isSelection() {}
+func (*InlineFragment) isSelection() {}
+
+// Argument is a field or directive argument.
+type Argument struct {
+	Name  string
+	Value *Value
+}
+
+// Directive is a directive. E.g., "@include(if:$withComments)".
+type Directive struct {
+	Name      string
+	Arguments []*Argument
+}
+
+// ValueKind is the kind of a Value.
+type ValueKind int
+
+// The kinds of values.
+const (
+	VariableValue ValueKind = iota
+	IntValue
+	FloatValue
+	StringValue
+	BooleanValue
+	NullValue
+	EnumValue
+	ListValue
+	ObjectValue
+)
+
+// Value is an input value.
+type Value struct {
+	Kind ValueKind
+	// Raw is the source text of the value. E.g., `"text"`, "$id" or "[1 2]".
+	Raw string
+	// Name is the variable name (without "$") of a VariableValue.
+	Name string
+	// List holds the elements of a ListValue.
+	List []*Value
+	// Fields holds the fields of an ObjectValue.
+	Fields []*Argument
+}
+
+// Variables returns the names of the variables that v refers to, in order
+// of appearance.
+func (v *Value) Variables() []string {
+	switch v.Kind {
+	case VariableValue:
+		return []string{v.Name}
+	case ListValue:
+		var names []string
+		for _, e := range v.List {
+			names = append(names, e.Variables()...)
+		}
+		return names
+	case ObjectValue:
+		var names []string
+		for _, f := range v.Fields {
+			names = append(names, f.Value.Variables()...)
+		}
+		return names
+	}
+	return nil
+}
+
+// Operation returns the named operation in d, or nil if there isn't one.
+// If name is empty and d has exactly one operation, that operation is returned.
+func (d *Document) Operation(name string) *Operation {
+	if name == "" && len(d.Operations) == 1 {
+		return d.Operations[0]
+	}
+	for _, op := range d.Operations {
+		if op.Name == name {
+			return op
+		}
+	}
+	return nil
+}
+
+// Fragment returns the named fragment in d, or nil if there isn't one.
+func (d *Document) Fragment(name string) *Fragment {
+	for _, f := range d.Fragments {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// Text returns the source text of op.
+func (d *Document) Text(op *Operation) string {
+	return strings.TrimSpace(d.Source[op.Pos:op.End])
+}
+
+// FragmentText returns the source text of f.
+func (d *Document) FragmentText(f *Fragment) string {
+	return strings.TrimSpace(d.Source[f.Pos:f.End])
+}
+
+// Bundle returns the source text of op followed by the definitions of the
+// fragments it uses, directly or through other fragments, in order of first
+// use. Fragments are looked up in docs.
+func Bundle(op *Operation, docs ...*Document) (string, error) {
+	var opDoc *Document
+	for _, d := range docs {
+		for _, o := range d.Operations {
+			if o == op {
+				opDoc = d
+			}
+		}
+	}
+	if opDoc == nil {
+		return "", fmt.Errorf("operation %s not found in documents", op.Name)
+	}
+	text := []string{opDoc.Text(op)}
+	seen := map[string]bool{}
+	var walk func([]Selection) error
+	walk = func(selections []Selection) error {
+		for _, s := range selections {
+			var err error
+			switch s := s.(type) {
+			case *Field:
+				err = walk(s.SelectionSet)
+			case *InlineFragment:
+				err = walk(s.SelectionSet)
+			case *FragmentSpread:
+				if seen[s.Name] {
+					continue
+				}
+				seen[s.Name] = true
+				f, d := lookupFragment(s.Name, docs)
+				if f == nil {
+					return fmt.Errorf("unknown fragment %s", s.Name)
+				}
+				text = append(text, d.FragmentText(f))
+				err = walk(f.SelectionSet)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(op.SelectionSet); err != nil {
+		return "", err
+	}
+	return strings.Join(text, "\n"), nil
+}
+
+func lookupFragment(name string, docs []*Document) (*Fragment, *Document) {
+	for _, d := range docs {
+		if f := d.Fragment(name); f != nil {
+			return f, d
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,336 @@
+package document
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Kind is the kind of a lexical token.
+type Kind int
+
+// The kinds of lexical tokens.
+const (
+	EOF         Kind = iota
+	Punctuator       // One of ! $ ( ) ... : = @ [ ] { | } &.
+	Name             // E.g., "hero".
+	Int              // E.g., "-12".
+	Float            // E.g., "1.5e3".
+	String           // E.g., `"text"`.
+	BlockString      // E.g., `"""text"""`.
+)
+
+// Token is a lexical token in a GraphQL document.
+type Token struct {
+	Kind Kind
+	// Value is the source text of the token.
+	Value string
+	// Pos is the byte offset of the token in the source.
+	Pos int
+}
+
+// Error is a syntax error in a GraphQL document.
+type Error struct {
+	Line, Column int
+	Message      string
+}
+
+// Error implements error interface.
+func (e *Error) Error() string {
+	return fmt.Sprintf("graphql: syntax error at %d:%d: %s", e.Line, e.Column, e.Message)
+}
+
+// Tokenize splits src into lexical tokens, dropping insignificant whitespace,
+// commas and comments. The final token is always of kind EOF.
+func Tokenize(src string) ([]Token, error) {
+	l := lexer{src: src}
+	var toks []Token
+	for {
+		tok, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+		toks = append(toks, tok)
+		if tok.Kind == EOF {
+			return toks, nil
+		}
+	}
+}
+
+type lexer struct {
+	src string
+	pos int
+}
+
+func (l *lexer) errorf(pos int, format string, a ...interface{}) error {
+	return errorAt(l.src, pos, fmt.Sprintf(format, a...))
+}
+
+func errorAt(src string, pos int, msg string) error {
+	line, col := 1, 1
+	for _, r := range src[:pos] {
+		if r == '\n' {
+			line, col = line+1, 1
+		} else {
+			col++
+		}
+	}
+	return &Error{Line: line, Column: col, Message: msg}
+}
+
+func (l *lexer) next() (Token, error) {
+	l.skipIgnored()
+	if l.pos >= len(l.src) {
+		return Token{Kind: EOF, Pos: l.pos}, nil
+	}
+	start := l.pos
+	c := l.src[l.pos]
+	switch {
+	case strings.IndexByte("!$()&:=@[]{|}", c) != -1:
+		l.pos++
+		return Token{Kind: Punctuator, Value: l.src[start:l.pos], Pos: start}, nil
+	case c == '.':
+		if strings.HasPrefix(l.src[l.pos:], "...") {
+			l.pos += 3
+			return Token{Kind: Punctuator, Value: "...", Pos: start}, nil
+		}
+		return Token{}, l.errorf(start, "unexpected %q", c)
+	case isNameStart(c):
+		for l.pos < len(l.src) && isNameContinue(l.src[l.pos]) {
+			l.pos++
+		}
+		return Token{Kind: Name, Value: l.src[start:l.pos], Pos: start}, nil
+	case c == '-' || isDigit(c):
+		return l.number()
+	case c == '"':
+		if strings.HasPrefix(l.src[l.pos:], `"""`) {
+			return l.blockString()
+		}
+		return l.string()
+	}
+	r, _ := utf8.DecodeRuneInString(l.src[l.pos:])
+	return Token{}, l.errorf(start, "unexpected character %q", r)
+}
+
+// skipIgnored skips whitespace, line terminators, commas, comments and the
+// byte order mark.
+func (l *lexer) skipIgnored() {
+	for l.pos < len(l.src) {
+		switch c := l.src[l.pos]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			l.pos++
+		case c == '#':
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' && l.src[l.pos] != '\r' {
+				l.pos++
+			}
+		case strings.HasPrefix(l.src[l.pos:], "\ufeff"):
+			l.pos += len("\ufeff")
+		default:
+			return
+		}
+	}
+}
+
+func (l *lexer) number() (Token, error) {
+	start := l.pos
+	kind := Int
+	if l.src[l.pos] == '-' {
+		l.pos++
+	}
+	if !l.digits() {
+		return Token{}, l.errorf(start, "invalid number")
+	}
+	if l.pos < len(l.src) && l.src[l.pos] == '.' {
+		kind = Float
+		l.pos++
+		if !l.digits() {
+			return Token{}, l.errorf(start, "invalid number")
+		}
+	}
+	if l.pos < len(l.src) && (l.src[l.pos] == 'e' || l.src[l.pos] == 'E') {
+		kind = Float
+		l.pos++
+		if l.pos < len(l.src) && (l.src[l.pos] == '+' || l.src[l.pos] == '-') {
+			l.pos++
+		}
+		if !l.digits() {
+			return Token{}, l.errorf(start, "invalid number")
+		}
+	}
+	if l.pos < len(l.src) && (isNameStart(l.src[l.pos]) || l.src[l.pos] == '.') {
+		return Token{}, l.errorf(start, "invalid number")
+	}
+	return Token{Kind: kind, Value: l.src[start:l.pos], Pos: start}, nil
+}
+
+// digits consumes a run of digits, reporting whether there was at least one.
+func (l *lexer) digits() bool {
+	start := l.pos
+	for l.pos < len(l.src) && isDigit(l.src[l.pos]) {
+		l.pos++
+	}
+	return l.pos > start
+}
+
+func (l *lexer) string() (Token, error) {
+	start := l.pos
+	l.pos++ // Opening quote.
+	for l.pos < len(l.src) {
+		switch l.src[l.pos] {
+		case '"':
+			l.pos++
+			return Token{Kind: String, Value: l.src[start:l.pos], Pos: start}, nil
+		case '\\':
+			l.pos += 2
+		case '\n', '\r':
+			return Token{}, l.errorf(start, "unterminated string")
+		default:
+			l.pos++
+		}
+	}
+	return Token{}, l.errorf(start, "unterminated string")
+}
+
+func (l *lexer) blockString() (Token, error) {
+	start := l.pos
+	l.pos += 3 // Opening quotes.
+	for l.pos < len(l.src) {
+		switch {
+		case strings.HasPrefix(l.src[l.pos:], `\"""`):
+			l.pos += 4
+		case strings.HasPrefix(l.src[l.pos:], `"""`):
+			l.pos += 3
+			return Token{Kind: BlockString, Value: l.src[start:l.pos], Pos: start}, nil
+		default:
+			l.pos++
+		}
+	}
+	return Token{}, l.errorf(start, "unterminated block string")
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func isNameContinue(c byte) bool { return isNameStart(c) || isDigit(c) }
+
+func isDigit(c byte) bool { return '0' <= c && c <= '9' }
+
+// Compact joins toks into minified source text, separating adjacent tokens
+// with a space only where they would otherwise run together.
+func Compact(toks []Token) string {
+	var buf []byte
+	for i, tok := range toks {
+		if tok.Kind == EOF {
+			break
+		}
+		if i > 0 && needsSpace(toks[i-1], tok) {
+			buf = append(buf, ' ')
+		}
+		buf = append(buf, tok.Value...)
+	}
+	return string(buf)
+}
+
+// needsSpace reports whether a and b must be separated to be read back as
+// the same two tokens.
+func needsSpace(a, b Token) bool {
+	word := func(t Token) bool { return t.Kind == Name || t.Kind == Int || t.Kind == Float }
+	if word(a) && word(b) {
+		return true
+	}
+	// Keep "... on T" readable, as it's commonly written.
+	if a.Kind == Punctuator && a.Value == "..." && b.Kind == Name && b.Value == "on" {
+		return true
+	}
+	// A number followed by "..." would be misread as a malformed float.
+	return (a.Kind == Int || a.Kind == Float) && b.Kind == Punctuator && b.Value == "..."
+}
+
+// Indent joins toks into human-readable source text: each selection on its
+// own line, indented by one indent per enclosing selection set, arguments
+// and variable definitions separated by ", ", and definitions separated by
+// a blank line.
+func Indent(toks []Token, indent string) string {
+	var buf []byte
+	depth := 0 // Of selection sets.
+	nest := 0  // Of parentheses, lists and object values.
+	newline := func() {
+		buf = append(buf, '\n')
+		for i := 0; i < depth; i++ {
+			buf = append(buf, indent...)
+		}
+	}
+	is := func(t Token, values ...string) bool {
+		for _, v := range values {
+			if t.Kind == Punctuator && t.Value == v {
+				return true
+			}
+		}
+		return false
+	}
+	for i, tok := range toks {
+		if tok.Kind == EOF {
+			break
+		}
+		var prev, prevprev Token
+		if i > 0 {
+			prev = toks[i-1]
+		}
+		if i > 1 {
+			prevprev = toks[i-2]
+		}
+
+		if nest > 0 {
+			switch {
+			case is(prev, "(", "[", "{", "$") || is(tok, ")", "]", "}", ":", "!"):
+			case is(prev, ":", "="):
+				buf = append(buf, ' ')
+			case is(tok, "=", "@"):
+				buf = append(buf, ' ')
+			default:
+				buf = append(buf, ", "...)
+			}
+		} else {
+			switch {
+			case i == 0:
+			case is(tok, "{"):
+				buf = append(buf, ' ')
+			case depth == 0 && is(prev, "}"):
+				buf = append(buf, "\n\n"...)
+			case depth == 0:
+				if needsSpace(prev, tok) {
+					buf = append(buf, ' ')
+				}
+			case is(prev, "{"), is(tok, "}", ":", "("):
+			case is(tok, "@"), is(prev, ":"):
+				buf = append(buf, ' ')
+			case is(prev, "..."):
+				if tok.Value == "on" {
+					buf = append(buf, ' ')
+				}
+			case prev.Kind == Name && prev.Value == "on" && is(prevprev, "..."):
+				buf = append(buf, ' ')
+			case prev.Kind == Name || is(prev, ")", "}"):
+				newline()
+			}
+		}
+
+		switch {
+		case is(tok, "(", "["), nest > 0 && is(tok, "{"):
+			nest++
+		case is(tok, ")", "]"), nest > 0 && is(tok, "}"):
+			nest--
+		case is(tok, "{"):
+			depth++
+			buf = append(buf, tok.Value...)
+			newline()
+			continue
+		case is(tok, "}"):
+			depth--
+			newline()
+		}
+		buf = append(buf, tok.Value...)
+	}
+	return string(buf)
+}
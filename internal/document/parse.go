@@ -0,0 +1,297 @@
+package document
+
+import "fmt"
+
+// Parse parses src as a GraphQL executable document.
+func Parse(src string) (*Document, error) {
+	toks, err := Tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+	p := parser{src: src, toks: toks}
+	return p.document()
+}
+
+type parser struct {
+	src  string
+	toks []Token
+	i    int
+}
+
+// bailout is used to unwind the parser on the first syntax error.
+type bailout struct{ err error }
+
+func (p *parser) document() (doc *Document, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			b, ok := r.(bailout)
+			if !ok {
+				panic(r)
+			}
+			doc, err = nil, b.err
+		}
+	}()
+	doc = &Document{Source: p.src}
+	if p.peek().Kind == EOF {
+		p.errorf("empty document")
+	}
+	for p.peek().Kind != EOF {
+		switch tok := p.peek(); {
+		case tok.Kind == Punctuator && tok.Value == "{":
+			start := tok.Pos
+			op := &Operation{Type: "query", Pos: start}
+			op.SelectionSet = p.selectionSet()
+			op.End = p.end()
+			doc.Operations = append(doc.Operations, op)
+		case tok.Kind == Name && (tok.Value == "query" || tok.Value == "mutation" || tok.Value == "subscription"):
+			doc.Operations = append(doc.Operations, p.operation())
+		case tok.Kind == Name && tok.Value == "fragment":
+			doc.Fragments = append(doc.Fragments, p.fragment())
+		default:
+			p.errorf("unexpected %q, expected operation or fragment definition", tok.Value)
+		}
+	}
+	return doc, nil
+}
+
+func (p *parser) operation() *Operation {
+	tok := p.advance()
+	op := &Operation{Type: tok.Value, Pos: tok.Pos}
+	if p.peek().Kind == Name {
+		op.Name = p.advance().Value
+	}
+	if p.skip("(") {
+		for !p.skip(")") {
+			op.VariableDefinitions = append(op.VariableDefinitions, p.variableDefinition())
+		}
+	}
+	op.Directives = p.directives()
+	op.SelectionSet = p.selectionSet()
+	op.End = p.end()
+	return op
+}
+
+func (p *parser) fragment() *Fragment {
+	tok := p.advance() // "fragment".
+	f := &Fragment{Pos: tok.Pos}
+	f.Name = p.name()
+	if f.Name == "on" {
+		p.errorf("fragment cannot be named \"on\"")
+	}
+	p.expectName("on")
+	f.TypeCondition = p.name()
+	f.Directives = p.directives()
+	f.SelectionSet = p.selectionSet()
+	f.End = p.end()
+	return f
+}
+
+func (p *parser) variableDefinition() *VariableDefinition {
+	p.expect("$")
+	v := &VariableDefinition{Name: p.name()}
+	p.expect(":")
+	v.Type = p.typ()
+	if p.skip("=") {
+		v.DefaultValue = p.value(true)
+	}
+	p.directives()
+	return v
+}
+
+func (p *parser) typ() *Type {
+	var t *Type
+	if p.skip("[") {
+		t = &Type{Elem: p.typ()}
+		p.expect("]")
+	} else {
+		t = &Type{Name: p.name()}
+	}
+	t.NonNull = p.skip("!")
+	return t
+}
+
+func (p *parser) selectionSet() []Selection {
+	p.expect("{")
+	if tok := p.peek(); tok.Kind == Punctuator && tok.Value == "}" {
+		p.errorf("empty selection set")
+	}
+	var selections []Selection
+	for !p.skip("}") {
+		selections = append(selections, p.selection())
+	}
+	return selections
+}
+
+func (p *parser) selection() Selection {
+	if tok := p.peek(); tok.Kind == Punctuator && tok.Value == "..." {
+		p.advance()
+		if next := p.peek(); next.Kind == Name && next.Value != "on" {
+			return &FragmentSpread{Name: p.name(), Directives: p.directives()}
+		}
+		f := &InlineFragment{}
+		if p.skipName("on") {
+			f.TypeCondition = p.name()
+		}
+		f.Directives = p.directives()
+		f.Text = p.src[tok.Pos:p.end()]
+		f.SelectionSet = p.selectionSet()
+		return f
+	}
+	return p.field()
+}
+
+func (p *parser) field() *Field {
+	start := p.peek().Pos
+	f := &Field{Name: p.name()}
+	if p.skip(":") {
+		f.Alias, f.Name = f.Name, p.name()
+	}
+	f.Arguments = p.arguments(false)
+	f.Directives = p.directives()
+	f.Text = p.src[start:p.end()]
+	if tok := p.peek(); tok.Kind == Punctuator && tok.Value == "{" {
+		f.SelectionSet = p.selectionSet()
+	}
+	return f
+}
+
+func (p *parser) arguments(constant bool) []*Argument {
+	if !p.skip("(") {
+		return nil
+	}
+	if tok := p.peek(); tok.Kind == Punctuator && tok.Value == ")" {
+		p.errorf("empty argument list")
+	}
+	var args []*Argument
+	for !p.skip(")") {
+		a := &Argument{Name: p.name()}
+		p.expect(":")
+		a.Value = p.value(constant)
+		args = append(args, a)
+	}
+	return args
+}
+
+func (p *parser) directives() []*Directive {
+	var ds []*Directive
+	for p.skip("@") {
+		ds = append(ds, &Directive{Name: p.name(), Arguments: p.arguments(false)})
+	}
+	return ds
+}
+
+func (p *parser) value(constant bool) *Value {
+	tok := p.peek()
+	start := tok.Pos
+	v := &Value{}
+	switch {
+	case tok.Kind == Punctuator && tok.Value == "$":
+		if constant {
+			p.errorf("unexpected variable in constant value")
+		}
+		p.advance()
+		v.Kind, v.Name = VariableValue, p.name()
+	case tok.Kind == Punctuator && tok.Value == "[":
+		p.advance()
+		v.Kind = ListValue
+		for !p.skip("]") {
+			v.List = append(v.List, p.value(constant))
+		}
+	case tok.Kind == Punctuator && tok.Value == "{":
+		p.advance()
+		v.Kind = ObjectValue
+		for !p.skip("}") {
+			f := &Argument{Name: p.name()}
+			p.expect(":")
+			f.Value = p.value(constant)
+			v.Fields = append(v.Fields, f)
+		}
+	case tok.Kind == Int:
+		p.advance()
+		v.Kind = IntValue
+	case tok.Kind == Float:
+		p.advance()
+		v.Kind = FloatValue
+	case tok.Kind == String || tok.Kind == BlockString:
+		p.advance()
+		v.Kind = StringValue
+	case tok.Kind == Name:
+		p.advance()
+		switch tok.Value {
+		case "true", "false":
+			v.Kind = BooleanValue
+		case "null":
+			v.Kind = NullValue
+		default:
+			v.Kind, v.Name = EnumValue, tok.Value
+		}
+	default:
+		p.errorf("unexpected %q, expected value", tok.Value)
+	}
+	v.Raw = p.src[start:p.end()]
+	return v
+}
+
+func (p *parser) peek() Token { return p.toks[p.i] }
+
+func (p *parser) advance() Token {
+	tok := p.toks[p.i]
+	if tok.Kind != EOF {
+		p.i++
+	}
+	return tok
+}
+
+// end returns the byte offset just past the last consumed token.
+func (p *parser) end() int {
+	tok := p.toks[p.i-1]
+	return tok.Pos + len(tok.Value)
+}
+
+// skip consumes the next token if it's the punctuator s, reporting whether it did.
+func (p *parser) skip(s string) bool {
+	if tok := p.peek(); tok.Kind == Punctuator && tok.Value == s {
+		p.advance()
+		return true
+	}
+	if p.peek().Kind == EOF && (s == "}" || s == ")" || s == "]") {
+		p.errorf("unexpected end of document, expected %q", s)
+	}
+	return false
+}
+
+// skipName consumes the next token if it's the name s, reporting whether it did.
+func (p *parser) skipName(s string) bool {
+	if tok := p.peek(); tok.Kind == Name && tok.Value == s {
+		p.advance()
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(s string) {
+	if !p.skip(s) {
+		p.errorf("unexpected %q, expected %q", p.peek().Value, s)
+	}
+}
+
+func (p *parser) expectName(s string) {
+	if !p.skipName(s) {
+		p.errorf("unexpected %q, expected %q", p.peek().Value, s)
+	}
+}
+
+func (p *parser) name() string {
+	tok := p.peek()
+	if tok.Kind != Name {
+		if tok.Kind == EOF {
+			p.errorf("unexpected end of document, expected name")
+		}
+		p.errorf("unexpected %q, expected name", tok.Value)
+	}
+	return p.advance().Value
+}
+
+func (p *parser) errorf(format string, a ...interface{}) {
+	panic(bailout{errorAt(p.src, p.peek().Pos, fmt.Sprintf(format, a...))})
+}
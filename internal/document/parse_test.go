@@ -0,0 +1,122 @@
+package document
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	doc, err := Parse(`
+		# Fetch a hero.
+		query Hero($episode: Episode = JEDI, $ids: [ID!]!) @cached {
+			hero(episode: $episode, filter: {ids: $ids, kind: "x"}) {
+				name
+				friend: bestFriend { name }
+				... on Droid @include(if: true) { primaryFunction }
+				...heroFields
+			}
+		}
+		fragment heroFields on Character { id }
+		{ viewer { login } }
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(doc.Operations), 2; got != want {
+		t.Fatalf("got %v operations, want %v", got, want)
+	}
+	op := doc.Operation("Hero")
+	if op == nil {
+		t.Fatal("operation Hero not found")
+	}
+	if got, want := op.VariableDefinitions[0].DefaultValue.Raw, "JEDI"; got != want {
+		t.Errorf("got default value %q, want %q", got, want)
+	}
+	if got, want := op.VariableDefinitions[1].Type.String(), "[ID!]!"; got != want {
+		t.Errorf("got variable type %q, want %q", got, want)
+	}
+	hero := op.SelectionSet[0].(*Field)
+	if got, want := hero.Text, `hero(episode: $episode, filter: {ids: $ids, kind: "x"})`; got != want {
+		t.Errorf("got field text %q, want %q", got, want)
+	}
+	var vars []string
+	for _, a := range hero.Arguments {
+		vars = append(vars, a.Value.Variables()...)
+	}
+	if want := []string{"episode", "ids"}; !reflect.DeepEqual(vars, want) {
+		t.Errorf("got variables %q, want %q", vars, want)
+	}
+	if got, want := hero.SelectionSet[1].(*Field).ResponseKey(), "friend"; got != want {
+		t.Errorf("got response key %q, want %q", got, want)
+	}
+	if got, want := hero.SelectionSet[2].(*InlineFragment).Text, "... on Droid @include(if: true)"; got != want {
+		t.Errorf("got inline fragment text %q, want %q", got, want)
+	}
+	if got, want := hero.SelectionSet[3].(*FragmentSpread).Name, "heroFields"; got != want {
+		t.Errorf("got fragment spread %q, want %q", got, want)
+	}
+	if got, want := doc.FragmentText(doc.Fragment("heroFields")), "fragment heroFields on Character { id }"; got != want {
+		t.Errorf("got fragment text %q, want %q", got, want)
+	}
+	if got, want := doc.Operations[1].Type, "query"; got != want {
+		t.Errorf("got shorthand operation type %q, want %q", got, want)
+	}
+}
+
+func TestParse_errors(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"", "graphql: syntax error at 1:1: empty document"},
+		{"{}", "graphql: syntax error at 1:2: empty selection set"},
+		{"{\n  hero(id: ) }", `graphql: syntax error at 2:12: unexpected ")", expected value`},
+		{"query { hero", `graphql: syntax error at 1:13: unexpected end of document, expected "}"`},
+		{`{ hero(name: "x) }`, "graphql: syntax error at 1:14: unterminated string"},
+		{"{ a } type T { a: Int }", `graphql: syntax error at 1:7: unexpected "type", expected operation or fragment definition`},
+	}
+	for _, tc := range tests {
+		_, err := Parse(tc.in)
+		if err == nil {
+			t.Errorf("%q: got error: nil, want: %q", tc.in, tc.want)
+			continue
+		}
+		if err.Error() != tc.want {
+			t.Errorf("%q:\ngot error:  %q\nwant error: %q", tc.in, err, tc.want)
+		}
+	}
+}
+
+func TestCompact(t *testing.T) {
+	toks, err := Tokenize("query Q($a: Int = 1) {\n  f(x: $a, y: [1, 2]) # comment\n  ... on T { g }\n}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := Compact(toks), "query Q($a:Int=1){f(x:$a y:[1 2])... on T{g}}"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestIndent(t *testing.T) {
+	toks, err := Tokenize(`query Q($a:Int=1$b:[ID!]!){f(x:$a y:[1 2] z:{k:"v"}) @include(if:true){g,a:h}... on T{i}...F}fragment F on T{j}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `query Q($a: Int = 1, $b: [ID!]!) {
+  f(x: $a, y: [1, 2], z: {k: "v"}) @include(if: true) {
+    g
+    a: h
+  }
+  ... on T {
+    i
+  }
+  ...F
+}
+
+fragment F on T {
+  j
+}`
+	if got := Indent(toks, "  "); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
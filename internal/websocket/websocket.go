@@ -0,0 +1,406 @@
+// Package websocket implements as much of the WebSocket protocol as GraphQL
+// subscriptions need: the opening handshake, text and binary messages, and
+// the ping, pong and close control frames. Both ends are implemented, the
+// server end so that transports can be tested against it.
+//
+// Specification: https://tools.ietf.org/html/rfc6455.
+package websocket
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MaxMessageSize is the size of the largest message a Conn reads.
+const MaxMessageSize = 32 << 20
+
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// Opcodes of frames.
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xa
+)
+
+// CloseNormal is the status code of a normal closure.
+const CloseNormal = 1000
+
+// CloseError is the error reading from a connection the other end closed.
+type CloseError struct {
+	// Code is the status code of the closure, or 1005 if none was given.
+	Code int
+	Text string
+}
+
+func (e *CloseError) Error() string {
+	if e.Text == "" {
+		return fmt.Sprintf("websocket: closed with status %d", e.Code)
+	}
+	return fmt.Sprintf("websocket: closed with status %d: %s", e.Code, e.Text)
+}
+
+// Conn is a WebSocket connection. Reads must be made by one goroutine at a
+// time; writes and Close may be made by any.
+type Conn struct {
+	conn     net.Conn
+	br       *bufio.Reader
+	server   bool
+	protocol string
+
+	mu     sync.Mutex // Of writes.
+	closed bool
+}
+
+// Dial opens a WebSocket connection to rawurl, a ws or wss URL (http and
+// https are taken to mean the same), sending header with the handshake and
+// asking for one of protocols as the sub-protocol.
+func Dial(ctx context.Context, rawurl string, header http.Header, protocols []string, tlsConfig *tls.Config) (*Conn, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	secure := false
+	switch u.Scheme {
+	case "ws", "http":
+		u.Scheme = "http"
+	case "wss", "https":
+		u.Scheme, secure = "https", true
+	default:
+		return nil, fmt.Errorf("websocket: unsupported URL scheme %q", u.Scheme)
+	}
+	addr := u.Host
+	if u.Port() == "" {
+		if secure {
+			addr = net.JoinHostPort(u.Hostname(), "443")
+		} else {
+			addr = net.JoinHostPort(u.Hostname(), "80")
+		}
+	}
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	// The handshake is done by now, or not at all.
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	if secure {
+		config := &tls.Config{}
+		if tlsConfig != nil {
+			config = tlsConfig.Clone()
+		}
+		if config.ServerName == "" {
+			config.ServerName = u.Hostname()
+		}
+		tc := tls.Client(conn, config)
+		if err := tc.Handshake(); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		conn = tc
+	}
+	c, err := handshake(conn, u, header, protocols)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	conn.SetDeadline(time.Time{})
+	return c, nil
+}
+
+func handshake(conn net.Conn, u *url.URL, header http.Header, protocols []string) (*Conn, error) {
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	key := make([]byte, 16)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, err
+	}
+	nonce := base64.StdEncoding.EncodeToString(key)
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Key", nonce)
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	if len(protocols) > 0 {
+		req.Header.Set("Sec-WebSocket-Protocol", strings.Join(protocols, ", "))
+	}
+	if err := req.Write(conn); err != nil {
+		return nil, err
+	}
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		resp.Body.Close()
+		return nil, &HandshakeError{StatusCode: resp.StatusCode, Status: resp.Status}
+	}
+	if !strings.EqualFold(resp.Header.Get("Upgrade"), "websocket") || resp.Header.Get("Sec-WebSocket-Accept") != accept(nonce) {
+		return nil, fmt.Errorf("websocket: server didn't accept the handshake")
+	}
+	protocol := resp.Header.Get("Sec-WebSocket-Protocol")
+	if protocol != "" && !contains(protocols, protocol) {
+		return nil, fmt.Errorf("websocket: server chose sub-protocol %q, which wasn't asked for", protocol)
+	}
+	return &Conn{conn: conn, br: br, protocol: protocol}, nil
+}
+
+// HandshakeError is the error dialing a server that responded to the
+// handshake with something other than a switch to WebSockets.
+type HandshakeError struct {
+	StatusCode int
+	Status     string // Such as "401 Unauthorized".
+}
+
+func (e *HandshakeError) Error() string {
+	return "websocket: bad handshake: " + e.Status
+}
+
+// Upgrade answers the WebSocket handshake r, choosing the first of the
+// sub-protocols the client asks for that's one of protocols, and returns
+// the server end of the connection.
+func Upgrade(w http.ResponseWriter, r *http.Request, protocols []string) (*Conn, error) {
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if r.Method != "GET" || !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") || key == "" {
+		http.Error(w, "not a WebSocket handshake", http.StatusBadRequest)
+		return nil, fmt.Errorf("websocket: not a WebSocket handshake")
+	}
+	var protocol string
+	for _, p := range strings.Split(r.Header.Get("Sec-WebSocket-Protocol"), ",") {
+		if p = strings.TrimSpace(p); contains(protocols, p) {
+			protocol = p
+			break
+		}
+	}
+	h, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "can't upgrade the connection", http.StatusInternalServerError)
+		return nil, fmt.Errorf("websocket: %T isn't an http.Hijacker", w)
+	}
+	conn, brw, err := h.Hijack()
+	if err != nil {
+		return nil, err
+	}
+	response := "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: " + accept(key) + "\r\n"
+	if protocol != "" {
+		response += "Sec-WebSocket-Protocol: " + protocol + "\r\n"
+	}
+	if _, err := io.WriteString(conn, response+"\r\n"); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &Conn{conn: conn, br: brw.Reader, server: true, protocol: protocol}, nil
+}
+
+// accept returns the Sec-WebSocket-Accept value for the key nonce.
+func accept(nonce string) string {
+	h := sha1.New()
+	io.WriteString(h, nonce+acceptGUID)
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Protocol returns the sub-protocol the server chose, or "" if it chose
+// none.
+func (c *Conn) Protocol() string {
+	return c.protocol
+}
+
+// ReadMessage returns the next text or binary message, joining its
+// fragments. Pings are answered as they're read. If the other end closes
+// the connection, the closure is acknowledged, and a *CloseError returned.
+func (c *Conn) ReadMessage() ([]byte, error) {
+	var msg []byte
+	reading := false // A fragmented message.
+	for {
+		fin, op, payload, err := c.readFrame()
+		if err != nil {
+			return nil, err
+		}
+		switch op {
+		case opPing:
+			if err := c.writeFrame(opPong, payload); err != nil {
+				return nil, err
+			}
+			continue
+		case opPong:
+			continue
+		case opClose:
+			e := &CloseError{Code: 1005}
+			if len(payload) >= 2 {
+				e.Code = int(binary.BigEndian.Uint16(payload))
+				e.Text = string(payload[2:])
+				payload = payload[:2]
+			}
+			c.writeFrame(opClose, payload)
+			c.conn.Close()
+			return nil, e
+		case opText, opBinary:
+			if reading {
+				return nil, fmt.Errorf("websocket: message begun before the last one ended")
+			}
+			msg = payload
+		case opContinuation:
+			if !reading {
+				return nil, fmt.Errorf("websocket: continuation frame without a message")
+			}
+			if len(msg)+len(payload) > MaxMessageSize {
+				return nil, fmt.Errorf("websocket: message larger than %d bytes", MaxMessageSize)
+			}
+			msg = append(msg, payload...)
+		default:
+			return nil, fmt.Errorf("websocket: unknown opcode %#x", op)
+		}
+		if fin {
+			return msg, nil
+		}
+		reading = true
+	}
+}
+
+func (c *Conn) readFrame() (fin bool, op byte, payload []byte, err error) {
+	var h [8]byte
+	if _, err := io.ReadFull(c.br, h[:2]); err != nil {
+		return false, 0, nil, err
+	}
+	fin, op = h[0]&0x80 != 0, h[0]&0x0f
+	if h[0]&0x70 != 0 {
+		return false, 0, nil, fmt.Errorf("websocket: reserved bits set")
+	}
+	masked := h[1]&0x80 != 0
+	if masked != c.server {
+		// Clients mask their frames, and servers don't.
+		return false, 0, nil, fmt.Errorf("websocket: frame masking is wrong for the direction")
+	}
+	n := uint64(h[1] & 0x7f)
+	switch n {
+	case 126:
+		if _, err := io.ReadFull(c.br, h[:2]); err != nil {
+			return false, 0, nil, err
+		}
+		n = uint64(binary.BigEndian.Uint16(h[:2]))
+	case 127:
+		if _, err := io.ReadFull(c.br, h[:8]); err != nil {
+			return false, 0, nil, err
+		}
+		n = binary.BigEndian.Uint64(h[:8])
+	}
+	if n > MaxMessageSize {
+		return false, 0, nil, fmt.Errorf("websocket: message larger than %d bytes", MaxMessageSize)
+	}
+	var key [4]byte
+	if masked {
+		if _, err := io.ReadFull(c.br, key[:]); err != nil {
+			return false, 0, nil, err
+		}
+	}
+	payload = make([]byte, n)
+	if _, err := io.ReadFull(c.br, payload); err != nil {
+		return false, 0, nil, err
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= key[i%4]
+		}
+	}
+	return fin, op, payload, nil
+}
+
+// WriteMessage writes p as a text message.
+func (c *Conn) WriteMessage(p []byte) error {
+	return c.writeFrame(opText, p)
+}
+
+// Ping sends a ping, which the other end answers with a pong that
+// ReadMessage skips.
+func (c *Conn) Ping() error {
+	return c.writeFrame(opPing, nil)
+}
+
+func (c *Conn) writeFrame(op byte, payload []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return fmt.Errorf("websocket: write to closed connection")
+	}
+	frame := make([]byte, 0, 14+len(payload))
+	frame = append(frame, 0x80|op)
+	var mask byte
+	if !c.server {
+		mask = 0x80
+	}
+	switch n := len(payload); {
+	case n < 126:
+		frame = append(frame, mask|byte(n))
+	case n <= 0xffff:
+		frame = append(frame, mask|126, byte(n>>8), byte(n))
+	default:
+		frame = append(frame, mask|127)
+		var b [8]byte
+		binary.BigEndian.PutUint64(b[:], uint64(n))
+		frame = append(frame, b[:]...)
+	}
+	if c.server {
+		frame = append(frame, payload...)
+	} else {
+		var key [4]byte
+		if _, err := io.ReadFull(rand.Reader, key[:]); err != nil {
+			return err
+		}
+		frame = append(frame, key[:]...)
+		for i, b := range payload {
+			frame = append(frame, b^key[i%4])
+		}
+	}
+	if op == opClose {
+		c.closed = true
+	}
+	_, err := c.conn.Write(frame)
+	return err
+}
+
+// Close sends a normal closure and closes the connection, without waiting
+// for the other end to acknowledge it.
+func (c *Conn) Close() error {
+	return c.CloseWithStatus(CloseNormal, "")
+}
+
+// CloseWithStatus is like Close, but closes the connection with the status
+// code and text given.
+func (c *Conn) CloseWithStatus(code int, text string) error {
+	payload := make([]byte, 2, 2+len(text))
+	binary.BigEndian.PutUint16(payload, uint16(code))
+	c.writeFrame(opClose, append(payload, text...))
+	return c.conn.Close()
+}
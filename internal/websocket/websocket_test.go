@@ -0,0 +1,75 @@
+package websocket
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestConn(t *testing.T) {
+	closed := make(chan error, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Upgrade(w, r, []string{"echo"})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		// Ping before every echo; the client answers them as it reads.
+		for {
+			msg, err := conn.ReadMessage()
+			if err != nil {
+				closed <- err
+				return
+			}
+			if err := conn.Ping(); err != nil {
+				t.Error(err)
+			}
+			if err := conn.WriteMessage(msg); err != nil {
+				t.Error(err)
+			}
+		}
+	}))
+	defer server.Close()
+
+	conn, err := Dial(context.Background(), "ws"+strings.TrimPrefix(server.URL, "http"), nil, []string{"other", "echo"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := conn.Protocol(), "echo"; got != want {
+		t.Errorf("got protocol %q, want %q", got, want)
+	}
+	// Sizes with each of the three payload length encodings.
+	for _, n := range []int{5, 200, 70000} {
+		msg := bytes.Repeat([]byte("x"), n)
+		if err := conn.WriteMessage(msg); err != nil {
+			t.Fatal(err)
+		}
+		got, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, msg) {
+			t.Errorf("got a message of %d bytes echoed as %d bytes", n, len(got))
+		}
+	}
+	conn.Close()
+	err = <-closed
+	if e, ok := err.(*CloseError); !ok || e.Code != CloseNormal {
+		t.Errorf("got error %v, want a normal closure", err)
+	}
+}
+
+func TestDial_handshakeError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	_, err := Dial(context.Background(), server.URL, nil, nil, nil)
+	if got, want := err, "websocket: bad handshake: 401 Unauthorized"; got == nil || got.Error() != want {
+		t.Errorf("got error: %v, want: %v", got, want)
+	}
+}
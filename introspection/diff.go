@@ -0,0 +1,272 @@
+package introspection
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Change describes a single difference between two schemas.
+type Change struct {
+	// Path locates the change. E.g., "Query.user(id:)" or "Episode.JEDI".
+	Path string
+	// Message is a human-readable description of the change.
+	Message string
+	// Breaking reports whether existing client operations may stop working.
+	Breaking bool
+}
+
+// String implements fmt.Stringer.
+func (c Change) String() string {
+	if c.Breaking {
+		return "BREAKING " + c.Path + ": " + c.Message
+	}
+	return "         " + c.Path + ": " + c.Message
+}
+
+// Diff reports the changes going from schema old to schema new, sorted by path.
+//
+// A change is breaking if an operation that was valid against old might be
+// invalid against new, or might receive data it does not expect.
+// Introspection types (those with names starting with "__") are not compared.
+func Diff(old, new *Schema) []Change {
+	var d differ
+	for _, ot := range old.Types {
+		if isIntrospectionType(ot.Name) {
+			continue
+		}
+		nt := new.Type(ot.Name)
+		if nt == nil {
+			d.add(ot.Name, true, "type removed")
+			continue
+		}
+		d.diffType(ot, *nt)
+	}
+	for _, nt := range new.Types {
+		if isIntrospectionType(nt.Name) {
+			continue
+		}
+		if old.Type(nt.Name) == nil {
+			d.add(nt.Name, false, "type added")
+		}
+	}
+	d.diffRoot("query", old.QueryType, new.QueryType)
+	d.diffRoot("mutation", old.MutationType, new.MutationType)
+	d.diffRoot("subscription", old.SubscriptionType, new.SubscriptionType)
+
+	sort.SliceStable(d.changes, func(i, j int) bool { return d.changes[i].Path < d.changes[j].Path })
+	return d.changes
+}
+
+// HasBreaking reports whether any of changes is breaking.
+func HasBreaking(changes []Change) bool {
+	for _, c := range changes {
+		if c.Breaking {
+			return true
+		}
+	}
+	return false
+}
+
+type differ struct {
+	changes []Change
+}
+
+func (d *differ) add(path string, breaking bool, format string, a ...interface{}) {
+	d.changes = append(d.changes, Change{Path: path, Message: fmt.Sprintf(format, a...), Breaking: breaking})
+}
+
+func (d *differ) diffRoot(op string, old, new *TypeName) {
+	switch {
+	case old == nil && new == nil:
+	case old == nil:
+		d.add("schema."+op, false, "root type %s added", new.Name)
+	case new == nil:
+		d.add("schema."+op, true, "root type %s removed", old.Name)
+	case old.Name != new.Name:
+		d.add("schema."+op, true, "root type changed from %s to %s", old.Name, new.Name)
+	}
+}
+
+func (d *differ) diffType(old, new Type) {
+	if old.Kind != new.Kind {
+		d.add(old.Name, true, "kind changed from %s to %s", old.Kind, new.Kind)
+		return
+	}
+	switch old.Kind {
+	case "OBJECT", "INTERFACE":
+		d.diffFields(old.Name, old.Fields, new.Fields)
+		d.diffMembers(old.Name, "interface", old.Interfaces, new.Interfaces)
+	case "INPUT_OBJECT":
+		d.diffInputValues(old.Name, "input field", old.InputFields, new.InputFields)
+	case "UNION":
+		d.diffMembers(old.Name, "union member", old.PossibleTypes, new.PossibleTypes)
+	case "ENUM":
+		d.diffEnumValues(old.Name, old.EnumValues, new.EnumValues)
+	}
+}
+
+func (d *differ) diffFields(typeName string, old, new []Field) {
+	for _, of := range old {
+		path := typeName + "." + of.Name
+		nf := findField(new, of.Name)
+		if nf == nil {
+			d.add(path, true, "field removed")
+			continue
+		}
+		if o, n := of.Type.String(), nf.Type.String(); o != n {
+			d.add(path, !isSafeOutputChange(of.Type, nf.Type), "type changed from %s to %s", o, n)
+		}
+		if !of.IsDeprecated && nf.IsDeprecated {
+			d.add(path, false, "field deprecated")
+		}
+		d.diffInputValues(path, "argument", of.Args, nf.Args)
+	}
+	for _, nf := range new {
+		if findField(old, nf.Name) == nil {
+			d.add(typeName+"."+nf.Name, false, "field added")
+		}
+	}
+}
+
+// diffInputValues compares field arguments or input object fields.
+// Adding a required one is breaking, since existing operations don't provide it.
+func (d *differ) diffInputValues(parent, what string, old, new []InputValue) {
+	path := func(name string) string {
+		if what == "argument" {
+			return parent + "(" + name + ":)"
+		}
+		return parent + "." + name
+	}
+	for _, ov := range old {
+		nv := findInputValue(new, ov.Name)
+		if nv == nil {
+			d.add(path(ov.Name), true, "%s removed", what)
+			continue
+		}
+		if o, n := ov.Type.String(), nv.Type.String(); o != n {
+			d.add(path(ov.Name), !isSafeInputChange(ov.Type, nv.Type), "type changed from %s to %s", o, n)
+		}
+	}
+	for _, nv := range new {
+		if findInputValue(old, nv.Name) != nil {
+			continue
+		}
+		if nv.Type.Kind == "NON_NULL" && nv.DefaultValue == nil {
+			d.add(path(nv.Name), true, "required %s added", what)
+		} else {
+			d.add(path(nv.Name), false, "optional %s added", what)
+		}
+	}
+}
+
+func (d *differ) diffMembers(typeName, what string, old, new []TypeRef) {
+	for _, o := range old {
+		if !containsTypeRef(new, o) {
+			d.add(typeName, true, "%s %s removed", what, o)
+		}
+	}
+	for _, n := range new {
+		if !containsTypeRef(old, n) {
+			d.add(typeName, false, "%s %s added", what, n)
+		}
+	}
+}
+
+func (d *differ) diffEnumValues(typeName string, old, new []EnumValue) {
+	for _, ov := range old {
+		nv := findEnumValue(new, ov.Name)
+		if nv == nil {
+			d.add(typeName+"."+ov.Name, true, "enum value removed")
+			continue
+		}
+		if !ov.IsDeprecated && nv.IsDeprecated {
+			d.add(typeName+"."+ov.Name, false, "enum value deprecated")
+		}
+	}
+	for _, nv := range new {
+		if findEnumValue(old, nv.Name) == nil {
+			d.add(typeName+"."+nv.Name, false, "enum value added")
+		}
+	}
+}
+
+// isSafeOutputChange reports whether a field of type old can be changed to
+// type new without breaking clients. Output types may only become stricter.
+// E.g., "String" -> "String!" is safe, "[Int]" -> "[Int!]!" is safe.
+func isSafeOutputChange(old, new TypeRef) bool {
+	if new.Kind == "NON_NULL" && new.OfType != nil {
+		if old.Kind == "NON_NULL" && old.OfType != nil {
+			return isSafeOutputChange(*old.OfType, *new.OfType)
+		}
+		return isSafeOutputChange(old, *new.OfType)
+	}
+	return isSameWrapping(old, new, isSafeOutputChange)
+}
+
+// isSafeInputChange reports whether an argument or input field of type old
+// can be changed to type new without breaking clients. Input types may only
+// become more lenient. E.g., "ID!" -> "ID" is safe.
+func isSafeInputChange(old, new TypeRef) bool {
+	if old.Kind == "NON_NULL" && old.OfType != nil {
+		if new.Kind == "NON_NULL" && new.OfType != nil {
+			return isSafeInputChange(*old.OfType, *new.OfType)
+		}
+		return isSafeInputChange(*old.OfType, new)
+	}
+	return isSameWrapping(old, new, isSafeInputChange)
+}
+
+// isSameWrapping compares old and new, that are not NON_NULL at the top
+// level, using safe to compare their list element types.
+func isSameWrapping(old, new TypeRef, safe func(old, new TypeRef) bool) bool {
+	if old.Kind != new.Kind {
+		return false
+	}
+	if old.Kind == "LIST" {
+		if old.OfType == nil || new.OfType == nil {
+			return old.OfType == new.OfType
+		}
+		return safe(*old.OfType, *new.OfType)
+	}
+	return old.String() == new.String()
+}
+
+func isIntrospectionType(name string) bool {
+	return len(name) >= 2 && name[:2] == "__"
+}
+
+func findField(fields []Field, name string) *Field {
+	for i := range fields {
+		if fields[i].Name == name {
+			return &fields[i]
+		}
+	}
+	return nil
+}
+
+func findInputValue(values []InputValue, name string) *InputValue {
+	for i := range values {
+		if values[i].Name == name {
+			return &values[i]
+		}
+	}
+	return nil
+}
+
+func findEnumValue(values []EnumValue, name string) *EnumValue {
+	for i := range values {
+		if values[i].Name == name {
+			return &values[i]
+		}
+	}
+	return nil
+}
+
+func containsTypeRef(refs []TypeRef, ref TypeRef) bool {
+	for _, r := range refs {
+		if r.String() == ref.String() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+package introspection_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dbmedialab/go-graphql-client/introspection"
+)
+
+const oldSchema = `{"data": {"__schema": {
+	"queryType": {"name": "Query"},
+	"mutationType": {"name": "Mutation"},
+	"types": [
+		{"kind": "OBJECT", "name": "Query", "fields": [
+			{"name": "hero", "args": [
+				{"name": "episode", "type": {"kind": "ENUM", "name": "Episode"}}
+			], "type": {"kind": "OBJECT", "name": "Character"}},
+			{"name": "droid", "args": [
+				{"name": "id", "type": {"kind": "NON_NULL", "ofType": {"kind": "SCALAR", "name": "ID"}}}
+			], "type": {"kind": "OBJECT", "name": "Character"}}
+		]},
+		{"kind": "OBJECT", "name": "Mutation", "fields": [
+			{"name": "createReview", "args": [
+				{"name": "review", "type": {"kind": "NON_NULL", "ofType": {"kind": "INPUT_OBJECT", "name": "ReviewInput"}}}
+			], "type": {"kind": "SCALAR", "name": "Int"}}
+		]},
+		{"kind": "OBJECT", "name": "Character", "fields": [
+			{"name": "name", "args": [], "type": {"kind": "SCALAR", "name": "String"}},
+			{"name": "height", "args": [], "type": {"kind": "SCALAR", "name": "Float"}}
+		]},
+		{"kind": "INPUT_OBJECT", "name": "ReviewInput", "inputFields": [
+			{"name": "stars", "type": {"kind": "NON_NULL", "ofType": {"kind": "SCALAR", "name": "Int"}}}
+		]},
+		{"kind": "ENUM", "name": "Episode", "enumValues": [
+			{"name": "NEWHOPE"}, {"name": "EMPIRE"}, {"name": "JEDI"}
+		]},
+		{"kind": "OBJECT", "name": "Starship", "fields": []},
+		{"kind": "OBJECT", "name": "__Type", "fields": []}
+	]
+}}}`
+
+const newSchema = `{"__schema": {
+	"queryType": {"name": "Query"},
+	"types": [
+		{"kind": "OBJECT", "name": "Query", "fields": [
+			{"name": "hero", "args": [
+				{"name": "episode", "type": {"kind": "ENUM", "name": "Episode"}},
+				{"name": "first", "type": {"kind": "SCALAR", "name": "Int"}}
+			], "type": {"kind": "NON_NULL", "ofType": {"kind": "OBJECT", "name": "Character"}}},
+			{"name": "droid", "args": [
+				{"name": "id", "type": {"kind": "SCALAR", "name": "ID"}},
+				{"name": "locale", "type": {"kind": "NON_NULL", "ofType": {"kind": "SCALAR", "name": "String"}}}
+			], "type": {"kind": "OBJECT", "name": "Character"}}
+		]},
+		{"kind": "OBJECT", "name": "Character", "fields": [
+			{"name": "name", "args": [], "type": {"kind": "SCALAR", "name": "ID"}, "isDeprecated": true},
+			{"name": "mass", "args": [], "type": {"kind": "SCALAR", "name": "Float"}}
+		]},
+		{"kind": "INPUT_OBJECT", "name": "ReviewInput", "inputFields": [
+			{"name": "stars", "type": {"kind": "SCALAR", "name": "Int"}},
+			{"name": "commentary", "type": {"kind": "NON_NULL", "ofType": {"kind": "SCALAR", "name": "String"}}}
+		]},
+		{"kind": "ENUM", "name": "Episode", "enumValues": [
+			{"name": "NEWHOPE"}, {"name": "JEDI"}, {"name": "CLONES"}
+		]},
+		{"kind": "INPUT_OBJECT", "name": "Starship", "inputFields": []},
+		{"kind": "OBJECT", "name": "Planet", "fields": []}
+	]
+}}`
+
+func TestDiff(t *testing.T) {
+	old, err := introspection.Parse([]byte(oldSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	new, err := introspection.Parse([]byte(newSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, c := range introspection.Diff(old, new) {
+		got = append(got, c.String())
+	}
+	want := []string{
+		"BREAKING Character.height: field removed",
+		"         Character.mass: field added",
+		"BREAKING Character.name: type changed from String to ID",
+		"         Character.name: field deprecated",
+		"         Episode.CLONES: enum value added",
+		"BREAKING Episode.EMPIRE: enum value removed",
+		"BREAKING Mutation: type removed",
+		"         Planet: type added",
+		"         Query.droid(id:): type changed from ID! to ID",
+		"BREAKING Query.droid(locale:): required argument added",
+		"         Query.hero: type changed from Character to Character!",
+		"         Query.hero(first:): optional argument added",
+		"BREAKING ReviewInput.commentary: required input field added",
+		"         ReviewInput.stars: type changed from Int! to Int",
+		"BREAKING Starship: kind changed from OBJECT to INPUT_OBJECT",
+		"BREAKING schema.mutation: root type Mutation removed",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got changes:\n%q\nwant:\n%q", got, want)
+	}
+}
+
+func TestDiff_identical(t *testing.T) {
+	s, err := introspection.Parse([]byte(oldSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changes := introspection.Diff(s, s); len(changes) != 0 {
+		t.Errorf("got %v changes, want none: %v", len(changes), changes)
+	}
+}
+
+func TestTypeRef_String(t *testing.T) {
+	name := "Int"
+	ref := introspection.TypeRef{Kind: "NON_NULL", OfType: &introspection.TypeRef{
+		Kind: "LIST", OfType: &introspection.TypeRef{
+			Kind: "NON_NULL", OfType: &introspection.TypeRef{Kind: "SCALAR", Name: &name},
+		},
+	}}
+	if got, want := ref.String(), "[Int!]!"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
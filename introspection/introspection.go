@@ -0,0 +1,141 @@
+// Package introspection provides types for decoding the result of a GraphQL
+// introspection query, and for comparing two such results.
+//
+// Specification: https://facebook.github.io/graphql/October2016/#sec-Introspection.
+package introspection
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Query is the introspection query whose result Parse understands.
+// It selects everything needed to describe types, fields, arguments,
+// input fields, enum values and union members, to a type nesting depth of 7.
+const Query = `query IntrospectionQuery{__schema{queryType{name},mutationType{name},subscriptionType{name},types{...FullType}}}` +
+	`fragment FullType on __Type{kind,name,description,` +
+	`fields(includeDeprecated:true){name,description,args{...InputValue},type{...TypeRef},isDeprecated,deprecationReason},` +
+	`inputFields{...InputValue},interfaces{...TypeRef},enumValues(includeDeprecated:true){name,description,isDeprecated,deprecationReason},possibleTypes{...TypeRef}}` +
+	`fragment InputValue on __InputValue{name,description,type{...TypeRef},defaultValue}` +
+	`fragment TypeRef on __Type{kind,name,ofType{kind,name,ofType{kind,name,ofType{kind,name,ofType{kind,name,ofType{kind,name,ofType{kind,name,ofType{kind,name}}}}}}}}`
+
+// Schema is the "__schema" object of an introspection result.
+type Schema struct {
+	QueryType        *TypeName `json:"queryType"`
+	MutationType     *TypeName `json:"mutationType"`
+	SubscriptionType *TypeName `json:"subscriptionType"`
+	Types            []Type    `json:"types"`
+}
+
+// TypeName names one of the root operation types.
+type TypeName struct {
+	Name string `json:"name"`
+}
+
+// Type is a named type in the schema.
+type Type struct {
+	Kind          string       `json:"kind"`
+	Name          string       `json:"name"`
+	Description   string       `json:"description"`
+	Fields        []Field      `json:"fields"`
+	InputFields   []InputValue `json:"inputFields"`
+	Interfaces    []TypeRef    `json:"interfaces"`
+	EnumValues    []EnumValue  `json:"enumValues"`
+	PossibleTypes []TypeRef    `json:"possibleTypes"`
+}
+
+// Field is a field of an object or interface type.
+type Field struct {
+	Name              string       `json:"name"`
+	Description       string       `json:"description"`
+	Args              []InputValue `json:"args"`
+	Type              TypeRef      `json:"type"`
+	IsDeprecated      bool         `json:"isDeprecated"`
+	DeprecationReason *string      `json:"deprecationReason"`
+}
+
+// InputValue is a field argument or a field of an input object type.
+type InputValue struct {
+	Name         string  `json:"name"`
+	Description  string  `json:"description"`
+	Type         TypeRef `json:"type"`
+	DefaultValue *string `json:"defaultValue"`
+}
+
+// EnumValue is a value of an enum type.
+type EnumValue struct {
+	Name              string  `json:"name"`
+	Description       string  `json:"description"`
+	IsDeprecated      bool    `json:"isDeprecated"`
+	DeprecationReason *string `json:"deprecationReason"`
+}
+
+// TypeRef is a reference to a type, possibly wrapped in NON_NULL and LIST.
+type TypeRef struct {
+	Kind   string   `json:"kind"`
+	Name   *string  `json:"name"`
+	OfType *TypeRef `json:"ofType"`
+}
+
+// String returns t in GraphQL notation. E.g., "[Int!]!".
+func (t TypeRef) String() string {
+	switch {
+	case t.Kind == "NON_NULL" && t.OfType != nil:
+		return t.OfType.String() + "!"
+	case t.Kind == "LIST" && t.OfType != nil:
+		return "[" + t.OfType.String() + "]"
+	case t.Name != nil:
+		return *t.Name
+	default:
+		return ""
+	}
+}
+
+// Type returns the named type in s, or nil if there isn't one.
+func (s *Schema) Type(name string) *Type {
+	for i := range s.Types {
+		if s.Types[i].Name == name {
+			return &s.Types[i]
+		}
+	}
+	return nil
+}
+
+// Field returns the named field of t, or nil if there isn't one.
+func (t *Type) Field(name string) *Field {
+	for i := range t.Fields {
+		if t.Fields[i].Name == name {
+			return &t.Fields[i]
+		}
+	}
+	return nil
+}
+
+// Parse parses an introspection result. It accepts a full GraphQL response
+// ({"data":{"__schema":...}}), its data ({"__schema":...}),
+// or the schema object itself.
+func Parse(data []byte) (*Schema, error) {
+	var envelope struct {
+		Data *struct {
+			Schema *Schema `json:"__schema"`
+		} `json:"data"`
+		Schema *Schema `json:"__schema"`
+	}
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, err
+	}
+	switch {
+	case envelope.Data != nil && envelope.Data.Schema != nil:
+		return envelope.Data.Schema, nil
+	case envelope.Schema != nil:
+		return envelope.Schema, nil
+	}
+	var s Schema
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	if s.Types == nil {
+		return nil, fmt.Errorf("introspection: no __schema found in input")
+	}
+	return &s, nil
+}
@@ -0,0 +1,245 @@
+package introspection
+
+import (
+	"fmt"
+
+	"github.com/dbmedialab/go-graphql-client/internal/document"
+)
+
+// Validate parses the GraphQL executable document src and checks it
+// against s, returning the first problem found.
+//
+// Only the rules that catch the most common mistakes are checked:
+// selected fields and their arguments exist, leaf fields have no selection
+// and composite fields have one, fragments exist and are spread on known
+// types, and variables are declared with known input types before use.
+func (s *Schema) Validate(src string) error {
+	doc, err := document.Parse(src)
+	if err != nil {
+		return err
+	}
+	v := validator{schema: s, doc: doc}
+	for _, f := range doc.Fragments {
+		t := s.Type(f.TypeCondition)
+		if t == nil {
+			return fmt.Errorf("fragment %s: unknown type %s", f.Name, f.TypeCondition)
+		}
+		// Variables used in fragments are checked by the operations spreading them.
+		if err := v.selectionSet(t, f.SelectionSet, nil); err != nil {
+			return fmt.Errorf("fragment %s: %v", f.Name, err)
+		}
+	}
+	for _, op := range doc.Operations {
+		if err := v.operation(op); err != nil {
+			if op.Name == "" {
+				return fmt.Errorf("%s: %v", op.Type, err)
+			}
+			return fmt.Errorf("%s %s: %v", op.Type, op.Name, err)
+		}
+	}
+	return nil
+}
+
+type validator struct {
+	schema *Schema
+	doc    *document.Document
+}
+
+// typ returns the named type, or nil if there isn't one. Built-in scalars
+// are always known, even if s omits them.
+func (v *validator) typ(name string) *Type {
+	if t := v.schema.Type(name); t != nil {
+		return t
+	}
+	switch name {
+	case "Int", "Float", "String", "Boolean", "ID":
+		return &Type{Kind: "SCALAR", Name: name}
+	}
+	return nil
+}
+
+func (v *validator) operation(op *document.Operation) error {
+	var root *TypeName
+	switch op.Type {
+	case "query":
+		root = v.schema.QueryType
+	case "mutation":
+		root = v.schema.MutationType
+	case "subscription":
+		root = v.schema.SubscriptionType
+	}
+	if root == nil || v.schema.Type(root.Name) == nil {
+		return fmt.Errorf("schema has no %s type", op.Type)
+	}
+	vars := map[string]bool{}
+	for _, d := range op.VariableDefinitions {
+		if vars[d.Name] {
+			return fmt.Errorf("variable $%s declared more than once", d.Name)
+		}
+		vars[d.Name] = true
+		if err := v.inputType(d.Type); err != nil {
+			return fmt.Errorf("variable $%s: %v", d.Name, err)
+		}
+	}
+	if err := v.selectionSet(v.schema.Type(root.Name), op.SelectionSet, vars); err != nil {
+		return err
+	}
+	// Check variable use in the fragments this operation spreads.
+	return v.fragmentVariables(op.SelectionSet, vars, map[string]bool{})
+}
+
+func (v *validator) inputType(t *document.Type) error {
+	if t.Elem != nil {
+		return v.inputType(t.Elem)
+	}
+	st := v.typ(t.Name)
+	if st == nil {
+		return fmt.Errorf("unknown type %s", t.Name)
+	}
+	switch st.Kind {
+	case "SCALAR", "ENUM", "INPUT_OBJECT":
+		return nil
+	}
+	return fmt.Errorf("%s is not an input type", t.Name)
+}
+
+// selectionSet checks selections on parent. If vars is non-nil, variables
+// used in arguments must be in it.
+func (v *validator) selectionSet(parent *Type, selections []document.Selection, vars map[string]bool) error {
+	for _, sel := range selections {
+		switch sel := sel.(type) {
+		case *document.Field:
+			if err := v.field(parent, sel, vars); err != nil {
+				return err
+			}
+		case *document.InlineFragment:
+			on := parent
+			if sel.TypeCondition != "" {
+				if on = v.schema.Type(sel.TypeCondition); on == nil {
+					return fmt.Errorf("unknown type %s", sel.TypeCondition)
+				}
+			}
+			if err := checkDirectives(sel.Directives, vars); err != nil {
+				return err
+			}
+			if err := v.selectionSet(on, sel.SelectionSet, vars); err != nil {
+				return err
+			}
+		case *document.FragmentSpread:
+			if v.doc.Fragment(sel.Name) == nil {
+				return fmt.Errorf("unknown fragment %s", sel.Name)
+			}
+			if err := checkDirectives(sel.Directives, vars); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (v *validator) field(parent *Type, f *document.Field, vars map[string]bool) error {
+	if err := checkDirectives(f.Directives, vars); err != nil {
+		return err
+	}
+	if f.Name == "__typename" {
+		if len(f.SelectionSet) != 0 {
+			return fmt.Errorf("field __typename cannot have a selection")
+		}
+		return nil
+	}
+	def := parent.Field(f.Name)
+	if def == nil {
+		return fmt.Errorf("type %s has no field %s", parent.Name, f.Name)
+	}
+	for _, a := range f.Arguments {
+		if findInputValue(def.Args, a.Name) == nil {
+			return fmt.Errorf("field %s.%s has no argument %s", parent.Name, f.Name, a.Name)
+		}
+		if err := checkVariables(a.Value, vars); err != nil {
+			return err
+		}
+	}
+	for _, a := range def.Args {
+		if a.Type.Kind == "NON_NULL" && a.DefaultValue == nil && !hasArgument(f.Arguments, a.Name) {
+			return fmt.Errorf("field %s.%s is missing required argument %s", parent.Name, f.Name, a.Name)
+		}
+	}
+	ref := def.Type
+	for ref.OfType != nil {
+		ref = *ref.OfType
+	}
+	t := v.typ(ref.String())
+	if t == nil {
+		return fmt.Errorf("unknown type %s", ref)
+	}
+	switch t.Kind {
+	case "OBJECT", "INTERFACE", "UNION":
+		if len(f.SelectionSet) == 0 {
+			return fmt.Errorf("field %s of type %s must have a selection", f.Name, t.Name)
+		}
+		return v.selectionSet(t, f.SelectionSet, vars)
+	}
+	if len(f.SelectionSet) != 0 {
+		return fmt.Errorf("field %s of type %s cannot have a selection", f.Name, t.Name)
+	}
+	return nil
+}
+
+// fragmentVariables checks variable use in the fragments spread by selections.
+func (v *validator) fragmentVariables(selections []document.Selection, vars map[string]bool, seen map[string]bool) error {
+	for _, sel := range selections {
+		var err error
+		switch sel := sel.(type) {
+		case *document.Field:
+			err = v.fragmentVariables(sel.SelectionSet, vars, seen)
+		case *document.InlineFragment:
+			err = v.fragmentVariables(sel.SelectionSet, vars, seen)
+		case *document.FragmentSpread:
+			if seen[sel.Name] {
+				continue
+			}
+			seen[sel.Name] = true
+			f := v.doc.Fragment(sel.Name)
+			if err = v.selectionSet(v.schema.Type(f.TypeCondition), f.SelectionSet, vars); err != nil {
+				return fmt.Errorf("fragment %s: %v", f.Name, err)
+			}
+			err = v.fragmentVariables(f.SelectionSet, vars, seen)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkDirectives(ds []*document.Directive, vars map[string]bool) error {
+	for _, d := range ds {
+		for _, a := range d.Arguments {
+			if err := checkVariables(a.Value, vars); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func checkVariables(value *document.Value, vars map[string]bool) error {
+	if vars == nil {
+		return nil
+	}
+	for _, name := range value.Variables() {
+		if !vars[name] {
+			return fmt.Errorf("variable $%s is not declared", name)
+		}
+	}
+	return nil
+}
+
+func hasArgument(args []*document.Argument, name string) bool {
+	for _, a := range args {
+		if a.Name == name {
+			return true
+		}
+	}
+	return false
+}
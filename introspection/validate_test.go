@@ -0,0 +1,45 @@
+package introspection_test
+
+import (
+	"testing"
+
+	"github.com/dbmedialab/go-graphql-client/introspection"
+)
+
+func TestSchema_Validate(t *testing.T) {
+	s, err := introspection.Parse([]byte(oldSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		in   string
+		want string // Empty if valid.
+	}{
+		{in: `query Hero($ep: Episode) { hero(episode: $ep) { __typename name ...h } } fragment h on Character { height }`},
+		{in: `mutation { createReview(review: {stars: 5}) }`},
+		{in: `{ hero { mass } }`, want: "query: type Character has no field mass"},
+		{in: `{ hero(first: 1) { name } }`, want: "query: field Query.hero has no argument first"},
+		{in: `{ droid { name } }`, want: "query: field Query.droid is missing required argument id"},
+		{in: `{ hero }`, want: "query: field hero of type Character must have a selection"},
+		{in: `{ hero { name { first } } }`, want: "query: field name of type String cannot have a selection"},
+		{in: `query Q { hero(episode: $ep) { name } }`, want: "query Q: variable $ep is not declared"},
+		{in: `query Q($c: Character) { hero { name } }`, want: "query Q: variable $c: Character is not an input type"},
+		{in: `query Q($x: Int, $x: Int) { hero { name } }`, want: "query Q: variable $x declared more than once"},
+		{in: `{ hero { ...missing } }`, want: "query: unknown fragment missing"},
+		{in: `{ hero { ... on Wookiee { name } } }`, want: "query: unknown type Wookiee"},
+		{in: `query Q { hero { ...h } } fragment h on Character { name @include(if: $show) }`, want: "query Q: fragment h: variable $show is not declared"},
+		{in: `subscription { hero { name } }`, want: "subscription: schema has no subscription type"},
+		{in: `{ hero {`, want: `graphql: syntax error at 1:9: unexpected end of document, expected "}"`},
+	}
+	for _, tc := range tests {
+		err := s.Validate(tc.in)
+		switch {
+		case tc.want == "" && err != nil:
+			t.Errorf("%q: got error: %v, want: nil", tc.in, err)
+		case tc.want != "" && err == nil:
+			t.Errorf("%q: got error: nil, want: %q", tc.in, tc.want)
+		case tc.want != "" && err.Error() != tc.want:
+			t.Errorf("%q:\ngot error:  %q\nwant error: %q", tc.in, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package introspection
+
+import (
+	"fmt"
+
+	"github.com/dbmedialab/go-graphql-client/internal/document"
+)
+
+// VariableTypes returns the types of the variables used in the GraphQL
+// executable document src, as declared in s by the arguments and input
+// fields they're bound to. E.g., for "{node(id:$id){id}}" it returns
+// {"id": "ID!"} if s declares the argument as "id: ID!".
+//
+// Variables bound only to fields, arguments or input fields that s doesn't
+// have are left out, for the caller to type some other way. A variable
+// bound in several places, such as by several root fields, gets one type
+// that suits all of them: non-null if any of them is. It's an error for
+// the places to be of different types otherwise.
+func (s *Schema) VariableTypes(src string) (map[string]string, error) {
+	doc, err := document.Parse(src)
+	if err != nil {
+		return nil, err
+	}
+	b := binder{schema: s, doc: doc, types: map[string]TypeRef{}, seen: map[string]bool{}}
+	for _, op := range doc.Operations {
+		var root *TypeName
+		switch op.Type {
+		case "query":
+			root = s.QueryType
+		case "mutation":
+			root = s.MutationType
+		case "subscription":
+			root = s.SubscriptionType
+		}
+		var t *Type
+		if root != nil {
+			t = s.Type(root.Name)
+		}
+		b.directives(op.Directives)
+		b.selectionSet(t, op.SelectionSet)
+	}
+	if b.err != nil {
+		return nil, b.err
+	}
+	types := make(map[string]string, len(b.types))
+	for name, t := range b.types {
+		types[name] = t.String()
+	}
+	return types, nil
+}
+
+// binder records the types of the positions variables are bound to.
+type binder struct {
+	schema *Schema
+	doc    *document.Document
+	types  map[string]TypeRef // By variable name.
+	seen   map[string]bool    // Fragments already visited.
+	err    error              // The first conflict between types.
+}
+
+// selectionSet binds the variables in selections on parent, which is nil
+// if it's not known.
+func (b *binder) selectionSet(parent *Type, selections []document.Selection) {
+	for _, sel := range selections {
+		switch sel := sel.(type) {
+		case *document.Field:
+			b.directives(sel.Directives)
+			var child *Type
+			if def := parent.field(sel.Name); def != nil {
+				for _, a := range sel.Arguments {
+					if iv := findInputValue(def.Args, a.Name); iv != nil {
+						b.value(a.Value, iv.Type)
+					}
+				}
+				child = b.schema.Type(namedType(def.Type))
+			}
+			b.selectionSet(child, sel.SelectionSet)
+		case *document.InlineFragment:
+			b.directives(sel.Directives)
+			on := parent
+			if sel.TypeCondition != "" {
+				on = b.schema.Type(sel.TypeCondition)
+			}
+			b.selectionSet(on, sel.SelectionSet)
+		case *document.FragmentSpread:
+			b.directives(sel.Directives)
+			if f := b.doc.Fragment(sel.Name); f != nil && !b.seen[f.Name] {
+				b.seen[f.Name] = true
+				b.selectionSet(b.schema.Type(f.TypeCondition), f.SelectionSet)
+			}
+		}
+	}
+}
+
+// directives binds the variables in the arguments of the built-in
+// @include and @skip directives.
+func (b *binder) directives(ds []*document.Directive) {
+	boolean := "Boolean"
+	nonNullBoolean := TypeRef{Kind: "NON_NULL", OfType: &TypeRef{Kind: "SCALAR", Name: &boolean}}
+	for _, d := range ds {
+		if d.Name != "include" && d.Name != "skip" {
+			continue
+		}
+		for _, a := range d.Arguments {
+			if a.Name == "if" {
+				b.value(a.Value, nonNullBoolean)
+			}
+		}
+	}
+}
+
+// value binds the variables in v, which is in a position of type t.
+func (b *binder) value(v *document.Value, t TypeRef) {
+	switch v.Kind {
+	case document.VariableValue:
+		old, ok := b.types[v.Name]
+		if !ok {
+			b.types[v.Name] = t
+			return
+		}
+		merged, ok := mergeTypes(old, t)
+		if !ok {
+			if b.err == nil {
+				b.err = fmt.Errorf("variable $%s is bound to both %s and %s", v.Name, old, t)
+			}
+			return
+		}
+		b.types[v.Name] = merged
+	case document.ListValue:
+		if t.Kind == "NON_NULL" && t.OfType != nil {
+			t = *t.OfType
+		}
+		if t.Kind != "LIST" || t.OfType == nil {
+			return
+		}
+		for _, e := range v.List {
+			b.value(e, *t.OfType)
+		}
+	case document.ObjectValue:
+		it := b.schema.Type(namedType(t))
+		if it == nil {
+			return
+		}
+		for _, f := range v.Fields {
+			if iv := findInputValue(it.InputFields, f.Name); iv != nil {
+				b.value(f.Value, iv.Type)
+			}
+		}
+	}
+}
+
+// mergeTypes returns the type of a variable that can be passed in positions
+// of types a and b, and whether there is one.
+func mergeTypes(a, b TypeRef) (TypeRef, bool) {
+	nonNull := false
+	if a.Kind == "NON_NULL" && a.OfType != nil {
+		a, nonNull = *a.OfType, true
+	}
+	if b.Kind == "NON_NULL" && b.OfType != nil {
+		b, nonNull = *b.OfType, true
+	}
+	var t TypeRef
+	switch {
+	case a.Kind == "LIST" && b.Kind == "LIST" && a.OfType != nil && b.OfType != nil:
+		elem, ok := mergeTypes(*a.OfType, *b.OfType)
+		if !ok {
+			return TypeRef{}, false
+		}
+		t = TypeRef{Kind: "LIST", OfType: &elem}
+	case a.Kind != "LIST" && b.Kind != "LIST" && a.String() == b.String():
+		t = a
+	default:
+		return TypeRef{}, false
+	}
+	if nonNull {
+		return TypeRef{Kind: "NON_NULL", OfType: &t}, true
+	}
+	return t, true
+}
+
+// field is like Field, but returns nil if t is nil.
+func (t *Type) field(name string) *Field {
+	if t == nil {
+		return nil
+	}
+	return t.Field(name)
+}
+
+// namedType returns the name of the type t wraps, or is.
+func namedType(t TypeRef) string {
+	for t.OfType != nil {
+		t = *t.OfType
+	}
+	return t.String()
+}
@@ -0,0 +1,87 @@
+package introspection_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dbmedialab/go-graphql-client/introspection"
+)
+
+func TestSchema_VariableTypes(t *testing.T) {
+	s, err := introspection.Parse([]byte(oldSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		in   string
+		want map[string]string
+	}{
+		{
+			in:   `{ hero(episode: $ep) { name @include(if: $withName) } droid(id: $id) { ...f } } fragment f on Character { height @skip(if: $short) }`,
+			want: map[string]string{"ep": "Episode", "withName": "Boolean!", "id": "ID!", "short": "Boolean!"},
+		},
+		{
+			in:   `mutation { createReview(review: {stars: $stars}) }`,
+			want: map[string]string{"stars": "Int!"},
+		},
+		{
+			in:   `mutation { createReview(review: $review) }`,
+			want: map[string]string{"review": "ReviewInput!"},
+		},
+		{
+			// Unknown positions are left out.
+			in:   `{ hero(episode: $ep, first: $first) { mass(unit: $unit) } starship(id: $id) { name } }`,
+			want: map[string]string{"ep": "Episode"},
+		},
+	}
+	for _, tc := range tests {
+		got, err := s.VariableTypes(tc.in)
+		if err != nil {
+			t.Errorf("%q: got error: %v", tc.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%q:\ngot:  %v\nwant: %v", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestSchema_VariableTypes_shared(t *testing.T) {
+	s, err := introspection.Parse([]byte(`{"__schema": {
+		"queryType": {"name": "Query"},
+		"types": [
+			{"kind": "OBJECT", "name": "Query", "fields": [
+				{"name": "user", "args": [
+					{"name": "id", "type": {"kind": "NON_NULL", "ofType": {"kind": "SCALAR", "name": "ID"}}}
+				], "type": {"kind": "OBJECT", "name": "User"}},
+				{"name": "node", "args": [
+					{"name": "id", "type": {"kind": "SCALAR", "name": "ID"}}
+				], "type": {"kind": "OBJECT", "name": "User"}},
+				{"name": "users", "args": [
+					{"name": "ids", "type": {"kind": "LIST", "ofType": {"kind": "NON_NULL", "ofType": {"kind": "SCALAR", "name": "ID"}}}}
+				], "type": {"kind": "LIST", "ofType": {"kind": "OBJECT", "name": "User"}}},
+				{"name": "nodes", "args": [
+					{"name": "ids", "type": {"kind": "NON_NULL", "ofType": {"kind": "LIST", "ofType": {"kind": "SCALAR", "name": "ID"}}}}
+				], "type": {"kind": "LIST", "ofType": {"kind": "OBJECT", "name": "User"}}}
+			]},
+			{"kind": "OBJECT", "name": "User", "fields": [
+				{"name": "name", "args": [], "type": {"kind": "SCALAR", "name": "String"}}
+			]}
+		]
+	}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.VariableTypes(`{ node(id: $id) { name } user(id: $id) { name } users(ids: $ids) { name } nodes(ids: $ids) { name } }`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"id": "ID!", "ids": "[ID!]!"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+
+	_, err = s.VariableTypes(`{ user(id: $x) { name } users(ids: $x) { name } }`)
+	if got, want := err, "variable $x is bound to both ID! and [ID!]"; got == nil || got.Error() != want {
+		t.Errorf("got error: %v, want: %v", got, want)
+	}
+}
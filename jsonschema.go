@@ -0,0 +1,186 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/dbmedialab/go-graphql-client/ident"
+)
+
+// GenerateResponseSchema returns a JSON Schema (draft-07) describing the
+// "data" of a response to the query GenerateQueryFields derives from v.
+// It can be used to check recorded fixtures or mock servers against the
+// shape that v expects, including from languages other than Go.
+//
+// The Go type of each field determines its schema: pointers may be null,
+// slices and arrays are lists, and types implementing json.Unmarshaler
+// accept any value. Fields of inline fragments are allowed but not required.
+func GenerateResponseSchema(v interface{}) ([]byte, error) {
+	g := schemaGenerator{visited: map[edge]int{}}
+	s, err := g.schema(reflect.TypeOf(v))
+	if err != nil {
+		return nil, err
+	}
+	s["$schema"] = "http://json-schema.org/draft-07/schema#"
+	return json.MarshalIndent(s, "", "\t")
+}
+
+type schemaGenerator struct {
+	// visited keeps recursive types from being expanded without bound,
+	// the same way as in writeQuery.
+	visited map[edge]int
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage(nil))
+)
+
+func (g schemaGenerator) schema(t reflect.Type) (map[string]interface{}, error) {
+	switch t.Kind() {
+	case reflect.Ptr:
+		s, err := g.schema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return nullable(s), nil
+	case reflect.Slice, reflect.Array:
+		if t == rawMessageType {
+			// Passed through as is, so any value.
+			return map[string]interface{}{}, nil
+		}
+		if isBytes(t) {
+			return map[string]interface{}{"type": "string", "contentEncoding": "base64"}, nil
+		}
+		items, err := g.schema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"type": "array", "items": items}, nil
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if t == durationType {
+			return map[string]interface{}{"type": "string", "format": "duration"}, nil
+		}
+		return map[string]interface{}{"type": "integer"}, nil
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}, nil
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}, nil
+	case reflect.Struct:
+		if t == timeType {
+			return map[string]interface{}{"type": "string", "format": "date-time"}, nil
+		}
+		if pt := reflect.PtrTo(t); pt.Implements(gqlUnmarshaler) || pt.Implements(jsonUnmarshaler) {
+			// A scalar with custom decoding; its JSON representation is unknown.
+			return map[string]interface{}{}, nil
+		}
+		if isScalar(t) {
+			// A scalar encoded as text.
+			return map[string]interface{}{"type": "string"}, nil
+		}
+		s := map[string]interface{}{
+			"type":       "object",
+			"properties": map[string]interface{}{},
+		}
+		if err := g.fields(s, t, true); err != nil {
+			return nil, err
+		}
+		return s, nil
+	}
+	// Interfaces, such as ID, and anything else accept any value.
+	return map[string]interface{}{}, nil
+}
+
+// fields adds the fields of struct t to object schema s.
+// Fields are required unless they're inside an inline fragment.
+func (g schemaGenerator) fields(s map[string]interface{}, t reflect.Type, required bool) error {
+	props := s["properties"].(map[string]interface{})
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if isExcluded(f) {
+			continue
+		}
+
+		edge := edge{t, i}
+		g.visited[edge]++
+		limit, err := getRecursionLimit(f)
+		if err != nil {
+			return fmt.Errorf("%s.%s: %v", t, f.Name, err)
+		}
+		if g.visited[edge] > limit {
+			g.visited[edge]--
+			if limit < 2 {
+				return fmt.Errorf("cycle found at %s.%s", t, f.Name)
+			}
+			continue
+		}
+
+		value, ok := fieldTag(f)
+		value = strings.TrimSpace(value)
+		switch {
+		case f.Anonymous && !ok:
+			// Embedded struct; its fields are inlined.
+			err = g.inline(s, f.Type, required)
+		case strings.HasPrefix(value, "..."):
+			// Inline fragment; its fields are only present for matching types.
+			err = g.inline(s, f.Type, false)
+		default:
+			name := responseKey(f)
+			var fs map[string]interface{}
+			fs, err = g.schema(f.Type)
+			props[name] = fs
+			if required {
+				req, _ := s["required"].([]string)
+				s["required"] = append(req, name)
+			}
+		}
+		g.visited[edge]--
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g schemaGenerator) inline(s map[string]interface{}, t reflect.Type, required bool) error {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	return g.fields(s, t, required)
+}
+
+// nullable returns s modified to also accept null.
+// A schema without a type already accepts any value, including null.
+func nullable(s map[string]interface{}) map[string]interface{} {
+	if typ, ok := s["type"].(string); ok {
+		s["type"] = []string{typ, "null"}
+	}
+	return s
+}
+
+// responseKey returns the key under which the value of struct field f
+// appears in a GraphQL response: the alias or name from its graphql tag,
+// or else its name in lowerCamelCase.
+func responseKey(f reflect.StructField) string {
+	value, ok := fieldTag(f)
+	if !ok {
+		return ident.ParseMixedCaps(f.Name).ToLowerCamelCase()
+	}
+	value = strings.TrimSpace(value)
+	if i := strings.IndexAny(value, "(@ {"); i != -1 {
+		value = value[:i]
+	}
+	if i := strings.Index(value, ":"); i != -1 {
+		value = value[:i]
+	}
+	return strings.TrimSpace(value)
+}
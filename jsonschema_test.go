@@ -0,0 +1,72 @@
+package graphql_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/dbmedialab/go-graphql-client"
+)
+
+func TestGenerateResponseSchema(t *testing.T) {
+	type actor struct {
+		Login graphql.String
+	}
+	var q struct {
+		Viewer struct {
+			actor
+			ID        graphql.ID
+			CreatedAt time.Time
+			Avatar    *graphql.String `graphql:"avatar: avatarUrl(size: 72)"`
+			Stars     []graphql.Int
+			Droid     struct {
+				PrimaryFunction graphql.String
+			} `graphql:"... on Droid"`
+		}
+	}
+	b, err := graphql.GenerateResponseSchema(q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	var want map[string]interface{}
+	err = json.Unmarshal([]byte(`{
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"type": "object",
+		"properties": {
+			"viewer": {
+				"type": "object",
+				"properties": {
+					"login": {"type": "string"},
+					"id": {},
+					"createdAt": {"type": "string", "format": "date-time"},
+					"avatar": {"type": ["string", "null"]},
+					"stars": {"type": "array", "items": {"type": "integer"}},
+					"primaryFunction": {"type": "string"}
+				},
+				"required": ["login", "id", "createdAt", "avatar", "stars"]
+			}
+		},
+		"required": ["viewer"]
+	}`), &want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got schema:\n%s", b)
+	}
+}
+
+func TestGenerateResponseSchema_cycle(t *testing.T) {
+	type node struct {
+		Children []node
+	}
+	_, err := graphql.GenerateResponseSchema(node{})
+	if got, want := err, "cycle found at graphql_test.node.Children"; got == nil || got.Error() != want {
+		t.Errorf("got error: %v, want: %v", got, want)
+	}
+}
@@ -9,7 +9,7 @@ import (
 	"time"
 
 	"github.com/dbmedialab/go-graphql-client"
-	"github.com/dbmedialab/go-graphql-client/internal/jsonutil"
+	"github.com/dbmedialab/go-graphql-client/jsonutil"
 )
 
 func TestUnmarshalGraphQL_benchmark(t *testing.T) {
@@ -0,0 +1,65 @@
+package jsonutil
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// parseDuration parses s as an ISO 8601 duration, such as "PT1H30M" or
+// "P1DT0.5S", or else as Go's time.ParseDuration does, such as "1h30m".
+// Days and weeks are taken to be 24 and 168 hours long; years and months,
+// whose lengths vary, aren't accepted.
+func parseDuration(s string) (time.Duration, error) {
+	iso := strings.TrimPrefix(s, "-")
+	if !strings.HasPrefix(iso, "P") {
+		return time.ParseDuration(s)
+	}
+	var (
+		d      float64
+		inTime bool
+		rest   = iso[1:]
+	)
+	if rest == "" || rest == "T" {
+		return 0, fmt.Errorf("invalid ISO 8601 duration %q", s)
+	}
+	for rest != "" {
+		if rest[0] == 'T' && !inTime {
+			inTime, rest = true, rest[1:]
+			continue
+		}
+		i := strings.IndexFunc(rest, func(r rune) bool { return (r < '0' || r > '9') && r != '.' && r != ',' })
+		if i <= 0 {
+			return 0, fmt.Errorf("invalid ISO 8601 duration %q", s)
+		}
+		n, err := strconv.ParseFloat(strings.Replace(rest[:i], ",", ".", 1), 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid ISO 8601 duration %q", s)
+		}
+		var unit time.Duration
+		switch u := rest[i]; {
+		case !inTime && u == 'W':
+			unit = 7 * 24 * time.Hour
+		case !inTime && u == 'D':
+			unit = 24 * time.Hour
+		case inTime && u == 'H':
+			unit = time.Hour
+		case inTime && u == 'M':
+			unit = time.Minute
+		case inTime && u == 'S':
+			unit = time.Second
+		default:
+			return 0, fmt.Errorf("unsupported ISO 8601 duration %q", s)
+		}
+		d += n * float64(unit)
+		rest = rest[i+1:]
+	}
+	if strings.HasPrefix(s, "-") {
+		d = -d
+	}
+	return time.Duration(d), nil
+}
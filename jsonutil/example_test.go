@@ -0,0 +1,33 @@
+package jsonutil_test
+
+import (
+	"fmt"
+
+	"github.com/dbmedialab/go-graphql-client"
+	"github.com/dbmedialab/go-graphql-client/jsonutil"
+)
+
+func ExampleUnmarshalGraphQL() {
+	// Data of a response to {me: viewer{login},node(id:"1"){... on User{login}}},
+	// read from a queue.
+	data := []byte(`{"me": {"login": "gopher"}, "node": {"__typename": "User", "login": "octocat"}}`)
+
+	var q struct {
+		Me struct {
+			Login graphql.String
+		} `graphql:"me: viewer"`
+		Node struct {
+			Typename graphql.String `graphql:"__typename"`
+			User     struct {
+				Login graphql.String
+			} `graphql:"... on User"`
+		} `graphql:"node(id: \"1\")"`
+	}
+	if err := jsonutil.UnmarshalGraphQL(data, &q); err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(q.Me.Login, q.Node.Typename, q.Node.User.Login)
+
+	// Output: gopher User octocat
+}
@@ -0,0 +1,68 @@
+//go:build go1.18
+// +build go1.18
+
+package jsonutil_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dbmedialab/go-graphql-client"
+	"github.com/dbmedialab/go-graphql-client/jsonutil"
+)
+
+// fuzzQuery has fields of the kinds of types that queries are made of.
+type fuzzQuery struct {
+	Typename string `graphql:"__typename"`
+	Str      graphql.String
+	Int      *graphql.Int
+	Float    graphql.Float
+	Bool     *graphql.Boolean
+	ID       graphql.ID
+	Time     time.Time
+	List     []graphql.Int
+	Matrix   [][]*graphql.String
+	Object   *struct {
+		Name graphql.String
+		Kids []struct {
+			Name graphql.String
+		}
+	}
+	Map   map[string]interface{} `graphql:"map"`
+	Union []struct {
+		Typename string `graphql:"__typename"`
+		A        struct {
+			X graphql.Int
+		} `graphql:"... on A"`
+		B *struct {
+			X graphql.String
+		} `graphql:"... on B"`
+	}
+	fuzzEmbedded
+}
+
+type fuzzEmbedded struct {
+	Embedded graphql.String
+}
+
+func FuzzUnmarshalGraphQL(f *testing.F) {
+	for _, seed := range []string{
+		`{}`,
+		`{"str": "s", "int": 1, "float": 1.5, "bool": true, "id": "x", "time": "2020-01-02T03:04:05Z"}`,
+		`{"list": [1, 2], "matrix": [["a", null], []], "object": {"name": "n", "kids": [{"name": "k"}]}}`,
+		`{"map": {"a": [1, {"b": null}]}, "union": [{"__typename": "A", "x": 1}, {"__typename": "B", "x": "y"}]}`,
+		`{"embedded": "e", "__typename": "Query"}`,
+		`{"int": 1e999, "list": {"a": 1}, "object": [1]}`,
+		`{"map": ` + strings.Repeat("[", 100) + strings.Repeat("]", 100) + `}`,
+	} {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var q fuzzQuery
+		err := jsonutil.UnmarshalGraphQL(data, &q)
+		if err != nil && strings.HasPrefix(err.Error(), "panic decoding ") {
+			t.Errorf("decoding %q: %v", data, err)
+		}
+	})
+}
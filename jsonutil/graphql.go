@@ -0,0 +1,1058 @@
+// Package jsonutil provides a function for decoding JSON
+// into a GraphQL query data structure.
+//
+// It decodes the "data" of GraphQL responses the way the graphql package
+// does, so that tools that get response JSON from elsewhere, such as from
+// queues or files, can decode it into the same structs as their queries:
+//
+//	var q struct {
+//		Viewer struct {
+//			Login graphql.String
+//		}
+//	}
+//	err := jsonutil.UnmarshalGraphQL(data, &q)
+//
+// Unlike encoding/json, it matches object keys to struct fields the way
+// GraphQL names them: by the response key in a field's graphql tag, which
+// may be an alias, or else by the field's name, ignoring case. Fields of
+// inline fragments (tags like `graphql:"... on User"`) and of embedded
+// structs are decoded from the keys of the object they're in, and if the
+// object has a __typename, only the fragment on that type is kept. Map
+// fields are decoded from whole objects, and json.RawMessage fields keep
+// the JSON of their values as is; so does a json.RawMessage given as the
+// whole data structure. Custom scalars, which have an UnmarshalGQL method
+// as graphql.Unmarshaler does, are given their whole values. Keys with no
+// struct field to decode them into are an error, and so are null or
+// missing values of fields whose tags have the required option, such as
+// `graphql:"login,required"`.
+//
+// Options control progress reporting, hooks called with each decoded
+// value, field naming and the maximum nesting depth.
+package jsonutil
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// UnmarshalGraphQL parses the JSON-encoded GraphQL response data and stores
+// the result in the GraphQL query data structure pointed to by v.
+//
+// The implementation is created on top of the JSON tokenizer available
+// in "encoding/json".Decoder.
+func UnmarshalGraphQL(data []byte, v interface{}) error {
+	return UnmarshalGraphQLProgress(data, v, nil)
+}
+
+// UnmarshalGraphQLProgress is like UnmarshalGraphQL, but calls objectDecoded,
+// if it's not nil, each time a JSON object has been decoded.
+func UnmarshalGraphQLProgress(data []byte, v interface{}, objectDecoded func()) error {
+	return UnmarshalGraphQLOptions(data, v, Options{ObjectDecoded: objectDecoded})
+}
+
+// Options are options for decoding.
+type Options struct {
+	// ObjectDecoded, if not nil, is called each time a JSON object
+	// has been decoded.
+	ObjectDecoded func()
+
+	// Hook, if not nil, is called with each struct field and slice element
+	// once its value has been decoded, and f is the struct field, or nil
+	// for slice elements. An error stops decoding.
+	Hook func(f *reflect.StructField, v reflect.Value) error
+
+	// MaxDepth, if positive, is the deepest nesting of JSON objects and
+	// arrays to decode, instead of DefaultMaxDepth. Deeper input fails
+	// with a *DepthError.
+	MaxDepth int
+
+	// FieldName, if not nil, names the response keys of struct fields
+	// without a graphql tag, given their Go names. By default, keys match
+	// such fields by name, ignoring case.
+	FieldName func(goName string) string
+
+	// BytesEncoding, if not nil, decodes strings into byte slices, instead
+	// of the standard base64 encoding that encoding/json uses. Byte slice
+	// types that unmarshal themselves are decoded by their methods.
+	BytesEncoding *base64.Encoding
+}
+
+// UnmarshalGraphQLOptions is like UnmarshalGraphQL, but with options.
+func UnmarshalGraphQLOptions(data []byte, v interface{}, opts Options) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	err := newDecoder(dec, opts).Decode(v)
+	if err != nil {
+		return err
+	}
+	tok, err := dec.Token()
+	switch err {
+	case io.EOF:
+		// Expect to get io.EOF. There shouldn't be any more
+		// tokens left after we've decoded v successfully.
+		return nil
+	case nil:
+		return fmt.Errorf("invalid token '%v' after top-level value", tok)
+	default:
+		return err
+	}
+}
+
+// DecodeGraphQL decodes the next JSON value read from dec, which should have
+// UseNumber set, into the GraphQL query data structure pointed to by v. It
+// reads the value token by token, so the encoded value needn't be in memory.
+func DecodeGraphQL(dec *json.Decoder, v interface{}, opts Options) error {
+	return newDecoder(dec, opts).Decode(v)
+}
+
+func newDecoder(dec *json.Decoder, opts Options) *decoder {
+	d := &decoder{
+		tokenizer:     dec,
+		objectDecoded: opts.ObjectDecoded,
+		hook:          opts.Hook,
+		fieldName:     opts.FieldName,
+		maxDepth:      opts.MaxDepth,
+		bytesEncoding: opts.BytesEncoding,
+	}
+	if d.maxDepth <= 0 {
+		d.maxDepth = DefaultMaxDepth
+	}
+	return d
+}
+
+// DefaultMaxDepth is the deepest nesting of JSON objects and arrays decoded
+// unless Options say otherwise, to bound the work that a hostile server can
+// make a client do.
+const DefaultMaxDepth = 10000
+
+// DepthError is returned for JSON input nested deeper than MaxDepth levels.
+type DepthError struct {
+	MaxDepth int
+}
+
+func (e *DepthError) Error() string {
+	return fmt.Sprintf("JSON input nested deeper than %d levels", e.MaxDepth)
+}
+
+// decoder is a JSON decoder that performs custom unmarshaling behavior
+// for GraphQL query data structures. It's implemented on top of a JSON tokenizer.
+type decoder struct {
+	tokenizer interface {
+		Token() (json.Token, error)
+		Decode(v interface{}) error
+	}
+
+	// Stack of what part of input JSON we're in the middle of - objects, arrays.
+	parseState []json.Delim
+
+	// Stacks of values where to unmarshal.
+	// The top of each stack is the reflect.Value where to unmarshal next JSON value.
+	//
+	// The reason there's more than one stack is because we might be unmarshaling
+	// a single JSON value into multiple GraphQL fragments or embedded structs, so
+	// we keep track of them all.
+	vs [][]reflect.Value
+
+	// Stack of the objects we're in the middle of, for choosing which
+	// of their inline fragments to keep.
+	objects []object
+
+	// Path to the value being decoded: object keys and array indices.
+	// While a hook is called, hookPath is the path to its value.
+	path     []string
+	hookPath []string
+
+	// objectDecoded, if not nil, is called at the end of each object.
+	objectDecoded func()
+
+	// hook, if not nil, is called with each of pending once its value
+	// has been decoded.
+	hook    func(f *reflect.StructField, v reflect.Value) error
+	pending []pendingValue
+
+	// fieldName, if not nil, names the keys of untagged struct fields.
+	fieldName func(goName string) string
+
+	maxDepth int // Of nesting of JSON objects and arrays.
+
+	bytesEncoding *base64.Encoding // Of byte slices, if not nil.
+}
+
+// pendingValue is a struct field or slice element being decoded into.
+type pendingValue struct {
+	f     *reflect.StructField // Nil for slice elements.
+	v     reflect.Value
+	depth int      // Of parse state where its value starts.
+	path  []string // To its value.
+}
+
+// object is a JSON object being decoded.
+type object struct {
+	typename  string          // The value of its "__typename" key, if any.
+	fragments []fragment      // The inline fragments it's being decoded into.
+	required  []requiredField // The fields tagged required it's being decoded into.
+	path      []string        // To the object.
+}
+
+// requiredField is a struct field with a `graphql:",required"` tag option,
+// which must have a non-null value in the object it's decoded from.
+type requiredField struct {
+	key   string        // Its response key, for errors.
+	on    string        // The type condition of the inline fragment it's in, if any.
+	v     reflect.Value // The field.
+	found bool          // Whether it has had a non-null value.
+}
+
+// fragment is an inline fragment being decoded into.
+type fragment struct {
+	typeCondition string
+	v             reflect.Value
+}
+
+// Decode decodes a single JSON value from d.tokenizer into v.
+// Panics in decoding, such as for a value the reflect package can't set
+// or from a hook, are returned as errors giving the path to the value.
+func (d *decoder) Decode(v interface{}) (err error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr {
+		return fmt.Errorf("cannot decode into non-pointer %T", v)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			path := d.path
+			if d.hookPath != nil {
+				path = d.hookPath
+			}
+			err = fmt.Errorf("panic decoding %q into %T: %v", strings.Join(path, "."), v, r)
+		}
+	}()
+	if rv.Elem().Type() == rawMessageType {
+		// Keep the whole value as is.
+		return unexpectedEOF(d.tokenizer.Decode(v))
+	}
+	d.vs = [][]reflect.Value{{rv.Elem()}}
+	return d.decode()
+}
+
+// decode decodes a single JSON value from d.tokenizer into d.vs.
+func (d *decoder) decode() error {
+	// The loop invariant is that the top of each d.vs stack
+	// is where we try to unmarshal the next JSON value we see.
+	for len(d.vs) > 0 {
+		tok, err := d.tokenizer.Token()
+		if err == io.EOF {
+			return errors.New("unexpected end of JSON input")
+		} else if err != nil {
+			return err
+		}
+
+		switch {
+
+		// Are we inside an object and seeing next key (rather than end of object)?
+		case d.state() == '{' && tok != json.Delim('}'):
+			key, ok := tok.(string)
+			if !ok {
+				return errors.New("unexpected non-key in JSON input")
+			}
+			d.path = append(d.path, key)
+			someFieldExist := false
+			var required []reflect.Value
+			for i := range d.vs {
+				v := d.vs[i][len(d.vs[i])-1]
+				if v.Kind() == reflect.Ptr {
+					v = v.Elem()
+				}
+				var f reflect.Value
+				if v.Kind() == reflect.Struct {
+					var sf *reflect.StructField
+					f, sf = fieldByGraphQLName(v, key, d.fieldName)
+					if f.IsValid() {
+						someFieldExist = true
+						d.addPending(sf, f)
+						if isRequired(*sf) {
+							required = append(required, f)
+						}
+					}
+				}
+				d.vs[i] = append(d.vs[i], f)
+			}
+			if !someFieldExist && key != "__typename" {
+				// The type name is used to pick inline fragments even
+				// if it's not wanted itself.
+				return fmt.Errorf("struct field for %s doesn't exist in any of %v places to unmarshal",
+					key, len(d.vs))
+			}
+
+			if d.rawTarget() {
+				// Capture the value verbatim.
+				d.found(required)
+				if err := d.decodeRaw(); err != nil {
+					return err
+				}
+				continue
+			}
+
+			// We've just consumed the current token, which was the key.
+			// Read the next token, which should be the value, and let the rest of code process it.
+			tok, err = d.tokenizer.Token()
+			if err == io.EOF {
+				return errors.New("unexpected end of JSON input")
+			} else if err != nil {
+				return err
+			}
+			if tok != nil {
+				d.found(required)
+			}
+			if typename, ok := tok.(string); ok && key == "__typename" && len(d.objects) > 0 {
+				d.objects[len(d.objects)-1].typename = typename
+			}
+
+		// Are we inside an array and seeing next value (rather than end of array)?
+		case d.state() == '[' && tok != json.Delim(']'):
+			someSliceExist := false
+			for i := range d.vs {
+				v := d.vs[i][len(d.vs[i])-1]
+				if v.Kind() == reflect.Ptr {
+					v = v.Elem()
+				}
+				var f reflect.Value
+				if v.Kind() == reflect.Slice {
+					v.Set(reflect.Append(v, reflect.Zero(v.Type().Elem()))) // v = append(v, T).
+					f = v.Index(v.Len() - 1)
+					if !someSliceExist {
+						d.path = append(d.path, strconv.Itoa(v.Len()-1))
+					}
+					someSliceExist = true
+					d.addPending(nil, f)
+				}
+				d.vs[i] = append(d.vs[i], f)
+			}
+			if !someSliceExist {
+				return fmt.Errorf("slice doesn't exist in any of %v places to unmarshal", len(d.vs))
+			}
+		}
+
+		if tok == json.Delim('{') && d.mapTarget() || (tok == json.Delim('{') || tok == json.Delim('[')) && d.scalarTarget() {
+			// A schema-less object, decoded whole into the map, or
+			// the value of a custom scalar.
+			value, err := d.readValue(tok, len(d.parseState))
+			if err != nil {
+				return err
+			}
+			for i := range d.vs {
+				v := d.vs[i][len(d.vs[i])-1]
+				if !v.IsValid() {
+					continue
+				}
+				if err := unmarshalValue(value, v); err != nil {
+					return err
+				}
+			}
+			d.popAllVs()
+			if d.objectDecoded != nil && tok == json.Delim('{') {
+				d.objectDecoded()
+			}
+			if err := d.runHooks(); err != nil {
+				return err
+			}
+			continue
+		}
+
+		switch tok := tok.(type) {
+		case string, json.Number, bool, nil:
+			// Value.
+
+			for i := range d.vs {
+				v := d.vs[i][len(d.vs[i])-1]
+				if !v.IsValid() {
+					continue
+				}
+				var err error
+				if s, ok := tok.(string); ok && d.bytesEncoding != nil && isBytes(v.Type()) {
+					err = d.unmarshalBytes(s, v)
+				} else {
+					err = unmarshalValue(tok, v)
+				}
+				if err != nil {
+					return err
+				}
+			}
+			d.popAllVs()
+
+		case json.Delim:
+			switch tok {
+			case '{':
+				// Start of object.
+
+				if len(d.parseState) >= d.maxDepth {
+					return &DepthError{MaxDepth: d.maxDepth}
+				}
+				if !d.fits(reflect.Struct) {
+					return fmt.Errorf("struct doesn't exist in any of %v places to unmarshal", len(d.vs))
+				}
+				d.pushState(tok)
+				d.objects = append(d.objects, object{path: append([]string(nil), d.path...)})
+				obj := &d.objects[len(d.objects)-1]
+
+				// Places to look for GraphQL fragments/embedded structs, and
+				// the type conditions of the inline fragments they're in.
+				type place struct {
+					v  reflect.Value
+					on string
+				}
+				frontier := make([]place, len(d.vs))
+				for i := range d.vs {
+					v := d.vs[i][len(d.vs[i])-1]
+					frontier[i] = place{v: v}
+					// TODO: Do this recursively or not? Add a test case if needed.
+					if v.Kind() == reflect.Ptr && v.IsNil() {
+						v.Set(reflect.New(v.Type().Elem())) // v = new(T).
+					}
+				}
+				// Find GraphQL fragments/embedded structs recursively, adding to frontier
+				// as new ones are discovered and exploring them further.
+				for len(frontier) > 0 {
+					v, on := frontier[0].v, frontier[0].on
+					frontier = frontier[1:]
+					if v.Kind() == reflect.Ptr {
+						v = v.Elem()
+					}
+					if v.Kind() != reflect.Struct {
+						continue
+					}
+					for i := 0; i < v.NumField(); i++ {
+						if v.Type().Field(i).Tag.Get("graphql") == "-" {
+							// Excluded from the query, so not in the response.
+							continue
+						}
+						fieldOn := on
+						if c := typeCondition(v.Type().Field(i)); c != "" {
+							fieldOn = c
+							obj.fragments = append(obj.fragments, fragment{typeCondition: c, v: v.Field(i)})
+						}
+						if isGraphQLFragment(v.Type().Field(i)) || v.Type().Field(i).Anonymous {
+							// Add GraphQL fragment or embedded struct.
+							if f := v.Field(i); f.Kind() == reflect.Ptr && f.IsNil() && f.CanSet() {
+								f.Set(reflect.New(f.Type().Elem())) // f = new(T).
+							}
+							d.vs = append(d.vs, []reflect.Value{v.Field(i)})
+							frontier = append(frontier, place{v: v.Field(i), on: fieldOn})
+						} else if isRequired(v.Type().Field(i)) {
+							obj.required = append(obj.required, requiredField{key: responseKey(v.Type().Field(i), d.fieldName), on: on, v: v.Field(i)})
+						}
+					}
+				}
+			case '[':
+				// Start of array.
+
+				if len(d.parseState) >= d.maxDepth {
+					return &DepthError{MaxDepth: d.maxDepth}
+				}
+				if !d.fits(reflect.Slice) {
+					return fmt.Errorf("slice doesn't exist in any of %v places to unmarshal", len(d.vs))
+				}
+				d.pushState(tok)
+
+				for i := range d.vs {
+					v := d.vs[i][len(d.vs[i])-1]
+					// TODO: Confirm this is needed, write a test case.
+					//if v.Kind() == reflect.Ptr && v.IsNil() {
+					//	v.Set(reflect.New(v.Type().Elem())) // v = new(T).
+					//}
+
+					// Reset slice to empty (in case it had non-zero initial value).
+					if v.Kind() == reflect.Ptr {
+						v = v.Elem()
+					}
+					if v.Kind() != reflect.Slice {
+						continue
+					}
+					v.Set(reflect.MakeSlice(v.Type(), 0, 0)) // v = make(T, 0, 0).
+				}
+			case '}', ']':
+				// End of object or array.
+				d.popAllVs()
+				d.popState()
+				if tok == '}' {
+					if err := d.endObject(); err != nil {
+						return err
+					}
+					if d.objectDecoded != nil {
+						d.objectDecoded()
+					}
+				}
+			default:
+				return errors.New("unexpected delimiter in JSON input")
+			}
+		default:
+			return errors.New("unexpected token in JSON input")
+		}
+		if err := d.runHooks(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addPending adds a struct field or slice element whose value is about
+// to be decoded to d.pending, if there's a hook to call with it.
+func (d *decoder) addPending(f *reflect.StructField, v reflect.Value) {
+	if d.hook != nil {
+		path := append([]string(nil), d.path...)
+		d.pending = append(d.pending, pendingValue{f: f, v: v, depth: len(d.parseState), path: path})
+	}
+}
+
+// runHooks calls the hook with the pending values that have been decoded:
+// those whose values started at the current parse state, which must have
+// ended since they're not values that have yet to begin.
+func (d *decoder) runHooks() error {
+	for len(d.pending) > 0 {
+		p := d.pending[len(d.pending)-1]
+		if p.depth != len(d.parseState) {
+			return nil
+		}
+		d.pending = d.pending[:len(d.pending)-1]
+		d.hookPath = p.path
+		err := d.hook(p.f, p.v)
+		d.hookPath = nil
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var rawMessageType = reflect.TypeOf(json.RawMessage(nil))
+
+// rawTarget reports whether the next JSON value is to be unmarshaled into
+// a json.RawMessage, or a pointer to one, in any of d.vs.
+func (d *decoder) rawTarget() bool {
+	for i := range d.vs {
+		t := d.vs[i][len(d.vs[i])-1]
+		if !t.IsValid() {
+			continue
+		}
+		if t.Kind() == reflect.Ptr {
+			t = reflect.Zero(t.Type().Elem())
+		}
+		if t.Type() == rawMessageType {
+			return true
+		}
+	}
+	return false
+}
+
+// gqlUnmarshaler is implemented by custom scalars that decode themselves
+// from the JSON value, decoded as by encoding/json with UseNumber set. It's
+// graphql.Unmarshaler, which this package can't import.
+type gqlUnmarshaler interface {
+	UnmarshalGQL(v interface{}) error
+}
+
+var gqlUnmarshalerType = reflect.TypeOf((*gqlUnmarshaler)(nil)).Elem()
+
+// isGQLUnmarshaler reports whether values of type t are custom scalars,
+// or pointers to them.
+func isGQLUnmarshaler(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return reflect.PtrTo(t).Implements(gqlUnmarshalerType)
+}
+
+// unmarshalGQL unmarshals JSON value, decoded as by encoding/json, into
+// custom scalar v, or the custom scalar v points to. A null value leaves
+// a scalar as is, and sets a pointer to nil.
+func unmarshalGQL(value interface{}, v reflect.Value) error {
+	if value == nil {
+		if v.Kind() == reflect.Ptr {
+			v.Set(reflect.Zero(v.Type()))
+		}
+		return nil
+	}
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	if !v.CanAddr() {
+		return fmt.Errorf("value %v is not addressable", v)
+	}
+	return v.Addr().Interface().(gqlUnmarshaler).UnmarshalGQL(value)
+}
+
+// decodeRaw decodes the next JSON value into d.vs, keeping its encoding
+// as is for json.RawMessage values.
+func (d *decoder) decodeRaw() error {
+	var raw json.RawMessage
+	if err := d.tokenizer.Decode(&raw); err != nil {
+		return unexpectedEOF(err)
+	}
+	for i := range d.vs {
+		v := d.vs[i][len(d.vs[i])-1]
+		if !v.IsValid() {
+			continue
+		}
+		var err error
+		if t := v.Type(); t == rawMessageType || t.Kind() == reflect.Ptr && t.Elem() == rawMessageType {
+			err = json.Unmarshal(raw, v.Addr().Interface())
+		} else {
+			// Decode the value for other fields that share its key,
+			// as deep as is left.
+			err = d.decodeNested(raw, v)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	d.popAllVs()
+	return d.runHooks()
+}
+
+// decodeNested decodes JSON value raw, which is at the current parse state,
+// into v.
+func (d *decoder) decodeNested(raw json.RawMessage, v reflect.Value) error {
+	left := d.maxDepth - len(d.parseState)
+	if left <= 0 {
+		if raw[0] == '{' || raw[0] == '[' {
+			return &DepthError{MaxDepth: d.maxDepth}
+		}
+		left = 1 // Enough for a scalar.
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	return newDecoder(dec, Options{
+		ObjectDecoded: d.objectDecoded,
+		Hook:          d.hook,
+		MaxDepth:      left,
+		FieldName:     d.fieldName,
+		BytesEncoding: d.bytesEncoding,
+	}).Decode(v.Addr().Interface())
+}
+
+// fits reports whether a JSON object, for kind reflect.Struct, or array, for
+// kind reflect.Slice, can be unmarshaled into any of d.vs. It can if the value
+// isn't to be unmarshaled at all, as for keys without struct fields.
+func (d *decoder) fits(kind reflect.Kind) bool {
+	targets := false
+	for i := range d.vs {
+		v := d.vs[i][len(d.vs[i])-1]
+		if !v.IsValid() {
+			continue
+		}
+		targets = true
+		t := v.Type()
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Kind() == kind {
+			return true
+		}
+	}
+	return !targets
+}
+
+// mapTarget reports whether the next JSON value is to be unmarshaled
+// into a map in any of d.vs.
+func (d *decoder) mapTarget() bool {
+	for i := range d.vs {
+		v := d.vs[i][len(d.vs[i])-1]
+		if v.Kind() == reflect.Ptr {
+			v = reflect.Zero(v.Type().Elem())
+		}
+		if v.Kind() == reflect.Map {
+			return true
+		}
+	}
+	return false
+}
+
+// scalarTarget reports whether any of d.vs is a custom scalar, or a pointer
+// to one, to unmarshal the next JSON value into whole.
+func (d *decoder) scalarTarget() bool {
+	for i := range d.vs {
+		if v := d.vs[i][len(d.vs[i])-1]; v.IsValid() && isGQLUnmarshaler(v.Type()) {
+			return true
+		}
+	}
+	return false
+}
+
+// readValue reads the rest of the JSON value that starts with tok,
+// returning it as the generic values encoding/json decodes into
+// an interface{}, except that numbers are json.Number. depth is the
+// nesting depth of the value.
+func (d *decoder) readValue(tok json.Token, depth int) (interface{}, error) {
+	if (tok == json.Delim('{') || tok == json.Delim('[')) && depth >= d.maxDepth {
+		return nil, &DepthError{MaxDepth: d.maxDepth}
+	}
+	switch tok {
+	case json.Delim('{'):
+		m := map[string]interface{}{}
+		for {
+			key, err := d.tokenizer.Token()
+			if err != nil {
+				return nil, unexpectedEOF(err)
+			}
+			if key == json.Delim('}') {
+				return m, nil
+			}
+			k, ok := key.(string)
+			if !ok {
+				return nil, errors.New("unexpected non-key in JSON input")
+			}
+			tok, err := d.tokenizer.Token()
+			if err != nil {
+				return nil, unexpectedEOF(err)
+			}
+			if m[k], err = d.readValue(tok, depth+1); err != nil {
+				return nil, err
+			}
+		}
+	case json.Delim('['):
+		a := []interface{}{}
+		for {
+			tok, err := d.tokenizer.Token()
+			if err != nil {
+				return nil, unexpectedEOF(err)
+			}
+			if tok == json.Delim(']') {
+				return a, nil
+			}
+			e, err := d.readValue(tok, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			a = append(a, e)
+		}
+	case json.Delim('}'), json.Delim(']'):
+		return nil, errors.New("unexpected delimiter in JSON input")
+	}
+	return tok, nil
+}
+
+// unexpectedEOF returns err, or a more descriptive error if it's io.EOF.
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return errors.New("unexpected end of JSON input")
+	}
+	return err
+}
+
+// endObject pops the object that has just been decoded off the stack.
+// If its type name matches the type condition of one of its inline
+// fragments, the fragments for other types are reset, so that only the
+// matching branch of a union or interface is populated. Otherwise, such
+// as when the fragments are on interfaces, all of them are kept.
+func (d *decoder) endObject() error {
+	obj := d.objects[len(d.objects)-1]
+	d.objects = d.objects[:len(d.objects)-1]
+	for _, r := range obj.required {
+		// Required fields in inline fragments are only checked if the
+		// object's type name says it's of the fragment's type.
+		if r.found || r.on != "" && r.on != obj.typename {
+			continue
+		}
+		return fmt.Errorf("required field %q is null or missing", strings.Join(append(obj.path, r.key), "."))
+	}
+	if obj.typename == "" {
+		return nil
+	}
+	matched := false
+	for _, f := range obj.fragments {
+		if f.typeCondition == obj.typename {
+			matched = true
+			break
+		}
+	}
+	if !matched {
+		return nil
+	}
+	for _, f := range obj.fragments {
+		if f.typeCondition != obj.typename {
+			f.v.Set(reflect.Zero(f.v.Type()))
+		}
+	}
+	return nil
+}
+
+// found marks the required fields of the object being decoded that are
+// among fields as having had non-null values.
+func (d *decoder) found(fields []reflect.Value) {
+	if len(fields) == 0 {
+		return
+	}
+	obj := &d.objects[len(d.objects)-1]
+	for i := range obj.required {
+		r := &obj.required[i]
+		for _, f := range fields {
+			if r.v.Type() == f.Type() && r.v.CanAddr() && f.CanAddr() && r.v.UnsafeAddr() == f.UnsafeAddr() {
+				r.found = true
+			}
+		}
+	}
+}
+
+// pushState pushes a new parse state s onto the stack.
+func (d *decoder) pushState(s json.Delim) {
+	d.parseState = append(d.parseState, s)
+}
+
+// popState pops a parse state (already obtained) off the stack.
+// The stack must be non-empty.
+func (d *decoder) popState() {
+	d.parseState = d.parseState[:len(d.parseState)-1]
+}
+
+// state reports the parse state on top of stack, or 0 if empty.
+func (d *decoder) state() json.Delim {
+	if len(d.parseState) == 0 {
+		return 0
+	}
+	return d.parseState[len(d.parseState)-1]
+}
+
+// popAllVs pops from all d.vs stacks, keeping only non-empty ones.
+func (d *decoder) popAllVs() {
+	var nonEmpty [][]reflect.Value
+	for i := range d.vs {
+		d.vs[i] = d.vs[i][:len(d.vs[i])-1]
+		if len(d.vs[i]) > 0 {
+			nonEmpty = append(nonEmpty, d.vs[i])
+		}
+	}
+	d.vs = nonEmpty
+	if len(d.path) > 0 {
+		d.path = d.path[:len(d.path)-1]
+	}
+}
+
+// fieldByGraphQLName returns a struct field of struct v that matches GraphQL name,
+// and its description, or invalid reflect.Value if none found. Untagged fields
+// are named by fieldName, if it's not nil.
+func fieldByGraphQLName(v reflect.Value, name string, fieldName func(string) string) (reflect.Value, *reflect.StructField) {
+	for i := 0; i < v.NumField(); i++ {
+		if f := v.Type().Field(i); hasGraphQLName(f, name, fieldName) {
+			return v.Field(i), &f
+		}
+	}
+	return reflect.Value{}, nil
+}
+
+// hasGraphQLName reports whether struct field f has GraphQL name.
+func hasGraphQLName(f reflect.StructField, name string, fieldName func(string) string) bool {
+	value, ok := graphqlTag(f)
+	if !ok && fieldName != nil {
+		return fieldName(f.Name) == name
+	}
+	if !ok {
+		// TODO: caseconv package is relatively slow. Optimize it, then consider using it here.
+		//return caseconv.MixedCapsToLowerCamelCase(f.Name) == name
+		return strings.EqualFold(f.Name, name)
+	}
+	value = strings.TrimSpace(value) // TODO: Parse better.
+	if strings.HasPrefix(value, "...") || value == "-" {
+		// GraphQL fragment, or a field excluded from the query.
+		// Neither has a name.
+		return false
+	}
+	if i := strings.IndexAny(value, "(@{"); i != -1 {
+		// Cut arguments, directives and selections.
+		value = value[:i]
+	}
+	if i := strings.Index(value, ":"); i != -1 {
+		value = value[:i]
+	}
+	return strings.TrimSpace(value) == name
+}
+
+// responseKey returns the key of struct field f in responses, for errors:
+// the alias or name in its graphql tag, or else its name, as fieldName
+// gives it if it's not nil.
+func responseKey(f reflect.StructField, fieldName func(string) string) string {
+	value, ok := graphqlTag(f)
+	if !ok {
+		if fieldName != nil {
+			return fieldName(f.Name)
+		}
+		return f.Name
+	}
+	if i := strings.IndexAny(value, "(@{"); i != -1 {
+		value = value[:i]
+	}
+	if i := strings.Index(value, ":"); i != -1 {
+		value = value[:i]
+	}
+	return strings.TrimSpace(value)
+}
+
+// graphqlTag returns the selection in struct field f's graphql tag, without
+// the options that may follow it after a comma, and whether f has one, the
+// way the graphql package reads tags.
+func graphqlTag(f reflect.StructField) (string, bool) {
+	value, ok := f.Tag.Lookup("graphql")
+	if !ok {
+		return "", false
+	}
+	value, _ = splitTagOptions(value)
+	if value == "" {
+		return "", false
+	}
+	return value, true
+}
+
+// isRequired reports whether struct field f has the required option in its
+// graphql tag, as in `graphql:",required"` or `graphql:"login,required"`.
+func isRequired(f reflect.StructField) bool {
+	value, ok := f.Tag.Lookup("graphql")
+	if !ok {
+		return false
+	}
+	_, options := splitTagOptions(value)
+	for _, o := range strings.Split(options, ",") {
+		if strings.TrimSpace(o) == "required" {
+			return true
+		}
+	}
+	return false
+}
+
+// splitTagOptions splits graphql tag value into the selection and the
+// options after its first comma outside of arguments, selection sets and
+// strings.
+func splitTagOptions(value string) (selection, options string) {
+	depth, inString := 0, false
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; {
+		case inString:
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+		case c == '(' || c == '{' || c == '[':
+			depth++
+		case c == ')' || c == '}' || c == ']':
+			depth--
+		case c == ',' && depth == 0:
+			return value[:i], value[i+1:]
+		}
+	}
+	return value, ""
+}
+
+// isGraphQLFragment reports whether struct field f is a GraphQL fragment.
+func isGraphQLFragment(f reflect.StructField) bool {
+	value, ok := graphqlTag(f)
+	if !ok {
+		return false
+	}
+	value = strings.TrimSpace(value) // TODO: Parse better.
+	return strings.HasPrefix(value, "...")
+}
+
+// typeCondition returns the type condition of the inline fragment that
+// struct field f is, or "" if it's not one or has none.
+func typeCondition(f reflect.StructField) string {
+	value, ok := graphqlTag(f)
+	if !ok {
+		return ""
+	}
+	value = strings.TrimSpace(value)
+	if !strings.HasPrefix(value, "...") {
+		return ""
+	}
+	fields := strings.Fields(strings.TrimPrefix(value, "..."))
+	if len(fields) < 2 || fields[0] != "on" {
+		return ""
+	}
+	return fields[1]
+}
+
+var (
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// isBytes reports whether t is a byte slice type, or a pointer to one,
+// that doesn't unmarshal itself.
+func isBytes(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Slice || t.Elem().Kind() != reflect.Uint8 {
+		return false
+	}
+	p := reflect.PtrTo(t)
+	return !p.Implements(jsonUnmarshalerType) && !p.Implements(textUnmarshalerType) && !p.Implements(gqlUnmarshalerType)
+}
+
+// settable returns v, or the value v points to, allocating it if v is nil.
+func settable(v reflect.Value) reflect.Value {
+	if v.Kind() != reflect.Ptr {
+		return v
+	}
+	if v.IsNil() {
+		v.Set(reflect.New(v.Type().Elem()))
+	}
+	return v.Elem()
+}
+
+// unmarshalBytes decodes s into byte slice v, or the byte slice v points
+// to, with d.bytesEncoding.
+func (d *decoder) unmarshalBytes(s string, v reflect.Value) error {
+	b, err := d.bytesEncoding.DecodeString(s)
+	if err != nil {
+		return err
+	}
+	settable(v).SetBytes(b)
+	return nil
+}
+
+// unmarshalValue unmarshals JSON value into v.
+func unmarshalValue(value json.Token, v reflect.Value) error {
+	if isGQLUnmarshaler(v.Type()) {
+		return unmarshalGQL(value, v)
+	}
+	t := v.Type()
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch value := value.(type) {
+	case string:
+		if t == durationType {
+			d, err := parseDuration(value)
+			if err != nil {
+				return err
+			}
+			settable(v).SetInt(int64(d))
+			return nil
+		}
+	case json.Number:
+		// Numbers are given whole to types that unmarshal text, such
+		// as decimals, so that no precision is lost.
+		if p := reflect.PtrTo(t); p.Implements(textUnmarshalerType) && !p.Implements(jsonUnmarshalerType) {
+			return settable(v).Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value))
+		}
+	}
+	b, err := json.Marshal(value) // TODO: Short-circuit (if profiling says it's worth it).
+	if err != nil {
+		return err
+	}
+	if !v.CanAddr() {
+		return fmt.Errorf("value %v is not addressable", v)
+	}
+	return json.Unmarshal(b, v.Addr().Interface())
+}
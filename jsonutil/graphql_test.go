@@ -0,0 +1,829 @@
+package jsonutil_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dbmedialab/go-graphql-client"
+	"github.com/dbmedialab/go-graphql-client/jsonutil"
+)
+
+func TestUnmarshalGraphQL(t *testing.T) {
+	/*
+		query {
+			me {
+				name
+				height
+			}
+		}
+	*/
+	type query struct {
+		Me struct {
+			Name   graphql.String
+			Height graphql.Float
+		}
+	}
+	var got query
+	err := jsonutil.UnmarshalGraphQL([]byte(`{
+		"me": {
+			"name": "Luke Skywalker",
+			"height": 1.72
+		}
+	}`), &got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want query
+	want.Me.Name = "Luke Skywalker"
+	want.Me.Height = 1.72
+	if !reflect.DeepEqual(got, want) {
+		t.Error("not equal")
+	}
+}
+
+func TestUnmarshalGraphQL_graphqlTag(t *testing.T) {
+	type query struct {
+		Foo graphql.String `graphql:"baz"`
+	}
+	var got query
+	err := jsonutil.UnmarshalGraphQL([]byte(`{
+		"baz": "bar"
+	}`), &got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := query{
+		Foo: "bar",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Error("not equal")
+	}
+}
+
+func TestUnmarshalGraphQL_directives(t *testing.T) {
+	type query struct {
+		Comments []graphql.String `graphql:"comments @include(if: $withComments)"`
+		Title    graphql.String   `graphql:"t: title @skip(if: $short)"`
+		Body     graphql.String   `graphql:"body(format: TEXT) @skip(if: $short)"`
+	}
+	var got query
+	err := jsonutil.UnmarshalGraphQL([]byte(`{
+		"comments": ["first"],
+		"t": "Hello",
+		"body": "World"
+	}`), &got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := query{
+		Comments: []graphql.String{"first"},
+		Title:    "Hello",
+		Body:     "World",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Error("not equal")
+	}
+}
+
+func TestUnmarshalGraphQL_excluded(t *testing.T) {
+	type bookkeeping struct {
+		Seen bool
+	}
+	type query struct {
+		Foo         graphql.String
+		Synced      bool `graphql:"-"`
+		bookkeeping `graphql:"-"`
+	}
+	got := query{Synced: true}
+	err := jsonutil.UnmarshalGraphQL([]byte(`{
+		"foo": "bar"
+	}`), &got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := query{
+		Foo:    "bar",
+		Synced: true,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Error("not equal")
+	}
+
+	// A response key that happens to be "-" doesn't match excluded fields.
+	err = jsonutil.UnmarshalGraphQL([]byte(`{"-": true}`), &got)
+	if err == nil {
+		t.Error("got error: nil, want: non-nil")
+	}
+}
+
+func TestUnmarshalGraphQL_jsonTag(t *testing.T) {
+	type query struct {
+		Foo graphql.String `json:"baz"`
+	}
+	var got query
+	err := jsonutil.UnmarshalGraphQL([]byte(`{
+		"foo": "bar"
+	}`), &got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := query{
+		Foo: "bar",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Error("not equal")
+	}
+}
+
+func TestUnmarshalGraphQL_array(t *testing.T) {
+	type query struct {
+		Foo []graphql.String
+		Bar []graphql.String
+		Baz []graphql.String
+	}
+	var got query
+	err := jsonutil.UnmarshalGraphQL([]byte(`{
+		"foo": [
+			"bar",
+			"baz"
+		],
+		"bar": [],
+		"baz": null
+	}`), &got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := query{
+		Foo: []graphql.String{"bar", "baz"},
+		Bar: []graphql.String{},
+		Baz: []graphql.String(nil),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Error("not equal")
+	}
+}
+
+// When unmarshaling into an array, its initial value should be overwritten
+// (rather than appended to).
+func TestUnmarshalGraphQL_arrayReset(t *testing.T) {
+	var got = []string{"initial"}
+	err := jsonutil.UnmarshalGraphQL([]byte(`["bar", "baz"]`), &got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"bar", "baz"}
+	if !reflect.DeepEqual(got, want) {
+		t.Error("not equal")
+	}
+}
+
+func TestUnmarshalGraphQL_objectArray(t *testing.T) {
+	type query struct {
+		Foo []struct {
+			Name graphql.String
+		}
+	}
+	var got query
+	err := jsonutil.UnmarshalGraphQL([]byte(`{
+		"foo": [
+			{"name": "bar"},
+			{"name": "baz"}
+		]
+	}`), &got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := query{
+		Foo: []struct{ Name graphql.String }{
+			{"bar"},
+			{"baz"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Error("not equal")
+	}
+}
+
+func TestUnmarshalGraphQL_pointer(t *testing.T) {
+	type query struct {
+		Foo *graphql.String
+		Bar *graphql.String
+	}
+	var got query
+	got.Bar = new(graphql.String) // Test that got.Bar gets set to nil.
+	err := jsonutil.UnmarshalGraphQL([]byte(`{
+		"foo": "foo",
+		"bar": null
+	}`), &got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := query{
+		Foo: graphql.NewString("foo"),
+		Bar: nil,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Error("not equal")
+	}
+}
+
+func TestUnmarshalGraphQL_objectPointerArray(t *testing.T) {
+	type query struct {
+		Foo []*struct {
+			Name graphql.String
+		}
+	}
+	var got query
+	err := jsonutil.UnmarshalGraphQL([]byte(`{
+		"foo": [
+			{"name": "bar"},
+			null,
+			{"name": "baz"}
+		]
+	}`), &got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := query{
+		Foo: []*struct{ Name graphql.String }{
+			{"bar"},
+			nil,
+			{"baz"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Error("not equal")
+	}
+}
+
+func TestUnmarshalGraphQL_pointerWithInlineFragment(t *testing.T) {
+	type actor struct {
+		User struct {
+			DatabaseID uint64
+		} `graphql:"... on User"`
+		Login string
+	}
+	type query struct {
+		Author actor
+		Editor *actor
+	}
+	var got query
+	err := jsonutil.UnmarshalGraphQL([]byte(`{
+		"author": {
+			"databaseId": 1,
+			"login": "test1"
+		},
+		"editor": {
+			"databaseId": 2,
+			"login": "test2"
+		}
+	}`), &got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want query
+	want.Author = actor{
+		User:  struct{ DatabaseID uint64 }{1},
+		Login: "test1",
+	}
+	want.Editor = &actor{
+		User:  struct{ DatabaseID uint64 }{2},
+		Login: "test2",
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Error("not equal")
+	}
+}
+
+func TestUnmarshalGraphQL_multipleValues(t *testing.T) {
+	type query struct {
+		Foo graphql.String
+	}
+	err := jsonutil.UnmarshalGraphQL([]byte(`{"foo": "bar"}{"foo": "baz"}`), new(query))
+	if err == nil {
+		t.Fatal("got error: nil, want: non-nil")
+	}
+	if got, want := err.Error(), "invalid token '{' after top-level value"; got != want {
+		t.Errorf("got error: %v, want: %v", got, want)
+	}
+}
+
+func TestUnmarshalGraphQL_union(t *testing.T) {
+	/*
+		{
+			__typename
+			... on ClosedEvent {
+				createdAt
+				actor {login}
+			}
+			... on ReopenedEvent {
+				createdAt
+				actor {login}
+			}
+		}
+	*/
+	type actor struct{ Login graphql.String }
+	type closedEvent struct {
+		Actor     actor
+		CreatedAt time.Time
+	}
+	type reopenedEvent struct {
+		Actor     actor
+		CreatedAt time.Time
+	}
+	type issueTimelineItem struct {
+		Typename      string        `graphql:"__typename"`
+		ClosedEvent   closedEvent   `graphql:"... on ClosedEvent"`
+		ReopenedEvent reopenedEvent `graphql:"... on ReopenedEvent"`
+	}
+	var got issueTimelineItem
+	err := jsonutil.UnmarshalGraphQL([]byte(`{
+		"__typename": "ClosedEvent",
+		"createdAt": "2017-06-29T04:12:01Z",
+		"actor": {
+			"login": "shurcooL-test"
+		}
+	}`), &got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := issueTimelineItem{
+		Typename: "ClosedEvent",
+		ClosedEvent: closedEvent{
+			Actor: actor{
+				Login: "shurcooL-test",
+			},
+			CreatedAt: time.Unix(1498709521, 0).UTC(),
+		},
+		// Only the branch matching __typename is populated.
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Error("not equal")
+	}
+}
+
+func TestUnmarshalGraphQL_unionWithoutTypenameField(t *testing.T) {
+	type closedEvent struct {
+		CreatedAt time.Time
+	}
+	type reopenedEvent struct {
+		CreatedAt time.Time
+	}
+	type node struct {
+		ID graphql.ID
+	}
+	type issueTimelineItem struct {
+		ClosedEvent   *closedEvent  `graphql:"... on ClosedEvent"`
+		ReopenedEvent reopenedEvent `graphql:"... on ReopenedEvent"`
+	}
+	var got []issueTimelineItem
+	err := jsonutil.UnmarshalGraphQL([]byte(`[
+		{"__typename": "ReopenedEvent", "createdAt": "2017-06-29T04:12:01Z"},
+		{"__typename": "ClosedEvent", "createdAt": "2017-06-29T04:12:01Z"}
+	]`), &got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []issueTimelineItem{
+		{ReopenedEvent: reopenedEvent{CreatedAt: time.Unix(1498709521, 0).UTC()}},
+		{ClosedEvent: &closedEvent{CreatedAt: time.Unix(1498709521, 0).UTC()}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	// Fragments on interfaces are all kept, as the type name doesn't match any.
+	type interfaceItem struct {
+		Node node `graphql:"... on Node"`
+	}
+	var gotItem interfaceItem
+	err = jsonutil.UnmarshalGraphQL([]byte(`{"__typename": "Issue", "id": "1"}`), &gotItem)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotItem.Node.ID != "1" {
+		t.Errorf("got %+v, want node ID 1", gotItem)
+	}
+}
+
+// Issue https://github.com/shurcooL/githubql/issues/18.
+func TestUnmarshalGraphQL_arrayInsideInlineFragment(t *testing.T) {
+	/*
+		query {
+			search(type: ISSUE, first: 1, query: "type:pr repo:owner/name") {
+				nodes {
+					... on PullRequest {
+						commits(last: 1) {
+							nodes {
+								url
+							}
+						}
+					}
+				}
+			}
+		}
+	*/
+	type query struct {
+		Search struct {
+			Nodes []struct {
+				PullRequest struct {
+					Commits struct {
+						Nodes []struct {
+							URL string `graphql:"url"`
+						}
+					} `graphql:"commits(last: 1)"`
+				} `graphql:"... on PullRequest"`
+			}
+		} `graphql:"search(type: ISSUE, first: 1, query: \"type:pr repo:owner/name\")"`
+	}
+	var got query
+	err := jsonutil.UnmarshalGraphQL([]byte(`{
+		"search": {
+			"nodes": [
+				{
+					"commits": {
+						"nodes": [
+							{
+								"url": "https://example.org/commit/49e1"
+							}
+						]
+					}
+				}
+			]
+		}
+	}`), &got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want query
+	want.Search.Nodes = make([]struct {
+		PullRequest struct {
+			Commits struct {
+				Nodes []struct {
+					URL string `graphql:"url"`
+				}
+			} `graphql:"commits(last: 1)"`
+		} `graphql:"... on PullRequest"`
+	}, 1)
+	want.Search.Nodes[0].PullRequest.Commits.Nodes = make([]struct {
+		URL string `graphql:"url"`
+	}, 1)
+	want.Search.Nodes[0].PullRequest.Commits.Nodes[0].URL = "https://example.org/commit/49e1"
+	if !reflect.DeepEqual(got, want) {
+		t.Error("not equal")
+	}
+}
+
+func TestUnmarshalGraphQL_map(t *testing.T) {
+	type query struct {
+		Page struct {
+			Title  string
+			Blocks []map[string]interface{}   `graphql:"blocks{type,data}"`
+			Meta   map[string]json.RawMessage `graphql:"meta"`
+		}
+	}
+	var got query
+	err := jsonutil.UnmarshalGraphQL([]byte(`{
+		"page": {
+			"title": "Home",
+			"blocks": [
+				{"type": "text", "data": {"body": "Hi", "tags": ["a", 1]}},
+				{"type": "image", "data": null}
+			],
+			"meta": {"views": 12, "author": {"name": "gopher"}}
+		}
+	}`), &got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want query
+	want.Page.Title = "Home"
+	want.Page.Blocks = []map[string]interface{}{
+		{"type": "text", "data": map[string]interface{}{"body": "Hi", "tags": []interface{}{"a", float64(1)}}},
+		{"type": "image", "data": nil},
+	}
+	want.Page.Meta = map[string]json.RawMessage{
+		"views":  json.RawMessage(`12`),
+		"author": json.RawMessage(`{"name":"gopher"}`),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("not equal:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestUnmarshalGraphQLOptions_hook(t *testing.T) {
+	type query struct {
+		Viewer struct {
+			Login string
+			Tags  []string
+		}
+	}
+	var got []string
+	hook := func(f *reflect.StructField, v reflect.Value) error {
+		name := "[]"
+		if f != nil {
+			name = f.Name
+		}
+		got = append(got, fmt.Sprintf("%s=%v", name, v.Interface()))
+		return nil
+	}
+	var q query
+	err := jsonutil.UnmarshalGraphQLOptions([]byte(`{"viewer": {"login": "gopher", "tags": ["a", "b"]}}`), &q, jsonutil.Options{Hook: hook})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"Login=gopher",
+		"[]=a",
+		"[]=b",
+		"Tags=[a b]",
+		"Viewer={gopher [a b]}",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got hook calls:\n%q\nwant:\n%q", got, want)
+	}
+}
+
+func TestUnmarshalGraphQL_panic(t *testing.T) {
+	type query struct {
+		Events []struct {
+			name string `graphql:"name"`
+		}
+	}
+	var got query
+	err := jsonutil.UnmarshalGraphQL([]byte(`{"events": [{"name": "a"}]}`), &got)
+	if err == nil || !strings.HasPrefix(err.Error(), `panic decoding "events.0.name" into *jsonutil_test.query: `) {
+		t.Errorf("got error: %v, want a panic decoding events.0.name", err)
+	}
+
+	hook := func(f *reflect.StructField, v reflect.Value) error {
+		panic("boom")
+	}
+	err = jsonutil.UnmarshalGraphQLOptions([]byte(`{"events": []}`), &got, jsonutil.Options{Hook: hook})
+	if got, want := err, `panic decoding "events" into *jsonutil_test.query: boom`; got == nil || got.Error() != want {
+		t.Errorf("got error: %v, want: %v", got, want)
+	}
+}
+
+func TestUnmarshalGraphQL_mismatchedTypes(t *testing.T) {
+	type query struct {
+		Str    graphql.String
+		List   []graphql.Int
+		Object *struct{ Name graphql.String }
+	}
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{`{"str": {}}`, "struct doesn't exist in any of 1 places to unmarshal"},
+		{`{"str": []}`, "slice doesn't exist in any of 1 places to unmarshal"},
+		{`{"list": {}}`, "struct doesn't exist in any of 1 places to unmarshal"},
+		{`{"object": []}`, "slice doesn't exist in any of 1 places to unmarshal"},
+		{`{"object": 1}`, "json: cannot unmarshal number into Go value of type struct { Name graphql.String }"},
+		{`[]`, "slice doesn't exist in any of 1 places to unmarshal"},
+	}
+	for _, tc := range tests {
+		var got query
+		err := jsonutil.UnmarshalGraphQL([]byte(tc.in), &got)
+		if err == nil || err.Error() != tc.want {
+			t.Errorf("%s: got error: %v, want: %v", tc.in, err, tc.want)
+		}
+	}
+}
+
+func TestUnmarshalGraphQLOptions_maxDepth(t *testing.T) {
+	type query struct {
+		Object struct {
+			Map map[string]interface{} `graphql:"map"`
+		}
+	}
+	tests := []struct {
+		in       string
+		maxDepth int
+		wantErr  bool
+	}{
+		{`{"object": {"map": {"a": [1]}}}`, 4, false},
+		{`{"object": {"map": {"a": [1]}}}`, 3, true},
+		{`{"object": {"map": {}}}`, 2, true},
+		{`{"object": {}}`, 1, true},
+	}
+	for _, tc := range tests {
+		var got query
+		err := jsonutil.UnmarshalGraphQLOptions([]byte(tc.in), &got, jsonutil.Options{MaxDepth: tc.maxDepth})
+		if !tc.wantErr {
+			if err != nil {
+				t.Errorf("%s with max depth %d: %v", tc.in, tc.maxDepth, err)
+			}
+			continue
+		}
+		if e, ok := err.(*jsonutil.DepthError); !ok || e.MaxDepth != tc.maxDepth {
+			t.Errorf("%s with max depth %d: got error %v, want a *DepthError", tc.in, tc.maxDepth, err)
+		}
+	}
+}
+
+func TestUnmarshalGraphQL_rawMessage(t *testing.T) {
+	type query struct {
+		Settings json.RawMessage  `graphql:"settings{theme,locale}"`
+		Tags     json.RawMessage  `graphql:"tags"`
+		Extra    *json.RawMessage `graphql:"extra"`
+		Missing  *json.RawMessage `graphql:"missing"`
+		Node     json.RawMessage  `graphql:"node"`
+		Fragment struct {
+			Node struct {
+				ID graphql.ID
+			}
+		} `graphql:"... on Query"`
+		After graphql.String
+	}
+	var got query
+	err := jsonutil.UnmarshalGraphQL([]byte(`{
+		"settings": {"theme": "dark",  "locale": null},
+		"tags": ["a", 1],
+		"extra": 1e999,
+		"missing": null,
+		"node": {"id": "1"},
+		"after": "x"
+	}`), &got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(got.Settings), `{"theme": "dark",  "locale": null}`; got != want {
+		t.Errorf("got settings: %s, want: %s", got, want)
+	}
+	if got, want := string(got.Tags), `["a", 1]`; got != want {
+		t.Errorf("got tags: %s, want: %s", got, want)
+	}
+	if got.Extra == nil || string(*got.Extra) != "1e999" {
+		t.Errorf("got extra: %v, want: 1e999", got.Extra)
+	}
+	if got.Missing != nil {
+		t.Errorf("got missing: %s, want: nil", *got.Missing)
+	}
+	if got, want := string(got.Node), `{"id": "1"}`; got != want {
+		t.Errorf("got raw node: %s, want: %s", got, want)
+	}
+	if got.Fragment.Node.ID != "1" {
+		t.Errorf("got node ID: %v, want: 1", got.Fragment.Node.ID)
+	}
+	if got.After != "x" {
+		t.Errorf("got after: %q, want: x", got.After)
+	}
+}
+
+func TestUnmarshalGraphQLOptions_rawMessageShared(t *testing.T) {
+	// Fields sharing a key with a json.RawMessage are decoded with the
+	// same options as the rest.
+	var got struct {
+		Raw      json.RawMessage `graphql:"data"`
+		Node     json.RawMessage `graphql:"node"`
+		Fragment struct {
+			Data []byte
+			Node struct{ ID graphql.ID }
+		} `graphql:"... on Query"`
+	}
+	objects := 0
+	err := jsonutil.UnmarshalGraphQLOptions([]byte(`{"data": "aGk_", "node": {"id": "1"}}`), &got, jsonutil.Options{
+		ObjectDecoded: func() { objects++ },
+		BytesEncoding: base64.RawURLEncoding,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(got.Fragment.Data), "hi?"; got != want {
+		t.Errorf("got data: %q, want: %q", got, want)
+	}
+	if got, want := objects, 2; got != want {
+		t.Errorf("got %d objects decoded, want %d", got, want)
+	}
+}
+
+func TestUnmarshalGraphQL_rawMessageWhole(t *testing.T) {
+	var got json.RawMessage
+	err := jsonutil.UnmarshalGraphQL([]byte(`{"viewer": {"login": "gopher"}}`), &got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(got), `{"viewer": {"login": "gopher"}}`; got != want {
+		t.Errorf("got: %s, want: %s", got, want)
+	}
+}
+
+// coordinates is a custom scalar decoded from an object.
+type coordinates struct {
+	Lat, Lng json.Number
+}
+
+func (c *coordinates) UnmarshalGQL(v interface{}) error {
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("coordinates must be an object, not %T", v)
+	}
+	c.Lat, c.Lng = obj["lat"].(json.Number), obj["lng"].(json.Number)
+	return nil
+}
+
+func TestUnmarshalGraphQL_customScalar(t *testing.T) {
+	var got struct {
+		Home  coordinates
+		Trips []*coordinates
+	}
+	err := jsonutil.UnmarshalGraphQL([]byte(`{
+		"home": {"lat": 59.9, "lng": 10.7},
+		"trips": [{"lat": 1, "lng": 2}, null]
+	}`), &got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (coordinates{Lat: "59.9", Lng: "10.7"}); got.Home != want {
+		t.Errorf("got home: %+v, want: %+v", got.Home, want)
+	}
+	if len(got.Trips) != 2 || got.Trips[0] == nil || *got.Trips[0] != (coordinates{Lat: "1", Lng: "2"}) || got.Trips[1] != nil {
+		t.Errorf("got trips: %v", got.Trips)
+	}
+
+	err = jsonutil.UnmarshalGraphQL([]byte(`{"home": "Oslo", "trips": []}`), &got)
+	if got, want := err, "coordinates must be an object, not string"; got == nil || got.Error() != want {
+		t.Errorf("got error: %v, want: %v", got, want)
+	}
+}
+
+func TestUnmarshalGraphQL_duration(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+	}{
+		{`"PT1H30M"`, 90 * time.Minute},
+		{`"P1DT0.5S"`, 24*time.Hour + 500*time.Millisecond},
+		{`"-PT2,25S"`, -2250 * time.Millisecond},
+		{`"P2W"`, 14 * 24 * time.Hour},
+		{`"1h30m"`, 90 * time.Minute},
+		{`1500`, 1500 * time.Nanosecond},
+	}
+	for _, tc := range tests {
+		var got struct {
+			D time.Duration
+		}
+		if err := jsonutil.UnmarshalGraphQL([]byte(`{"d": `+tc.in+`}`), &got); err != nil {
+			t.Errorf("%s: %v", tc.in, err)
+			continue
+		}
+		if got.D != tc.want {
+			t.Errorf("%s: got %v, want %v", tc.in, got.D, tc.want)
+		}
+	}
+
+	var got struct {
+		D *time.Duration
+	}
+	err := jsonutil.UnmarshalGraphQL([]byte(`{"d": "P1Y"}`), &got)
+	if got, want := err, `unsupported ISO 8601 duration "P1Y"`; got == nil || got.Error() != want {
+		t.Errorf("got error: %v, want: %v", got, want)
+	}
+}
+
+func TestUnmarshalGraphQL_required(t *testing.T) {
+	type query struct {
+		Viewer *struct {
+			Login graphql.String  `graphql:",required"`
+			Bio   *graphql.String `graphql:"bio"`
+			Owner struct {
+				Name graphql.String `graphql:"fullName: name,required"`
+			} `graphql:"owner"`
+			Bot struct {
+				App graphql.String `graphql:"app,required"`
+			} `graphql:"... on Bot"`
+		} `graphql:"viewer,required"`
+	}
+	tests := []struct {
+		in      string
+		wantErr string
+	}{
+		{in: `{"viewer": {"login": "gopher", "bio": null, "owner": {"fullName": "Go"}}}`},
+		{in: `{"viewer": {"__typename": "User", "login": "gopher", "owner": {"fullName": "Go"}}}`},
+		{in: `{"viewer": null}`, wantErr: `required field "viewer" is null or missing`},
+		{in: `{}`, wantErr: `required field "viewer" is null or missing`},
+		{in: `{"viewer": {"login": null, "owner": {"fullName": "Go"}}}`, wantErr: `required field "viewer.Login" is null or missing`},
+		{in: `{"viewer": {"login": "gopher", "owner": {}}}`, wantErr: `required field "viewer.owner.fullName" is null or missing`},
+		{in: `{"viewer": {"__typename": "Bot", "login": "gopher", "owner": {"fullName": "Go"}}}`, wantErr: `required field "viewer.app" is null or missing`},
+	}
+	for _, tc := range tests {
+		var got query
+		err := jsonutil.UnmarshalGraphQL([]byte(tc.in), &got)
+		if tc.wantErr == "" {
+			if err != nil {
+				t.Errorf("%s: %v", tc.in, err)
+			}
+			continue
+		}
+		if err == nil || err.Error() != tc.wantErr {
+			t.Errorf("%s: got error: %v, want: %v", tc.in, err, tc.wantErr)
+		}
+	}
+}
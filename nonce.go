@@ -0,0 +1,75 @@
+package graphql
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// NonceTransport is an http.RoundTripper that attaches a nonce to each
+// request, and checks that the server echoes it back in its response, as
+// some gateways require to protect against replayed mutations. Use it as
+// the transport of the http.Client given to NewClient.
+//
+// Nonces are timestamps in nanoseconds since the Unix epoch, strictly
+// increasing for each NonceTransport even if the clock isn't.
+type NonceTransport struct {
+	// Base is the transport to send requests with.
+	// If nil, http.DefaultTransport is used.
+	Base http.RoundTripper
+
+	// Header is the name of the request and response header holding the
+	// nonce. If empty, "X-Request-Nonce" is used.
+	Header string
+
+	// Clock is used to make nonces. If nil, the system clock is used.
+	Clock Clock
+
+	mu   sync.Mutex
+	last int64
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *NonceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	header := t.Header
+	if header == "" {
+		header = "X-Request-Nonce"
+	}
+	nonce := strconv.FormatInt(t.next(), 10)
+
+	// Don't modify the caller's request.
+	r := new(http.Request)
+	*r = *req
+	r.Header = make(http.Header, len(req.Header)+1)
+	for k, v := range req.Header {
+		r.Header[k] = v
+	}
+	r.Header.Set(header, nonce)
+
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	resp, err := base.RoundTrip(r)
+	if err != nil {
+		return nil, err
+	}
+	if got := resp.Header.Get(header); got != nonce {
+		resp.Body.Close()
+		return nil, fmt.Errorf("graphql: response nonce %q doesn't match request nonce %q", got, nonce)
+	}
+	return resp, nil
+}
+
+// next returns the next nonce.
+func (t *NonceTransport) next() int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	n := clockOrSystem(t.Clock).Now().UnixNano()
+	if n <= t.last {
+		n = t.last + 1
+	}
+	t.last = n
+	return n
+}
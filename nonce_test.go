@@ -0,0 +1,94 @@
+package graphql_test
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dbmedialab/go-graphql-client"
+)
+
+func TestNonceTransport(t *testing.T) {
+	var nonces []string
+	echo := true
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		nonce := req.Header.Get("X-Request-Nonce")
+		nonces = append(nonces, nonce)
+		if echo {
+			w.Header().Set("X-Request-Nonce", nonce)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: &graphql.NonceTransport{Base: localRoundTripper{handler: mux}}})
+
+	var q struct {
+		Viewer struct {
+			Login graphql.String
+		}
+	}
+	for i := 0; i < 3; i++ {
+		if err := client.Query(context.Background(), &q, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var last int64
+	for _, nonce := range nonces {
+		n, err := strconv.ParseInt(nonce, 10, 64)
+		if err != nil {
+			t.Fatalf("got malformed nonce %q: %v", nonce, err)
+		}
+		if n <= last {
+			t.Errorf("got nonces %v, want strictly increasing", nonces)
+		}
+		last = n
+	}
+
+	echo = false
+	if err := client.Query(context.Background(), &q, nil); err == nil {
+		t.Error("got error: nil, want: non-nil for a response without the nonce")
+	}
+}
+
+// fakeClock is a Clock that returns the times in it, one per call.
+type fakeClock []time.Time
+
+func (c *fakeClock) Now() time.Time {
+	t := (*c)[0]
+	*c = (*c)[1:]
+	return t
+}
+
+func TestNonceTransport_clock(t *testing.T) {
+	var nonces []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		nonce := req.Header.Get("X-Nonce")
+		nonces = append(nonces, nonce)
+		w.Header().Set("X-Nonce", nonce)
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+	})
+	// The clock goes backwards between the second and third requests.
+	clock := fakeClock{time.Unix(0, 100), time.Unix(0, 200), time.Unix(0, 150)}
+	transport := &graphql.NonceTransport{Base: localRoundTripper{handler: mux}, Header: "X-Nonce", Clock: &clock}
+	client := graphql.NewClient("/graphql", &http.Client{Transport: transport})
+
+	var q struct {
+		Viewer struct {
+			Login graphql.String
+		}
+	}
+	for i := 0; i < 3; i++ {
+		if err := client.Query(context.Background(), &q, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, want := strings.Join(nonces, " "), "100 200 201"; got != want {
+		t.Errorf("got nonces %q, want %q", got, want)
+	}
+}
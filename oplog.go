@@ -0,0 +1,165 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/dbmedialab/go-graphql-client/internal/document"
+)
+
+// OperationLog is the structured log document of an operation, one per
+// operation sent through an OperationLogger. Its JSON keys follow the
+// Elastic Common Schema where it has fields for them (@timestamp, event.*,
+// error.*, service.name), so that ELK and OpenSearch ingestion pipelines
+// take the documents as they are; the rest are under graphql.*. Dotted keys
+// are expanded into objects by Elasticsearch.
+type OperationLog struct {
+	// Timestamp is when the operation was sent.
+	Timestamp time.Time `json:"@timestamp"`
+	// Duration is how long it took, in nanoseconds as event.duration is.
+	Duration time.Duration `json:"event.duration"`
+	// Outcome is "success", or "failure" if there was an error, of the
+	// transport or reported by the server.
+	Outcome string `json:"event.outcome"`
+	Service string `json:"service.name,omitempty"`
+
+	OperationName string `json:"graphql.operation.name,omitempty"`
+	// OperationType is "query", "mutation" or "subscription".
+	OperationType string `json:"graphql.operation.type"`
+	// QueryHash is the query's HashQuery.
+	QueryHash string `json:"graphql.query.hash"`
+	// RequestBytes and ResponseBytes are the sizes of the request and
+	// response, as JSON.
+	RequestBytes  int `json:"graphql.request.bytes"`
+	ResponseBytes int `json:"graphql.response.bytes,omitempty"`
+	// ErrorCount is the number of errors in the response, and ErrorCodes
+	// their distinct "code" extensions, in order.
+	ErrorCount int      `json:"graphql.error.count,omitempty"`
+	ErrorCodes []string `json:"graphql.error.codes,omitempty"`
+
+	// ErrorType is the kind of error the operation failed with, if it
+	// did, as ErrorKind names it, such as "timeout" or "graphql".
+	ErrorType    string `json:"error.type,omitempty"`
+	ErrorMessage string `json:"error.message,omitempty"`
+}
+
+// LogSink receives the log documents of operations. Implementations may
+// ship them anywhere, such as straight to an ingestion endpoint.
+type LogSink interface {
+	WriteLog(l *OperationLog) error
+}
+
+// LogSinkFunc is an adapter to allow the use of ordinary functions as
+// LogSinks.
+type LogSinkFunc func(l *OperationLog) error
+
+// WriteLog calls f(l).
+func (f LogSinkFunc) WriteLog(l *OperationLog) error {
+	return f(l)
+}
+
+// JSONLogSink is a LogSink that writes each document as a line of JSON, as
+// Filebeat, Fluent Bit and Logstash read log files. It's safe for
+// concurrent use.
+type JSONLogSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewJSONLogSink returns a JSONLogSink that writes to w.
+func NewJSONLogSink(w io.Writer) *JSONLogSink {
+	return &JSONLogSink{w: w}
+}
+
+// WriteLog writes l as a line of JSON.
+func (s *JSONLogSink) WriteLog(l *OperationLog) error {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.w.Write(append(b, '\n'))
+	return err
+}
+
+// OperationLogger writes a structured log document for each operation
+// to a LogSink, as an alternative, or addition, to metrics. Install it
+// with WithMiddleware(l.Middleware).
+type OperationLogger struct {
+	Sink LogSink
+
+	// Service, if not empty, is the service.name of the documents.
+	Service string
+
+	// ErrorLog, if not nil, is called with the errors the sink returns.
+	// They don't fail operations.
+	ErrorLog func(error)
+
+	// Clock, if not nil, times operations instead of the system clock.
+	Clock Clock
+}
+
+// Middleware logs the operations sent through next.
+func (l *OperationLogger) Middleware(next Transport) Transport {
+	return TransportFunc(func(ctx context.Context, req Request) (*Response, error) {
+		clock := clockOrSystem(l.Clock)
+		start := clock.Now()
+		resp, err := next.Do(ctx, req)
+		doc := &OperationLog{
+			Timestamp:     start.UTC(),
+			Duration:      clock.Now().Sub(start),
+			Outcome:       "success",
+			Service:       l.Service,
+			OperationName: req.OperationName,
+			OperationType: operationType(req.Query),
+			QueryHash:     HashQuery(req.Query),
+		}
+		if doc.OperationName == "" {
+			doc.OperationName = queryOperationName(req.Query)
+		}
+		if b, err := json.Marshal(req); err == nil {
+			doc.RequestBytes = len(b)
+		}
+		if resp != nil {
+			if b, err := json.Marshal(resp); err == nil {
+				doc.ResponseBytes = len(b)
+			}
+			doc.ErrorCount = len(resp.Errors)
+			seen := map[string]bool{}
+			for _, e := range resp.Errors {
+				code, _ := e.Extensions["code"].(string)
+				if code != "" && !seen[code] {
+					seen[code] = true
+					doc.ErrorCodes = append(doc.ErrorCodes, code)
+				}
+			}
+		}
+		switch {
+		case err != nil:
+			doc.Outcome, doc.ErrorType, doc.ErrorMessage = "failure", ErrorKind(transportError(ctx, err)), err.Error()
+		case doc.ErrorCount > 0:
+			doc.Outcome, doc.ErrorType, doc.ErrorMessage = "failure", "graphql", resp.Errors[0].Message
+		}
+		if werr := l.Sink.WriteLog(doc); werr != nil && l.ErrorLog != nil {
+			l.ErrorLog(werr)
+		}
+		return resp, err
+	})
+}
+
+// operationType returns the type of the operation in query: "query",
+// "mutation" or "subscription".
+func operationType(query string) string {
+	toks, err := document.Tokenize(query)
+	if err == nil && len(toks) > 0 && toks[0].Kind == document.Name {
+		switch toks[0].Value {
+		case "mutation", "subscription":
+			return toks[0].Value
+		}
+	}
+	return "query"
+}
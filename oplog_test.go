@@ -0,0 +1,99 @@
+package graphql_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dbmedialab/go-graphql-client"
+)
+
+func TestOperationLogger(t *testing.T) {
+	clock := &settableClock{t: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)}
+	calls := 0
+	transport := graphql.TransportFunc(func(ctx context.Context, req graphql.Request) (*graphql.Response, error) {
+		clock.t = clock.t.Add(250 * time.Millisecond)
+		calls++
+		switch calls {
+		case 1:
+			return &graphql.Response{Data: []byte(`{"viewer": {"login": "gopher"}}`)}, nil
+		case 2:
+			resp := &graphql.Response{Data: []byte(`{"like": null}`)}
+			err := json.Unmarshal([]byte(`[
+				{"message": "not allowed", "extensions": {"code": "FORBIDDEN"}},
+				{"message": "still not allowed", "extensions": {"code": "FORBIDDEN"}}
+			]`), &resp.Errors)
+			return resp, err
+		}
+		return nil, fmt.Errorf("connection reset")
+	})
+	var buf bytes.Buffer
+	logger := &graphql.OperationLogger{Sink: graphql.NewJSONLogSink(&buf), Service: "shop", Clock: clock}
+	client := graphql.NewPluggableClient(transport, graphql.WithMiddleware(logger.Middleware))
+
+	var q struct {
+		Viewer struct{ Login graphql.String }
+	}
+	if err := client.QueryCustom(context.Background(), &q, "query Viewer { viewer { login } }", nil); err != nil {
+		t.Fatal(err)
+	}
+	var m struct{ Like graphql.Boolean }
+	client.MutateCustom(context.Background(), &m, `mutation { like(id: "1") }`, nil)
+	client.Query(context.Background(), &q, nil)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d documents, want 3:\n%s", len(lines), buf.String())
+	}
+	want := []map[string]interface{}{
+		{
+			"@timestamp":             "2020-01-02T03:04:05Z",
+			"event.duration":         2.5e8,
+			"event.outcome":          "success",
+			"service.name":           "shop",
+			"graphql.operation.name": "Viewer",
+			"graphql.operation.type": "query",
+			"graphql.query.hash":     graphql.HashQuery("query Viewer{viewer{login}}"),
+			"graphql.request.bytes":  float64(len(`{"query":"query Viewer { viewer { login } }","operationName":"Viewer"}`)),
+			"graphql.response.bytes": float64(len(`{"data":{"viewer":{"login":"gopher"}}}`)),
+		},
+		{
+			"@timestamp":             "2020-01-02T03:04:05.25Z",
+			"event.duration":         2.5e8,
+			"event.outcome":          "failure",
+			"service.name":           "shop",
+			"graphql.operation.type": "mutation",
+			"graphql.query.hash":     graphql.HashQuery(`mutation { like(id: "1") }`),
+			"graphql.request.bytes":  float64(len(`{"query":"mutation { like(id: \"1\") }"}`)),
+			"graphql.response.bytes": float64(len(`{"data":{"like":null},"errors":[{"message":"not allowed","extensions":{"code":"FORBIDDEN"}},{"message":"still not allowed","extensions":{"code":"FORBIDDEN"}}]}`)),
+			"graphql.error.count":    2.0,
+			"graphql.error.codes":    []interface{}{"FORBIDDEN"},
+			"error.type":             "graphql",
+			"error.message":          "not allowed",
+		},
+		{
+			"@timestamp":             "2020-01-02T03:04:05.5Z",
+			"event.duration":         2.5e8,
+			"event.outcome":          "failure",
+			"service.name":           "shop",
+			"graphql.operation.type": "query",
+			"graphql.query.hash":     graphql.HashQuery("{viewer{login}}"),
+			"graphql.request.bytes":  float64(len(`{"query":"{viewer{login}}"}`)),
+			"error.type":             "transport",
+			"error.message":          "connection reset",
+		},
+	}
+	for i, line := range lines {
+		var got map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &got); err != nil {
+			t.Fatal(err)
+		}
+		if g, w := fmt.Sprint(got), fmt.Sprint(want[i]); g != w {
+			t.Errorf("document %d:\ngot:  %s\nwant: %s", i, g, w)
+		}
+	}
+}
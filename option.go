@@ -0,0 +1,111 @@
+package graphql
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// ClientOption configures a Client. Options are given to NewClient,
+// NewPluggableClient, or Client.With.
+type ClientOption func(*Client)
+
+// Middleware wraps a Transport, for example to add logging, metrics, or retries.
+type Middleware func(Transport) Transport
+
+// WithHeader sets the HTTP header key to value on every request.
+// Transports other than TransportHTTP may ignore it.
+func WithHeader(key, value string) ClientOption {
+	return func(c *Client) {
+		// Copy the header, since it may be shared with the client this
+		// one was cloned from.
+		h := make(http.Header, len(c.header)+1)
+		for k, v := range c.header {
+			h[k] = v
+		}
+		h.Set(key, value)
+		c.header = h
+	}
+}
+
+// WithTimeout limits the time each operation may take, including reading
+// the response. A zero d means no limit beyond that of the context.
+func WithTimeout(d time.Duration) ClientOption {
+	return func(c *Client) {
+		c.timeout = d
+	}
+}
+
+// WithMinifiedQueries makes QueryCustom and MutateCustom send their queries
+// as NormalizeQuery minifies them, without comments and formatting, so that
+// hand-written documents, such as those kept in .graphql files, are the
+// same bytes on the wire however they're formatted, for caches and
+// allowlists keyed by them. Queries derived from structs are minified
+// already.
+func WithMinifiedQueries() ClientOption {
+	return func(c *Client) {
+		c.minifyQueries = true
+	}
+}
+
+// WithMiddleware wraps the client's transport with middleware. The first
+// middleware given is the outermost one.
+func WithMiddleware(middleware ...Middleware) ClientOption {
+	return func(c *Client) {
+		for i := len(middleware) - 1; i >= 0; i-- {
+			c.transport = middleware[i](c.transport)
+		}
+	}
+}
+
+// WithEndpointURL directs the client's operations to url, as WithEndpoint
+// does for a single context. url must be allowed by the transport.
+func WithEndpointURL(url string) ClientOption {
+	return func(c *Client) {
+		c.endpoint = url
+	}
+}
+
+// With returns a copy of c with opts applied. The copy shares c's transport,
+// and so its connections, so it's cheap to make one per tenant or per class
+// of request. c itself is not modified.
+func (c *Client) With(opts ...ClientOption) *Client {
+	clone := *c
+	for _, opt := range opts {
+		opt(&clone)
+	}
+	return &clone
+}
+
+// send sends req with the client's transport, applying its options.
+// Errors are returned with their kinds.
+func (c *Client) send(ctx context.Context, req Request) (*Response, error) {
+	ctx, req, cancel := c.prepare(ctx, req)
+	defer cancel()
+	out, err := c.transport.Do(ctx, req)
+	if out != nil {
+		receiveExtensions(ctx, out.Extensions)
+	}
+	return out, transportError(ctx, err)
+}
+
+// prepare applies the client's options to the context and request
+// of an operation. cancel must be called when the operation is done.
+func (c *Client) prepare(ctx context.Context, req Request) (_ context.Context, _ Request, cancel context.CancelFunc) {
+	cancel = func() {}
+	if c.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+	}
+	if len(c.header) > 0 {
+		req.Header = c.header
+	}
+	if c.endpoint != "" && ctx.Value(endpointKey{}) == nil {
+		ctx = WithEndpoint(ctx, c.endpoint)
+	}
+	if c.tenant != "" && ctx.Value(tenantKey{}) == nil {
+		ctx = WithTenant(ctx, c.tenant)
+	}
+	ctx = withHTTPOverrides(ctx, c.httpOverrides)
+	req = withRequestExtensions(ctx, req)
+	return ctx, req, cancel
+}
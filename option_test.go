@@ -0,0 +1,115 @@
+package graphql_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/dbmedialab/go-graphql-client"
+)
+
+func TestClient_With(t *testing.T) {
+	var gotTenant string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		gotTenant = req.Header.Get("X-Tenant")
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}},
+		graphql.WithHeader("X-Tenant", "a"))
+
+	var calls int
+	count := func(next graphql.Transport) graphql.Transport {
+		return graphql.TransportFunc(func(ctx context.Context, req graphql.Request) (*graphql.Response, error) {
+			calls++
+			return next.Do(ctx, req)
+		})
+	}
+	tenantB := client.With(graphql.WithHeader("X-Tenant", "b"), graphql.WithMiddleware(count))
+
+	var q struct {
+		Viewer struct {
+			Login graphql.String
+		}
+	}
+	if err := tenantB.Query(context.Background(), &q, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := gotTenant, "b"; got != want {
+		t.Errorf("got X-Tenant: %q, want: %q", got, want)
+	}
+	if got, want := calls, 1; got != want {
+		t.Errorf("got %d middleware calls, want %d", got, want)
+	}
+
+	// The original client is unchanged.
+	if err := client.Query(context.Background(), &q, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := gotTenant, "a"; got != want {
+		t.Errorf("got X-Tenant: %q, want: %q", got, want)
+	}
+	if got, want := calls, 1; got != want {
+		t.Errorf("got %d middleware calls, want %d", got, want)
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	var gotDeadline bool
+	transport := graphql.TransportFunc(func(ctx context.Context, req graphql.Request) (*graphql.Response, error) {
+		_, gotDeadline = ctx.Deadline()
+		return &graphql.Response{Data: []byte(`{"viewer": {"login": "gopher"}}`)}, nil
+	})
+	client := graphql.NewPluggableClient(transport, graphql.WithTimeout(time.Second))
+
+	var q struct {
+		Viewer struct {
+			Login graphql.String
+		}
+	}
+	if err := client.Query(context.Background(), &q, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !gotDeadline {
+		t.Error("got context without deadline, want one")
+	}
+}
+
+func TestWithMinifiedQueries(t *testing.T) {
+	var gotQuery, gotOperationName string
+	transport := graphql.TransportFunc(func(ctx context.Context, req graphql.Request) (*graphql.Response, error) {
+		gotQuery, gotOperationName = req.Query, req.OperationName
+		return &graphql.Response{Data: []byte(`{"viewer": {"login": "gopher"}}`)}, nil
+	})
+	query := `# The signed-in user.
+query Viewer {
+	viewer {
+		login, # Their handle.
+		bio(format: "plain  text")
+	}
+}
+`
+	var q struct {
+		Viewer struct {
+			Login graphql.String
+			Bio   graphql.String
+		}
+	}
+	client := graphql.NewPluggableClient(transport, graphql.WithMinifiedQueries())
+	if err := client.QueryCustom(context.Background(), &q, query, nil); err != nil {
+		t.Fatal(err)
+	}
+	if want := `query Viewer{viewer{login bio(format:"plain  text")}}`; gotQuery != want {
+		t.Errorf("got query: %s, want: %s", gotQuery, want)
+	}
+	if gotOperationName != "Viewer" {
+		t.Errorf("got operation name %q, want Viewer", gotOperationName)
+	}
+
+	err := client.MutateCustom(context.Background(), &q, `mutation { like(id: "1) }`, nil)
+	if err == nil {
+		t.Error("got error: nil, want: non-nil for a query that can't be tokenized")
+	}
+}
@@ -0,0 +1,175 @@
+//go:build go1.13
+// +build go1.13
+
+package graphql
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+)
+
+// PoolStats are the statistics of a client's connections to a host.
+type PoolStats struct {
+	Host string // As "host:port".
+
+	// Open is how many connections are open to the host.
+	Open int
+	// Idle is how many open connections aren't carrying requests. With
+	// HTTP/2, where a connection carries several requests at once, it's
+	// only an estimate.
+	Idle int
+	// InFlight is how many requests to the host are in flight, from
+	// being sent until their responses are read.
+	InFlight int
+}
+
+// PoolMonitor tracks the connections a client created by NewClient has open
+// to each host, and the requests in flight on them, which helps tune the
+// http.Transport's MaxIdleConnsPerHost for workloads dominated by one
+// GraphQL server. Install it with WithPoolMonitor. The zero value is ready
+// to use.
+type PoolMonitor struct {
+	// Metrics, if not nil, is called with a host's statistics each time
+	// they change. It must not block.
+	Metrics func(PoolStats)
+
+	mu    sync.Mutex
+	hosts map[string]*PoolStats
+}
+
+// WithPoolMonitor makes m track the connections of a client created by
+// NewClient. Like WithDialOptions, it has no effect on clients with other
+// transports, or whose HTTP client has a transport other than an
+// *http.Transport; if both are given, it must be given after
+// WithDialOptions.
+func WithPoolMonitor(m *PoolMonitor) ClientOption {
+	return func(c *Client) {
+		withHTTPTransport(c, func(ht *http.Transport) http.RoundTripper {
+			dial := ht.DialContext
+			if dial == nil {
+				dial = (&net.Dialer{}).DialContext
+			}
+			ht.DialContext = func(ctx context.Context, network, address string) (net.Conn, error) {
+				conn, err := dial(ctx, network, address)
+				if err != nil {
+					return nil, err
+				}
+				m.update(address, func(s *PoolStats) { s.Open++ })
+				return &monitoredConn{Conn: conn, m: m, host: address}, nil
+			}
+			return monitoredTransport{next: ht, m: m}
+		})
+	}
+}
+
+// Stats returns the statistics of each host the client has connected to,
+// sorted by host.
+func (m *PoolMonitor) Stats() []PoolStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stats := make([]PoolStats, 0, len(m.hosts))
+	for _, s := range m.hosts {
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Host < stats[j].Host })
+	return stats
+}
+
+// Host returns the statistics of host, given as "host:port".
+func (m *PoolMonitor) Host(host string) PoolStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if s := m.hosts[host]; s != nil {
+		return *s
+	}
+	return PoolStats{Host: host}
+}
+
+// update applies f to the statistics of host, and reports them.
+func (m *PoolMonitor) update(host string, f func(*PoolStats)) {
+	m.mu.Lock()
+	if m.hosts == nil {
+		m.hosts = map[string]*PoolStats{}
+	}
+	s := m.hosts[host]
+	if s == nil {
+		s = &PoolStats{Host: host}
+		m.hosts[host] = s
+	}
+	f(s)
+	s.Idle = s.Open - s.InFlight
+	if s.Idle < 0 {
+		s.Idle = 0
+	}
+	stats := *s
+	m.mu.Unlock()
+	if m.Metrics != nil {
+		m.Metrics(stats)
+	}
+}
+
+// monitoredConn is a connection counted as open until it's closed.
+type monitoredConn struct {
+	net.Conn
+	m    *PoolMonitor
+	host string
+	once sync.Once
+}
+
+func (c *monitoredConn) Close() error {
+	c.once.Do(func() {
+		c.m.update(c.host, func(s *PoolStats) { s.Open-- })
+	})
+	return c.Conn.Close()
+}
+
+// monitoredTransport counts the requests in flight through next.
+type monitoredTransport struct {
+	next http.RoundTripper
+	m    *PoolMonitor
+}
+
+func (t monitoredTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := hostAddr(req.URL)
+	t.m.update(host, func(s *PoolStats) { s.InFlight++ })
+	done := func() {
+		t.m.update(host, func(s *PoolStats) { s.InFlight-- })
+	}
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		done()
+		return nil, err
+	}
+	resp.Body = &monitoredBody{ReadCloser: resp.Body, done: done}
+	return resp, nil
+}
+
+// monitoredBody is the body of a response in flight until it's closed.
+type monitoredBody struct {
+	io.ReadCloser
+	done func()
+	once sync.Once
+}
+
+func (b *monitoredBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.done)
+	return err
+}
+
+// hostAddr returns the "host:port" address that requests to u are sent to.
+func hostAddr(u *url.URL) string {
+	if u.Port() != "" {
+		return u.Host
+	}
+	port := "80"
+	if u.Scheme == "https" {
+		port = "443"
+	}
+	return net.JoinHostPort(u.Hostname(), port)
+}
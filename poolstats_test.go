@@ -0,0 +1,58 @@
+//go:build go1.13
+// +build go1.13
+
+package graphql_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/dbmedialab/go-graphql-client"
+)
+
+func TestPoolMonitor(t *testing.T) {
+	var m graphql.PoolMonitor
+	var inHandler graphql.PoolStats
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		inHandler = m.Host(req.Host)
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+	}))
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	var mu sync.Mutex
+	var reports []graphql.PoolStats
+	m.Metrics = func(s graphql.PoolStats) {
+		mu.Lock()
+		reports = append(reports, s)
+		mu.Unlock()
+	}
+	client := graphql.NewClient(server.URL, nil, graphql.WithPoolMonitor(&m))
+	var q struct{ Viewer struct{ Login string } }
+	for i := 0; i < 2; i++ {
+		if err := client.Query(context.Background(), &q, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if want := (graphql.PoolStats{Host: host, Open: 1, InFlight: 1}); inHandler != want {
+		t.Errorf("got stats in flight %+v, want %+v", inHandler, want)
+	}
+	// The connection is reused by the second request.
+	want := graphql.PoolStats{Host: host, Open: 1, Idle: 1}
+	if got := m.Stats(); len(got) != 1 || got[0] != want {
+		t.Errorf("got stats %+v, want [%+v]", got, want)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	// Each request is reported in flight and done, and the connection
+	// opened between the first pair.
+	if got, want := len(reports), 5; got != want {
+		t.Errorf("got %d reports, want %d", got, want)
+	}
+}
@@ -0,0 +1,41 @@
+package graphql
+
+import "github.com/dbmedialab/go-graphql-client/internal/document"
+
+// Printer prints the documents of operations derived from structs by Query
+// and Mutate, for example to order fields, follow the conventions of another
+// client, or add comments for the server's logs. Printers are set with
+// WithPrinter.
+type Printer interface {
+	// Print returns the document to send for doc, the minified document
+	// derived from v, including the definitions of the fragments it uses.
+	// An error is returned from the operation, which isn't sent.
+	Print(doc string, v interface{}) (string, error)
+}
+
+// PrinterFunc is an adapter to allow the use of ordinary functions as
+// Printers.
+type PrinterFunc func(doc string, v interface{}) (string, error)
+
+// Print calls f(doc, v).
+func (f PrinterFunc) Print(doc string, v interface{}) (string, error) {
+	return f(doc, v)
+}
+
+// IndentPrinter returns a Printer that formats documents with each field
+// on its own line, indented by indent per level of nesting.
+func IndentPrinter(indent string) Printer {
+	return PrinterFunc(func(doc string, v interface{}) (string, error) {
+		return indentQuery(doc, indent), nil
+	})
+}
+
+// indentQuery formats query with indent. query is returned as is if it
+// isn't valid enough to format, for the server to report.
+func indentQuery(query, indent string) string {
+	toks, err := document.Tokenize(query)
+	if err != nil {
+		return query
+	}
+	return document.Indent(toks, indent)
+}
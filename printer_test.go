@@ -0,0 +1,48 @@
+package graphql_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/dbmedialab/go-graphql-client"
+)
+
+func TestWithPrinter(t *testing.T) {
+	var gotQuery string
+	transport := graphql.TransportFunc(func(ctx context.Context, req graphql.Request) (*graphql.Response, error) {
+		gotQuery = req.Query
+		return &graphql.Response{Data: []byte(`{"viewer": {"login": "gopher"}}`)}, nil
+	})
+	client := graphql.NewPluggableClient(transport)
+
+	var q struct {
+		Viewer struct {
+			Login graphql.String
+		}
+	}
+	comment := graphql.PrinterFunc(func(doc string, v interface{}) (string, error) {
+		return fmt.Sprintf("# %T\n%s", v, doc), nil
+	})
+	if err := client.Query(context.Background(), &q, nil, graphql.OperationName("Viewer"), graphql.WithPrinter(comment)); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := gotQuery, "# *struct { Viewer struct { Login graphql.String } }\nquery Viewer{viewer{login}}"; got != want {
+		t.Errorf("got query: %q, want: %q", got, want)
+	}
+
+	if err := client.Query(context.Background(), &q, nil, graphql.WithPrinter(graphql.IndentPrinter("  "))); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := gotQuery, "{\n  viewer {\n    login\n  }\n}"; got != want {
+		t.Errorf("got query: %q, want: %q", got, want)
+	}
+
+	failing := graphql.PrinterFunc(func(doc string, v interface{}) (string, error) {
+		return "", fmt.Errorf("cannot print")
+	})
+	err := client.Query(context.Background(), &q, nil, graphql.WithPrinter(failing))
+	if got, want := err, "cannot print"; got == nil || got.Error() != want {
+		t.Errorf("got error: %v, want: %v", got, want)
+	}
+}
@@ -0,0 +1,73 @@
+package graphql
+
+import (
+	"context"
+	"io"
+)
+
+// Progress describes how far along a client is in receiving a response.
+type Progress struct {
+	BytesRead      int64 // Bytes of the response body read so far.
+	ObjectsDecoded int64 // JSON objects in the response data decoded so far.
+}
+
+// progressObjectInterval is how many objects are decoded between calls
+// to a progress callback, so that it isn't called too often.
+const progressObjectInterval = 1000
+
+// WithProgress makes the client call fn as a response is read and decoded,
+// and once more when decoding is done. It's meant for rendering progress
+// of huge responses, or for emitting liveness signals while handling them.
+// fn is called from the goroutine executing the operation.
+//
+// Bytes read are only reported by transports that support it,
+// such as TransportHTTP.
+func WithProgress(fn func(Progress)) ClientOption {
+	return func(c *Client) {
+		c.progress = fn
+	}
+}
+
+type progressKey struct{}
+
+// progressReporter accumulates the progress of one operation.
+type progressReporter struct {
+	fn func(Progress)
+	p  Progress
+}
+
+// progressFrom returns the reporter for the operation ctx is for, or nil.
+func progressFrom(ctx context.Context) *progressReporter {
+	r, _ := ctx.Value(progressKey{}).(*progressReporter)
+	return r
+}
+
+func (r *progressReporter) read(n int) {
+	if n > 0 {
+		r.p.BytesRead += int64(n)
+		r.fn(r.p)
+	}
+}
+
+func (r *progressReporter) objectDecoded() {
+	r.p.ObjectsDecoded++
+	if r.p.ObjectsDecoded%progressObjectInterval == 0 {
+		r.fn(r.p)
+	}
+}
+
+func (r *progressReporter) done() {
+	r.fn(r.p)
+}
+
+// progressReader is an io.Reader that reports bytes read.
+type progressReader struct {
+	r   io.Reader
+	rep *progressReporter
+}
+
+func (pr progressReader) Read(p []byte) (int, error) {
+	n, err := pr.r.Read(p)
+	pr.rep.read(n)
+	return n, err
+}
@@ -0,0 +1,40 @@
+package graphql_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/dbmedialab/go-graphql-client"
+)
+
+func TestWithProgress(t *testing.T) {
+	const body = `{"data": {"users": [{"login": "a"}, {"login": "b"}]}}`
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, body)
+	})
+	var last graphql.Progress
+	calls := 0
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}},
+		graphql.WithProgress(func(p graphql.Progress) {
+			calls++
+			last = p
+		}))
+
+	var q struct {
+		Users []struct {
+			Login graphql.String
+		}
+	}
+	if err := client.Query(context.Background(), &q, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := last, (graphql.Progress{BytesRead: int64(len(body)), ObjectsDecoded: 3}); got != want {
+		t.Errorf("got final progress: %+v, want: %+v", got, want)
+	}
+	if calls < 2 {
+		t.Errorf("got %d progress calls, want at least 2", calls)
+	}
+}
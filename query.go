@@ -2,6 +2,7 @@ package graphql
 
 import (
 	"bytes"
+	"encoding"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -9,30 +10,84 @@ import (
 	"sort"
 	"strconv"
 	"strings"
-
-	"github.com/dbmedialab/go-graphql-client/ident"
+	"sync"
 )
 
-func constructQuery(v interface{}, variables map[string]interface{}) string {
-	query := GenerateQueryFields(v)
+func constructQuery(v interface{}, variables map[string]interface{}, opts ...QueryOption) (string, error) {
+	return constructOperation("query", v, variables, opts...)
+}
+
+func constructMutation(v interface{}, variables map[string]interface{}, opts ...QueryOption) (string, error) {
+	return constructOperation("mutation", v, variables, opts...)
+}
+
+func constructSubscription(v interface{}, variables map[string]interface{}, opts ...QueryOption) (string, error) {
+	return constructOperation("subscription", v, variables, opts...)
+}
+
+// constructOperation constructs the document of an operation of type op,
+// "query", "mutation" or "subscription", derived from v. Queries without
+// a name or variables are written in the shorthand form.
+func constructOperation(op string, v interface{}, variables map[string]interface{}, opts ...QueryOption) (string, error) {
+	query, err := generateQueryFields(v, opts)
+	if err != nil {
+		return "", err
+	}
+	o := newQueryOptions(opts)
+	if o.maxDepth > 0 {
+		if err := checkDepth(query, o.maxDepth); err != nil {
+			return "", err
+		}
+	}
+	name := o.operationName
+	if name != "" {
+		name = " " + name
+	}
 	if variables != nil {
-		return "query(" + queryArguments(variables) + ")" + query
+		args, err := typedQueryArguments(op, query, variables, o)
+		if err != nil {
+			return "", err
+		}
+		return op + name + "(" + args + ")" + query, nil
 	}
-	return query
+	if name == "" && op == "query" {
+		return query, nil
+	}
+	return op + name + query, nil
 }
 
-func constructMutation(v interface{}, variables map[string]interface{}) string {
-	query := GenerateQueryFields(v)
-	if variables != nil {
-		return "mutation(" + queryArguments(variables) + ")" + query
+// typedQueryArguments is like queryArguments, but if the query options have
+// a schema, variables are typed by the arguments they're bound to in query,
+// the selection set of an operation of type op.
+//
+// Panics in typing variables, such as from a GraphQLType method, are returned
+// as errors.
+func typedQueryArguments(op, query string, variables map[string]interface{}, o *queryOptions) (args string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("graphql: panic declaring variables: %v", r)
+		}
+	}()
+	if o.schema == nil {
+		return queryArguments(variables, nil, o.scalars), nil
+	}
+	types, err := o.schema.VariableTypes(op + query)
+	if err != nil {
+		return "", err
 	}
-	return "mutation" + query
+	return queryArguments(variables, types, o.scalars), nil
 }
 
 // queryArguments constructs a minified arguments string for variables.
+// Variables made with WithType are declared with the type they were given,
+// those in types with the type given there, and others with a type derived
+// from their value, using scalars, or the default scalar types if it's nil.
 //
 // E.g., map[string]interface{}{"a": Int(123), "b": NewBoolean(true)} -> "$a:Int!$b:Boolean".
-func queryArguments(variables map[string]interface{}) string {
+func queryArguments(variables map[string]interface{}, types map[string]string, scalars ScalarTypes) string {
+	if scalars == nil {
+		scalars = defaultScalarTypes
+	}
 	// Sort keys in order to produce deterministic output for testing purposes.
 	// TODO: If tests can be made to work with non-deterministic output, then no need to sort.
 	keys := make([]string, 0, len(variables))
@@ -46,7 +101,13 @@ func queryArguments(variables map[string]interface{}) string {
 		io.WriteString(&buf, "$")
 		io.WriteString(&buf, k)
 		io.WriteString(&buf, ":")
-		writeArgumentType(&buf, reflect.TypeOf(variables[k]), true)
+		if tv, ok := variables[k].(typedValue); ok {
+			io.WriteString(&buf, tv.typ)
+		} else if t, ok := types[k]; ok {
+			io.WriteString(&buf, t)
+		} else {
+			writeArgumentType(&buf, reflect.TypeOf(variables[k]), true, scalars)
+		}
 		// Don't insert a comma here.
 		// Commas in GraphQL are insignificant, and we want minified output.
 		// See https://facebook.github.io/graphql/October2016/#sec-Insignificant-Commas.
@@ -57,24 +118,32 @@ func queryArguments(variables map[string]interface{}) string {
 // writeArgumentType writes a minified GraphQL type for t to w.
 // value indicates whether t is a value (required) type or pointer (optional) type.
 // If value is true, then "!" is written at the end of t.
-func writeArgumentType(w io.Writer, t reflect.Type, value bool) {
+// Named types are looked up in scalars.
+func writeArgumentType(w io.Writer, t reflect.Type, value bool, scalars ScalarTypes) {
 	if t.Kind() == reflect.Ptr {
 		// Pointer is an optional type, so no "!" at the end of the pointer's underlying type.
-		writeArgumentType(w, t.Elem(), false)
+		writeArgumentType(w, t.Elem(), false, scalars)
 		return
 	}
 
-	switch t.Kind() {
-	case reflect.Slice, reflect.Array:
+	// Mapped types and custom scalars are named, even if they're lists.
+	_, mapped := scalars[t]
+	custom := t.Implements(gqlMarshaler) || reflect.PtrTo(t).Implements(gqlMarshaler)
+	switch {
+	case (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && !mapped && !custom:
 		// List. E.g., "[Int]".
 		io.WriteString(w, "[")
-		writeArgumentType(w, t.Elem(), true)
+		writeArgumentType(w, t.Elem(), true, scalars)
 		io.WriteString(w, "]")
 	default:
 		// Named type. E.g., "Int".
 		name := t.Name()
-		if name == "string" { // HACK: Workaround for https://github.com/shurcooL/githubql/issues/12.
-			name = "ID"
+		if n, ok := scalars[t]; ok {
+			name = n
+		} else if t.Implements(graphQLTyper) {
+			name = reflect.Zero(t).Interface().(GraphQLTyper).GraphQLType()
+		} else if t.Kind() == reflect.Struct {
+			name = inputTypeName(t)
 		}
 		io.WriteString(w, name)
 	}
@@ -97,10 +166,120 @@ func writeArgumentType(w io.Writer, t reflect.Type, value bool) {
 // Arguments, Aliases, and Fragments can also all be prepended to a Fields snippet;
 // see http://graphql.org/learn/queries/
 // for more description of each of these concepts.
+//
+// GenerateQueryFields panics if v can't be made into a query, for example
+// because it has recursive types; see GenerateQueryFieldsE.
 func GenerateQueryFields(v interface{}) string {
+	query, err := generateQueryFields(v, nil)
+	if err != nil {
+		panic(err)
+	}
+	return query
+}
+
+// GenerateQueryFieldsE is like GenerateQueryFields, but returns an error
+// instead of panicking if v can't be made into a query.
+func GenerateQueryFieldsE(v interface{}) (string, error) {
+	return generateQueryFields(v, nil)
+}
+
+// Wrap returns the selection set that selects field, which may have an
+// alias, arguments and directives, with the selection set fields, such as
+// GenerateQueryFields or another Wrap returns. Braces are added around
+// fields if it doesn't have them. The result can be passed to QueryCustom
+// and MutateCustom as the query.
+//
+// E.g., Wrap("repository(owner: $owner, name: $name)", "{name,stargazerCount}")
+// -> "{repository(owner: $owner, name: $name){name,stargazerCount}}".
+func Wrap(field, fields string) string {
+	field, fields = strings.TrimSpace(field), strings.TrimSpace(fields)
+	if fields != "" && !strings.HasPrefix(fields, "{") {
+		fields = "{" + fields + "}"
+	}
+	return "{" + field + fields + "}"
+}
+
+// GenerateQueryFieldsIndented is like GenerateQueryFields, but formats the
+// fields one per line, indented by indent per level of nesting.
+//
+// E.g., struct{Foo Int, BarBaz *Boolean} -> "{\n  foo\n  barBaz\n}" for an indent of "  ".
+func GenerateQueryFieldsIndented(v interface{}, indent string) string {
+	return indentQuery(GenerateQueryFields(v), indent)
+}
+
+// GenerateQueryFieldsFromValue is like GenerateQueryFields, but walks the
+// value v, not just its type, and leaves out the fields of struct pointer
+// type that are nil, with their selections. This lets one large type serve
+// many views of the same data, by leaving the branches that a view doesn't
+// need nil:
+//
+//	q := Repository{Owner: &Owner{}} // Select the owner, but not the issues.
+//
+// The elements of lists, which may differ, aren't walked: their fields are
+// selected as for GenerateQueryFields. The Query option FromValue makes
+// Query and Mutate select fields this way.
+//
+// GenerateQueryFieldsFromValue panics if v can't be made into a query; see
+// GenerateQueryFieldsE.
+func GenerateQueryFieldsFromValue(v interface{}) string {
+	query, err := generateQueryFields(v, []QueryOption{FromValue()})
+	if err != nil {
+		panic(err)
+	}
+	return query
+}
+
+func generateQueryFields(v interface{}, opts []QueryOption) (string, error) {
+	o := newQueryOptions(opts)
+	if o.fromValue {
+		// Fields depend on the value, so they can't be cached.
+		var buf bytes.Buffer
+		err := writeQueryE(&buf, reflect.TypeOf(v), reflect.ValueOf(v), map[edge]int{}, []string{}, false, o)
+		return buf.String(), err
+	}
+	key := queryCacheKey{t: reflect.TypeOf(v), typename: o.typename, namer: o.fieldNamer}
+	if query, ok := queryCache.Load(key); ok {
+		return query.(string), nil
+	}
 	var buf bytes.Buffer
-	writeQuery(&buf, reflect.TypeOf(v), map[edge]int{}, []string{}, false)
-	return buf.String()
+	if err := writeQueryE(&buf, key.t, reflect.Value{}, map[edge]int{}, []string{}, false, o); err != nil {
+		return "", err
+	}
+	queryCache.Store(key, buf.String())
+	return buf.String(), nil
+}
+
+// queryCache holds the fields generated for each type, so that operations
+// on the same type don't walk it again. Type definitions, including the
+// tags that control recursion, can't change, so entries never go stale.
+var queryCache sync.Map // Of queryCacheKey to string.
+
+// queryCacheKey is the type and those query options that affect
+// the fields generated for it.
+type queryCacheKey struct {
+	t        reflect.Type
+	typename bool
+	namer    *fieldNamer
+}
+
+// queryError is used to unwind writeQuery on the first error.
+type queryError struct{ err error }
+
+// writeQueryE is like writeQuery, but returns the error writeQuery unwinds
+// with. Other panics, such as from a type the reflect package can't handle,
+// are returned as errors too, so that one bad type can't crash a program.
+func writeQueryE(w io.Writer, t reflect.Type, v reflect.Value, visited map[edge]int, visitPath []string, inline bool, opts *queryOptions) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			qe, ok := r.(queryError)
+			if !ok {
+				qe = queryError{fmt.Errorf("%s: panic: %v", t, r)}
+			}
+			err = qe.err
+		}
+	}()
+	writeQuery(w, t, v, visited, visitPath, inline, opts)
+	return nil
 }
 
 // edge is simply a tuple to key the visitation map that we use to keep
@@ -111,34 +290,78 @@ type edge struct {
 }
 
 // writeQuery writes a minified query for t to w.
+// If v is valid, it's a value of t, and nil struct pointers in it are left
+// out of the query.
 // If inline is true, the struct fields of t are inlined into parent struct.
-func writeQuery(w io.Writer, t reflect.Type, visited map[edge]int, visitPath []string, inline bool) {
+// It panics with a queryError if t can't be made into a query.
+func writeQuery(w io.Writer, t reflect.Type, v reflect.Value, visited map[edge]int, visitPath []string, inline bool, opts *queryOptions) {
 	switch t.Kind() {
-	case reflect.Ptr, reflect.Slice:
-		writeQuery(w, t.Elem(), visited, visitPath, false)
+	case reflect.Ptr:
+		if v.IsValid() {
+			v = v.Elem()
+		}
+		writeQuery(w, t.Elem(), v, visited, visitPath, false, opts)
+	case reflect.Slice:
+		// Elements may differ, so they're selected by type.
+		writeQuery(w, t.Elem(), reflect.Value{}, visited, visitPath, false, opts)
 	case reflect.Struct:
-		// If the type implements json.Unmarshaler, it's a scalar. Don't expand it.
-		if reflect.PtrTo(t).Implements(jsonUnmarshaler) {
+		// If the type decodes itself, it's a scalar. Don't expand it.
+		if isScalar(t) {
 			return
 		}
+		var f reflect.StructField
+		depth := len(visitPath)
+		defer func() {
+			// Give other panics the path to the field they're for.
+			if r := recover(); r != nil {
+				if _, ok := r.(queryError); !ok {
+					at := t.String()
+					if f.Name != "" {
+						at += "." + f.Name
+					}
+					path := append(visitPath[:depth:depth], at)
+					r = queryError{fmt.Errorf("%s: panic: %v", strings.Join(path, "->"), r)}
+				}
+				panic(r)
+			}
+		}()
 		if !inline {
 			io.WriteString(w, "{")
 		}
+		first := true
+		if !inline && opts.typename && !selectsTypename(t) {
+			io.WriteString(w, "__typename")
+			first = false
+		}
 		for i := 0; i < t.NumField(); i++ {
-			if i != 0 {
+			f = t.Field(i)
+			if isExcluded(f) {
+				continue
+			}
+			var fv reflect.Value
+			if v.IsValid() {
+				if fv = v.Field(i); isNilBranch(fv) {
+					continue
+				}
+			}
+			if !first {
 				io.WriteString(w, ",")
 			}
-			f := t.Field(i)
+			first = false
 
 			// Check how many times we've traversed this before (recursion limit).
 			edge := edge{t, i}
 			visited[edge]++
-			switch limit := getRecursionLimit(f); {
+			limit, err := getRecursionLimit(f)
+			if err != nil {
+				panic(queryError{fmt.Errorf("%s.%s: %v", t, f.Name, err)})
+			}
+			switch {
 			case limit < 2:
 				// if not recursion limit configured: cycle is error.
 				if visited[edge] > 1 {
 					visitPath = append(visitPath, t.Name())
-					panic(fmt.Errorf("cycle found: %s", strings.Join(visitPath, "->")))
+					panic(queryError{fmt.Errorf("cycle found: %s", strings.Join(visitPath, "->"))})
 				}
 			default:
 				// if recursion limit configured: if we're under, that's fine; if over, skip.
@@ -147,17 +370,25 @@ func writeQuery(w io.Writer, t reflect.Type, visited map[edge]int, visitPath []s
 				}
 			}
 
-			value, ok := f.Tag.Lookup("graphql")
+			value, ok := fieldTag(f)
+			if isMap(f.Type) && !ok {
+				panic(queryError{fmt.Errorf("%s.%s: map field must have a graphql tag", t, f.Name)})
+			}
 			inlineField := f.Anonymous && !ok
 			if !inlineField {
 				if ok {
 					io.WriteString(w, value)
 				} else {
-					io.WriteString(w, ident.ParseMixedCaps(f.Name).ToLowerCamelCase())
+					io.WriteString(w, opts.fieldName(f.Name))
 				}
 			}
+			if namedSpread(f) != "" {
+				// The fragment's fields are in its definition.
+				visited[edge]--
+				continue
+			}
 			visitPath = append(visitPath, t.String()+"."+f.Name)
-			writeQuery(w, f.Type, visited, visitPath, inlineField)
+			writeQuery(w, f.Type, fv, visited, visitPath, inlineField, opts)
 			visitPath = visitPath[:len(visitPath)-1]
 			visited[edge]--
 		}
@@ -167,19 +398,125 @@ func writeQuery(w io.Writer, t reflect.Type, visited map[edge]int, visitPath []s
 	}
 }
 
-func getRecursionLimit(f reflect.StructField) int {
+// isNilBranch reports whether v is a nil pointer to a struct whose fields
+// would be selected.
+func isNilBranch(v reflect.Value) bool {
+	if v.Kind() != reflect.Ptr || !v.IsNil() {
+		return false
+	}
+	t := v.Type().Elem()
+	return t.Kind() == reflect.Struct && !isScalar(t)
+}
+
+// selectsTypename reports whether struct t, including any structs inlined
+// into it, has a field selecting __typename.
+func selectsTypename(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		value, ok := fieldTag(f)
+		if strings.TrimSpace(value) == "__typename" {
+			return true
+		}
+		if f.Anonymous && !ok {
+			ft := f.Type
+			for ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct && selectsTypename(ft) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// fieldTag returns the selection in struct field f's graphql tag, without
+// the options that may follow it after a comma, such as ",required", and
+// whether f has one. A tag of only options, such as `graphql:",required"`,
+// selects the field by its name, as no tag does.
+func fieldTag(f reflect.StructField) (string, bool) {
+	value, ok := f.Tag.Lookup("graphql")
+	if !ok {
+		return "", false
+	}
+	value, _ = splitTagOptions(value)
+	if value == "" {
+		return "", false
+	}
+	return value, true
+}
+
+// splitTagOptions splits graphql tag value into the selection and the
+// options after its first comma outside of arguments, selection sets and
+// strings.
+func splitTagOptions(value string) (selection, options string) {
+	depth, inString := 0, false
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; {
+		case inString:
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+		case c == '(' || c == '{' || c == '[':
+			depth++
+		case c == ')' || c == '}' || c == ']':
+			depth--
+		case c == ',' && depth == 0:
+			return value[:i], value[i+1:]
+		}
+	}
+	return value, ""
+}
+
+// isExcluded reports whether struct field f is excluded from queries
+// by a `graphql:"-"` tag.
+func isExcluded(f reflect.StructField) bool {
+	return f.Tag.Get("graphql") == "-"
+}
+
+// isMap reports whether t is a map, or a pointer to or slice of them.
+// Map fields hold schema-less objects: their selection, if any, is given
+// by their tag.
+func isMap(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Map
+}
+
+func getRecursionLimit(f reflect.StructField) (int, error) {
 	value, ok := f.Tag.Lookup("graphql-recurse")
 	if !ok {
-		return 1
+		return 1, nil
 	}
 	n, err := strconv.Atoi(value)
 	if err != nil {
-		panic(fmt.Errorf("graphql-recurse tag should be int: %s", err))
+		return 0, fmt.Errorf("graphql-recurse tag should be int: %s", err)
 	}
 	if n < 2 {
-		panic(fmt.Errorf("graphql-recurse tag only makes sense for values greater than 1"))
+		return 0, fmt.Errorf("graphql-recurse tag only makes sense for values greater than 1")
 	}
-	return n
+	return n, nil
 }
 
-var jsonUnmarshaler = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+var (
+	jsonUnmarshaler = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshaler = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	textMarshaler   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	jsonMarshaler   = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// isScalar reports whether struct type t is a scalar, rather than an object
+// whose fields are selected: whether it's a custom scalar, with Marshaler or
+// Unmarshaler methods, it's encoded as JSON by methods of its own, as with
+// json.Unmarshaler, or as text, as with time.Time and other implementations
+// of encoding.TextUnmarshaler and encoding.TextMarshaler.
+func isScalar(t reflect.Type) bool {
+	pt := reflect.PtrTo(t)
+	return pt.Implements(gqlUnmarshaler) || pt.Implements(gqlMarshaler) ||
+		pt.Implements(jsonUnmarshaler) || pt.Implements(textUnmarshaler) || pt.Implements(textMarshaler)
+}
@@ -1,8 +1,11 @@
 package graphql
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/url"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -228,15 +231,173 @@ func TestConstructQuery(t *testing.T) {
 			}{},
 			want: `{viewer{login,createdAt,id,databaseId}}`,
 		},
+		{
+			inV: struct {
+				Synced bool `graphql:"-"` // Should be skipped, even first.
+				Viewer struct {
+					Login  String
+					Avatar struct {
+						URL String
+					} `graphql:"-"`
+					ID ID
+				}
+			}{},
+			want: `{viewer{login,id}}`,
+		},
+		{
+			inV: struct {
+				Viewer struct {
+					Login String `graphql:",required"`
+					Name  String `graphql:"fullName: name(format: \"a,b\"),required"`
+				} `graphql:"viewer,required"`
+			}{},
+			want: `{viewer{login,fullName: name(format: "a,b")}}`,
+		},
+	}
+	for _, tc := range tests {
+		got, err := constructQuery(tc.inV, tc.inVariables)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tc.want {
+			t.Errorf("\ngot:  %q\nwant: %q\n", got, tc.want)
+		}
+	}
+}
+
+func TestConstructQuery_directives(t *testing.T) {
+	v := struct {
+		Issue struct {
+			Title    String
+			Comments []struct {
+				Body String
+			} `graphql:"comments(first: 10) @include(if: $withComments)"`
+		} `graphql:"issue(number: $number)"`
+	}{}
+	got, err := constructQuery(v, map[string]interface{}{"number": Int(1), "withComments": Boolean(true)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `query($number:Int!$withComments:Boolean!){issue(number: $number){title,comments(first: 10) @include(if: $withComments){body}}}`
+	if got != want {
+		t.Errorf("\ngot:  %q\nwant: %q\n", got, want)
+	}
+}
+
+func TestConstructQuery_injectTypename(t *testing.T) {
+	type actor struct {
+		Login String
+	}
+	type event struct {
+		Actor actor
+	}
+	v := struct {
+		Node struct {
+			Typename string `graphql:"__typename"` // Not selected twice.
+			ID       ID
+			Event    struct {
+				event
+			} `graphql:"... on ClosedEvent"`
+		} `graphql:"node(id: $id)"`
+	}{}
+	got, err := constructQuery(v, map[string]interface{}{"id": WithType("ID!", "1")}, InjectTypename())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `query($id:ID!){__typename,node(id: $id){__typename,id,... on ClosedEvent{__typename,actor{__typename,login}}}}`
+	if got != want {
+		t.Errorf("\ngot:  %q\nwant: %q\n", got, want)
+	}
+}
+
+func TestConstructQuery_operationName(t *testing.T) {
+	var q struct {
+		Viewer struct {
+			Login String
+		}
+	}
+	tests := []struct {
+		construct func(interface{}, map[string]interface{}, ...QueryOption) (string, error)
+		variables map[string]interface{}
+		want      string
+	}{
+		{constructQuery, nil, `query GetViewer{viewer{login}}`},
+		{constructQuery, map[string]interface{}{"id": Int(1)}, `query GetViewer($id:Int!){viewer{login}}`},
+		{constructMutation, nil, `mutation GetViewer{viewer{login}}`},
 	}
 	for _, tc := range tests {
-		got := constructQuery(tc.inV, tc.inVariables)
+		got, err := tc.construct(q, tc.variables, OperationName("GetViewer"))
+		if err != nil {
+			t.Fatal(err)
+		}
 		if got != tc.want {
 			t.Errorf("\ngot:  %q\nwant: %q\n", got, tc.want)
 		}
 	}
 }
 
+func TestWrap(t *testing.T) {
+	var repo struct {
+		Name           String
+		StargazerCount Int
+	}
+	tests := []struct {
+		field, fields string
+		want          string
+	}{
+		{
+			field:  "repository(owner: $owner, name: $name)",
+			fields: GenerateQueryFields(repo),
+			want:   "{repository(owner: $owner, name: $name){name,stargazerCount}}",
+		},
+		{
+			field:  " viewer ",
+			fields: Wrap("r: repository(name: $name) @include(if: $withRepo)", "name"),
+			want:   "{viewer{r: repository(name: $name) @include(if: $withRepo){name}}}",
+		},
+		{
+			field: "totalCount",
+			want:  "{totalCount}",
+		},
+	}
+	for _, tc := range tests {
+		if got := Wrap(tc.field, tc.fields); got != tc.want {
+			t.Errorf("Wrap(%q, %q):\ngot:  %q\nwant: %q", tc.field, tc.fields, got, tc.want)
+		}
+	}
+}
+
+func TestGenerateQueryFieldsIndented(t *testing.T) {
+	var q struct {
+		Repository struct {
+			Issue struct {
+				Title    String
+				Comments struct {
+					Nodes []struct {
+						Body String
+					}
+				} `graphql:"comments(first: 10)"`
+			} `graphql:"issue(number: $number)"`
+		} `graphql:"repository(owner: $owner, name: $name)"`
+	}
+	got := GenerateQueryFieldsIndented(q, "\t")
+	want := `{
+	repository(owner: $owner, name: $name) {
+		issue(number: $number) {
+			title
+			comments(first: 10) {
+				nodes {
+					body
+				}
+			}
+		}
+	}
+}`
+	if got != want {
+		t.Errorf("\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
 func TestConstructMutation(t *testing.T) {
 	tests := []struct {
 		inV         interface{}
@@ -265,7 +426,10 @@ func TestConstructMutation(t *testing.T) {
 		},
 	}
 	for _, tc := range tests {
-		got := constructMutation(tc.inV, tc.inVariables)
+		got, err := constructMutation(tc.inV, tc.inVariables)
+		if err != nil {
+			t.Fatal(err)
+		}
 		if got != tc.want {
 			t.Errorf("\ngot:  %q\nwant: %q\n", got, tc.want)
 		}
@@ -274,8 +438,9 @@ func TestConstructMutation(t *testing.T) {
 
 func TestQueryArguments(t *testing.T) {
 	tests := []struct {
-		in   map[string]interface{}
-		want string
+		in      map[string]interface{}
+		scalars ScalarTypes
+		want    string
 	}{
 		{
 			in:   map[string]interface{}{"a": Int(123), "b": NewBoolean(true)},
@@ -304,7 +469,26 @@ func TestQueryArguments(t *testing.T) {
 		},
 		{
 			in:   map[string]interface{}{"id": ID("someID")},
-			want: "$id:ID!",
+			want: "$id:String!",
+		},
+		{
+			in:      map[string]interface{}{"id": ID("someID")},
+			scalars: ScalarTypes{reflect.TypeOf(""): "ID"},
+			want:    "$id:ID!",
+		},
+		{
+			in:   map[string]interface{}{"s": "x", "n": 1, "f": (*float64)(nil), "bs": []bool{}},
+			want: "$bs:[Boolean!]!$f:Float$n:Int!$s:String!",
+		},
+		{
+			in: map[string]interface{}{
+				"slug":    slug("hello-world"),
+				"slugs":   []*slug{},
+				"maybe":   (*slug)(nil),
+				"email":   WithType("EmailAddress!", "gopher@example.com"),
+				"aliases": WithType("[EmailAddress!]", []string{}),
+			},
+			want: "$aliases:[EmailAddress!]$email:EmailAddress!$maybe:Slug$slug:Slug!$slugs:[Slug]!",
 		},
 		{
 			in:   map[string]interface{}{"ids": []ID{"someID", "anotherID"}},
@@ -316,7 +500,7 @@ func TestQueryArguments(t *testing.T) {
 		},
 	}
 	for i, tc := range tests {
-		got := queryArguments(tc.in)
+		got := queryArguments(tc.in, nil, tc.scalars)
 		if got != tc.want {
 			t.Errorf("test case %d:\n got: %q\nwant: %q", i, got, tc.want)
 		}
@@ -377,6 +561,123 @@ func TestConstructRecursiveQuery(t *testing.T) {
 	})
 }
 
+func TestGenerateQueryFieldsE(t *testing.T) {
+	type Recurser struct {
+		Children []Recurser
+	}
+	type badLimit struct {
+		Children []Recurser `graphql-recurse:"many"`
+	}
+	tests := []struct {
+		in   interface{}
+		want string
+	}{
+		{Recurser{}, "cycle found: graphql.Recurser.Children->Recurser"},
+		{badLimit{}, `graphql.badLimit.Children: graphql-recurse tag should be int: strconv.Atoi: parsing "many": invalid syntax`},
+	}
+	for _, tc := range tests {
+		_, err := GenerateQueryFieldsE(tc.in)
+		if got := err; got == nil || got.Error() != tc.want {
+			t.Errorf("got error: %v, want: %v", got, tc.want)
+		}
+	}
+}
+
+func TestGenerateQueryFields_cached(t *testing.T) {
+	type viewer struct {
+		Login String
+	}
+	var q struct {
+		Viewer viewer
+	}
+	want := `{viewer{login}}`
+	for i := 0; i < 2; i++ {
+		if got := GenerateQueryFields(q); got != want {
+			t.Errorf("call %d: got: %q, want: %q", i, got, want)
+		}
+	}
+	if _, ok := queryCache.Load(queryCacheKey{t: reflect.TypeOf(q)}); !ok {
+		t.Error("fields were not cached")
+	}
+	// The options that change the fields are part of the key.
+	got, err := constructQuery(q, nil, InjectTypename())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{__typename,viewer{__typename,login}}`; got != want {
+		t.Errorf("got: %q, want: %q", got, want)
+	}
+}
+
+func TestGenerateQueryFields_map(t *testing.T) {
+	var q struct {
+		Page struct {
+			Blocks []map[string]interface{} `graphql:"blocks{type,data}"`
+			Meta   map[string]interface{}   `graphql:"meta"`
+		} `graphql:"page(slug: $slug)"`
+	}
+	if got, want := GenerateQueryFields(q), `{page(slug: $slug){blocks{type,data},meta}}`; got != want {
+		t.Errorf("\ngot:  %q\nwant: %q\n", got, want)
+	}
+
+	var untagged struct {
+		Meta map[string]interface{}
+	}
+	_, err := GenerateQueryFieldsE(untagged)
+	if got, want := err, "struct { Meta map[string]interface {} }.Meta: map field must have a graphql tag"; got == nil || got.Error() != want {
+		t.Errorf("got error: %v, want: %v", got, want)
+	}
+}
+
+func TestGenerateQueryFields_rawMessage(t *testing.T) {
+	var q struct {
+		Viewer struct {
+			Settings json.RawMessage `graphql:"settings{theme,locale}"`
+			Avatar   *json.RawMessage
+		}
+	}
+	if got, want := GenerateQueryFields(q), `{viewer{settings{theme,locale},avatar}}`; got != want {
+		t.Errorf("\ngot:  %q\nwant: %q\n", got, want)
+	}
+}
+
+func TestGenerateQueryFields_textScalars(t *testing.T) {
+	var q struct {
+		Event struct {
+			At  time.Time
+			Day civilDate
+		}
+	}
+	if got, want := GenerateQueryFields(q), `{event{at,day}}`; got != want {
+		t.Errorf("\ngot:  %q\nwant: %q\n", got, want)
+	}
+}
+
+func TestWithType_encoding(t *testing.T) {
+	b, err := json.Marshal(map[string]interface{}{"email": WithType("EmailAddress!", "gopher@example.com")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(b), `{"email":"gopher@example.com"}`; got != want {
+		t.Errorf("got: %s, want: %s", got, want)
+	}
+}
+
+// slug is a custom server scalar, represented as a string.
+type slug string
+
+func (slug) GraphQLType() string { return "Slug" }
+
+// civilDate is a date without a time, encoded as text.
+type civilDate struct {
+	Year, Month, Day int
+}
+
+func (d *civilDate) UnmarshalText(text []byte) error {
+	_, err := fmt.Sscanf(string(text), "%d-%d-%d", &d.Year, &d.Month, &d.Day)
+	return err
+}
+
 func gatherPanic(fn func()) (err error) {
 	defer func() {
 		rcvr := recover()
@@ -436,3 +737,170 @@ type AddReactionInput struct {
 	// A unique identifier for the client performing the mutation. (Optional.)
 	ClientMutationID *String `json:"clientMutationId,omitempty"`
 }
+
+// badTyper panics in GraphQLType, as it's called on the zero value.
+type badTyper struct{ name *string }
+
+func (t badTyper) GraphQLType() string { return *t.name }
+
+func TestConstructQuery_panic(t *testing.T) {
+	var q struct {
+		Viewer struct {
+			Login String
+		} `graphql:"viewer(t: $t)"`
+	}
+	_, err := constructQuery(q, map[string]interface{}{"t": badTyper{}})
+	if err == nil || !strings.HasPrefix(err.Error(), "graphql: panic declaring variables: ") {
+		t.Errorf("got error: %v, want a panic declaring variables", err)
+	}
+}
+
+func TestConstructQuery_maxDepth(t *testing.T) {
+	var q struct {
+		Viewer struct {
+			Login String
+			Repo  struct {
+				Issues []struct {
+					Title String
+				} `graphql:"issues(filter: {states: [OPEN]}, first: 10)"`
+				Event struct {
+					Actor struct {
+						Login String
+					}
+				} `graphql:"... on Event"`
+			} `graphql:"repo: repository(name: \"a\") @include(if: $withRepo)"`
+		}
+	}
+	tests := []struct {
+		max  int
+		want string
+	}{
+		{4, ""},
+		{3, "graphql: selection of issues is deeper than the maximum depth of 3"},
+		{1, "graphql: selection of viewer is deeper than the maximum depth of 1"},
+	}
+	for _, tc := range tests {
+		_, err := constructQuery(q, nil, MaxDepth(tc.max))
+		if tc.want == "" {
+			if err != nil {
+				t.Errorf("max depth %d: %v", tc.max, err)
+			}
+		} else if err == nil || err.Error() != tc.want {
+			t.Errorf("max depth %d: got error: %v, want: %v", tc.max, err, tc.want)
+		}
+	}
+}
+
+func TestConstructQuery_maxDepthInlineFragments(t *testing.T) {
+	var q struct {
+		Node struct {
+			User struct {
+				Name String
+			} `graphql:"... on User @include(if: true)"`
+			Any struct {
+				ID String
+			} `graphql:"... @skip(if: false)"`
+		}
+	}
+	if _, err := constructQuery(q, nil, MaxDepth(2)); err != nil {
+		t.Errorf("max depth 2: %v", err)
+	}
+	_, err := constructQuery(q, nil, MaxDepth(1))
+	if want := "graphql: selection of node is deeper than the maximum depth of 1"; err == nil || err.Error() != want {
+		t.Errorf("max depth 1: got error: %v, want: %v", err, want)
+	}
+}
+
+func TestVariablesFrom(t *testing.T) {
+	type paging struct {
+		First *Int `graphql:"first,omitempty"`
+		After *String
+	}
+	type vars struct {
+		Login   String
+		ID      string `graphql:"id" graphql-type:"ID!"`
+		Limit   int    `graphql:"max"`
+		Skipped String `graphql:"-"`
+		private String
+		paging
+	}
+	variables := VariablesFrom(&vars{Login: "gopher", ID: "1", Limit: 5})
+	if got, want := queryArguments(variables, nil, nil), "$after:String$id:ID!$login:String!$max:Int!"; got != want {
+		t.Errorf("got arguments: %q, want: %q", got, want)
+	}
+	b, err := json.Marshal(variables)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(b), `{"after":null,"id":"1","login":"gopher","max":5}`; got != want {
+		t.Errorf("got variables: %s, want: %s", got, want)
+	}
+
+	variables = VariablesFrom(vars{paging: paging{First: NewInt(10)}})
+	if got, want := queryArguments(variables, nil, nil), "$after:String$first:Int$id:ID!$login:String!$max:Int!"; got != want {
+		t.Errorf("got arguments: %q, want: %q", got, want)
+	}
+}
+
+func TestGenerateQueryFieldsFromValue(t *testing.T) {
+	type user struct {
+		Login String
+		Bio   *String
+	}
+	type issue struct {
+		Title  String
+		Author *user
+	}
+	type repository struct {
+		Name   String
+		Owner  *user
+		Issues *struct {
+			Nodes []issue
+		} `graphql:"issues(first: 10)"`
+		OnFork *struct {
+			Parent *struct{ Name String }
+		} `graphql:"... on Fork"`
+	}
+	tests := []struct {
+		in   repository
+		want string
+	}{
+		{
+			in:   repository{},
+			want: `{name}`,
+		},
+		{
+			in:   repository{Owner: &user{}},
+			want: `{name,owner{login,bio}}`,
+		},
+		{
+			in: repository{Issues: &struct {
+				Nodes []issue
+			}{}},
+			want: `{name,issues(first: 10){nodes{title,author{login,bio}}}}`,
+		},
+		{
+			in: repository{OnFork: &struct {
+				Parent *struct{ Name String }
+			}{Parent: &struct{ Name String }{}}},
+			want: `{name,... on Fork{parent{name}}}`,
+		},
+	}
+	for _, tc := range tests {
+		if got := GenerateQueryFieldsFromValue(&tc.in); got != tc.want {
+			t.Errorf("\ngot:  %q\nwant: %q\n", got, tc.want)
+		}
+	}
+
+	got, err := constructQuery(&repository{Owner: &user{}}, nil, FromValue())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{name,owner{login,bio}}`; got != want {
+		t.Errorf("got query: %q, want: %q", got, want)
+	}
+	// The type's query is still generated in full.
+	if got, want := GenerateQueryFields(&repository{}), `{name,owner{login,bio},issues(first: 10){nodes{title,author{login,bio}}},... on Fork{parent{name}}}`; got != want {
+		t.Errorf("got query: %q, want: %q", got, want)
+	}
+}
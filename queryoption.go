@@ -0,0 +1,102 @@
+package graphql
+
+import "github.com/dbmedialab/go-graphql-client/introspection"
+
+// QueryOption configures how the query for a single call to Query or Mutate
+// is derived from its struct. Defaults for every call can be set with
+// WithQueryOptions.
+type QueryOption func(*queryOptions)
+
+type queryOptions struct {
+	typename      bool    // Select __typename in every selection set.
+	operationName string  // Name of the operation, if not empty.
+	printer       Printer // Prints the query, if not nil.
+
+	schema  *introspection.Schema // Types variables, if not nil.
+	scalars ScalarTypes           // Types variables of Go scalar types, if not nil.
+
+	fieldNamer *fieldNamer // Names untagged fields, if not nil.
+	maxDepth   int         // Of the fields, if positive.
+	fromValue  bool        // Leave out nil branches of the value.
+
+	costModel *CostModel // Estimates the cost of operations, if not nil.
+	maxCost   int        // Of operations, if costModel isn't nil.
+}
+
+func newQueryOptions(opts []QueryOption) *queryOptions {
+	o := &queryOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// FromValue derives the query from the value of the struct given to Query
+// or Mutate, rather than just its type, leaving out nil struct pointers; see
+// GenerateQueryFieldsFromValue.
+func FromValue() QueryOption {
+	return func(o *queryOptions) {
+		o.fromValue = true
+	}
+}
+
+// InjectTypename selects __typename in every selection set of the query,
+// as union handling, caching, and debugging often need, without having to
+// add a field for it to every struct. Responses may then include
+// __typename for objects whose structs have no field for it.
+func InjectTypename() QueryOption {
+	return func(o *queryOptions) {
+		o.typename = true
+	}
+}
+
+// OperationName names the operation, so that servers and gateways can tell
+// it apart from others in logs and traces. The name is also sent as the
+// request's operationName.
+func OperationName(name string) QueryOption {
+	return func(o *queryOptions) {
+		o.operationName = name
+	}
+}
+
+// Indent formats the query with each field on its own line, indented by
+// indent per level of nesting, instead of minified, to make queries logged
+// or seen by the server easier to read.
+func Indent(indent string) QueryOption {
+	return WithPrinter(IndentPrinter(indent))
+}
+
+// WithPrinter prints the query with p, instead of minified.
+func WithPrinter(p Printer) QueryOption {
+	return func(o *queryOptions) {
+		o.printer = p
+	}
+}
+
+// WithQueryOptions sets query options used by every call to Query and
+// Mutate, before those given to the call itself.
+func WithQueryOptions(opts ...QueryOption) ClientOption {
+	return func(c *Client) {
+		c.queryOptions = append(c.queryOptions[:len(c.queryOptions):len(c.queryOptions)], opts...)
+	}
+}
+
+// WithSchema makes Query and Mutate declare each variable with the type of
+// the argument or input field it's bound to in s, so that variables needn't
+// be of this package's scalar types just to be declared correctly. Variables
+// bound to nothing s knows of are still typed by their values. A variable
+// bound by several fields, such as several root fields, is declared once,
+// with a type that suits all of them; the operation fails if there's none.
+func WithSchema(s *introspection.Schema) ClientOption {
+	return WithQueryOptions(func(o *queryOptions) {
+		o.schema = s
+	})
+}
+
+// withQueryOptions returns the client's query options followed by opts.
+func (c *Client) withQueryOptions(opts []QueryOption) []QueryOption {
+	if len(c.queryOptions) == 0 {
+		return opts
+	}
+	return append(c.queryOptions[:len(c.queryOptions):len(c.queryOptions)], opts...)
+}
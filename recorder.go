@@ -0,0 +1,228 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sync"
+)
+
+// Cassette is a recording of operations and their responses, made by
+// TransportRecorder and played back by TransportReplayer, such as for
+// test fixtures. It's safe for concurrent use.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+
+	mu   sync.Mutex
+	used map[int]bool // Interactions played back.
+}
+
+// Interaction is an operation in a cassette.
+type Interaction struct {
+	Request Request `json:"request"`
+	// Response is the response to the request, if there was one.
+	Response *Response `json:"response,omitempty"`
+	// Error is the error sending the request failed with, if it did.
+	Error string `json:"error,omitempty"`
+}
+
+// LoadCassette reads the cassette in file path, as written by WriteFile.
+func LoadCassette(path string) (*Cassette, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c := &Cassette{}
+	if err := json.Unmarshal(b, c); err != nil {
+		return nil, fmt.Errorf("graphql: cassette %s: %v", path, err)
+	}
+	return c, nil
+}
+
+// WriteFile writes c to file path, as indented JSON, replacing the file.
+func (c *Cassette) WriteFile(path string) error {
+	c.mu.Lock()
+	b, err := json.MarshalIndent(c, "", "  ")
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(b, '\n'), 0644)
+}
+
+func (c *Cassette) add(in Interaction) {
+	c.mu.Lock()
+	c.Interactions = append(c.Interactions, in)
+	c.mu.Unlock()
+}
+
+// Redacted replaces the values that Redaction redacts.
+const Redacted = "REDACTED"
+
+// Redaction says which values to replace with Redacted in cassettes, so
+// that they can be shared without secrets or personal data. HTTP headers,
+// including credentials, are never recorded.
+type Redaction struct {
+	// Variables names the variables, and fields of input objects at any
+	// depth, whose values are redacted.
+	Variables []string `json:"variables,omitempty"`
+	// Fields names the keys in response data, at any depth, whose values
+	// are redacted.
+	Fields []string `json:"fields,omitempty"`
+}
+
+// request returns req with the variables r names redacted.
+func (r *Redaction) request(req Request) (Request, error) {
+	if r == nil || len(r.Variables) == 0 || len(req.Variables) == 0 {
+		return req, nil
+	}
+	b, err := json.Marshal(req.Variables)
+	if err != nil {
+		return req, err
+	}
+	var variables map[string]interface{}
+	if err := decodeGeneric(b, &variables); err != nil {
+		return req, err
+	}
+	redact(variables, r.Variables)
+	req.Variables = variables
+	return req, nil
+}
+
+// response returns a copy of resp with the fields r names redacted.
+func (r *Redaction) response(resp *Response) (*Response, error) {
+	if r == nil || len(r.Fields) == 0 || len(resp.Data) == 0 {
+		return resp, nil
+	}
+	var data interface{}
+	if err := decodeGeneric(resp.Data, &data); err != nil {
+		return resp, err
+	}
+	redact(data, r.Fields)
+	b, err := json.Marshal(data)
+	if err != nil {
+		return resp, err
+	}
+	redacted := *resp
+	redacted.Data = b
+	return &redacted, nil
+}
+
+// decodeGeneric decodes JSON b into v, keeping numbers as they are.
+func decodeGeneric(b []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+// redact replaces the values of the keys in v, a value decoded from JSON,
+// that are named in keys with Redacted.
+func redact(v interface{}, keys []string) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, value := range v {
+			if containsString(keys, k) {
+				v[k] = Redacted
+			} else {
+				redact(value, keys)
+			}
+		}
+	case []interface{}:
+		for _, value := range v {
+			redact(value, keys)
+		}
+	}
+}
+
+func containsString(ss []string, s string) bool {
+	for _, x := range ss {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+// TransportRecorder is a Transport that sends requests with another, and
+// records each request, and the response or error, in a cassette.
+type TransportRecorder struct {
+	Transport Transport // Sends the requests.
+	Cassette  *Cassette // Records them.
+
+	// Redaction, if not nil, says what to leave out of the recording.
+	Redaction *Redaction
+}
+
+// Do sends req with t.Transport and records it.
+func (t TransportRecorder) Do(ctx context.Context, req Request) (*Response, error) {
+	resp, err := t.Transport.Do(ctx, req)
+	in := Interaction{}
+	var rerr error
+	if in.Request, rerr = t.Redaction.request(req); rerr != nil {
+		return resp, fmt.Errorf("graphql: redacting request: %v", rerr)
+	}
+	if err != nil {
+		in.Error = err.Error()
+	}
+	if resp != nil {
+		if in.Response, rerr = t.Redaction.response(resp); rerr != nil {
+			return resp, fmt.Errorf("graphql: redacting response: %v", rerr)
+		}
+	}
+	t.Cassette.add(in)
+	return resp, err
+}
+
+// TransportReplayer is a Transport that plays back the responses recorded
+// in a cassette, without sending requests anywhere. The response to a
+// request is that recorded for the first interaction with the same
+// canonical request (see Request.Canonical) that hasn't been played back
+// yet, or, if they all have, for the last of them. Requests that weren't
+// recorded fail.
+type TransportReplayer struct {
+	Cassette *Cassette
+
+	// Redaction, if not nil, is the redaction the cassette was recorded
+	// with, so that requests match those recorded.
+	Redaction *Redaction
+}
+
+// Do returns the response recorded for req.
+func (t TransportReplayer) Do(ctx context.Context, req Request) (*Response, error) {
+	req, err := t.Redaction.request(req)
+	if err != nil {
+		return nil, err
+	}
+	key, err := req.Canonical()
+	if err != nil {
+		return nil, err
+	}
+	c := t.Cassette
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	match := -1
+	for i, in := range c.Interactions {
+		recorded, err := in.Request.Canonical()
+		if err != nil || !bytes.Equal(recorded, key) {
+			continue
+		}
+		match = i
+		if !c.used[i] {
+			break
+		}
+	}
+	if match == -1 {
+		return nil, fmt.Errorf("graphql: no interaction recorded for request %s", key)
+	}
+	if c.used == nil {
+		c.used = map[int]bool{}
+	}
+	c.used[match] = true
+	in := c.Interactions[match]
+	if in.Error != "" {
+		return in.Response, fmt.Errorf("%s", in.Error)
+	}
+	return in.Response, nil
+}
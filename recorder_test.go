@@ -0,0 +1,98 @@
+package graphql_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dbmedialab/go-graphql-client"
+)
+
+func TestTransportRecorder(t *testing.T) {
+	transport := graphql.TransportFunc(func(ctx context.Context, req graphql.Request) (*graphql.Response, error) {
+		return &graphql.Response{Data: []byte(`{"viewer": {"login": "gopher", "email": "gopher@example.com", "age": 10}}`)}, nil
+	})
+	cassette := &graphql.Cassette{}
+	redaction := &graphql.Redaction{Variables: []string{"password"}, Fields: []string{"email"}}
+	client := graphql.NewPluggableClient(graphql.TransportRecorder{Transport: transport, Cassette: cassette, Redaction: redaction})
+
+	var q struct {
+		Viewer struct {
+			Login string
+			Email string
+			Age   int
+		} `graphql:"viewer(password: $password)"`
+	}
+	variables := map[string]interface{}{"password": graphql.String("hunter2")}
+	if err := client.Query(context.Background(), &q, variables); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := q.Viewer.Email, "gopher@example.com"; got != want {
+		t.Errorf("got email: %q, want: %q", got, want)
+	}
+
+	path := filepath.Join(os.TempDir(), "graphql-cassette-test.json")
+	defer os.Remove(path)
+	if err := cassette.WriteFile(path); err != nil {
+		t.Fatal(err)
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"hunter2", "gopher@example.com"} {
+		if strings.Contains(string(b), secret) {
+			t.Errorf("cassette holds %q:\n%s", secret, b)
+		}
+	}
+	if !strings.Contains(string(b), `"age": 10`) {
+		t.Errorf("cassette doesn't hold the unredacted age, as recorded:\n%s", b)
+	}
+
+	loaded, err := graphql.LoadCassette(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client = graphql.NewPluggableClient(graphql.TransportReplayer{Cassette: loaded, Redaction: redaction})
+	if err := client.Query(context.Background(), &q, variables); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := q.Viewer.Email, graphql.Redacted; got != want {
+		t.Errorf("got replayed email: %q, want: %q", got, want)
+	}
+	if got, want := q.Viewer.Login, "gopher"; got != want {
+		t.Errorf("got replayed login: %q, want: %q", got, want)
+	}
+
+	_, err = graphql.TransportReplayer{Cassette: loaded}.Do(context.Background(), graphql.Request{Query: "{viewer{login}}"})
+	if err == nil || !strings.Contains(err.Error(), "no interaction recorded") {
+		t.Errorf("got error: %v, want no interaction recorded", err)
+	}
+}
+
+func TestTransportReplayer_order(t *testing.T) {
+	req := graphql.Request{Query: "{n}"}
+	cassette := &graphql.Cassette{Interactions: []graphql.Interaction{
+		{Request: req, Response: &graphql.Response{Data: []byte(`{"n": 1}`)}},
+		{Request: req, Error: "connection reset"},
+		{Request: req, Response: &graphql.Response{Data: []byte(`{"n": 3}`)}},
+	}}
+	replayer := graphql.TransportReplayer{Cassette: cassette}
+	var got []string
+	for i := 0; i < 4; i++ {
+		resp, err := replayer.Do(context.Background(), req)
+		switch {
+		case err != nil:
+			got = append(got, err.Error())
+		default:
+			got = append(got, string(resp.Data))
+		}
+	}
+	want := []string{`{"n": 1}`, "connection reset", `{"n": 3}`, `{"n": 3}`}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("got: %q, want: %q", got, want)
+	}
+}
@@ -0,0 +1,111 @@
+package graphql
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/dbmedialab/go-graphql-client/internal/document"
+	"github.com/dbmedialab/go-graphql-client/introspection"
+)
+
+// Registry holds named GraphQL operations, parsed and checked once
+// (typically at init), for use with QueryCustom and MutateCustom.
+//
+// The zero value is an empty registry ready to use. It's safe for
+// concurrent use.
+type Registry struct {
+	// Schema, if set, is used to validate documents as they are registered.
+	Schema *introspection.Schema
+
+	mu  sync.RWMutex
+	ops map[string]*Operation
+}
+
+// Operation is a named operation held by a Registry.
+type Operation struct {
+	Name string
+	// Type is "query", "mutation" or "subscription".
+	Type string
+	// Document is the operation, followed by the definitions of
+	// the fragments it uses.
+	Document string
+	// Retry, if not nil, is how the registry's RetryMiddleware
+	// retries the operation.
+	Retry *RetryPolicy
+	// Timeout, if positive, is how long the registry's TimeoutMiddleware
+	// gives the operation when the caller's context has no deadline.
+	Timeout time.Duration
+}
+
+// Register parses the GraphQL documents and registers every operation in
+// them by name. Fragments defined in any of the documents may be used by
+// operations in the others. Nothing is registered if an error is returned.
+func (r *Registry) Register(documents ...string) error {
+	var sources []registrySource
+	for i, d := range documents {
+		sources = append(sources, registrySource{name: fmt.Sprintf("document %d", i), text: d})
+	}
+	return r.register(sources)
+}
+
+// Operation returns the named operation, or nil if it's not registered.
+func (r *Registry) Operation(name string) *Operation {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.ops[name]
+}
+
+// registrySource is a document to register, named for error messages.
+type registrySource struct {
+	name string
+	text string
+}
+
+func (r *Registry) register(sources []registrySource) error {
+	var docs []*document.Document
+	for _, src := range sources {
+		doc, err := document.Parse(src.text)
+		if err != nil {
+			return fmt.Errorf("%s: %v", src.name, err)
+		}
+		docs = append(docs, doc)
+	}
+
+	ops := map[string]*Operation{}
+	for i, doc := range docs {
+		for _, op := range doc.Operations {
+			if op.Name == "" {
+				return fmt.Errorf("%s: anonymous %s cannot be registered", sources[i].name, op.Type)
+			}
+			if _, ok := ops[op.Name]; ok {
+				return fmt.Errorf("%s: operation %s defined more than once", sources[i].name, op.Name)
+			}
+			text, err := document.Bundle(op, docs...)
+			if err != nil {
+				return fmt.Errorf("%s: %s %s: %v", sources[i].name, op.Type, op.Name, err)
+			}
+			if r.Schema != nil {
+				if err := r.Schema.Validate(text); err != nil {
+					return fmt.Errorf("%s: %v", sources[i].name, err)
+				}
+			}
+			ops[op.Name] = &Operation{Name: op.Name, Type: op.Type, Document: text}
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for name := range ops {
+		if _, ok := r.ops[name]; ok {
+			return fmt.Errorf("operation %s is already registered", name)
+		}
+	}
+	if r.ops == nil {
+		r.ops = map[string]*Operation{}
+	}
+	for name, op := range ops {
+		r.ops[name] = op
+	}
+	return nil
+}
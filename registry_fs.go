@@ -0,0 +1,47 @@
+//go:build go1.16
+// +build go1.16
+
+package graphql
+
+import (
+	"fmt"
+	"io/fs"
+)
+
+// LoadFS registers the operations in the files of fsys matching the
+// patterns, which use the syntax of fs.Glob. It's typically used with an
+// embed.FS, at init:
+//
+//	//go:embed queries/*.graphql
+//	var queries embed.FS
+//
+//	var registry graphql.Registry
+//
+//	func init() {
+//		if err := registry.LoadFS(queries, "queries/*.graphql"); err != nil {
+//			panic(err)
+//		}
+//	}
+//
+// Fragments defined in any of the files may be used by operations in the others.
+// Nothing is registered if an error is returned.
+func (r *Registry) LoadFS(fsys fs.FS, patterns ...string) error {
+	var sources []registrySource
+	for _, pattern := range patterns {
+		names, err := fs.Glob(fsys, pattern)
+		if err != nil {
+			return err
+		}
+		if len(names) == 0 {
+			return fmt.Errorf("pattern %s: no matching files", pattern)
+		}
+		for _, name := range names {
+			b, err := fs.ReadFile(fsys, name)
+			if err != nil {
+				return err
+			}
+			sources = append(sources, registrySource{name: name, text: string(b)})
+		}
+	}
+	return r.register(sources)
+}
@@ -0,0 +1,31 @@
+//go:build go1.16
+// +build go1.16
+
+package graphql_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/dbmedialab/go-graphql-client"
+)
+
+func TestRegistry_LoadFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"queries/hero.graphql":      {Data: []byte("query Hero { hero { ...heroFields } }")},
+		"queries/fragments.graphql": {Data: []byte("fragment heroFields on Character { name }")},
+		"README.md":                 {Data: []byte("not a document")},
+	}
+	var r graphql.Registry
+	if err := r.LoadFS(fsys, "queries/*.graphql"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := r.Operation("Hero").Document, "query Hero { hero { ...heroFields } }\nfragment heroFields on Character { name }"; got != want {
+		t.Errorf("got document:\n%s\nwant:\n%s", got, want)
+	}
+
+	err := r.LoadFS(fsys, "mutations/*.graphql")
+	if got, want := err, "pattern mutations/*.graphql: no matching files"; got == nil || got.Error() != want {
+		t.Errorf("got error: %v, want: %v", got, want)
+	}
+}
@@ -0,0 +1,74 @@
+package graphql_test
+
+import (
+	"testing"
+
+	"github.com/dbmedialab/go-graphql-client"
+	"github.com/dbmedialab/go-graphql-client/introspection"
+)
+
+func TestRegistry(t *testing.T) {
+	var r graphql.Registry
+	err := r.Register(
+		`query Hero { hero { ...heroFields } } mutation Like($id: ID!) { like(id: $id) }`,
+		`fragment heroFields on Character { name friends { ...heroFields } }`,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	op := r.Operation("Hero")
+	if op == nil {
+		t.Fatal("operation Hero not registered")
+	}
+	if got, want := op.Document, "query Hero { hero { ...heroFields } }\nfragment heroFields on Character { name friends { ...heroFields } }"; got != want {
+		t.Errorf("got document:\n%s\nwant:\n%s", got, want)
+	}
+	if got, want := r.Operation("Like").Type, "mutation"; got != want {
+		t.Errorf("got type %q, want %q", got, want)
+	}
+	if r.Operation("Missing") != nil {
+		t.Error("got non-nil operation for unregistered name")
+	}
+
+	// Registering the same name again fails, and registers nothing.
+	err = r.Register(`query Other { a } query Hero { b }`)
+	if got, want := err, "operation Hero is already registered"; got == nil || got.Error() != want {
+		t.Errorf("got error: %v, want: %v", got, want)
+	}
+	if r.Operation("Other") != nil {
+		t.Error("operation Other registered despite error")
+	}
+}
+
+func TestRegistry_errors(t *testing.T) {
+	schema, err := introspection.Parse([]byte(`{"__schema": {
+		"queryType": {"name": "Query"},
+		"types": [{"kind": "OBJECT", "name": "Query", "fields": [
+			{"name": "hero", "args": [], "type": {"kind": "SCALAR", "name": "String"}}
+		]}]
+	}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"{ hero }", "document 0: anonymous query cannot be registered"},
+		{"query A { hero } query A { hero }", "document 0: operation A defined more than once"},
+		{"query A { ...f }", "document 0: query A: unknown fragment f"},
+		{"query A { heroes }", "document 0: query A: type Query has no field heroes"},
+		{"query A {", `document 0: graphql: syntax error at 1:10: unexpected end of document, expected "}"`},
+	}
+	for _, tc := range tests {
+		r := graphql.Registry{Schema: schema}
+		err := r.Register(tc.in)
+		if err == nil {
+			t.Errorf("%q: got error: nil, want: %q", tc.in, tc.want)
+			continue
+		}
+		if err.Error() != tc.want {
+			t.Errorf("%q:\ngot error:  %q\nwant error: %q", tc.in, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package graphql
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ResultChange is a difference between two results of the same query.
+type ResultChange struct {
+	// Path is the path of the changed value in the response, made of
+	// response keys and list indices. E.g., "viewer.repositories[2].name".
+	Path string
+
+	// Old and New are the values before and after. For list elements that
+	// were added or removed, and for objects that appeared or disappeared,
+	// one of them is nil.
+	Old, New interface{}
+}
+
+func (c ResultChange) String() string {
+	return fmt.Sprintf("%s: %v -> %v", c.Path, c.Old, c.New)
+}
+
+// DiffResults compares two results, old and new, of the query derived from
+// their type, returning the changes from old to new in the order the fields
+// appear in the query. old and new must be of the same type, typically
+// pointers to the structs that were passed to Query.
+func DiffResults(old, new interface{}) ([]ResultChange, error) {
+	ov, nv := reflect.ValueOf(old), reflect.ValueOf(new)
+	if ov.Type() != nv.Type() {
+		return nil, fmt.Errorf("graphql: can't diff results of different types %T and %T", old, new)
+	}
+	var d resultDiffer
+	d.diff(nil, ov, nv)
+	return d.changes, nil
+}
+
+type resultDiffer struct {
+	changes []ResultChange
+}
+
+func (d *resultDiffer) add(path []string, old, new interface{}) {
+	d.changes = append(d.changes, ResultChange{Path: strings.Join(path, "."), Old: old, New: new})
+}
+
+func (d *resultDiffer) diff(path []string, ov, nv reflect.Value) {
+	switch ov.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		switch {
+		case ov.IsNil() && nv.IsNil():
+		case ov.IsNil():
+			d.add(path, nil, nv.Interface())
+		case nv.IsNil():
+			d.add(path, ov.Interface(), nil)
+		case ov.Kind() == reflect.Interface:
+			// E.g., an ID; compare the values in it.
+			if !reflect.DeepEqual(ov.Interface(), nv.Interface()) {
+				d.add(path, ov.Interface(), nv.Interface())
+			}
+		default:
+			d.diff(path, ov.Elem(), nv.Elem())
+		}
+	case reflect.Slice, reflect.Array:
+		n := ov.Len()
+		if nv.Len() > n {
+			n = nv.Len()
+		}
+		for i := 0; i < n; i++ {
+			elemPath := indexPath(path, i)
+			switch {
+			case i >= ov.Len():
+				d.add(elemPath, nil, nv.Index(i).Interface())
+			case i >= nv.Len():
+				d.add(elemPath, ov.Index(i).Interface(), nil)
+			default:
+				d.diff(elemPath, ov.Index(i), nv.Index(i))
+			}
+		}
+	case reflect.Struct:
+		t := ov.Type()
+		if isScalar(t) {
+			// A scalar.
+			if !reflect.DeepEqual(ov.Interface(), nv.Interface()) {
+				d.add(path, ov.Interface(), nv.Interface())
+			}
+			return
+		}
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if isExcluded(f) {
+				continue
+			}
+			value, ok := fieldTag(f)
+			if (f.Anonymous && !ok) || strings.HasPrefix(strings.TrimSpace(value), "...") {
+				// Embedded struct or fragment; its fields are those of this object.
+				d.diff(path, ov.Field(i), nv.Field(i))
+				continue
+			}
+			d.diff(append(path[:len(path):len(path)], responseKey(f)), ov.Field(i), nv.Field(i))
+		}
+	default:
+		if !reflect.DeepEqual(ov.Interface(), nv.Interface()) {
+			d.add(path, ov.Interface(), nv.Interface())
+		}
+	}
+}
+
+// indexPath returns path with the last element indexed by i.
+func indexPath(path []string, i int) []string {
+	p := append([]string(nil), path...)
+	index := fmt.Sprintf("[%d]", i)
+	if len(p) == 0 {
+		return []string{index}
+	}
+	p[len(p)-1] += index
+	return p
+}
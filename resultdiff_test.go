@@ -0,0 +1,48 @@
+package graphql_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dbmedialab/go-graphql-client"
+)
+
+func TestDiffResults(t *testing.T) {
+	type repo struct {
+		Name  graphql.String
+		Stars graphql.Int `graphql:"stars: stargazerCount"`
+	}
+	type query struct {
+		Viewer struct {
+			Login graphql.String
+			Bio   *graphql.String
+			Repos []repo `graphql:"repositories(first: 10)"`
+		}
+	}
+	var old, new query
+	old.Viewer.Login = "gopher"
+	old.Viewer.Repos = []repo{{"a", 1}, {"b", 2}}
+	new.Viewer.Login = "gopher"
+	new.Viewer.Bio = graphql.NewString("Hi")
+	new.Viewer.Repos = []repo{{"a", 3}}
+
+	got, err := graphql.DiffResults(&old, &new)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []graphql.ResultChange{
+		{Path: "viewer.bio", Old: nil, New: new.Viewer.Bio},
+		{Path: "viewer.repositories[0].stars", Old: graphql.Int(1), New: graphql.Int(3)},
+		{Path: "viewer.repositories[1]", Old: repo{"b", 2}, New: nil},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got changes:\n%v\nwant:\n%v", got, want)
+	}
+
+	if changes, err := graphql.DiffResults(&old, &old); err != nil || len(changes) != 0 {
+		t.Errorf("got changes %v, error %v for identical results, want none", changes, err)
+	}
+	if _, err := graphql.DiffResults(&old, &struct{}{}); err == nil {
+		t.Error("got error: nil, want: non-nil for different types")
+	}
+}
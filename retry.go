@@ -0,0 +1,112 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// RetryPolicy says how a failed operation may be retried.
+type RetryPolicy struct {
+	// Idempotent says the operation can safely be executed more than
+	// once. Operations that aren't are never retried.
+	Idempotent bool
+
+	// MaxAttempts is how many times the operation may be attempted in all,
+	// including the first time.
+	MaxAttempts int
+
+	// RetryableCodes are the error codes, from the "code" extension of
+	// errors in a response, that make it worth retrying. Failures to get
+	// a response at all are always worth retrying.
+	RetryableCodes []string
+
+	// Backoff is how long to wait before the first retry. It's doubled
+	// for each retry after that.
+	Backoff time.Duration
+
+	// MaxRetryAfter is the longest the server may ask for a retry to be
+	// put off, as RetryAfter reads it from the response, for the retry to
+	// be made: when it asks for longer, the failure is returned instead.
+	// If zero, the server's wait is always honored.
+	MaxRetryAfter time.Duration
+
+	// Clock tells the time, and waits between attempts if it's a
+	// SleepClock. If nil, the system clock is used.
+	Clock Clock
+}
+
+// SetRetryPolicy sets the retry policy of the named registered operation.
+func (r *Registry) SetRetryPolicy(name string, p RetryPolicy) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	op, ok := r.ops[name]
+	if !ok {
+		return fmt.Errorf("operation %s is not registered", name)
+	}
+	// Operations handed out already aren't modified.
+	updated := *op
+	updated.Retry = &p
+	r.ops[name] = &updated
+	return nil
+}
+
+// RetryMiddleware retries operations sent through next according to the
+// retry policies of the registered operations they're for, looked up by
+// the operation name in their queries. Other operations aren't retried.
+// Install it with WithMiddleware(r.RetryMiddleware).
+//
+// Failures the server says when to retry, with a Retry-After header or a
+// retryAfter extension, are retried after the wait it asks for, rather than
+// the backoff, whatever their error codes. Retries that couldn't be made
+// before the context's deadline aren't waited for.
+func (r *Registry) RetryMiddleware(next Transport) Transport {
+	return TransportFunc(func(ctx context.Context, req Request) (*Response, error) {
+		var p *RetryPolicy
+		if op := r.Operation(queryOperationName(req.Query)); op != nil {
+			p = op.Retry
+		}
+		if p == nil || !p.Idempotent {
+			return next.Do(ctx, req)
+		}
+		clock := clockOrSystem(p.Clock)
+		backoff := p.Backoff
+		for attempt := 1; ; attempt++ {
+			resp, err := next.Do(ctx, req)
+			after, hinted := retryAfter(resp, err)
+			if attempt >= p.MaxAttempts || !hinted && !p.retryable(resp, err) {
+				return resp, err
+			}
+			wait := backoff
+			if hinted {
+				if p.MaxRetryAfter > 0 && after > p.MaxRetryAfter {
+					return resp, err
+				}
+				wait = after
+			}
+			if deadline, ok := ctx.Deadline(); ok && deadline.Sub(clock.Now()) < wait {
+				return resp, err
+			}
+			if wait > 0 && !sleep(ctx, clock, wait) {
+				return resp, err
+			}
+			backoff *= 2
+		}
+	})
+}
+
+// retryable reports whether an attempt with result resp and err should be retried.
+func (p *RetryPolicy) retryable(resp *Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	for _, e := range resp.Errors {
+		code, _ := e.Extensions["code"].(string)
+		for _, c := range p.RetryableCodes {
+			if code == c {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,134 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dbmedialab/go-graphql-client"
+)
+
+// sleepClock is a SleepClock whose waits pass at once, moving its time on,
+// and are recorded.
+type sleepClock struct {
+	settableClock
+	mu    sync.Mutex
+	waits []time.Duration
+}
+
+func (c *sleepClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.waits = append(c.waits, d)
+	c.t = c.t.Add(d)
+	ch := make(chan time.Time, 1)
+	ch <- c.t
+	return ch
+}
+
+func (c *sleepClock) Waits() []time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]time.Duration(nil), c.waits...)
+}
+
+func TestRegistry_RetryMiddleware(t *testing.T) {
+	var r graphql.Registry
+	err := r.Register(`query Viewer { viewer { login } } mutation Like($id: ID!) { like(id: $id) }`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock := &sleepClock{settableClock: settableClock{t: time.Unix(0, 0)}}
+	if err := r.SetRetryPolicy("Viewer", graphql.RetryPolicy{Idempotent: true, MaxAttempts: 3, RetryableCodes: []string{"UNAVAILABLE"}, Backoff: time.Second, Clock: clock}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.SetRetryPolicy("Missing", graphql.RetryPolicy{}); err == nil {
+		t.Error("got error: nil, want: non-nil for an unregistered operation")
+	}
+
+	attempts := 0
+	transport := graphql.TransportFunc(func(ctx context.Context, req graphql.Request) (*graphql.Response, error) {
+		attempts++
+		switch {
+		case strings.HasPrefix(req.Query, "mutation"), attempts == 1:
+			return nil, fmt.Errorf("connection reset")
+		case attempts == 2:
+			resp := &graphql.Response{Data: []byte(`{"viewer": null}`)}
+			err := json.Unmarshal([]byte(`[{"message": "try again", "extensions": {"code": "UNAVAILABLE"}}]`), &resp.Errors)
+			return resp, err
+		}
+		return &graphql.Response{Data: []byte(`{"viewer": {"login": "gopher"}}`)}, nil
+	})
+	client := graphql.NewPluggableClient(transport, graphql.WithMiddleware(r.RetryMiddleware))
+
+	var q struct {
+		Viewer *struct {
+			Login graphql.String
+		}
+	}
+	if err := client.QueryCustom(context.Background(), &q, r.Operation("Viewer").Document, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := attempts, 3; got != want {
+		t.Errorf("got %d attempts, want %d", got, want)
+	}
+	if got, want := clock.Waits(), []time.Duration{time.Second, 2 * time.Second}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got waits %v, want %v", got, want)
+	}
+
+	// Operations without a policy aren't retried.
+	attempts = 0
+	var m struct{ Like graphql.Boolean }
+	err = client.MutateCustom(context.Background(), &m, r.Operation("Like").Document, map[string]interface{}{"id": graphql.ID("1")})
+	if err == nil {
+		t.Error("got error: nil, want: non-nil")
+	}
+	if got, want := attempts, 1; got != want {
+		t.Errorf("got %d attempts, want %d", got, want)
+	}
+}
+
+func TestRegistry_RetryMiddleware_retryAfter(t *testing.T) {
+	var r graphql.Registry
+	if err := r.Register(`query Viewer { viewer { login } }`); err != nil {
+		t.Fatal(err)
+	}
+	clock := &sleepClock{settableClock: settableClock{t: time.Unix(0, 0)}}
+	if err := r.SetRetryPolicy("Viewer", graphql.RetryPolicy{Idempotent: true, MaxAttempts: 3, Backoff: time.Hour, MaxRetryAfter: time.Minute, Clock: clock}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Errors the server says when to retry are retried after that wait,
+	// rather than the backoff, even without a retryable code.
+	waits := []string{"5", "3600"}
+	attempts := 0
+	transport := graphql.TransportFunc(func(ctx context.Context, req graphql.Request) (*graphql.Response, error) {
+		attempts++
+		resp := &graphql.Response{Data: []byte(`{"viewer": null}`)}
+		err := json.Unmarshal([]byte(`[{"message": "rate limited", "extensions": {"retryAfter": `+waits[attempts-1]+`}}]`), &resp.Errors)
+		return resp, err
+	})
+	client := graphql.NewPluggableClient(transport, graphql.WithMiddleware(r.RetryMiddleware))
+
+	var q struct {
+		Viewer *struct {
+			Login graphql.String
+		}
+	}
+	err := client.QueryCustom(context.Background(), &q, r.Operation("Viewer").Document, nil)
+	if got, want := err, "rate limited"; got == nil || got.Error() != want {
+		t.Errorf("got error: %v, want: %v", got, want)
+	}
+	// The second wait is longer than MaxRetryAfter, so isn't waited for.
+	if got, want := attempts, 2; got != want {
+		t.Errorf("got %d attempts, want %d", got, want)
+	}
+	if got, want := clock.Waits(), []time.Duration{5 * time.Second}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got waits %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,125 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// retryAfterExtensions are the extensions servers commonly give how long
+// to wait before retrying in, in seconds, or in milliseconds for those
+// ending in "Ms".
+var retryAfterExtensions = []string{"retryAfter", "retry_after", "retryAfterMs", "retry_after_ms"}
+
+// RetryAfter returns how long the server asked for the operation that
+// failed with err to be put off before it's retried, if it did: with the
+// Retry-After header of a response with status 429 Too Many Requests or 503
+// Service Unavailable, or with a retryAfter extension of a GraphQL error,
+// in seconds (or retryAfterMs, in milliseconds).
+func RetryAfter(err error) (time.Duration, bool) {
+	for err != nil {
+		switch e := err.(type) {
+		case *httpStatusError:
+			return e.retryAfter, e.hasRetryAfter
+		case errors:
+			return errorsRetryAfter(e)
+		}
+		u, ok := err.(interface{ Unwrap() error })
+		if !ok {
+			break
+		}
+		err = u.Unwrap()
+	}
+	return 0, false
+}
+
+// retryAfter is like RetryAfter, but also looks in the errors and
+// extensions of resp, the response to an attempt that failed with err.
+func retryAfter(resp *Response, err error) (time.Duration, bool) {
+	if err != nil || resp == nil {
+		return RetryAfter(err)
+	}
+	if d, ok := errorsRetryAfter(resp.Errors); ok {
+		return d, true
+	}
+	return extensionRetryAfter(resp.Extensions)
+}
+
+// errorsRetryAfter returns the longest wait the extensions of errs ask for.
+func errorsRetryAfter(errs errors) (d time.Duration, ok bool) {
+	for _, e := range errs {
+		if ed, eok := extensionRetryAfter(e.Extensions); eok && (!ok || ed > d) {
+			d, ok = ed, true
+		}
+	}
+	return d, ok
+}
+
+func extensionRetryAfter(extensions map[string]interface{}) (time.Duration, bool) {
+	for _, name := range retryAfterExtensions {
+		v, ok := extensions[name]
+		if !ok {
+			continue
+		}
+		var n float64
+		switch v := v.(type) {
+		case float64:
+			n = v
+		case json.Number:
+			n, _ = v.Float64()
+		case string:
+			var err error
+			if n, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		default:
+			continue
+		}
+		if n < 0 {
+			continue
+		}
+		unit := time.Second
+		if strings.HasSuffix(name, "Ms") || strings.HasSuffix(name, "_ms") {
+			unit = time.Millisecond
+		}
+		return time.Duration(n * float64(unit)), true
+	}
+	return 0, false
+}
+
+// parseRetryAfter parses the value of a Retry-After header, a number of
+// seconds or an HTTP date, as a wait from now.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if n < 0 {
+			return 0, false
+		}
+		return time.Duration(n) * time.Second, true
+	}
+	t, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	if d := t.Sub(now); d > 0 {
+		return d, true
+	}
+	return 0, true
+}
+
+// httpStatusError is the error of a response with an unexpected status.
+type httpStatusError struct {
+	status        string
+	retryAfter    time.Duration
+	hasRetryAfter bool
+}
+
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("unexpected status: %v", e.status)
+}
@@ -0,0 +1,56 @@
+package graphql_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/dbmedialab/go-graphql-client"
+)
+
+func TestRetryAfter(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/limited", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	mux.HandleFunc("/unavailable", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Retry-After", "Fri, 31 Dec 1999 23:59:59 GMT")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": null, "errors": [
+			{"message": "slow down", "extensions": {"retryAfterMs": 1500}},
+			{"message": "slower", "extensions": {"retryAfter": "2"}}
+		]}`)
+	})
+	client := &http.Client{Transport: localRoundTripper{handler: mux}}
+	var q struct{ Viewer struct{ Login string } }
+
+	tests := []struct {
+		url  string
+		want time.Duration
+		ok   bool
+	}{
+		{url: "/limited", want: 2 * time.Minute, ok: true},
+		// Dates that have passed ask for no wait at all.
+		{url: "/unavailable", want: 0, ok: true},
+		// The longest wait of the errors' is taken.
+		{url: "/graphql", want: 2 * time.Second, ok: true},
+	}
+	for _, tc := range tests {
+		err := graphql.NewClient(tc.url, client).Query(context.Background(), &q, nil)
+		if err == nil {
+			t.Fatalf("%s: got error: nil, want: non-nil", tc.url)
+		}
+		if got, ok := graphql.RetryAfter(err); got != tc.want || ok != tc.ok {
+			t.Errorf("%s: got %v, %v, want %v, %v", tc.url, got, ok, tc.want, tc.ok)
+		}
+	}
+	if _, ok := graphql.RetryAfter(fmt.Errorf("connection reset")); ok {
+		t.Error("got a wait for an error without one")
+	}
+}
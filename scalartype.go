@@ -0,0 +1,90 @@
+package graphql
+
+import (
+	"reflect"
+	"time"
+)
+
+// ScalarTypes maps Go types to the names of the GraphQL types that variables
+// of them are declared with, such as reflect.TypeOf("") to "String". As with
+// other types, variables of a mapped type are declared non-null ("String!")
+// and pointers to it nullable ("String"); slices of it are declared as lists.
+//
+// Types that aren't mapped are declared with the type named by their
+// GraphQLType method, if they have one, or else with their Go name, as this
+// package's scalar types are.
+type ScalarTypes map[reflect.Type]string
+
+// defaultScalarTypes maps Go's basic types to GraphQL's built-in scalars,
+// time.Time and time.Duration to the DateTime and Duration scalars that
+// servers commonly define, and byte slices to Base64.
+var defaultScalarTypes = ScalarTypes{
+	reflect.TypeOf(""):          "String",
+	reflect.TypeOf(false):       "Boolean",
+	reflect.TypeOf(int(0)):      "Int",
+	reflect.TypeOf(int8(0)):     "Int",
+	reflect.TypeOf(int16(0)):    "Int",
+	reflect.TypeOf(int32(0)):    "Int",
+	reflect.TypeOf(int64(0)):    "Int",
+	reflect.TypeOf(uint(0)):     "Int",
+	reflect.TypeOf(uint8(0)):    "Int",
+	reflect.TypeOf(uint16(0)):   "Int",
+	reflect.TypeOf(uint32(0)):   "Int",
+	reflect.TypeOf(float32(0)):  "Float",
+	reflect.TypeOf(float64(0)):  "Float",
+	reflect.TypeOf(time.Time{}): "DateTime",
+	reflect.TypeOf([]byte(nil)): "Base64",
+	durationType:                "Duration",
+}
+
+// DefaultScalarTypes returns the mapping used unless WithScalarTypes says
+// otherwise: Go's string, bool, integer and floating-point types are
+// declared as GraphQL's String, Boolean, Int and Float, time.Time as
+// DateTime, time.Duration as Duration, and []byte as Base64. time.Time
+// variables are sent in RFC 3339 format, and response fields of type
+// time.Time are decoded from it; time.Duration variables are sent in ISO
+// 8601 format, such as "PT1H30M", and fields decoded from it, or from Go's
+// format, such as "1h30m", or from a number of nanoseconds; []byte variables
+// and fields are base64-encoded strings (see WithBytesEncoding).
+// For servers whose scalars for times or bytes are named differently, map
+// the Go types to those names:
+//
+//	graphql.WithScalarTypes(graphql.ScalarTypes{
+//		reflect.TypeOf(time.Time{}): "Timestamp",
+//		reflect.TypeOf([]byte(nil)): "Bytes",
+//	})
+func DefaultScalarTypes() ScalarTypes {
+	return defaultScalarTypes.with(nil)
+}
+
+// with returns a copy of s with the mappings in types added, replacing
+// those for the same Go types.
+func (s ScalarTypes) with(types ScalarTypes) ScalarTypes {
+	m := make(ScalarTypes, len(s)+len(types))
+	for t, name := range s {
+		m[t] = name
+	}
+	for t, name := range types {
+		m[t] = name
+	}
+	return m
+}
+
+// WithScalarTypes makes Query and Mutate declare variables of the Go types
+// in types with the GraphQL types they're mapped to, in addition to, or
+// instead of, the default mapping.
+func WithScalarTypes(types ScalarTypes) ClientOption {
+	return WithQueryOptions(func(o *queryOptions) {
+		if o.scalars == nil {
+			o.scalars = defaultScalarTypes
+		}
+		o.scalars = o.scalars.with(types)
+	})
+}
+
+// StringsAsIDs makes Query and Mutate declare string variables as IDs, as
+// they once were by default, for code written against servers that take
+// string IDs as arguments far more often than strings.
+func StringsAsIDs() ClientOption {
+	return WithScalarTypes(ScalarTypes{reflect.TypeOf(""): "ID"})
+}
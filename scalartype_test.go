@@ -0,0 +1,114 @@
+package graphql_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/dbmedialab/go-graphql-client"
+)
+
+func TestWithScalarTypes(t *testing.T) {
+	type email string
+	tests := []struct {
+		name string
+		opts []graphql.ClientOption
+		want string
+	}{
+		{
+			name: "default",
+			want: "query($id:String!$to:email!){viewer{login}}",
+		},
+		{
+			name: "strings as IDs",
+			opts: []graphql.ClientOption{graphql.StringsAsIDs()},
+			want: "query($id:ID!$to:email!){viewer{login}}",
+		},
+		{
+			name: "custom",
+			opts: []graphql.ClientOption{
+				graphql.StringsAsIDs(),
+				graphql.WithScalarTypes(graphql.ScalarTypes{reflect.TypeOf(email("")): "EmailAddress"}),
+			},
+			want: "query($id:ID!$to:EmailAddress!){viewer{login}}",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var gotQuery string
+			transport := graphql.TransportFunc(func(ctx context.Context, req graphql.Request) (*graphql.Response, error) {
+				gotQuery = req.Query
+				return &graphql.Response{Data: []byte(`{"viewer": {"login": "gopher"}}`)}, nil
+			})
+			client := graphql.NewPluggableClient(transport, tc.opts...)
+			var q struct {
+				Viewer struct {
+					Login graphql.String
+				}
+			}
+			variables := map[string]interface{}{"id": "1", "to": email("gopher@example.com")}
+			if err := client.Query(context.Background(), &q, variables); err != nil {
+				t.Fatal(err)
+			}
+			if gotQuery != tc.want {
+				t.Errorf("got query: %q, want: %q", gotQuery, tc.want)
+			}
+		})
+	}
+}
+
+func TestDefaultScalarTypes(t *testing.T) {
+	types := graphql.DefaultScalarTypes()
+	if got, want := types[reflect.TypeOf("")], "String"; got != want {
+		t.Errorf("got string type: %q, want: %q", got, want)
+	}
+	types[reflect.TypeOf("")] = "ID"
+	if got, want := graphql.DefaultScalarTypes()[reflect.TypeOf("")], "String"; got != want {
+		t.Errorf("after changing a copy, got string type: %q, want: %q", got, want)
+	}
+}
+
+func TestClient_Query_time(t *testing.T) {
+	var gotBody string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		body, _ := ioutil.ReadAll(req.Body)
+		gotBody = string(body)
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": {"events": [{"at": "2020-01-02T03:04:05+01:00"}]}}`)
+	})
+	var q struct {
+		Events []struct {
+			At time.Time
+		} `graphql:"events(since: $since, until: $until)"`
+	}
+	variables := map[string]interface{}{
+		"since": time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		"until": (*time.Time)(nil),
+	}
+
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}})
+	if err := client.Query(context.Background(), &q, variables); err != nil {
+		t.Fatal(err)
+	}
+	want := `{"query":"query($since:DateTime!$until:DateTime){events(since: $since, until: $until){at}}","variables":{"since":"2020-01-01T00:00:00Z","until":null}}` + "\n"
+	if gotBody != want {
+		t.Errorf("got body: %q, want: %q", gotBody, want)
+	}
+	if len(q.Events) != 1 || !q.Events[0].At.Equal(time.Date(2020, 1, 2, 2, 4, 5, 0, time.UTC)) {
+		t.Errorf("got events: %v", q.Events)
+	}
+
+	client = graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}},
+		graphql.WithScalarTypes(graphql.ScalarTypes{reflect.TypeOf(time.Time{}): "Timestamp"}))
+	if err := client.Query(context.Background(), &q, variables); err != nil {
+		t.Fatal(err)
+	}
+	want = `{"query":"query($since:Timestamp!$until:Timestamp){events(since: $since, until: $until){at}}","variables":{"since":"2020-01-01T00:00:00Z","until":null}}` + "\n"
+	if gotBody != want {
+		t.Errorf("got body: %q, want: %q", gotBody, want)
+	}
+}
@@ -0,0 +1,59 @@
+package graphql_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dbmedialab/go-graphql-client"
+	"github.com/dbmedialab/go-graphql-client/introspection"
+)
+
+func TestWithSchema(t *testing.T) {
+	schema, err := introspection.Parse([]byte(`{"__schema": {
+		"queryType": {"name": "Query"},
+		"types": [
+			{"kind": "OBJECT", "name": "Query", "fields": [
+				{"name": "repository", "args": [
+					{"name": "owner", "type": {"kind": "NON_NULL", "ofType": {"kind": "SCALAR", "name": "String"}}},
+					{"name": "name", "type": {"kind": "NON_NULL", "ofType": {"kind": "SCALAR", "name": "String"}}}
+				], "type": {"kind": "OBJECT", "name": "Repository"}}
+			]},
+			{"kind": "OBJECT", "name": "Repository", "fields": [
+				{"name": "issue", "args": [
+					{"name": "number", "type": {"kind": "NON_NULL", "ofType": {"kind": "SCALAR", "name": "Int"}}}
+				], "type": {"kind": "OBJECT", "name": "Issue"}}
+			]},
+			{"kind": "OBJECT", "name": "Issue", "fields": [
+				{"name": "title", "args": [], "type": {"kind": "SCALAR", "name": "String"}}
+			]}
+		]
+	}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var gotQuery string
+	transport := graphql.TransportFunc(func(ctx context.Context, req graphql.Request) (*graphql.Response, error) {
+		gotQuery = req.Query
+		return &graphql.Response{Data: []byte(`{"repository": {"issue": {"title": "Bug"}}}`)}, nil
+	})
+	client := graphql.NewPluggableClient(transport, graphql.WithSchema(schema))
+
+	var q struct {
+		Repository struct {
+			Issue struct {
+				Title graphql.String
+			} `graphql:"issue(number: $number)"`
+		} `graphql:"repository(owner: $owner, name: $name)"`
+	}
+	variables := map[string]interface{}{
+		"owner":  "golang",
+		"name":   "go",
+		"number": 1,
+	}
+	if err := client.Query(context.Background(), &q, variables); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := gotQuery, `query($name:String!$number:Int!$owner:String!){repository(owner: $owner, name: $name){issue(number: $number){title}}}`; got != want {
+		t.Errorf("got query:\n%s\nwant:\n%s", got, want)
+	}
+}
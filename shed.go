@@ -0,0 +1,146 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Priority is the importance of an operation, for load shedding by a
+// Shedder. Higher values are more important.
+type Priority int
+
+// Common priorities. Operations have PriorityNormal unless the context
+// they're executed with says otherwise.
+const (
+	PriorityLow    Priority = -1
+	PriorityNormal Priority = 0
+	PriorityHigh   Priority = 1
+)
+
+type priorityKey struct{}
+
+// WithPriority returns a copy of ctx with which operations are executed
+// with priority p.
+func WithPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, p)
+}
+
+// priorityFrom returns the priority operations are executed with using ctx.
+func priorityFrom(ctx context.Context) Priority {
+	p, ok := ctx.Value(priorityKey{}).(Priority)
+	if !ok {
+		return PriorityNormal
+	}
+	return p
+}
+
+// ErrShed is returned for operations that a Shedder refused to execute.
+var ErrShed = withKinds(fmt.Errorf("graphql: operation shed under load"), ErrRateLimited)
+
+// Shedder limits how many operations are executed concurrently, shedding
+// or delaying less important operations first. Install it with
+// WithMiddleware(s.Middleware).
+//
+// Operations with a priority below PriorityNormal fail with ErrShed as soon
+// as LowPriorityLimit operations are in flight. Other operations wait for
+// one of the MaxInFlight slots, with more important operations getting
+// slots first.
+type Shedder struct {
+	// MaxInFlight is how many operations may be executed at once. If
+	// zero, there is no limit, and operations never wait.
+	MaxInFlight int
+
+	// LowPriorityLimit is how many operations may be in flight before low
+	// priority operations are shed. If zero, three quarters of MaxInFlight
+	// is used, unless there's no MaxInFlight either, in which case low
+	// priority operations aren't shed.
+	LowPriorityLimit int
+
+	mu       sync.Mutex
+	inFlight int
+	waiters  []*shedWaiter
+}
+
+type shedWaiter struct {
+	priority Priority
+	ready    chan struct{} // Closed once the waiter has been given a slot.
+}
+
+// Middleware sheds or delays operations sent through next.
+func (s *Shedder) Middleware(next Transport) Transport {
+	return TransportFunc(func(ctx context.Context, req Request) (*Response, error) {
+		if err := s.acquire(ctx, priorityFrom(ctx)); err != nil {
+			return nil, err
+		}
+		defer s.release()
+		return next.Do(ctx, req)
+	})
+}
+
+func (s *Shedder) lowPriorityLimit() int {
+	if s.LowPriorityLimit == 0 {
+		return s.MaxInFlight * 3 / 4
+	}
+	return s.LowPriorityLimit
+}
+
+// acquire takes a slot for an operation of priority p.
+func (s *Shedder) acquire(ctx context.Context, p Priority) error {
+	s.mu.Lock()
+	limited := s.MaxInFlight > 0
+	if p < PriorityNormal && (limited || s.LowPriorityLimit > 0) && s.inFlight >= s.lowPriorityLimit() {
+		s.mu.Unlock()
+		return ErrShed
+	}
+	if !limited || s.inFlight < s.MaxInFlight {
+		s.inFlight++
+		s.mu.Unlock()
+		return nil
+	}
+	w := &shedWaiter{priority: p, ready: make(chan struct{})}
+	s.waiters = append(s.waiters, w)
+	s.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for i, other := range s.waiters {
+			if other == w {
+				s.waiters = append(s.waiters[:i], s.waiters[i+1:]...)
+				return ctx.Err()
+			}
+		}
+		// The slot was handed over just as ctx was done; pass it on.
+		s.releaseLocked()
+		return ctx.Err()
+	}
+}
+
+// release gives up a slot.
+func (s *Shedder) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.releaseLocked()
+}
+
+// releaseLocked gives up a slot, handing it to the most important waiter,
+// first come first served among equals. s.mu must be held.
+func (s *Shedder) releaseLocked() {
+	if len(s.waiters) == 0 {
+		s.inFlight--
+		return
+	}
+	best := 0
+	for i, w := range s.waiters {
+		if w.priority > s.waiters[best].priority {
+			best = i
+		}
+	}
+	w := s.waiters[best]
+	s.waiters = append(s.waiters[:best], s.waiters[best+1:]...)
+	close(w.ready)
+}
@@ -0,0 +1,98 @@
+package graphql_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/dbmedialab/go-graphql-client"
+)
+
+func TestShedder(t *testing.T) {
+	s := &graphql.Shedder{MaxInFlight: 2, LowPriorityLimit: 1}
+	block := make(chan struct{})
+	started := make(chan string, 10)
+	transport := graphql.TransportFunc(func(ctx context.Context, req graphql.Request) (*graphql.Response, error) {
+		started <- req.Query
+		<-block
+		return &graphql.Response{Data: []byte(`{"a": 1}`)}, nil
+	})
+	client := graphql.NewPluggableClient(transport, graphql.WithMiddleware(s.Middleware))
+
+	query := func(ctx context.Context, query string) error {
+		var q struct{ A graphql.Int }
+		return client.QueryCustom(ctx, &q, query, nil)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	run := func(ctx context.Context, q string) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- query(ctx, q)
+		}()
+	}
+
+	// Fill both slots.
+	run(context.Background(), "{a}")
+	<-started
+	run(context.Background(), "{a}")
+	<-started
+
+	// A low priority operation is shed right away.
+	if err := query(graphql.WithPriority(context.Background(), graphql.PriorityLow), "{a}"); err != graphql.ErrShed {
+		t.Errorf("got error: %v, want: ErrShed", err)
+	}
+
+	// A canceled waiter gives up.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := query(ctx, "{a}"); err != context.Canceled {
+		t.Errorf("got error: %v, want: context.Canceled", err)
+	}
+
+	close(block)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestShedder_noLimit(t *testing.T) {
+	var s graphql.Shedder
+	block := make(chan struct{})
+	started := make(chan struct{}, 10)
+	transport := graphql.TransportFunc(func(ctx context.Context, req graphql.Request) (*graphql.Response, error) {
+		started <- struct{}{}
+		<-block
+		return &graphql.Response{Data: []byte(`{"a": 1}`)}, nil
+	})
+	client := graphql.NewPluggableClient(transport, graphql.WithMiddleware(s.Middleware))
+
+	// With no MaxInFlight, operations of any priority never wait.
+	var wg sync.WaitGroup
+	errs := make(chan error, 3)
+	for _, p := range []graphql.Priority{graphql.PriorityNormal, graphql.PriorityLow, graphql.PriorityHigh} {
+		wg.Add(1)
+		go func(p graphql.Priority) {
+			defer wg.Done()
+			var q struct{ A graphql.Int }
+			errs <- client.QueryCustom(graphql.WithPriority(context.Background(), p), &q, "{a}", nil)
+		}(p)
+	}
+	for i := 0; i < 3; i++ {
+		<-started
+	}
+	close(block)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+}
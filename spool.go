@@ -0,0 +1,148 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/dbmedialab/go-graphql-client/jsonutil"
+)
+
+// doSpooled executes a single GraphQL operation with t, spooling large
+// responses to disk, and decodes the response into v as it's read back.
+func (c *Client) doSpooled(ctx context.Context, t TransportHTTP, in Request, v interface{}, progress *progressReporter) error {
+	ctx, in, cancel := c.prepare(ctx, in)
+	defer cancel()
+	resp, err := t.post(ctx, in)
+	if err != nil {
+		return transportError(ctx, err)
+	}
+	var body io.Reader = resp.Body
+	if progress != nil {
+		body = progressReader{r: body, rep: progress}
+	}
+	var digest hash.Hash
+	var wantDigest []byte
+	if t.VerifyDigest {
+		digest, wantDigest, err = responseDigest(resp.Header)
+		if err != nil {
+			resp.Body.Close()
+			return transportError(ctx, err)
+		}
+		body = io.TeeReader(body, digest)
+	}
+	r, err := spool(body, t.SpoolThreshold)
+	resp.Body.Close()
+	if err != nil {
+		return transportError(ctx, err)
+	}
+	defer r.Close()
+	if digest != nil {
+		if err := checkDigest(digest, wantDigest); err != nil {
+			return transportError(ctx, err)
+		}
+	}
+
+	receive := func(ext map[string]interface{}) { receiveExtensions(ctx, ext) }
+	err = decodeResponse(r, v, c.decodeOptions(progress), receive)
+	if err == io.EOF && t.AllowEmptyResponses {
+		return nil
+	}
+	return decodeError(err)
+}
+
+// spool reads all of r, returning a reader of its contents. Contents larger
+// than threshold bytes are written to a temporary file, which is removed when
+// the returned reader is closed.
+func spool(r io.Reader, threshold int64) (io.ReadCloser, error) {
+	var buf bytes.Buffer
+	n, err := io.CopyN(&buf, r, threshold+1)
+	if err == io.EOF || (err == nil && n <= threshold) {
+		return ioutil.NopCloser(&buf), nil
+	} else if err != nil {
+		return nil, err
+	}
+	f, err := ioutil.TempFile("", "graphql-response-")
+	if err != nil {
+		return nil, err
+	}
+	sf := spoolFile{f}
+	if _, err := buf.WriteTo(f); err != nil {
+		sf.Close()
+		return nil, err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		sf.Close()
+		return nil, err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		sf.Close()
+		return nil, err
+	}
+	return sf, nil
+}
+
+// spoolFile is a temporary file that's removed when closed.
+type spoolFile struct {
+	*os.File
+}
+
+func (f spoolFile) Close() error {
+	err := f.File.Close()
+	if rerr := os.Remove(f.Name()); err == nil {
+		err = rerr
+	}
+	return err
+}
+
+// decodeResponse decodes a GraphQL response read from r, populating its data
+// into v token by token, without holding the encoded response in memory.
+// Its extensions, if any, are passed to receive.
+func decodeResponse(r io.Reader, v interface{}, opts jsonutil.Options, receive func(map[string]interface{})) error {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	if tok, err := dec.Token(); err != nil {
+		return err
+	} else if tok != json.Delim('{') {
+		return fmt.Errorf("unexpected token '%v' at start of response", tok)
+	}
+	var errs errors
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case "data":
+			err = jsonutil.DecodeGraphQL(dec, v, opts)
+		case "errors":
+			err = dec.Decode(&errs)
+		case "extensions":
+			// Decoded as by TransportHTTP, without UseNumber.
+			var raw json.RawMessage
+			var ext map[string]interface{}
+			if err = dec.Decode(&raw); err == nil {
+				err = json.Unmarshal(raw, &ext)
+			}
+			if err == nil && len(ext) > 0 {
+				receive(ext)
+			}
+		default:
+			// Skip anything else.
+			var skip json.RawMessage
+			err = dec.Decode(&skip)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package graphql
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSpool(t *testing.T) {
+	const body = `{"data": {}}`
+	for _, tc := range []struct {
+		threshold int64
+		wantFile  bool
+	}{
+		{threshold: int64(len(body)), wantFile: false},
+		{threshold: int64(len(body)) - 1, wantFile: true},
+	} {
+		r, err := spool(strings.NewReader(body), tc.threshold)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f, isFile := r.(spoolFile)
+		if isFile != tc.wantFile {
+			t.Errorf("threshold %d: got spooled to file: %v, want: %v", tc.threshold, isFile, tc.wantFile)
+		}
+		got, err := ioutil.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != body {
+			t.Errorf("threshold %d: got %q, want %q", tc.threshold, got, body)
+		}
+		if err := r.Close(); err != nil {
+			t.Error(err)
+		}
+		if isFile {
+			if _, err := os.Stat(f.Name()); !os.IsNotExist(err) {
+				t.Errorf("spool file %s not removed: %v", f.Name(), err)
+			}
+		}
+	}
+}
+
+func TestClient_spooled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"data": {"users": [{"login": "a"}, {"login": "b"}], "missing": null},
+			"errors": [{"message": "missing failed", "path": ["missing"]}],
+			"extensions": {"cost": 2}
+		}`))
+	}))
+	defer srv.Close()
+	client := NewPluggableClient(TransportHTTP{URL: srv.URL, SpoolThreshold: 16})
+
+	var q struct {
+		Users []struct {
+			Login String
+		}
+		Missing *struct {
+			Login String
+		}
+	}
+	err := client.Query(context.Background(), &q, nil)
+	if got, want := err, "missing failed"; got == nil || got.Error() != want {
+		t.Errorf("got error: %v, want: %v", got, want)
+	}
+	if len(q.Users) != 2 || q.Users[1].Login != "b" {
+		t.Errorf("got users: %+v", q.Users)
+	}
+}
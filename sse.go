@@ -0,0 +1,207 @@
+package graphql
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/shurcooL/go/ctxhttp"
+)
+
+var (
+	_ Transport             = TransportSSE{}
+	_ SubscriptionTransport = TransportSSE{}
+)
+
+// TransportSSE executes operations over Server-Sent Events, as the
+// graphql-sse protocol's distinct connections mode gives: each operation is
+// a POST request, whose response is a text/event-stream of its results.
+// It suits networks that don't let WebSockets through.
+//
+// It's a SubscriptionTransport, for Client.Subscribe, and a Transport, so a
+// client made with NewPluggableClient can send its queries and mutations
+// this way too. Servers that answer with a single JSON result instead of a
+// stream are understood as well.
+//
+// Protocol: https://github.com/enisdenjo/graphql-sse/blob/master/PROTOCOL.md.
+type TransportSSE struct {
+	URL        string // GraphQL server URL.
+	HTTPClient *http.Client
+}
+
+// Do executes req, returning its first result.
+func (t TransportSSE) Do(ctx context.Context, req Request) (*Response, error) {
+	stream, err := t.Subscribe(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+	out, err := stream.Next()
+	if err == io.EOF {
+		return nil, errNoResult
+	}
+	return out, err
+}
+
+// Subscribe sends req, and returns the stream of its results.
+func (t TransportSSE) Subscribe(ctx context.Context, req Request) (ResponseStream, error) {
+	if t.HTTPClient == nil {
+		t.HTTPClient = http.DefaultClient
+	}
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(req); err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequest("POST", t.URL, &buf)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range req.Header {
+		httpReq.Header[k] = v
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "text/event-stream")
+	ctx, cancel := context.WithCancel(ctx)
+	resp, err := ctxhttp.Do(ctx, t.HTTPClient, httpReq)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		cancel()
+		return nil, statusError(resp)
+	}
+	s := &sseStream{ctx: ctx, cancel: cancel, body: resp.Body}
+	if mt, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mt != "text/event-stream" {
+		s.single = true
+	} else {
+		s.events = newEventReader(resp.Body)
+	}
+	return s, nil
+}
+
+// sseStream is the stream of results of an operation sent by TransportSSE.
+type sseStream struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	body   io.ReadCloser
+
+	events *eventReader // Of the response, if it's an event stream.
+	single bool         // Of a response that's a single result, instead.
+}
+
+// Next returns the data of the next "next" event, or of the response if
+// it's a single result.
+func (s *sseStream) Next() (*Response, error) {
+	if s.single {
+		if s.body == nil {
+			return nil, io.EOF
+		}
+		var out Response
+		err := json.NewDecoder(s.body).Decode(&out)
+		s.body.Close()
+		s.body = nil
+		if err != nil {
+			return nil, s.err(err)
+		}
+		return &out, nil
+	}
+	for {
+		e, err := s.events.next()
+		if err == io.EOF {
+			// The server may end the stream without completing it.
+			return nil, io.EOF
+		}
+		if err != nil {
+			return nil, s.err(err)
+		}
+		switch e.typ {
+		case "next", "message":
+			var out Response
+			if err := json.Unmarshal([]byte(e.data), &out); err != nil {
+				return nil, err
+			}
+			return &out, nil
+		case "complete":
+			return nil, io.EOF
+		}
+	}
+}
+
+// Close stops the operation, by abandoning its response.
+func (s *sseStream) Close() error {
+	s.cancel()
+	if s.body != nil {
+		return s.body.Close()
+	}
+	return nil
+}
+
+// err returns the context's error, if it's done, since that's why reading
+// the response failed; err otherwise.
+func (s *sseStream) err(err error) error {
+	if e := s.ctx.Err(); e != nil {
+		return e
+	}
+	return err
+}
+
+// event is an event of an event stream.
+type event struct {
+	typ  string // "message", unless the event names its type.
+	data string
+}
+
+// eventReader reads the events of a text/event-stream.
+type eventReader struct {
+	r *bufio.Reader
+}
+
+func newEventReader(r io.Reader) *eventReader {
+	return &eventReader{r: bufio.NewReader(r)}
+}
+
+// next returns the next event, skipping any without data or type. It
+// returns io.EOF at the end of the stream.
+//
+// Specification: https://html.spec.whatwg.org/multipage/server-sent-events.html#event-stream-interpretation.
+func (r *eventReader) next() (event, error) {
+	var e event
+	var data []string
+	for {
+		line, err := r.r.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			return event{}, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			if e.typ == "" && data == nil {
+				continue
+			}
+			if e.typ == "" {
+				e.typ = "message"
+			}
+			e.data = strings.Join(data, "\n")
+			return e, nil
+		}
+		if strings.HasPrefix(line, ":") {
+			continue // A comment, such as a keep-alive.
+		}
+		field, value := line, ""
+		if i := strings.IndexByte(line, ':'); i >= 0 {
+			field, value = line[:i], strings.TrimPrefix(line[i+1:], " ")
+		}
+		switch field {
+		case "event":
+			e.typ = value
+		case "data":
+			data = append(data, value)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package graphql_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dbmedialab/go-graphql-client"
+)
+
+func TestTransportSSE(t *testing.T) {
+	var gotAccept, gotBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		gotAccept = req.Header.Get("Accept")
+		b, _ := ioutil.ReadAll(req.Body)
+		gotBody = string(b)
+		switch req.URL.Path {
+		case "/stream":
+			w.Header().Set("Content-Type", "text/event-stream")
+			mustWrite(w, ": keep-alive\n\n")
+			mustWrite(w, "event: next\ndata: {\"data\":\ndata: {\"tick\": 1}}\n\n")
+			w.(http.Flusher).Flush()
+			mustWrite(w, "event: next\r\ndata: {\"data\": {\"tick\": 2}}\r\n\r\n")
+			mustWrite(w, "event: complete\ndata:\n\n")
+		case "/json":
+			w.Header().Set("Content-Type", "application/json")
+			mustWrite(w, `{"data": {"tick": 3}}`)
+		case "/hang":
+			w.Header().Set("Content-Type", "text/event-stream")
+			mustWrite(w, "event: next\ndata: {\"data\": {\"tick\": 4}}\n\n")
+			w.(http.Flusher).Flush()
+			<-req.Context().Done()
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	var s struct{ Tick int }
+	client := graphql.NewPluggableClient(graphql.TransportSSE{URL: server.URL + "/stream"})
+	results, err := client.Subscribe(context.Background(), &s, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ticks []int
+	for r := range results {
+		if r.Err != nil {
+			t.Fatal(r.Err)
+		}
+		ticks = append(ticks, r.Data.(*struct{ Tick int }).Tick)
+	}
+	if len(ticks) != 2 || ticks[0] != 1 || ticks[1] != 2 {
+		t.Errorf("got ticks %v, want [1 2]", ticks)
+	}
+	if want := "text/event-stream"; gotAccept != want {
+		t.Errorf("got Accept %q, want %q", gotAccept, want)
+	}
+	if want := `{"query":"subscription{tick}"}` + "\n"; gotBody != want {
+		t.Errorf("got body %q, want %q", gotBody, want)
+	}
+
+	// Single results are understood too.
+	client = graphql.NewPluggableClient(graphql.TransportSSE{URL: server.URL + "/json"})
+	if err := client.Query(context.Background(), &s, nil); err != nil {
+		t.Fatal(err)
+	}
+	if s.Tick != 3 {
+		t.Errorf("got tick %d, want 3", s.Tick)
+	}
+
+	// Cancelling the context stops the subscription.
+	client = graphql.NewPluggableClient(graphql.TransportSSE{URL: server.URL + "/hang"})
+	ctx, cancel := context.WithCancel(context.Background())
+	results, err = client.Subscribe(ctx, &s, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := <-results; r.Err != nil || r.Data.(*struct{ Tick int }).Tick != 4 {
+		t.Errorf("got result %+v, want tick 4", r)
+	}
+	cancel()
+	if _, ok := <-results; ok {
+		t.Error("got a result after cancelling, want the channel closed")
+	}
+
+	client = graphql.NewPluggableClient(graphql.TransportSSE{URL: server.URL + "/missing"})
+	_, err = client.Subscribe(context.Background(), &s, nil)
+	if got, want := err, "unexpected status: 404 Not Found"; got == nil || got.Error() != want {
+		t.Errorf("got error: %v, want: %v", got, want)
+	}
+}
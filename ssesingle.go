@@ -0,0 +1,393 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/shurcooL/go/ctxhttp"
+)
+
+var (
+	_ Transport             = (*SSEConnection)(nil)
+	_ SubscriptionTransport = (*SSEConnection)(nil)
+)
+
+// sseTokenHeader is the header of the requests of single connection mode
+// that says which event stream they're for.
+const sseTokenHeader = "X-GraphQL-Event-Stream-Token"
+
+// SSEConnection executes operations over a single Server-Sent Events
+// stream, as the graphql-sse protocol's single connection mode gives, so
+// that any number of them take only one long-lived connection, for proxies
+// strict about how many there are. The stream is reserved with a PUT
+// request and opened with a GET, once there's an operation to carry; each
+// operation is then sent with a POST, and stopped with a DELETE. Once the
+// stream carries no more operations, it's closed.
+//
+// Like TransportSSE, it's a SubscriptionTransport and a Transport. It must
+// not be copied once used.
+//
+// Protocol: https://github.com/enisdenjo/graphql-sse/blob/master/PROTOCOL.md.
+type SSEConnection struct {
+	URL        string // GraphQL server URL.
+	HTTPClient *http.Client
+
+	// Header holds HTTP headers to send with the reservation and the
+	// event stream, such as for authentication. Operations are sent with
+	// their own headers.
+	Header http.Header
+
+	mu     sync.Mutex
+	stream *sseEventStream // If one is open.
+	lastID int64           // Of the last operation.
+}
+
+// sseEventStream is an event stream of an SSEConnection, and the operations
+// it carries.
+type sseEventStream struct {
+	token  string
+	cancel context.CancelFunc
+	ops    map[string]*sseOperation // By ID.
+}
+
+// Do executes req, returning its first result.
+func (c *SSEConnection) Do(ctx context.Context, req Request) (*Response, error) {
+	stream, err := c.Subscribe(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+	out, err := stream.Next()
+	if err == io.EOF {
+		return nil, errNoResult
+	}
+	return out, err
+}
+
+// Subscribe sends req over the event stream, opening it if needed, and
+// returns the stream of its results.
+func (c *SSEConnection) Subscribe(ctx context.Context, req Request) (ResponseStream, error) {
+	c.mu.Lock()
+	if c.stream == nil {
+		if err := c.connect(ctx); err != nil {
+			c.mu.Unlock()
+			return nil, err
+		}
+	}
+	c.lastID++
+	op := &sseOperation{
+		c:      c,
+		ctx:    ctx,
+		stream: c.stream,
+		id:     strconv.FormatInt(c.lastID, 10),
+		notify: make(chan struct{}, 1),
+	}
+	c.stream.ops[op.id] = op
+	c.mu.Unlock()
+
+	extensions := make(map[string]interface{}, len(req.Extensions)+1)
+	for k, v := range req.Extensions {
+		extensions[k] = v
+	}
+	extensions["operationId"] = op.id
+	req.Extensions = extensions
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(req); err != nil {
+		op.Close()
+		return nil, err
+	}
+	resp, err := c.send(ctx, "POST", c.URL, op.stream.token, req.Header, &buf)
+	if err != nil {
+		op.Close()
+		return nil, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted && resp.StatusCode != http.StatusOK {
+		op.Close()
+		return nil, statusError(resp)
+	}
+	return op, nil
+}
+
+// Close closes the event stream, failing the operations it carries.
+func (c *SSEConnection) Close() error {
+	c.mu.Lock()
+	s := c.stream
+	c.mu.Unlock()
+	if s != nil {
+		c.fail(s, fmt.Errorf("graphql: event stream closed"))
+	}
+	return nil
+}
+
+// connect reserves an event stream and opens it. c.mu must be held.
+func (c *SSEConnection) connect(ctx context.Context) error {
+	resp, err := c.send(ctx, "PUT", c.URL, "", c.Header, nil)
+	if err != nil {
+		return err
+	}
+	b, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return statusError(resp)
+	}
+	token := strings.TrimSpace(string(b))
+
+	// The stream outlives the operation opening it.
+	streamCtx, cancel := context.WithCancel(context.Background())
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			cancel()
+		case <-stop:
+		}
+	}()
+	req, err := c.request("GET", c.URL, token, c.Header, nil)
+	if err != nil {
+		cancel()
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err = ctxhttp.Do(streamCtx, c.client(), req)
+	if err != nil {
+		cancel()
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		cancel()
+		return statusError(resp)
+	}
+	s := &sseEventStream{token: token, cancel: cancel, ops: map[string]*sseOperation{}}
+	c.stream = s
+	go c.read(s, resp.Body)
+	return nil
+}
+
+// read dispatches the events of s, read from body, to its operations.
+func (c *SSEConnection) read(s *sseEventStream, body io.ReadCloser) {
+	defer body.Close()
+	events := newEventReader(body)
+	for {
+		e, err := events.next()
+		if err != nil {
+			if err == io.EOF {
+				err = fmt.Errorf("graphql: server closed the event stream")
+			}
+			c.fail(s, err)
+			return
+		}
+		if e.typ != "next" && e.typ != "complete" {
+			continue
+		}
+		var msg struct {
+			ID      string          `json:"id"`
+			Payload json.RawMessage `json:"payload"`
+		}
+		if json.Unmarshal([]byte(e.data), &msg) != nil {
+			continue
+		}
+		c.mu.Lock()
+		op := s.ops[msg.ID]
+		c.mu.Unlock()
+		if op == nil {
+			continue
+		}
+		if e.typ == "complete" {
+			op.push(sseResult{complete: true})
+			continue
+		}
+		var out Response
+		if err := json.Unmarshal(msg.Payload, &out); err != nil {
+			op.push(sseResult{err: err})
+			continue
+		}
+		op.push(sseResult{resp: &out})
+	}
+}
+
+// fail closes s, if it's still open, ending its operations with err.
+func (c *SSEConnection) fail(s *sseEventStream, err error) {
+	c.mu.Lock()
+	if c.stream != s {
+		// It's been closed already.
+		c.mu.Unlock()
+		return
+	}
+	c.stream = nil
+	ops := make([]*sseOperation, 0, len(s.ops))
+	for _, op := range s.ops {
+		ops = append(ops, op)
+	}
+	c.mu.Unlock()
+	s.cancel()
+	for _, op := range ops {
+		op.push(sseResult{err: err})
+	}
+}
+
+func (c *SSEConnection) client() *http.Client {
+	if c.HTTPClient == nil {
+		return http.DefaultClient
+	}
+	return c.HTTPClient
+}
+
+func (c *SSEConnection) request(method, url, token string, header http.Header, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	if token != "" {
+		req.Header.Set(sseTokenHeader, token)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return req, nil
+}
+
+// send sends a request of the protocol. The caller must close the response
+// body.
+func (c *SSEConnection) send(ctx context.Context, method, url, token string, header http.Header, body io.Reader) (*http.Response, error) {
+	req, err := c.request(method, url, token, header, body)
+	if err != nil {
+		return nil, err
+	}
+	return ctxhttp.Do(ctx, c.client(), req)
+}
+
+// sseResult is what an event stream delivers to an operation.
+type sseResult struct {
+	resp     *Response
+	err      error
+	complete bool
+}
+
+// sseOperation is an operation carried by an event stream of an
+// SSEConnection.
+type sseOperation struct {
+	c      *SSEConnection
+	ctx    context.Context
+	stream *sseEventStream
+	id     string
+
+	mu     sync.Mutex
+	queue  []sseResult   // Delivered, but not yet returned by Next.
+	notify chan struct{} // Signalled when a result is queued.
+
+	ended int32 // Set to 1 once the operation is completed or has failed.
+	once  sync.Once
+}
+
+// push queues r. Results are queued, rather than handed over, so that an
+// operation read slowly doesn't hold up the others.
+func (op *sseOperation) push(r sseResult) {
+	op.mu.Lock()
+	op.queue = append(op.queue, r)
+	op.mu.Unlock()
+	select {
+	case op.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (op *sseOperation) Next() (*Response, error) {
+	for {
+		op.mu.Lock()
+		if len(op.queue) > 0 {
+			r := op.queue[0]
+			op.queue = op.queue[1:]
+			op.mu.Unlock()
+			switch {
+			case r.err != nil:
+				atomic.StoreInt32(&op.ended, 1)
+				return nil, r.err
+			case r.complete:
+				atomic.StoreInt32(&op.ended, 1)
+				return nil, io.EOF
+			}
+			return r.resp, nil
+		}
+		op.mu.Unlock()
+		select {
+		case <-op.notify:
+		case <-op.ctx.Done():
+			return nil, op.ctx.Err()
+		}
+	}
+}
+
+// Close stops the operation, unless it has ended, and closes the event
+// stream if it carries no others.
+func (op *sseOperation) Close() error {
+	var err error
+	op.once.Do(func() {
+		c, s := op.c, op.stream
+		c.mu.Lock()
+		delete(s.ops, op.id)
+		last := c.stream == s && len(s.ops) == 0
+		if last {
+			c.stream = nil
+		}
+		c.mu.Unlock()
+		if atomic.LoadInt32(&op.ended) == 0 && !op.completing() {
+			err = op.stop()
+		}
+		if last {
+			s.cancel()
+		}
+	})
+	return err
+}
+
+// completing reports whether the operation's completion is queued, as it
+// is when a query's result has been read, but not the end that follows it.
+func (op *sseOperation) completing() bool {
+	op.mu.Lock()
+	defer op.mu.Unlock()
+	for _, r := range op.queue {
+		if r.complete || r.err != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// stop asks the server to stop the operation.
+func (op *sseOperation) stop() error {
+	u, err := url.Parse(op.c.URL)
+	if err != nil {
+		return err
+	}
+	q := u.Query()
+	q.Set("operationId", op.id)
+	u.RawQuery = q.Encode()
+	resp, err := op.c.send(context.Background(), "DELETE", u.String(), op.stream.token, op.c.Header, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return statusError(resp)
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/dbmedialab/go-graphql-client"
+)
+
+// sseSingleServer is a server of graphql-sse's single connection mode,
+// which answers each operation with a result holding its ID, completing it
+// unless it's a subscription.
+type sseSingleServer struct {
+	mu               sync.Mutex
+	puts, gets       int
+	deleted          []string
+	events           chan string
+	missingTokenSeen bool
+}
+
+func (s *sseSingleServer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if req.Method != "PUT" && req.Header.Get("X-GraphQL-Event-Stream-Token") != fmt.Sprint("token", s.puts) {
+		s.missingTokenSeen = true
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	switch req.Method {
+	case "PUT":
+		s.puts++
+		s.events = make(chan string, 10)
+		w.WriteHeader(http.StatusCreated)
+		mustWrite(w, fmt.Sprint("token", s.puts))
+	case "GET":
+		s.gets++
+		events := s.events
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.(http.Flusher).Flush()
+		s.mu.Unlock()
+		defer s.mu.Lock()
+		for {
+			select {
+			case e := <-events:
+				mustWrite(w, e)
+				w.(http.Flusher).Flush()
+			case <-req.Context().Done():
+				return
+			}
+		}
+	case "POST":
+		var r struct {
+			Query      string
+			Extensions struct{ OperationID string }
+		}
+		json.NewDecoder(req.Body).Decode(&r)
+		id := r.Extensions.OperationID
+		s.events <- fmt.Sprintf("event: next\ndata: {\"id\": %q, \"payload\": {\"data\": {\"op\": %q}}}\n\n", id, id)
+		if r.Query[0] != 's' {
+			s.events <- fmt.Sprintf("event: complete\ndata: {\"id\": %q}\n\n", id)
+		}
+		w.WriteHeader(http.StatusAccepted)
+	case "DELETE":
+		s.deleted = append(s.deleted, req.URL.Query().Get("operationId"))
+	}
+}
+
+func TestSSEConnection(t *testing.T) {
+	s := &sseSingleServer{}
+	server := httptest.NewServer(s)
+	defer server.Close()
+
+	conn := &graphql.SSEConnection{URL: server.URL}
+	client := graphql.NewPluggableClient(conn)
+	var sub struct{ Op string }
+	ctx, cancel := context.WithCancel(context.Background())
+	results, err := client.Subscribe(ctx, &sub, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Both operations are carried by one stream.
+	var q struct{ Op string }
+	if err := client.Query(context.Background(), &q, nil); err != nil {
+		t.Fatal(err)
+	}
+	if q.Op != "2" {
+		t.Errorf("got query result for operation %q, want 2", q.Op)
+	}
+	if r := <-results; r.Err != nil || r.Data.(*struct{ Op string }).Op != "1" {
+		t.Errorf("got subscription result %+v, want one for operation 1", r)
+	}
+	cancel()
+	for range results {
+	}
+
+	// The stream was closed with the last operation, so another is opened.
+	if err := client.Query(context.Background(), &q, nil); err != nil {
+		t.Fatal(err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.puts != 2 || s.gets != 2 {
+		t.Errorf("got %d reservations and %d streams, want 2 of each", s.puts, s.gets)
+	}
+	// The subscription, which the server didn't complete, is stopped.
+	stopped := false
+	for _, id := range s.deleted {
+		stopped = stopped || id == "1"
+	}
+	if !stopped {
+		t.Errorf("got operations %q stopped, want 1 among them", s.deleted)
+	}
+	if s.missingTokenSeen {
+		t.Error("got a request without the stream's token")
+	}
+}
@@ -0,0 +1,147 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/dbmedialab/go-graphql-client/internal/document"
+)
+
+// StaleResultError is returned, along with a populated result, when an
+// operation failed but an earlier result for it was served instead
+// by a StaleCache.
+type StaleResultError struct {
+	Err      error     // The error the operation failed with.
+	StoredAt time.Time // When the stale result was received.
+
+	response *Response
+}
+
+func (e *StaleResultError) Error() string {
+	return "graphql: serving stale result: " + e.Err.Error()
+}
+
+// Unwrap returns the error the operation failed with.
+func (e *StaleResultError) Unwrap() error { return e.Err }
+
+// StaleCache keeps the latest successful response to each query, and serves
+// it when the same query later fails to reach the server, for read paths
+// that prefer stale data to an outage. Install it with
+// WithMiddleware(c.Middleware). The result is still populated in that
+// case, and the operation returns a *StaleResultError.
+//
+// Responses are kept per tenant, headers, endpoint, and URL overrides, as
+// well as per query and variables, so a cache shared by the clients of a
+// ClientFactory never serves one tenant another's data.
+//
+// Only queries are cached; mutations and subscriptions are never served
+// stale. Responses with errors aren't cached, and errors reported by the
+// server, as opposed to failures to get a response at all, are returned
+// as usual.
+type StaleCache struct {
+	// MaxAge is how old a response may be to still be served.
+	// If zero, there is no limit.
+	MaxAge time.Duration
+
+	// MaxEntries is how many responses are kept. When it's exceeded, the
+	// oldest response is dropped. If zero, there is no limit.
+	MaxEntries int
+
+	// Clock tells the time. If nil, the system clock is used.
+	Clock Clock
+
+	mu      sync.Mutex
+	entries map[string]staleEntry
+}
+
+type staleEntry struct {
+	response *Response
+	storedAt time.Time
+}
+
+// Middleware caches responses to queries sent through next,
+// and serves them when next fails.
+func (c *StaleCache) Middleware(next Transport) Transport {
+	return TransportFunc(func(ctx context.Context, req Request) (*Response, error) {
+		if !isQuery(req.Query) {
+			return next.Do(ctx, req)
+		}
+		key, err := cacheKey(ctx, req)
+		if err != nil {
+			return next.Do(ctx, req)
+		}
+
+		resp, err := next.Do(ctx, req)
+		now := clockOrSystem(c.Clock).Now()
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if err == nil {
+			if len(resp.Errors) == 0 {
+				c.store(key, staleEntry{response: resp, storedAt: now})
+			}
+			return resp, nil
+		}
+		e, ok := c.entries[key]
+		if !ok || (c.MaxAge > 0 && now.Sub(e.storedAt) > c.MaxAge) {
+			return nil, err
+		}
+		return nil, &StaleResultError{Err: err, StoredAt: e.storedAt, response: e.response}
+	})
+}
+
+// store stores e under key, evicting the oldest entry if there are too many.
+// c.mu must be held.
+func (c *StaleCache) store(key string, e staleEntry) {
+	if c.entries == nil {
+		c.entries = map[string]staleEntry{}
+	}
+	c.entries[key] = e
+	if c.MaxEntries > 0 && len(c.entries) > c.MaxEntries {
+		var oldest string
+		for k, e := range c.entries {
+			if oldest == "" || e.storedAt.Before(c.entries[oldest].storedAt) {
+				oldest = k
+			}
+		}
+		delete(c.entries, oldest)
+	}
+}
+
+// cacheKey returns the key of the response to req, sent with ctx: its
+// canonical form, and what else decides the response, such as who it's for
+// and where it's sent.
+func cacheKey(ctx context.Context, req Request) (string, error) {
+	canonical, err := req.Canonical()
+	if err != nil {
+		return "", err
+	}
+	tenant, _ := ctx.Value(tenantKey{}).(string)
+	endpoint, _ := ctx.Value(endpointKey{}).(string)
+	o := httpOverridesFrom(ctx)
+	scope, err := json.Marshal(struct {
+		Tenant   string      `json:"tenant,omitempty"`
+		Header   http.Header `json:"header,omitempty"`
+		Endpoint string      `json:"endpoint,omitempty"`
+		Method   string      `json:"method,omitempty"`
+		Path     string      `json:"path,omitempty"`
+		Query    url.Values  `json:"query,omitempty"`
+	}{tenant, req.Header, endpoint, o.method, o.path, o.query})
+	if err != nil {
+		return "", err
+	}
+	return string(canonical) + "\n" + string(scope), nil
+}
+
+// isQuery reports whether the operation in query is a query,
+// as opposed to a mutation or subscription.
+func isQuery(query string) bool {
+	toks, err := document.Tokenize(query)
+	if err != nil || len(toks) == 0 {
+		return false
+	}
+	return toks[0].Value == "{" || toks[0].Value == "query"
+}
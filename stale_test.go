@@ -0,0 +1,114 @@
+package graphql_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/dbmedialab/go-graphql-client"
+)
+
+func TestStaleCache(t *testing.T) {
+	clock := &settableClock{t: time.Unix(600, 0)}
+	cache := &graphql.StaleCache{MaxAge: time.Hour, Clock: clock}
+	down := false
+	transport := graphql.TransportFunc(func(ctx context.Context, req graphql.Request) (*graphql.Response, error) {
+		if down {
+			return nil, fmt.Errorf("connection refused")
+		}
+		return &graphql.Response{Data: []byte(`{"viewer": {"login": "gopher"}}`)}, nil
+	})
+	client := graphql.NewPluggableClient(transport, graphql.WithMiddleware(cache.Middleware))
+
+	type query struct {
+		Viewer struct {
+			Login graphql.String
+		}
+	}
+	var q query
+	if err := client.Query(context.Background(), &q, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	down = true
+	clock.t = clock.t.Add(time.Minute)
+	q = query{}
+	err := client.Query(context.Background(), &q, nil)
+	stale, ok := err.(*graphql.StaleResultError)
+	if !ok {
+		t.Fatalf("got error: %v, want: *StaleResultError", err)
+	}
+	if got, want := stale.Err.Error(), "connection refused"; got != want {
+		t.Errorf("got underlying error: %v, want: %v", got, want)
+	}
+	if !stale.StoredAt.Equal(time.Unix(600, 0)) {
+		t.Errorf("got stored at: %v, want: %v", stale.StoredAt, time.Unix(600, 0))
+	}
+	if got, want := q.Viewer.Login, graphql.String("gopher"); got != want {
+		t.Errorf("got stale login: %q, want: %q", got, want)
+	}
+
+	// Too old results aren't served.
+	clock.t = clock.t.Add(time.Hour)
+	err = client.Query(context.Background(), &q, nil)
+	if _, ok := err.(*graphql.StaleResultError); ok || err == nil {
+		t.Errorf("got error: %v, want: connection refused", err)
+	}
+
+	// Mutations are never served stale.
+	var m struct {
+		Viewer struct {
+			Login graphql.String
+		}
+	}
+	down = false
+	if err := client.Mutate(context.Background(), &m, nil); err != nil {
+		t.Fatal(err)
+	}
+	down = true
+	if err := client.Mutate(context.Background(), &m, nil); err == nil || err.Error() != "connection refused" {
+		t.Errorf("got error: %v, want: connection refused", err)
+	}
+}
+
+func TestStaleCache_scope(t *testing.T) {
+	cache := &graphql.StaleCache{}
+	down := false
+	transport := graphql.TransportFunc(func(ctx context.Context, req graphql.Request) (*graphql.Response, error) {
+		if down {
+			return nil, fmt.Errorf("down")
+		}
+		return &graphql.Response{Data: []byte(`{"viewer": {"login": "` + req.Header.Get("X-User") + `"}}`)}, nil
+	})
+	base := graphql.NewPluggableClient(transport, graphql.WithMiddleware(cache.Middleware))
+	alice := base.With(graphql.WithHeader("X-User", "alice"))
+
+	var q struct {
+		Viewer struct {
+			Login graphql.String
+		}
+	}
+	if err := alice.Query(context.Background(), &q, nil); err != nil {
+		t.Fatal(err)
+	}
+	down = true
+	for name, query := range map[string]func() error{
+		"header": func() error {
+			return base.With(graphql.WithHeader("X-User", "bob")).Query(context.Background(), &q, nil)
+		},
+		"tenant": func() error {
+			return alice.Query(graphql.WithTenant(context.Background(), "bob"), &q, nil)
+		},
+		"path": func() error {
+			return alice.Query(graphql.WithURLPath(context.Background(), "v2"), &q, nil)
+		},
+	} {
+		if err := query(); err == nil || err.Error() != "down" {
+			t.Errorf("%s: got error: %v, want: down", name, err)
+		}
+	}
+	if err := alice.Query(context.Background(), &q, nil); err == nil || err.Error() != "graphql: serving stale result: down" {
+		t.Errorf("got error: %v, want the stale result", err)
+	}
+}
@@ -0,0 +1,151 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/dbmedialab/go-graphql-client/jsonutil"
+)
+
+// SubscriptionTransport is implemented by transports that can execute
+// subscriptions, such as TransportWS and TransportSSE. A client whose
+// transport is one subscribes with it; WithSubscriptionTransport gives one
+// to other clients.
+type SubscriptionTransport interface {
+	// Subscribe starts the subscription req, whose results are read from
+	// the stream returned until it's closed, or ctx is done.
+	Subscribe(ctx context.Context, req Request) (ResponseStream, error)
+}
+
+// ResponseStream is the stream of results of a subscription.
+type ResponseStream interface {
+	// Next blocks until the next result is received, and returns it. It
+	// returns io.EOF once the server has completed the subscription, and
+	// the context's error once the subscription's context is done. If the
+	// stream has reconnected, it returns a *Reconnect, and may be read on.
+	Next() (*Response, error)
+	// Close stops the subscription.
+	Close() error
+}
+
+// errNoResult is the error of operations that TransportWS and TransportSSE
+// execute with Do, if the server completes them without a result.
+var errNoResult = fmt.Errorf("graphql: server completed the operation without a result")
+
+// SubscriptionResult is a result of a subscription started by
+// Client.Subscribe.
+type SubscriptionResult struct {
+	// Data is a pointer to a new value of the type the subscription was
+	// derived from, populated with the result's data. It's nil if the
+	// subscription has failed.
+	Data interface{}
+	// Err holds the GraphQL errors of the result, or the error the
+	// subscription failed with, if it did.
+	Err error
+	// Reconnected is set, with Data and Err nil, when the subscription's
+	// connection dropped and was reconnected, as a transport such as a
+	// TransportWS with a ReconnectPolicy does. Results may have been
+	// missed in the meantime.
+	Reconnected *Reconnect
+}
+
+// WithSubscriptionTransport makes the client use t for subscriptions, such
+// as a TransportWS for a client whose other operations are sent over HTTP.
+func WithSubscriptionTransport(t SubscriptionTransport) ClientOption {
+	return func(c *Client) {
+		c.subscriber = t
+	}
+}
+
+// Subscribe starts a GraphQL subscription, with a subscription derived from
+// s, a pointer to struct that corresponds to the GraphQL schema. s isn't
+// populated: each result is decoded into a new value of its type, and sent
+// on the channel returned.
+//
+// The channel is closed once the server completes the subscription, the
+// subscription fails, in which case the last result holds the error, or ctx
+// is done, which stops the subscription. Transports that reconnect dropped
+// subscriptions report it with a result whose Reconnected is set. The
+// client's timeout doesn't apply to subscriptions, since they last for as
+// long as they're wanted.
+func (c *Client) Subscribe(ctx context.Context, s interface{}, variables map[string]interface{}, opts ...QueryOption) (<-chan SubscriptionResult, error) {
+	if c.subscriber == nil {
+		return nil, fmt.Errorf("graphql: client's transport doesn't support subscriptions")
+	}
+	t := reflect.TypeOf(s)
+	if t == nil || t.Kind() != reflect.Ptr {
+		return nil, validationError(fmt.Errorf("graphql: Subscribe needs a pointer to a struct, not %T", s))
+	}
+	query, variables, err := c.construct(constructSubscription, s, variables, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.enums.check(variables); err != nil {
+		return nil, validationError(err)
+	}
+	in := Request{
+		Query:         query,
+		Variables:     c.inputVariables(variables),
+		OperationName: queryOperationName(query),
+	}
+	if len(c.header) > 0 {
+		in.Header = c.header
+	}
+	in = withRequestExtensions(ctx, in)
+
+	ctx, cancel := context.WithCancel(ctx)
+	stream, err := c.subscriber.Subscribe(ctx, in)
+	if err != nil {
+		cancel()
+		return nil, transportError(ctx, err)
+	}
+	results := make(chan SubscriptionResult)
+	go func() {
+		defer close(results)
+		defer cancel()
+		defer stream.Close()
+		for {
+			r, ok := c.next(ctx, stream, t.Elem())
+			if !ok {
+				return
+			}
+			select {
+			case results <- r:
+			case <-ctx.Done():
+				return
+			}
+			if r.Data == nil && r.Reconnected == nil {
+				return
+			}
+		}
+	}()
+	return results, nil
+}
+
+// next returns the next result of stream, decoded into a new value of type
+// t, or false if there are no more to send.
+func (c *Client) next(ctx context.Context, stream ResponseStream, t reflect.Type) (SubscriptionResult, bool) {
+	out, err := stream.Next()
+	if err == io.EOF || ctx.Err() != nil {
+		return SubscriptionResult{}, false
+	}
+	if e, ok := err.(*Reconnect); ok {
+		return SubscriptionResult{Reconnected: e}, true
+	}
+	if err != nil {
+		return SubscriptionResult{Err: transportError(ctx, err)}, true
+	}
+	r := SubscriptionResult{Data: reflect.New(t).Interface()}
+	if len(out.Data) > 0 {
+		if err := jsonutil.UnmarshalGraphQLOptions(out.Data, r.Data, c.decodeOptions(nil)); err != nil {
+			r.Err = decodeError(err)
+			return r, true
+		}
+	}
+	if len(out.Errors) > 0 {
+		r.Err = out.Errors
+	}
+	return r, true
+}
@@ -0,0 +1,414 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dbmedialab/go-graphql-client"
+	"github.com/dbmedialab/go-graphql-client/internal/websocket"
+)
+
+// graphqlWSServer returns a server speaking the first of protocols that the
+// client asks for, which answers the message starting an operation with the
+// messages serve returns, and sends the messages the client sends on
+// received.
+func graphqlWSServer(t *testing.T, protocols []string, received chan<- string, serve func(start string) []string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Upgrade(w, r, protocols)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		for {
+			b, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			msg := string(b)
+			if received != nil {
+				received <- msg
+			}
+			var m struct{ Type string }
+			json.Unmarshal(b, &m)
+			var replies []string
+			switch m.Type {
+			case "connection_init":
+				replies = []string{`{"type":"connection_ack"}`, `{"type":"ka"}`}
+				if conn.Protocol() == graphql.ProtocolGraphQLTransportWS {
+					replies = []string{`{"type":"ping"}`, `{"type":"connection_ack"}`}
+				}
+			case "start", "subscribe":
+				replies = serve(msg)
+			}
+			for _, reply := range replies {
+				if err := conn.WriteMessage([]byte(reply)); err != nil {
+					return
+				}
+			}
+		}
+	}))
+}
+
+func wsURL(server *httptest.Server) string {
+	return "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+func TestClient_Subscribe(t *testing.T) {
+	received := make(chan string, 10)
+	server := graphqlWSServer(t, []string{"graphql-ws"}, received, func(start string) []string {
+		return []string{
+			`{"id":"1","type":"data","payload":{"data":{"issueAdded":{"number":1,"title":"Crash"}}}}`,
+			`{"id":"1","type":"data","payload":{"data":{"issueAdded":null},"errors":[{"message":"not visible"}]}}`,
+			`{"id":"1","type":"complete"}`,
+		}
+	})
+	defer server.Close()
+
+	var s struct {
+		IssueAdded *struct {
+			Number int
+			Title  string
+		} `graphql:"issueAdded(repo: $repo)"`
+	}
+	client := graphql.NewClient("http://example.invalid/graphql", nil, graphql.WithSubscriptionTransport(graphql.TransportWS{
+		URL:              wsURL(server),
+		ConnectionParams: map[string]interface{}{"token": "t"},
+	}))
+	results, err := client.Subscribe(context.Background(), &s, map[string]interface{}{"repo": graphql.String("go")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []graphql.SubscriptionResult
+	for r := range results {
+		got = append(got, r)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d results, want 2", len(got))
+	}
+	first := got[0].Data.(*struct {
+		IssueAdded *struct {
+			Number int
+			Title  string
+		} `graphql:"issueAdded(repo: $repo)"`
+	})
+	if got[0].Err != nil || first.IssueAdded == nil || first.IssueAdded.Title != "Crash" {
+		t.Errorf("got first result %+v, want issue Crash", got[0])
+	}
+	if got, want := got[1].Err, "not visible"; got == nil || got.Error() != want {
+		t.Errorf("got error: %v, want: %v", got, want)
+	}
+	if s.IssueAdded != nil {
+		t.Error("got s populated, want it left as it is")
+	}
+
+	if got, want := <-received, `{"type":"connection_init","payload":{"token":"t"}}`; got != want {
+		t.Errorf("got init %s, want %s", got, want)
+	}
+	if got, want := <-received, `{"id":"1","type":"start","payload":{"query":"subscription($repo:String!){issueAdded(repo: $repo){number,title}}","variables":{"repo":"go"}}}`; got != want {
+		t.Errorf("got start %s, want %s", got, want)
+	}
+}
+
+func TestClient_Subscribe_cancel(t *testing.T) {
+	received := make(chan string, 10)
+	server := graphqlWSServer(t, []string{"graphql-ws"}, received, func(start string) []string {
+		return []string{`{"id":"1","type":"data","payload":{"data":{"tick":1}}}`}
+	})
+	defer server.Close()
+
+	var s struct{ Tick int }
+	client := graphql.NewPluggableClient(graphql.TransportWS{URL: wsURL(server)})
+	ctx, cancel := context.WithCancel(context.Background())
+	results, err := client.Subscribe(ctx, &s, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := <-results; r.Err != nil || r.Data.(*struct{ Tick int }).Tick != 1 {
+		t.Errorf("got result %+v, want tick 1", r)
+	}
+	cancel()
+	if _, ok := <-results; ok {
+		t.Error("got a result after cancelling, want the channel closed")
+	}
+	for msg := range received {
+		if msg == `{"id":"1","type":"stop"}` {
+			break
+		}
+	}
+
+	_, err = graphql.NewClient("http://example.invalid/graphql", nil).Subscribe(context.Background(), &s, nil)
+	if got, want := err, "graphql: client's transport doesn't support subscriptions"; got == nil || got.Error() != want {
+		t.Errorf("got error: %v, want: %v", got, want)
+	}
+}
+
+func TestTransportWS_Do(t *testing.T) {
+	server := graphqlWSServer(t, []string{"graphql-ws"}, nil, func(start string) []string {
+		if strings.Contains(start, "bad") {
+			return []string{`{"id":"1","type":"error","payload":[{"message":"Cannot query field \"bad\""}]}`}
+		}
+		return []string{
+			`{"id":"1","type":"data","payload":{"data":{"viewer":{"login":"gopher"}}}}`,
+			`{"id":"1","type":"complete"}`,
+		}
+	})
+	defer server.Close()
+
+	client := graphql.NewPluggableClient(graphql.TransportWS{URL: wsURL(server)})
+	var q struct {
+		Viewer struct{ Login string }
+	}
+	if err := client.Query(context.Background(), &q, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := q.Viewer.Login, "gopher"; got != want {
+		t.Errorf("got login %q, want %q", got, want)
+	}
+	var bad struct{ Bad string }
+	err := client.Query(context.Background(), &bad, nil)
+	if got, want := err, `Cannot query field "bad"`; got == nil || got.Error() != want {
+		t.Errorf("got error: %v, want: %v", got, want)
+	}
+}
+
+func TestTransportWS_graphqlTransportWS(t *testing.T) {
+	received := make(chan string, 10)
+	server := graphqlWSServer(t, []string{"graphql-transport-ws", "graphql-ws"}, received, func(start string) []string {
+		return []string{
+			`{"type":"ping"}`,
+			`{"id":"1","type":"next","payload":{"data":{"tick":1}}}`,
+			`{"id":"1","type":"complete"}`,
+		}
+	})
+	defer server.Close()
+
+	var s struct{ Tick int }
+	client := graphql.NewPluggableClient(graphql.TransportWS{URL: wsURL(server)})
+	results, err := client.Subscribe(context.Background(), &s, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ticks []int
+	for r := range results {
+		if r.Err != nil {
+			t.Fatal(r.Err)
+		}
+		ticks = append(ticks, r.Data.(*struct{ Tick int }).Tick)
+	}
+	if len(ticks) != 1 || ticks[0] != 1 {
+		t.Errorf("got ticks %v, want [1]", ticks)
+	}
+	want := []string{
+		`{"type":"connection_init"}`,
+		`{"type":"pong"}`,
+		`{"id":"1","type":"subscribe","payload":{"query":"subscription{tick}"}}`,
+		`{"type":"pong"}`,
+	}
+	for _, w := range want {
+		if got := <-received; got != w {
+			t.Errorf("got message %s, want %s", got, w)
+		}
+	}
+
+	// The legacy protocol is spoken if asked for, though the server
+	// prefers the other.
+	client = graphql.NewPluggableClient(graphql.TransportWS{URL: wsURL(server), Protocol: graphql.ProtocolGraphQLWS})
+	if err := client.Query(context.Background(), &s, nil); err != nil {
+		t.Fatal(err)
+	}
+	<-received
+	if got, want := <-received, `{"id":"1","type":"start","payload":{"query":"{tick}"}}`; got != want {
+		t.Errorf("got message %s, want %s", got, want)
+	}
+}
+
+func TestTransportWS_reconnect(t *testing.T) {
+	var conns int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Upgrade(w, r, []string{"graphql-ws"})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		n := atomic.AddInt32(&conns, 1)
+		for {
+			b, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			var m struct{ Type string }
+			json.Unmarshal(b, &m)
+			switch {
+			case m.Type == "connection_init":
+				conn.WriteMessage([]byte(`{"type":"connection_ack"}`))
+			case m.Type == "start" && n == 1:
+				conn.WriteMessage([]byte(`{"id":"1","type":"data","payload":{"data":{"tick":1}}}`))
+				conn.CloseWithStatus(1012, "restarting")
+				return
+			case m.Type == "start" && n == 2:
+				conn.WriteMessage([]byte(`{"id":"1","type":"data","payload":{"data":{"tick":2}}}`))
+				conn.WriteMessage([]byte(`{"id":"1","type":"complete"}`))
+			case m.Type == "start":
+				conn.CloseWithStatus(4401, "Unauthorized")
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	var s struct{ Tick int }
+	clock := &sleepClock{}
+	client := graphql.NewPluggableClient(graphql.TransportWS{
+		URL:       wsURL(server),
+		Reconnect: &graphql.ReconnectPolicy{Clock: clock},
+	})
+	results, err := client.Subscribe(context.Background(), &s, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for r := range results {
+		switch {
+		case r.Reconnected != nil:
+			got = append(got, fmt.Sprintf("reconnected after %d", r.Reconnected.Attempts))
+		case r.Err != nil:
+			got = append(got, r.Err.Error())
+		default:
+			got = append(got, fmt.Sprintf("tick %d", r.Data.(*struct{ Tick int }).Tick))
+		}
+	}
+	if want := []string{"tick 1", "reconnected after 1", "tick 2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got results %q, want %q", got, want)
+	}
+	if got, want := clock.Waits(), []time.Duration{time.Second}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got waits %v, want %v", got, want)
+	}
+
+	// Closing for a problem with the client isn't worth reconnecting.
+	results, err = client.Subscribe(context.Background(), &s, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = nil
+	for r := range results {
+		got = append(got, r.Err.Error())
+	}
+	if want := []string{"websocket: closed with status 4401: Unauthorized"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got results %q, want %q", got, want)
+	}
+}
+
+// droppingServer returns a server whose first connection sends a result and
+// then drops, and whose later ones are refused with 4401 Unauthorized. The
+// number of connections made is counted in conns.
+func droppingServer(t *testing.T, conns *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Upgrade(w, r, []string{"graphql-ws"})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		n := atomic.AddInt32(conns, 1)
+		for {
+			b, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			var m struct{ Type string }
+			json.Unmarshal(b, &m)
+			switch {
+			case m.Type == "connection_init" && n > 1:
+				conn.CloseWithStatus(4401, "Unauthorized")
+				return
+			case m.Type == "connection_init":
+				conn.WriteMessage([]byte(`{"type":"connection_ack"}`))
+			case m.Type == "start":
+				conn.WriteMessage([]byte(`{"id":"1","type":"data","payload":{"data":{"tick":1}}}`))
+				conn.CloseWithStatus(1012, "restarting")
+				return
+			}
+		}
+	}))
+}
+
+func TestTransportWS_reconnectRefused(t *testing.T) {
+	var conns int32
+	server := droppingServer(t, &conns)
+	defer server.Close()
+
+	transport := graphql.TransportWS{
+		URL:       wsURL(server),
+		Reconnect: &graphql.ReconnectPolicy{Clock: &sleepClock{}},
+	}
+	stream, err := transport.Subscribe(context.Background(), graphql.Request{Query: "subscription{tick}"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Close()
+	if _, err := stream.Next(); err != nil {
+		t.Fatal(err)
+	}
+	// The attempt refused isn't retried, though attempts aren't limited.
+	_, err = stream.Next()
+	if got, want := err, "websocket: closed with status 4401: Unauthorized"; got == nil || got.Error() != want {
+		t.Errorf("got error: %v, want: %v", got, want)
+	}
+	if got, want := atomic.LoadInt32(&conns), int32(2); got != want {
+		t.Errorf("got %d connections, want %d", got, want)
+	}
+}
+
+// blockingClock is a SleepClock whose waits never pass, which says when
+// they're started.
+type blockingClock struct {
+	settableClock
+	waiting chan time.Duration
+}
+
+func (c *blockingClock) After(d time.Duration) <-chan time.Time {
+	c.waiting <- d
+	return nil
+}
+
+func TestTransportWS_reconnectClose(t *testing.T) {
+	var conns int32
+	server := droppingServer(t, &conns)
+	defer server.Close()
+
+	clock := &blockingClock{waiting: make(chan time.Duration, 1)}
+	transport := graphql.TransportWS{
+		URL:       wsURL(server),
+		Reconnect: &graphql.ReconnectPolicy{Clock: clock},
+	}
+	stream, err := transport.Subscribe(context.Background(), graphql.Request{Query: "subscription{tick}"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Next(); err != nil {
+		t.Fatal(err)
+	}
+	errs := make(chan error)
+	go func() {
+		_, err := stream.Next()
+		errs <- err
+	}()
+	<-clock.waiting
+	stream.Close()
+	if err := <-errs; err != context.Canceled {
+		t.Errorf("got error: %v, want: %v", err, context.Canceled)
+	}
+	if got, want := atomic.LoadInt32(&conns), int32(1); got != want {
+		t.Errorf("got %d connections, want %d", got, want)
+	}
+}
@@ -0,0 +1,80 @@
+package graphql
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"text/template"
+
+	"github.com/dbmedialab/go-graphql-client/internal/document"
+	"github.com/dbmedialab/go-graphql-client/introspection"
+)
+
+// templateCacheSize bounds the number of rendered documents a Template keeps.
+const templateCacheSize = 1024
+
+// Template renders GraphQL documents from a text/template.
+// Every rendered document is parsed and, if Schema is set, validated,
+// so a malformed dynamic query is reported as an error rather than being
+// sent to the server. Results are cached by the hash of the parameters.
+//
+// A Template is safe for concurrent use.
+type Template struct {
+	// Schema, if set, is used to validate rendered documents.
+	Schema *introspection.Schema
+
+	tmpl *template.Template
+
+	mu    sync.Mutex
+	cache map[[sha256.Size]byte]string
+}
+
+// NewTemplate parses text as a text/template that renders a GraphQL document.
+func NewTemplate(name, text string) (*Template, error) {
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	return &Template{tmpl: tmpl}, nil
+}
+
+// Render executes the template with params and returns the checked document.
+// params must be encodable as JSON; its encoding is the cache key.
+func (t *Template) Render(params interface{}) (string, error) {
+	key, err := json.Marshal(params)
+	if err != nil {
+		return "", fmt.Errorf("template %s: params: %v", t.tmpl.Name(), err)
+	}
+	sum := sha256.Sum256(key)
+
+	t.mu.Lock()
+	doc, ok := t.cache[sum]
+	t.mu.Unlock()
+	if ok {
+		return doc, nil
+	}
+
+	var buf bytes.Buffer
+	if err := t.tmpl.Execute(&buf, params); err != nil {
+		return "", err
+	}
+	doc = buf.String()
+	if t.Schema != nil {
+		err = t.Schema.Validate(doc)
+	} else {
+		_, err = document.Parse(doc)
+	}
+	if err != nil {
+		return "", fmt.Errorf("template %s: rendered invalid document: %v", t.tmpl.Name(), err)
+	}
+
+	t.mu.Lock()
+	if t.cache == nil || len(t.cache) >= templateCacheSize {
+		t.cache = map[[sha256.Size]byte]string{}
+	}
+	t.cache[sum] = doc
+	t.mu.Unlock()
+	return doc, nil
+}
@@ -0,0 +1,52 @@
+package graphql_test
+
+import (
+	"testing"
+
+	"github.com/dbmedialab/go-graphql-client"
+	"github.com/dbmedialab/go-graphql-client/introspection"
+)
+
+func TestTemplate(t *testing.T) {
+	tmpl, err := graphql.NewTemplate("columns", `query { items { {{range .Columns}}{{.}} {{end}} } }`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := tmpl.Render(map[string]interface{}{"Columns": []string{"id", "name"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "query { items { id name  } }"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// No columns renders an empty selection set, which is rejected.
+	_, err = tmpl.Render(map[string]interface{}{"Columns": []string{}})
+	if got, want := err, "template columns: rendered invalid document: graphql: syntax error at 1:18: empty selection set"; got == nil || got.Error() != want {
+		t.Errorf("got error: %v, want: %v", got, want)
+	}
+}
+
+func TestTemplate_schema(t *testing.T) {
+	schema, err := introspection.Parse([]byte(`{"__schema": {
+		"queryType": {"name": "Query"},
+		"types": [{"kind": "OBJECT", "name": "Query", "fields": [
+			{"name": "id", "args": [], "type": {"kind": "SCALAR", "name": "ID"}}
+		]}]
+	}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl, err := graphql.NewTemplate("field", `{ {{.}} }`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl.Schema = schema
+	if _, err := tmpl.Render("id"); err != nil {
+		t.Errorf("got error: %v, want: nil", err)
+	}
+	_, err = tmpl.Render("secret")
+	if got, want := err, "template field: rendered invalid document: query: type Query has no field secret"; got == nil || got.Error() != want {
+		t.Errorf("got error: %v, want: %v", got, want)
+	}
+}
@@ -0,0 +1,45 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// SetTimeout sets the default timeout of the named registered operation,
+// so that slow operations, such as analytics queries, and fast lookups
+// can be given budgets of their own in one place.
+func (r *Registry) SetTimeout(name string, d time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	op, ok := r.ops[name]
+	if !ok {
+		return fmt.Errorf("operation %s is not registered", name)
+	}
+	// Operations handed out already aren't modified.
+	updated := *op
+	updated.Timeout = d
+	r.ops[name] = &updated
+	return nil
+}
+
+// TimeoutMiddleware gives operations sent through next the timeouts of
+// the registered operations they're for, looked up by the operation name
+// in their queries, if their contexts have no deadline. Callers that set
+// a deadline of their own, or clients with WithTimeout, keep theirs.
+// Install it with WithMiddleware(r.TimeoutMiddleware), before
+// r.RetryMiddleware if the timeout is to cover every attempt.
+func (r *Registry) TimeoutMiddleware(next Transport) Transport {
+	return TransportFunc(func(ctx context.Context, req Request) (*Response, error) {
+		if _, ok := ctx.Deadline(); ok {
+			return next.Do(ctx, req)
+		}
+		op := r.Operation(queryOperationName(req.Query))
+		if op == nil || op.Timeout <= 0 {
+			return next.Do(ctx, req)
+		}
+		ctx, cancel := context.WithTimeout(ctx, op.Timeout)
+		defer cancel()
+		return next.Do(ctx, req)
+	})
+}
@@ -0,0 +1,58 @@
+package graphql_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dbmedialab/go-graphql-client"
+)
+
+func TestRegistry_TimeoutMiddleware(t *testing.T) {
+	var r graphql.Registry
+	if err := r.Register(`query Report { report } query Lookup { lookup }`); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.SetTimeout("Report", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.SetTimeout("Missing", time.Second); err == nil {
+		t.Error("got error: nil, want: non-nil for an unregistered operation")
+	}
+
+	var deadline time.Time
+	var hasDeadline bool
+	transport := graphql.TransportFunc(func(ctx context.Context, req graphql.Request) (*graphql.Response, error) {
+		deadline, hasDeadline = ctx.Deadline()
+		return &graphql.Response{Data: []byte(`{"report": "ok", "lookup": "ok"}`)}, nil
+	})
+	client := graphql.NewPluggableClient(transport, graphql.WithMiddleware(r.TimeoutMiddleware))
+	var q map[string]interface{}
+
+	start := time.Now()
+	if err := client.QueryCustom(context.Background(), &q, r.Operation("Report").Document, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !hasDeadline || deadline.Before(start.Add(time.Hour)) || deadline.After(time.Now().Add(time.Hour)) {
+		t.Errorf("got deadline %v (%v), want in an hour", deadline, hasDeadline)
+	}
+
+	// The caller's deadline is kept.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	want, _ := ctx.Deadline()
+	if err := client.QueryCustom(ctx, &q, r.Operation("Report").Document, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !deadline.Equal(want) {
+		t.Errorf("got deadline %v, want the caller's %v", deadline, want)
+	}
+
+	// Operations without a timeout have no deadline.
+	if err := client.QueryCustom(context.Background(), &q, r.Operation("Lookup").Document, nil); err != nil {
+		t.Fatal(err)
+	}
+	if hasDeadline {
+		t.Errorf("got deadline %v, want none", deadline)
+	}
+}
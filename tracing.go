@@ -0,0 +1,100 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+)
+
+// Tracing is the timing information of an operation's execution that
+// servers with Apollo tracing enabled send in the "tracing" entry of the
+// response's extensions. Offsets are from StartTime.
+//
+// Format: https://github.com/apollographql/apollo-tracing.
+type Tracing struct {
+	Version    int           `json:"version"`
+	StartTime  time.Time     `json:"startTime"`
+	EndTime    time.Time     `json:"endTime"`
+	Duration   time.Duration `json:"duration"`
+	Parsing    TracingPhase  `json:"parsing"`
+	Validation TracingPhase  `json:"validation"`
+	Execution  struct {
+		Resolvers []ResolverTiming `json:"resolvers"`
+	} `json:"execution"`
+}
+
+// TracingPhase is the timing of a phase of an operation's execution, such as
+// parsing or validation.
+type TracingPhase struct {
+	StartOffset time.Duration `json:"startOffset"`
+	Duration    time.Duration `json:"duration"`
+}
+
+// ResolverTiming is the timing of a field's resolver.
+type ResolverTiming struct {
+	// Path is the path to the field in the response, of field names and,
+	// within lists, indices, as float64.
+	Path        []interface{} `json:"path"`
+	ParentType  string        `json:"parentType"`
+	FieldName   string        `json:"fieldName"`
+	ReturnType  string        `json:"returnType"`
+	StartOffset time.Duration `json:"startOffset"`
+	Duration    time.Duration `json:"duration"`
+}
+
+// ParseTracing returns the tracing in the response extensions ext, or nil if
+// there isn't any.
+func ParseTracing(ext map[string]interface{}) (*Tracing, error) {
+	v, ok := ext["tracing"]
+	if !ok || v == nil {
+		return nil, nil
+	}
+	// Extensions are decoded generically, so encode the entry again to
+	// decode it into its structure.
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var t Tracing
+	if err := json.Unmarshal(b, &t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// Slowest returns the n resolvers that took the longest, slowest first, or
+// all of them if there are fewer.
+func (t *Tracing) Slowest(n int) []ResolverTiming {
+	resolvers := make([]ResolverTiming, len(t.Execution.Resolvers))
+	copy(resolvers, t.Execution.Resolvers)
+	sort.SliceStable(resolvers, func(i, j int) bool {
+		return resolvers[i].Duration > resolvers[j].Duration
+	})
+	if n < len(resolvers) {
+		resolvers = resolvers[:n]
+	}
+	return resolvers
+}
+
+type tracingKey struct{}
+
+// WithTracing returns a copy of ctx with which receive is called with the
+// tracing of the response to each operation, if it has valid tracing, once
+// the response has been received. Tracing that can't be parsed is ignored,
+// so as not to fail the operation.
+func WithTracing(ctx context.Context, receive func(t *Tracing)) context.Context {
+	return context.WithValue(ctx, tracingKey{}, receive)
+}
+
+// receiveTracing passes the tracing in ext to the receiver set with ctx, if
+// any.
+func receiveTracing(ctx context.Context, ext map[string]interface{}) {
+	receive, ok := ctx.Value(tracingKey{}).(func(*Tracing))
+	if !ok {
+		return
+	}
+	if t, err := ParseTracing(ext); err == nil && t != nil {
+		receive(t)
+	}
+}
@@ -0,0 +1,82 @@
+package graphql_test
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/dbmedialab/go-graphql-client"
+)
+
+func TestWithTracing(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher", "repositories": [{"name": "go"}]}}, "extensions": {"tracing": {
+			"version": 1,
+			"startTime": "2026-10-14T12:00:00.000Z",
+			"endTime": "2026-10-14T12:00:00.030Z",
+			"duration": 30000000,
+			"parsing": {"startOffset": 12000, "duration": 80000},
+			"validation": {"startOffset": 95000, "duration": 40000},
+			"execution": {"resolvers": [
+				{"path": ["viewer"], "parentType": "Query", "fieldName": "viewer", "returnType": "User!", "startOffset": 150000, "duration": 2000000},
+				{"path": ["viewer", "repositories"], "parentType": "User", "fieldName": "repositories", "returnType": "[Repository!]!", "startOffset": 2200000, "duration": 25000000},
+				{"path": ["viewer", "repositories", 0, "name"], "parentType": "Repository", "fieldName": "name", "returnType": "String!", "startOffset": 27300000, "duration": 10000}
+			]}
+		}}}`)
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}})
+
+	var got *graphql.Tracing
+	ctx := graphql.WithTracing(context.Background(), func(t *graphql.Tracing) {
+		got = t
+	})
+	var q struct {
+		Viewer struct {
+			Login        graphql.String
+			Repositories []struct{ Name graphql.String }
+		}
+	}
+	if err := client.Query(ctx, &q, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got == nil {
+		t.Fatal("got no tracing")
+	}
+	if want := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC); !got.StartTime.Equal(want) {
+		t.Errorf("got start time %v, want %v", got.StartTime, want)
+	}
+	if got, want := got.Duration, 30*time.Millisecond; got != want {
+		t.Errorf("got duration %v, want %v", got, want)
+	}
+	if got, want := got.Validation, (graphql.TracingPhase{StartOffset: 95 * time.Microsecond, Duration: 40 * time.Microsecond}); got != want {
+		t.Errorf("got validation %+v, want %+v", got, want)
+	}
+	if got, want := len(got.Execution.Resolvers), 3; got != want {
+		t.Fatalf("got %d resolvers, want %d", got, want)
+	}
+	want := []graphql.ResolverTiming{
+		{Path: []interface{}{"viewer", "repositories"}, ParentType: "User", FieldName: "repositories", ReturnType: "[Repository!]!", StartOffset: 2200 * time.Microsecond, Duration: 25 * time.Millisecond},
+		{Path: []interface{}{"viewer"}, ParentType: "Query", FieldName: "viewer", ReturnType: "User!", StartOffset: 150 * time.Microsecond, Duration: 2 * time.Millisecond},
+	}
+	if slowest := got.Slowest(2); !reflect.DeepEqual(slowest, want) {
+		t.Errorf("got slowest %+v, want %+v", slowest, want)
+	}
+	if got, want := got.Execution.Resolvers[2].Path, []interface{}{"viewer", "repositories", 0.0, "name"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got path %v, want %v", got, want)
+	}
+}
+
+func TestParseTracing(t *testing.T) {
+	got, err := graphql.ParseTracing(map[string]interface{}{"cost": 2.0})
+	if err != nil || got != nil {
+		t.Errorf("got %+v, %v, want no tracing", got, err)
+	}
+	_, err = graphql.ParseTracing(map[string]interface{}{"tracing": map[string]interface{}{"duration": "slow"}})
+	if err == nil {
+		t.Error("got no error parsing invalid tracing, want one")
+	}
+}
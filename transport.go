@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 
 	"github.com/shurcooL/go/ctxhttp"
@@ -21,14 +22,32 @@ type Transport interface {
 	Do(context.Context, Request) (*Response, error)
 }
 
+// TransportFunc is an adapter to allow the use of ordinary functions as
+// Transports, which is handy for writing Middleware.
+type TransportFunc func(context.Context, Request) (*Response, error)
+
+// Do calls f(ctx, req).
+func (f TransportFunc) Do(ctx context.Context, req Request) (*Response, error) {
+	return f(ctx, req)
+}
+
 // Request is a type used by the Transport interface.  Users of the library
 // don't need to use this type unless they're implementing a Transport.
 //
 // Request gathers all fields used in a graphql request (the query together
 // with assignments of any variables) together for serialization.
 type Request struct {
-	Query     string                 `json:"query"`
-	Variables map[string]interface{} `json:"variables,omitempty"`
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+	OperationName string                 `json:"operationName,omitempty"`
+
+	// Extensions holds extension entries for the server, such as those
+	// of a gateway's contract. See WithRequestExtension.
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+
+	// Header holds HTTP headers to send with the request, if the
+	// transport supports them. It's not part of the serialized request.
+	Header http.Header `json:"-"`
 }
 
 // Response is a type used by the Transport interface.  Users of the library
@@ -39,40 +58,143 @@ type Request struct {
 // (A second phase of deserialization maps the raw data into your go types;
 // this is not handled by the Transport interface.)
 type Response struct {
-	Data   json.RawMessage
-	Errors errors
-	//Extensions interface{} // Unused.
+	Data   json.RawMessage `json:"data,omitempty"`
+	Errors errors          `json:"errors,omitempty"`
+
+	// Extensions holds the extension entries the server sent, such as
+	// tracing or cost information. See WithResponseExtensions, and
+	// WithTracing.
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
 }
 
 var (
 	_ Transport = TransportHTTP{}
-	//_ Transport = TransportRecorder{}
-	//_ Transport = TransportReplayer{}
+	_ Transport = TransportRecorder{}
+	_ Transport = TransportReplayer{}
 )
 
 type TransportHTTP struct {
 	URL        string // GraphQL server URL.
 	HTTPClient *http.Client
+
+	// AllowedURLs lists the other GraphQL server URLs that a context
+	// made by WithEndpoint may direct requests to.
+	AllowedURLs []string
+
+	// SpoolThreshold, if positive, makes a Client using this transport
+	// directly write response bodies larger than this many bytes to a
+	// temporary file, and decode them from there, so that they're never
+	// held in memory whole.
+	SpoolThreshold int64
+
+	// VerifyDigest makes the transport check each response body against
+	// the digest the server sent in its Content-Digest or Digest header,
+	// before decoding it. Responses without a SHA-256 or SHA-512 digest,
+	// or with a mismatched one, fail.
+	VerifyDigest bool
+
+	// AllowEmptyResponses makes 204 No Content responses, and 200 OK
+	// responses with empty bodies, as some gateways send for
+	// fire-and-forget mutations, successful responses with null data.
+	// The results of such operations are left as they are. Otherwise,
+	// they fail.
+	AllowEmptyResponses bool
+}
+
+type endpointKey struct{}
+
+// WithEndpoint returns a copy of ctx that directs requests sent with it by
+// TransportHTTP to url rather than to the transport's URL. url must be listed
+// in the transport's AllowedURLs. This suits setups where the same logical
+// server is available at several regional endpoints.
+func WithEndpoint(ctx context.Context, url string) context.Context {
+	return context.WithValue(ctx, endpointKey{}, url)
+}
+
+// endpoint returns the URL to send requests made with ctx to.
+func (t TransportHTTP) endpoint(ctx context.Context) (string, error) {
+	url, ok := ctx.Value(endpointKey{}).(string)
+	if !ok || url == t.URL {
+		return t.URL, nil
+	}
+	for _, allowed := range t.AllowedURLs {
+		if url == allowed {
+			return url, nil
+		}
+	}
+	return "", fmt.Errorf("graphql: endpoint %q is not allowed", url)
 }
 
 func (t TransportHTTP) Do(ctx context.Context, req Request) (*Response, error) {
+	resp, err := t.post(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var body io.Reader = resp.Body
+	if progress := progressFrom(ctx); progress != nil {
+		body = progressReader{r: body, rep: progress}
+	}
+	if t.VerifyDigest {
+		b, err := readVerified(resp.Header, body)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(b)
+	}
+	out := Response{}
+	err = json.NewDecoder(body).Decode(&out)
+	if err == io.EOF && t.AllowEmptyResponses {
+		out.Data, err = json.RawMessage("null"), nil
+	}
+	return &out, err
+}
+
+// post sends req, returning the response if its status is OK.
+// The caller must close the response body.
+func (t TransportHTTP) post(ctx context.Context, req Request) (*http.Response, error) {
 	if t.HTTPClient == nil {
 		t.HTTPClient = http.DefaultClient
 	}
-	var buf bytes.Buffer
-	err := json.NewEncoder(&buf).Encode(req)
+	url, err := t.endpoint(ctx)
+	if err != nil {
+		return nil, err
+	}
+	o := httpOverridesFrom(ctx)
+	method := "POST"
+	if o.method != "" {
+		method = o.method
+	}
+	get := method == "GET"
+	if url, err = o.url(url, req, get); err != nil {
+		return nil, err
+	}
+	var body io.Reader
+	if !get {
+		var buf bytes.Buffer
+		err = json.NewEncoder(&buf).Encode(req)
+		if err != nil {
+			return nil, err
+		}
+		body = &buf
+	}
+	httpReq, err := http.NewRequest(method, url, body)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := ctxhttp.Post(ctx, t.HTTPClient, t.URL, "application/json", &buf)
+	for k, v := range req.Header {
+		httpReq.Header[k] = v
+	}
+	if !get {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := ctxhttp.Do(ctx, t.HTTPClient, httpReq)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status: %v", resp.Status)
+	if resp.StatusCode != http.StatusOK && !(resp.StatusCode == http.StatusNoContent && t.AllowEmptyResponses) {
+		resp.Body.Close()
+		return nil, statusError(resp)
 	}
-	out := Response{}
-	err = json.NewDecoder(resp.Body).Decode(&out)
-	return &out, err
+	return resp, nil
 }
@@ -0,0 +1,96 @@
+package graphql_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/dbmedialab/go-graphql-client"
+)
+
+func TestWithEndpoint(t *testing.T) {
+	var gotPath string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) {
+		gotPath = req.URL.Path
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+	})
+	client := graphql.NewPluggableClient(graphql.TransportHTTP{
+		URL:         "/graphql",
+		HTTPClient:  &http.Client{Transport: localRoundTripper{handler: mux}},
+		AllowedURLs: []string{"/eu/graphql"},
+	})
+
+	var q struct {
+		Viewer struct {
+			Login graphql.String
+		}
+	}
+	for _, tc := range []struct {
+		url     string
+		want    string
+		wantErr string
+	}{
+		{url: "", want: "/graphql"},
+		{url: "/graphql", want: "/graphql"},
+		{url: "/eu/graphql", want: "/eu/graphql"},
+		{url: "/us/graphql", wantErr: `graphql: endpoint "/us/graphql" is not allowed`},
+	} {
+		gotPath = ""
+		ctx := context.Background()
+		if tc.url != "" {
+			ctx = graphql.WithEndpoint(ctx, tc.url)
+		}
+		err := client.Query(ctx, &q, nil)
+		if tc.wantErr != "" {
+			if err == nil || err.Error() != tc.wantErr {
+				t.Errorf("%q: got error: %v, want: %v", tc.url, err, tc.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", tc.url, err)
+		}
+		if gotPath != tc.want {
+			t.Errorf("%q: got request to %q, want %q", tc.url, gotPath, tc.want)
+		}
+	}
+}
+
+func TestTransportHTTP_AllowEmptyResponses(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/no-content", func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("/empty", func(w http.ResponseWriter, req *http.Request) {})
+	httpClient := &http.Client{Transport: localRoundTripper{handler: mux}}
+
+	for _, tc := range []struct {
+		url            string
+		spoolThreshold int64
+	}{
+		{url: "/no-content"},
+		{url: "/empty"},
+		{url: "/no-content", spoolThreshold: 1 << 20},
+		{url: "/empty", spoolThreshold: 1 << 20},
+	} {
+		var m struct {
+			TrackEvent struct {
+				OK graphql.Boolean
+			} `graphql:"trackEvent(name: \"view\")"`
+		}
+		m.TrackEvent.OK = true
+		transport := graphql.TransportHTTP{URL: tc.url, HTTPClient: httpClient, SpoolThreshold: tc.spoolThreshold}
+		if err := graphql.NewPluggableClient(transport).Mutate(context.Background(), &m, nil); err == nil {
+			t.Errorf("%s (spooled: %v): got error: nil, want: non-nil without AllowEmptyResponses", tc.url, tc.spoolThreshold > 0)
+		}
+		transport.AllowEmptyResponses = true
+		if err := graphql.NewPluggableClient(transport).Mutate(context.Background(), &m, nil); err != nil {
+			t.Errorf("%s (spooled: %v): %v", tc.url, tc.spoolThreshold > 0, err)
+		}
+		if !m.TrackEvent.OK {
+			t.Errorf("%s (spooled: %v): got result changed, want it left as it was", tc.url, tc.spoolThreshold > 0)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package graphql
+
+import "context"
+
+// Validator validates the decoded results of operations, for example by
+// the validate tags of their struct fields. The *Validate type of
+// github.com/go-playground/validator satisfies it, so it can be passed to
+// WithValidator as is:
+//
+//	client := graphql.NewClient(url, nil, graphql.WithValidator(validator.New()))
+type Validator interface {
+	// Struct validates v, a pointer to the struct an operation's result
+	// was decoded into, returning an error that describes the fields that
+	// are invalid, if any.
+	Struct(v interface{}) error
+}
+
+// ValidatorFunc is an adapter to allow the use of ordinary functions as
+// Validators.
+type ValidatorFunc func(v interface{}) error
+
+// Struct calls f(v).
+func (f ValidatorFunc) Struct(v interface{}) error {
+	return f(v)
+}
+
+// ResultValidationError is returned by operations whose results are
+// rejected by the client's Validator. It's of kind ErrValidation.
+type ResultValidationError struct {
+	// Err is the error returned by the Validator, such as the
+	// ValidationErrors of github.com/go-playground/validator,
+	// which describes each invalid field.
+	Err error
+}
+
+func (e *ResultValidationError) Error() string {
+	return "graphql: invalid result: " + e.Err.Error()
+}
+
+// Unwrap returns e.Err.
+func (e *ResultValidationError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is ErrValidation.
+func (e *ResultValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// WithValidator validates the result of every operation with v, once it has
+// been decoded, including partial and stale results. An operation whose
+// result is invalid fails with a *ResultValidationError, even if the
+// response also had GraphQL errors.
+func WithValidator(v Validator) ClientOption {
+	return WithResultHooks(func(ctx context.Context, req Request, result interface{}) error {
+		if err := v.Struct(result); err != nil {
+			return &ResultValidationError{Err: err}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,73 @@
+package graphql_test
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dbmedialab/go-graphql-client"
+)
+
+// requiredValidator is a Validator that checks for fields tagged
+// validate:"required" being non-zero, as github.com/go-playground/validator
+// does.
+var requiredValidator = graphql.ValidatorFunc(func(v interface{}) error {
+	var invalid []string
+	var walk func(path string, v reflect.Value)
+	walk = func(path string, v reflect.Value) {
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return
+			}
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			return
+		}
+		for i := 0; i < v.NumField(); i++ {
+			f := v.Type().Field(i)
+			name := strings.TrimPrefix(path+"."+f.Name, ".")
+			if f.Tag.Get("validate") == "required" && reflect.DeepEqual(v.Field(i).Interface(), reflect.Zero(f.Type).Interface()) {
+				invalid = append(invalid, name)
+			}
+			walk(name, v.Field(i))
+		}
+	}
+	walk("", reflect.ValueOf(v))
+	if len(invalid) > 0 {
+		return fmt.Errorf("required fields missing: %s", strings.Join(invalid, ", "))
+	}
+	return nil
+})
+
+func TestWithValidator(t *testing.T) {
+	data := `{"article": {"id": "1", "title": "Hello", "author": {"name": ""}}}`
+	transport := graphql.TransportFunc(func(ctx context.Context, req graphql.Request) (*graphql.Response, error) {
+		return &graphql.Response{Data: []byte(data)}, nil
+	})
+	client := graphql.NewPluggableClient(transport, graphql.WithValidator(requiredValidator))
+
+	var q struct {
+		Article struct {
+			ID     graphql.ID     `validate:"required"`
+			Title  graphql.String `validate:"required"`
+			Author struct {
+				Name graphql.String `validate:"required"`
+			}
+		}
+	}
+	err := client.Query(context.Background(), &q, nil)
+	if got, want := err, "graphql: invalid result: required fields missing: Article.Author.Name"; got == nil || got.Error() != want {
+		t.Errorf("got error: %v, want: %v", got, want)
+	}
+	if _, ok := err.(*graphql.ResultValidationError); !ok {
+		t.Errorf("got error of type %T, want *graphql.ResultValidationError", err)
+	}
+
+	data = `{"article": {"id": "1", "title": "Hello", "author": {"name": "gopher"}}}`
+	if err := client.Query(context.Background(), &q, nil); err != nil {
+		t.Errorf("got error: %v, want: nil", err)
+	}
+}
@@ -0,0 +1,167 @@
+package graphql
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/dbmedialab/go-graphql-client/internal/document"
+)
+
+// WithUnusedVariables sets how the client handles variables given to an
+// operation that its document doesn't use, which strict servers reject
+// when they're declared. handle is called with the request and the names
+// of the unused variables, in sorted order. If it returns an error, the
+// operation fails with it without being sent. Otherwise, the unused
+// variables are left out of the request, and out of the variable
+// declarations of queries and mutations derived from structs.
+//
+// By default, all variables are sent. RejectUnusedVariables is a handle
+// that fails every operation with unused variables; one that logs them and
+// returns nil warns about them instead.
+func WithUnusedVariables(handle func(req Request, unused []string) error) ClientOption {
+	return func(c *Client) {
+		c.unusedVariables = handle
+	}
+}
+
+// RejectUnusedVariables returns an error naming the unused variables,
+// for use with WithUnusedVariables.
+func RejectUnusedVariables(req Request, unused []string) error {
+	return fmt.Errorf("graphql: unused variables: $%s", strings.Join(unused, ", $"))
+}
+
+// WithVariableChecks makes QueryCustom and MutateCustom check the variables
+// given against the variable definitions of the operation in the document,
+// and fail without sending it if any non-null variable without a default
+// value isn't given, or is nil, or if any variable given isn't defined.
+// The error names the variables, rather than leaving servers to reject the
+// request, often with no more than an HTTP 400. Documents that can't be
+// parsed are sent as they are, for the server to report.
+func WithVariableChecks() ClientOption {
+	return func(c *Client) {
+		c.checkVariables = true
+	}
+}
+
+// checkVariables returns an error if variables don't match the variable
+// definitions of the operation in query.
+func checkVariables(query string, variables map[string]interface{}) error {
+	doc, err := document.Parse(query)
+	if err != nil {
+		return nil
+	}
+	op := doc.Operation(queryOperationName(query))
+	if op == nil {
+		return nil
+	}
+	var missing, undefined []string
+	defined := map[string]bool{}
+	for _, d := range op.VariableDefinitions {
+		defined[d.Name] = true
+		if !d.Type.NonNull || d.DefaultValue != nil {
+			continue
+		}
+		if v, ok := variables[d.Name]; !ok || isNil(v) {
+			missing = append(missing, d.Name)
+		}
+	}
+	for name := range variables {
+		if !defined[name] {
+			undefined = append(undefined, name)
+		}
+	}
+	if len(missing) == 0 && len(undefined) == 0 {
+		return nil
+	}
+	sort.Strings(undefined)
+	var problems []string
+	if len(missing) > 0 {
+		problems = append(problems, "required variables not given: $"+strings.Join(missing, ", $"))
+	}
+	if len(undefined) > 0 {
+		problems = append(problems, "variables not defined: $"+strings.Join(undefined, ", $"))
+	}
+	name := op.Type
+	if op.Name != "" {
+		name += " " + op.Name
+	}
+	return fmt.Errorf("graphql: %s: %s", name, strings.Join(problems, "; "))
+}
+
+// isNil reports whether v is nil, or a nil pointer, map, slice or interface.
+func isNil(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		return rv.IsNil()
+	}
+	return false
+}
+
+// usedVariables returns the variables that query uses, having reported any
+// others to the client's handler for unused variables. variables is returned
+// as is if the client has no handler, or all of them are used.
+func (c *Client) usedVariables(query string, variables map[string]interface{}) (map[string]interface{}, error) {
+	if c.unusedVariables == nil || len(variables) == 0 {
+		return variables, nil
+	}
+	names, err := variableNames(query)
+	if err != nil {
+		// Let the server report the syntax error.
+		return variables, nil
+	}
+	var unused []string
+	for name := range variables {
+		if !names[name] {
+			unused = append(unused, name)
+		}
+	}
+	if len(unused) == 0 {
+		return variables, nil
+	}
+	sort.Strings(unused)
+	if err := c.unusedVariables(Request{Query: query, Variables: variables}, unused); err != nil {
+		return nil, err
+	}
+	if len(unused) == len(variables) {
+		return nil, nil
+	}
+	used := make(map[string]interface{}, len(variables)-len(unused))
+	for name, value := range variables {
+		if names[name] {
+			used[name] = value
+		}
+	}
+	return used, nil
+}
+
+// variableNames returns the names of the variables used in query, other
+// than in variable definitions.
+func variableNames(query string) (map[string]bool, error) {
+	toks, err := document.Tokenize(query)
+	if err != nil {
+		return nil, err
+	}
+	names := map[string]bool{}
+	depth := 0           // Of braces.
+	definitions := false // Whether in the variable definitions of an operation.
+	for i, tok := range toks {
+		switch {
+		case tok.Kind == document.Punctuator && tok.Value == "{":
+			depth++
+		case tok.Kind == document.Punctuator && tok.Value == "}":
+			depth--
+		case tok.Kind == document.Punctuator && tok.Value == "(" && depth == 0:
+			definitions = true
+		case tok.Kind == document.Punctuator && tok.Value == ")" && depth == 0:
+			definitions = false
+		case tok.Kind == document.Name && !definitions && i > 0 && toks[i-1].Kind == document.Punctuator && toks[i-1].Value == "$":
+			names[tok.Value] = true
+		}
+	}
+	return names, nil
+}
@@ -0,0 +1,118 @@
+package graphql_test
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/dbmedialab/go-graphql-client"
+)
+
+func TestWithUnusedVariables(t *testing.T) {
+	var got []graphql.Request
+	transport := graphql.TransportFunc(func(ctx context.Context, req graphql.Request) (*graphql.Response, error) {
+		got = append(got, req)
+		return &graphql.Response{Data: []byte(`{"node": {"id": "1"}}`)}, nil
+	})
+	var q struct {
+		Node struct {
+			ID graphql.ID
+		} `graphql:"node(id: $id)"`
+	}
+	variables := map[string]interface{}{
+		"id":    graphql.ID("1"),
+		"first": graphql.Int(10),
+		"after": graphql.String("x"),
+	}
+
+	client := graphql.NewPluggableClient(transport, graphql.WithUnusedVariables(graphql.RejectUnusedVariables))
+	err := client.Query(context.Background(), &q, variables)
+	if got, want := err, "graphql: unused variables: $after, $first"; got == nil || got.Error() != want {
+		t.Errorf("got error: %v, want: %v", got, want)
+	}
+	err = client.QueryCustom(context.Background(), &q, `query($id:ID!){node(id:$id){id}}`, variables)
+	if got, want := err, "graphql: unused variables: $after, $first"; got == nil || got.Error() != want {
+		t.Errorf("got error: %v, want: %v", got, want)
+	}
+	if len(got) != 0 {
+		t.Errorf("got %d requests sent, want none", len(got))
+	}
+
+	var warnings []string
+	warn := func(req graphql.Request, unused []string) error {
+		warnings = append(warnings, fmt.Sprint(unused))
+		return nil
+	}
+	client = graphql.NewPluggableClient(transport, graphql.WithUnusedVariables(warn), graphql.StringsAsIDs())
+	if err := client.Query(context.Background(), &q, variables); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.QueryCustom(context.Background(), &q, `query($id:ID!){node(id:$id){id}}`, variables); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"[after first]", "[after first]"}; !reflect.DeepEqual(warnings, want) {
+		t.Errorf("got warnings: %v, want: %v", warnings, want)
+	}
+	want := []graphql.Request{
+		{Query: `query($id:ID!){node(id: $id){id}}`, Variables: map[string]interface{}{"id": graphql.ID("1")}},
+		{Query: `query($id:ID!){node(id:$id){id}}`, Variables: map[string]interface{}{"id": graphql.ID("1")}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got requests:\n%v\nwant:\n%v", got, want)
+	}
+}
+
+func TestWithVariableChecks(t *testing.T) {
+	sent := 0
+	transport := graphql.TransportFunc(func(ctx context.Context, req graphql.Request) (*graphql.Response, error) {
+		sent++
+		return &graphql.Response{Data: []byte(`{"search": {"count": 1}}`)}, nil
+	})
+	var q struct {
+		Search struct {
+			Count graphql.Int
+		}
+	}
+	query := `query Search($query: String!, $first: Int! = 10, $after: String, $type: SearchType!) {
+		search(query: $query, first: $first, after: $after, type: $type) { count }
+	}`
+	client := graphql.NewPluggableClient(transport, graphql.WithVariableChecks())
+
+	tests := []struct {
+		variables map[string]interface{}
+		want      string
+	}{
+		{
+			variables: map[string]interface{}{"query": graphql.String("go"), "type": graphql.String("REPOSITORY")},
+		},
+		{
+			variables: map[string]interface{}{"query": (*graphql.String)(nil), "first": graphql.Int(5), "owner": graphql.String("golang"), "limit": 1},
+			want:      "graphql: query Search: required variables not given: $query, $type; variables not defined: $limit, $owner",
+		},
+		{
+			variables: map[string]interface{}{"type": graphql.String("USER"), "after": nil},
+			want:      "graphql: query Search: required variables not given: $query",
+		},
+	}
+	for _, tc := range tests {
+		err := client.QueryCustom(context.Background(), &q, query, tc.variables)
+		if tc.want == "" {
+			if err != nil {
+				t.Errorf("%v: %v", tc.variables, err)
+			}
+			continue
+		}
+		if err == nil || err.Error() != tc.want {
+			t.Errorf("got error: %v, want: %v", err, tc.want)
+		}
+	}
+	if sent != 1 {
+		t.Errorf("got %d requests sent, want 1", sent)
+	}
+
+	err := client.MutateCustom(context.Background(), &q, `mutation { like(id: $id) }`, map[string]interface{}{"id": graphql.ID("1")})
+	if got, want := err, "graphql: mutation: variables not defined: $id"; got == nil || got.Error() != want {
+		t.Errorf("got error: %v, want: %v", got, want)
+	}
+}
@@ -0,0 +1,114 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// GraphQLTyper is implemented by types of variables whose GraphQL type isn't
+// named like their Go type, such as a custom server scalar:
+//
+//	type Slug string
+//
+//	func (Slug) GraphQLType() string { return "Slug" }
+//
+// GraphQLType returns the name of the type. As with other types, variables
+// of the type are declared non-null ("Slug!") and pointers to it nullable
+// ("Slug"). It's called on the zero value of the type.
+type GraphQLTyper interface {
+	GraphQLType() string
+}
+
+var graphQLTyper = reflect.TypeOf((*GraphQLTyper)(nil)).Elem()
+
+// WithType returns value as a variable to be declared as being of GraphQL
+// type typ, such as "Slug!" or "[EmailAddress!]", whatever the Go type of
+// value. It's sent as value would be.
+func WithType(typ string, value interface{}) interface{} {
+	return typedValue{typ: typ, value: value}
+}
+
+// typedValue is a variable value with an explicit GraphQL type.
+type typedValue struct {
+	typ   string
+	value interface{}
+}
+
+// MarshalJSON encodes v as its value.
+func (v typedValue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.value)
+}
+
+// VariablesFrom returns the fields of struct v, or of the struct v points
+// to, as variables for Query, Mutate and their Custom variants, so that
+// variables can be given with the types checked by the compiler instead of
+// in a map:
+//
+//	client.Query(ctx, &q, graphql.VariablesFrom(struct {
+//		Login String
+//		First *Int   `graphql:"first,omitempty"`
+//		ID    string `graphql:"id" graphql-type:"ID!"`
+//	}{Login: "gopher", ID: id}))
+//
+// A field's variable is named by its graphql tag, or else by the field's
+// name in lowerCamelCase, and declared with a type derived from the field's
+// Go type, as would be for a value in a map; a graphql-type tag gives the
+// type explicitly, as WithType does. With the omitempty option, fields
+// holding the zero value of their type are left out. The fields of embedded
+// structs without a graphql tag are variables too. Fields tagged
+// `graphql:"-"` and unexported fields are skipped.
+//
+// VariablesFrom panics if v isn't a struct or a pointer to one.
+func VariablesFrom(v interface{}) map[string]interface{} {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("graphql: variables must be a struct, not %T", v))
+	}
+	variables := map[string]interface{}{}
+	addVariables(variables, rv)
+	return variables
+}
+
+// addVariables adds the fields of struct v to variables.
+func addVariables(variables map[string]interface{}, v reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, tagged := f.Tag.Lookup("graphql")
+		if ft := f.Type; f.Anonymous && !tagged && (ft.Kind() == reflect.Struct || ft.Kind() == reflect.Ptr && ft.Elem().Kind() == reflect.Struct) {
+			if fv := reflect.Indirect(v.Field(i)); fv.IsValid() {
+				addVariables(variables, fv)
+			}
+			continue
+		}
+		if f.PkgPath != "" || tag == "-" {
+			continue
+		}
+		name, opts := tag, ""
+		if i := strings.Index(tag, ","); i != -1 {
+			name, opts = tag[:i], tag[i+1:]
+		}
+		if name == "" {
+			name = LowerCamelCase(f.Name)
+		}
+		value := v.Field(i)
+		if opts == "omitempty" && isZero(value) {
+			continue
+		}
+		if typ, ok := f.Tag.Lookup("graphql-type"); ok {
+			variables[name] = WithType(typ, value.Interface())
+		} else {
+			variables[name] = value.Interface()
+		}
+	}
+}
+
+// isZero reports whether v holds the zero value of its type.
+func isZero(v reflect.Value) bool {
+	return reflect.DeepEqual(v.Interface(), reflect.Zero(v.Type()).Interface())
+}
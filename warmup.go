@@ -0,0 +1,16 @@
+package graphql
+
+import "context"
+
+// Warmup prepares c for its first real operation by executing a trivial
+// query, "{__typename}", which every GraphQL server supports. This resolves
+// the server's address and sets up a connection (and TLS session) that
+// later operations can reuse, moving that latency out of the first request.
+// It's meant to be called during startup; its error, if any, says the
+// server is unreachable or misbehaving.
+func (c *Client) Warmup(ctx context.Context) error {
+	var q struct {
+		Typename String `graphql:"__typename"`
+	}
+	return c.do(ctx, &q, "{__typename}", nil)
+}
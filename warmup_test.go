@@ -0,0 +1,30 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/dbmedialab/go-graphql-client"
+)
+
+func TestClient_Warmup(t *testing.T) {
+	var gotQuery string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		var in struct{ Query string }
+		json.NewDecoder(req.Body).Decode(&in)
+		gotQuery = in.Query
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": {"__typename": "Query"}}`)
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}})
+
+	if err := client.Warmup(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := gotQuery, "{__typename}"; got != want {
+		t.Errorf("got query: %q, want: %q", got, want)
+	}
+}
@@ -0,0 +1,438 @@
+package graphql
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dbmedialab/go-graphql-client/internal/websocket"
+)
+
+var (
+	_ Transport             = TransportWS{}
+	_ SubscriptionTransport = TransportWS{}
+)
+
+// The WebSocket sub-protocols of GraphQL that TransportWS speaks.
+const (
+	// ProtocolGraphQLWS is the legacy protocol of Apollo's
+	// subscriptions-transport-ws.
+	//
+	// Protocol: https://github.com/apollographql/subscriptions-transport-ws/blob/master/PROTOCOL.md.
+	ProtocolGraphQLWS = "graphql-ws"
+
+	// ProtocolGraphQLTransportWS is the protocol of the graphql-ws
+	// library, which newer servers, such as GraphQL Yoga and Mercurius,
+	// speak instead.
+	//
+	// Protocol: https://github.com/enisdenjo/graphql-ws/blob/master/PROTOCOL.md.
+	ProtocolGraphQLTransportWS = "graphql-transport-ws"
+)
+
+// TransportWS executes operations over WebSocket connections, speaking
+// either of the GraphQL sub-protocols, ProtocolGraphQLWS or
+// ProtocolGraphQLTransportWS. Each operation has a connection of its own.
+//
+// It's a SubscriptionTransport, for Client.Subscribe, and a Transport, so a
+// client made with NewPluggableClient can send its queries and mutations
+// over WebSockets too.
+type TransportWS struct {
+	URL string // GraphQL server URL, ws:// or wss://.
+
+	// Protocol is the sub-protocol to speak. If empty, both are offered,
+	// and the one the server chooses is spoken; ProtocolGraphQLWS if it
+	// doesn't say.
+	Protocol string
+
+	// Header holds HTTP headers to send with the handshake, along with
+	// those of each request.
+	Header http.Header
+
+	// ConnectionParams is the payload of the connection_init message,
+	// which servers commonly read credentials from.
+	ConnectionParams map[string]interface{}
+
+	// TLSConfig configures wss:// connections. If nil, the default
+	// configuration is used.
+	TLSConfig *tls.Config
+
+	// Reconnect, if not nil, makes subscriptions whose connections drop
+	// reconnect, initialize the connection again, and restart, rather
+	// than fail. Results the server sent while they were down are lost.
+	Reconnect *ReconnectPolicy
+}
+
+// ReconnectPolicy says how a TransportWS reconnects subscriptions whose
+// connections drop. Connections the server closes normally, or for a
+// problem with the client, with status 1002, 1003, or 4400 to 4499, such
+// as 4401 Unauthorized, aren't reconnected.
+type ReconnectPolicy struct {
+	// MaxAttempts is how many times reconnecting may be attempted in a
+	// row before the subscription fails. Zero means no limit.
+	MaxAttempts int
+
+	// Backoff is how long to wait before the first attempt. It's doubled
+	// for each attempt after that, up to MaxBackoff. If zero, a second is
+	// used.
+	Backoff time.Duration
+
+	// MaxBackoff is the longest wait between attempts. If zero, a minute
+	// is used.
+	MaxBackoff time.Duration
+
+	// Clock waits between attempts if it's a SleepClock. If nil, the
+	// system clock is used.
+	Clock Clock
+}
+
+// Reconnect is returned by the Next method of a ResponseStream whose
+// connection dropped, and was reconnected, for Client.Subscribe to report
+// as a SubscriptionResult. Next may be called again.
+//
+// Attempts that fail as a dropped connection does are retried; those the
+// server refuses, such as with 4401 Unauthorized, fail the subscription.
+type Reconnect struct {
+	// Err is what the connection dropped with.
+	Err error
+	// Attempts is how many attempts reconnecting took.
+	Attempts int
+}
+
+func (r *Reconnect) Error() string {
+	return fmt.Sprintf("graphql: reconnected after %d attempts: %v", r.Attempts, r.Err)
+}
+
+// Do executes req, returning its first result.
+func (t TransportWS) Do(ctx context.Context, req Request) (*Response, error) {
+	stream, err := t.Subscribe(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+	out, err := stream.Next()
+	if e, ok := err.(errors); ok {
+		// The operation failed, as it can over HTTP, without data.
+		return &Response{Data: json.RawMessage("null"), Errors: e}, nil
+	}
+	if err == io.EOF {
+		return nil, errNoResult
+	}
+	return out, err
+}
+
+// Subscribe connects to the server and starts the subscription req.
+func (t TransportWS) Subscribe(ctx context.Context, req Request) (ResponseStream, error) {
+	if t.Reconnect == nil {
+		return t.open(ctx, req)
+	}
+	// Closing the stream stops it reconnecting.
+	ctx, cancel := context.WithCancel(ctx)
+	s, err := t.open(ctx, req)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	return &reconnectingStream{t: t, ctx: ctx, cancel: cancel, req: req, s: s}, nil
+}
+
+// open connects to the server and starts req.
+func (t TransportWS) open(ctx context.Context, req Request) (*wsStream, error) {
+	header := make(http.Header, len(t.Header)+len(req.Header))
+	for k, v := range t.Header {
+		header[k] = v
+	}
+	for k, v := range req.Header {
+		header[k] = v
+	}
+	protocols := []string{ProtocolGraphQLTransportWS, ProtocolGraphQLWS}
+	if t.Protocol != "" {
+		protocols = []string{t.Protocol}
+	}
+	conn, err := websocket.Dial(ctx, t.URL, header, protocols, t.TLSConfig)
+	if err != nil {
+		return nil, err
+	}
+	s := &wsStream{ctx: ctx, conn: conn, id: "1", done: make(chan struct{})}
+	s.transportWS = conn.Protocol() == ProtocolGraphQLTransportWS
+	go s.watch()
+	if err := s.init(t.ConnectionParams); err != nil {
+		s.Close()
+		return nil, s.err(err)
+	}
+	start := "start"
+	if s.transportWS {
+		start = "subscribe"
+	}
+	if err := s.send(start, req); err != nil {
+		s.Close()
+		return nil, s.err(err)
+	}
+	return s, nil
+}
+
+// wsMessage is a message of either protocol.
+type wsMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// wsStream is the stream of results of an operation over a WebSocket
+// connection.
+type wsStream struct {
+	ctx  context.Context
+	conn *websocket.Conn
+	id   string // Of the operation.
+
+	// transportWS says the protocol is ProtocolGraphQLTransportWS, not
+	// ProtocolGraphQLWS.
+	transportWS bool
+
+	completed int32 // Set to 1 once the server has completed the operation.
+
+	once sync.Once
+	done chan struct{} // Closed by Close.
+}
+
+// watch stops the operation once its context is done.
+func (s *wsStream) watch() {
+	select {
+	case <-s.ctx.Done():
+		s.Close()
+	case <-s.done:
+	}
+}
+
+// init initializes the connection, with params as the payload.
+func (s *wsStream) init(params map[string]interface{}) error {
+	var payload interface{}
+	if params != nil {
+		payload = params
+	}
+	if err := s.send("connection_init", payload); err != nil {
+		return err
+	}
+	for {
+		msg, err := s.read()
+		if err != nil {
+			return err
+		}
+		switch msg.Type {
+		case "connection_ack":
+			return nil
+		case "ka":
+		case "ping":
+			if err := s.send("pong", nil); err != nil {
+				return err
+			}
+		case "connection_error":
+			return fmt.Errorf("graphql: server refused the connection: %s", msg.Payload)
+		default:
+			return fmt.Errorf("graphql: unexpected %q message before connection_ack", msg.Type)
+		}
+	}
+}
+
+// Next returns the operation's next result. If the server reports an error
+// with the operation, the errors are returned, as an error.
+func (s *wsStream) Next() (*Response, error) {
+	for {
+		msg, err := s.read()
+		if err != nil {
+			return nil, s.err(err)
+		}
+		if msg.ID != "" && msg.ID != s.id {
+			continue
+		}
+		switch msg.Type {
+		case "data", "next":
+			var out Response
+			if err := json.Unmarshal(msg.Payload, &out); err != nil {
+				return nil, err
+			}
+			return &out, nil
+		case "error":
+			return nil, wsErrors(msg.Payload)
+		case "complete":
+			atomic.StoreInt32(&s.completed, 1)
+			return nil, io.EOF
+		case "ping":
+			if err := s.send("pong", nil); err != nil {
+				return nil, s.err(err)
+			}
+		case "connection_error":
+			return nil, fmt.Errorf("graphql: server closed the connection: %s", msg.Payload)
+		}
+	}
+}
+
+// wsErrors returns the payload of an error message, an error or a list of
+// them, as errors.
+func wsErrors(payload json.RawMessage) error {
+	var errs errors
+	if json.Unmarshal(payload, &errs) == nil && len(errs) > 0 {
+		return errs
+	}
+	errs = make(errors, 1)
+	if json.Unmarshal(payload, &errs[0]) != nil || errs[0].Message == "" {
+		return fmt.Errorf("graphql: operation failed: %s", payload)
+	}
+	return errs
+}
+
+// Close stops the operation and closes the connection.
+func (s *wsStream) Close() error {
+	var err error
+	s.once.Do(func() {
+		close(s.done)
+		if atomic.LoadInt32(&s.completed) == 0 {
+			// Stop the operation, unless the server has completed it.
+			if s.transportWS {
+				s.send("complete", nil)
+			} else {
+				s.send("stop", nil)
+			}
+		}
+		if !s.transportWS {
+			// graphql-transport-ws has no message ending the
+			// connection; it's just closed.
+			s.send("connection_terminate", nil)
+		}
+		err = s.conn.Close()
+	})
+	return err
+}
+
+func (s *wsStream) send(typ string, payload interface{}) error {
+	msg := wsMessage{Type: typ}
+	switch typ {
+	case "start", "stop", "subscribe", "complete":
+		msg.ID = s.id
+	}
+	if payload != nil {
+		b, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		msg.Payload = b
+	}
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return s.conn.WriteMessage(b)
+}
+
+func (s *wsStream) read() (wsMessage, error) {
+	var msg wsMessage
+	b, err := s.conn.ReadMessage()
+	if err == io.EOF {
+		// Not to be mistaken for the operation completing.
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return msg, err
+	}
+	err = json.Unmarshal(b, &msg)
+	return msg, err
+}
+
+// err returns the context's error, if it's done, since that's why the
+// connection failed; err otherwise.
+func (s *wsStream) err(err error) error {
+	if e := s.ctx.Err(); e != nil {
+		return e
+	}
+	return err
+}
+
+// reconnectingStream is a stream of a TransportWS that reconnects when its
+// connection drops.
+type reconnectingStream struct {
+	t      TransportWS
+	ctx    context.Context // Canceled by Close.
+	cancel context.CancelFunc
+	req    Request
+
+	mu     sync.Mutex // Of s and closed, since Close may be called while reconnecting.
+	s      *wsStream
+	closed bool
+}
+
+func (r *reconnectingStream) Next() (*Response, error) {
+	r.mu.Lock()
+	s := r.s
+	r.mu.Unlock()
+	out, err := s.Next()
+	if err == nil || r.ctx.Err() != nil || !reconnectable(err) {
+		return out, err
+	}
+	s.Close()
+	p := r.t.Reconnect
+	clock := clockOrSystem(p.Clock)
+	backoff, max := p.Backoff, p.MaxBackoff
+	if backoff == 0 {
+		backoff = time.Second
+	}
+	if max == 0 {
+		max = time.Minute
+	}
+	for attempt := 1; ; attempt++ {
+		if !sleep(r.ctx, clock, backoff) {
+			return nil, r.ctx.Err()
+		}
+		s, openErr := r.t.open(r.ctx, r.req)
+		if openErr == nil {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			if r.closed {
+				s.Close()
+				return nil, r.ctx.Err()
+			}
+			r.s = s
+			return nil, &Reconnect{Err: err, Attempts: attempt}
+		}
+		if r.ctx.Err() != nil || !reconnectable(openErr) || p.MaxAttempts > 0 && attempt >= p.MaxAttempts {
+			return nil, openErr
+		}
+		if backoff *= 2; backoff > max {
+			backoff = max
+		}
+	}
+}
+
+// Close stops the subscription, and any reconnecting in progress.
+func (r *reconnectingStream) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closed = true
+	r.cancel()
+	return r.s.Close()
+}
+
+// reconnectable reports whether a subscription whose connection failed with
+// err is worth reconnecting: if the connection dropped, or the server closed
+// or refused it other than normally or for a problem with the client.
+func reconnectable(err error) bool {
+	if e, ok := err.(*websocket.HandshakeError); ok {
+		return e.StatusCode >= 500 || e.StatusCode == http.StatusTooManyRequests
+	}
+	if e, ok := err.(*websocket.CloseError); ok {
+		switch {
+		case e.Code == websocket.CloseNormal, e.Code == 1002, e.Code == 1003:
+			return false
+		case e.Code >= 4400 && e.Code < 4500:
+			return false
+		}
+		return true
+	}
+	_, isNet := err.(net.Error)
+	return isNet || err == io.ErrUnexpectedEOF
+}